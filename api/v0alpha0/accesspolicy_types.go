@@ -25,14 +25,23 @@ import (
 )
 
 // AccessPolicySpec defines the desired state of AccessPolicy.
+// +kubebuilder:validation:XValidation:message="rules of an AccessPolicy in 'Audit' mode cannot specify 'ExternalAuth' authorization type",rule="has(self.mode) && self.mode == 'Audit' ? self.rules.all(r, !(has(r.authorization) && r.authorization.type == 'ExternalAuth')) : true"
 type AccessPolicySpec struct {
 	// TargetRefs specifies the targets of the AccessPolicy.
 	// An AccessPolicy must target at least one resource.
+	//
+	// An AccessPolicy targets either XBackends or Gateways. The rules of AccessPolicies targeting a Gateway
+	// are evaluated before the rules of AccessPolicies targeting the XBackends behind it, and a request
+	// must be allowed by all of them. SectionName can be used to target individual listeners of a Gateway.
+	// Plain HTTP listeners sharing a port are told apart by hostname: the rules of an AccessPolicy
+	// targeting one of them apply to the requests for the hostnames of the routes attached to it.
+	//
 	// +required
 	// +kubebuilder:validation:MinItems=1
 	// +kubebuilder:validation:MaxItems=10
 	// +listType=atomic
-	// +kubebuilder:validation:XValidation:rule="self.all(x, x.group == 'agentic.prototype.x-k8s.io' && x.kind == 'XBackend')",message="TargetRef must have group agentic.prototype.x-k8s.io group and kind XBackend"
+	// +kubebuilder:validation:XValidation:rule="self.all(x, (x.group == 'agentic.prototype.x-k8s.io' && x.kind == 'XBackend') || (x.group == 'gateway.networking.k8s.io' && x.kind == 'Gateway'))",message="TargetRef must have group agentic.prototype.x-k8s.io and kind XBackend, or group gateway.networking.k8s.io and kind Gateway"
+	// +kubebuilder:validation:XValidation:rule="self.all(x, x.kind == self[0].kind)",message="All targetRefs must have the same Kind"
 	TargetRefs []gwapiv1.LocalPolicyTargetReferenceWithSectionName `json:"targetRefs"`
 	// Rules defines a list of rules to be applied to the target.
 	// An AccessPolicy must have at least one rule.
@@ -41,12 +50,75 @@ type AccessPolicySpec struct {
 	// +kubebuilder:validation:MaxItems=10
 	// +listType=atomic
 	// +kubebuilder:validation:XValidation:rule="self.all(r, self.filter(x, x.name == r.name).size() == 1)",message="AccessRule names must be unique"
-	// +kubebuilder:validation:XValidation:rule="self.filter(r, has(r.authorization) && r.authorization.type == 'ExternalAuth').size() <= 1",message="a maximum of one rule per policy can specify 'ExternalAuth' authorization type"
 	Rules []AccessRule `json:"rules"`
+	// DefaultAllowances toggles the requests that are allowed for any source, in addition to the rules,
+	// in order for agents to be able to use the targeted backends.
+	//
+	// When an allowance is disabled, it only applies to the sources of the Allow rules of the AccessPolicy.
+	// When multiple AccessPolicies target the same backend, an allowance is disabled if any of them
+	// disables it.
+	//
+	// +optional
+	DefaultAllowances *DefaultAllowances `json:"defaultAllowances,omitempty"`
+	// Mode specifies whether the AccessPolicy is enforced or only audited.
+	//
+	// In Audit mode, the rules of the AccessPolicy are evaluated but never deny a request. Instead,
+	// the decision they would have made is recorded in the access log of the Gateway, so that the
+	// AccessPolicy can be observed before it is enforced. The rules of AccessPolicies in Audit mode
+	// targeting a backend are evaluated together, separately from the rules of the enforced ones.
+	//
+	// Defaults to Enforce.
+	//
+	// +optional
+	// +kubebuilder:default=Enforce
+	Mode AccessPolicyMode `json:"mode,omitempty"`
+}
+
+// AccessPolicyMode specifies how the rules of an AccessPolicy are applied.
+// +kubebuilder:validation:Enum=Enforce;Audit
+type AccessPolicyMode string
+
+const (
+	// AccessPolicyModeEnforce is used to allow and deny requests according to the rules.
+	AccessPolicyModeEnforce AccessPolicyMode = "Enforce"
+
+	// AccessPolicyModeAudit is used to only log the decisions of the rules, without enforcing them.
+	AccessPolicyModeAudit AccessPolicyMode = "Audit"
+)
+
+// DefaultAllowances specifies which requests are allowed for any source of a targeted backend.
+type DefaultAllowances struct {
+	// InitializeAndListTools allows any source to initialize MCP sessions and list the available tools.
+	//
+	// Defaults to true.
+	//
+	// +optional
+	// +kubebuilder:default=true
+	InitializeAndListTools *bool `json:"initializeAndListTools,omitempty"`
+
+	// CloseSession allows any source to close MCP sessions with an HTTP DELETE request.
+	//
+	// Defaults to true.
+	//
+	// +optional
+	// +kubebuilder:default=true
+	CloseSession *bool `json:"closeSession,omitempty"`
+
+	// HTTPGet allows any source to open an SSE stream with an HTTP GET request to the MCP endpoint.
+	//
+	// Defaults to true.
+	//
+	// +optional
+	// +kubebuilder:default=true
+	HTTPGet *bool `json:"httpGet,omitempty"`
 }
 
 // AccessRule specifies an authorization rule for the targeted backend.
-// If the tool list is empty, the rule denies access to all tools from Source.
+// If the tool list is empty, the rule denies access to all tools from its sources.
+// +kubebuilder:validation:XValidation:message="rules with action 'Deny' cannot specify 'ExternalAuth' authorization type",rule="has(self.action) && self.action == 'Deny' ? !(has(self.authorization) && self.authorization.type == 'ExternalAuth') : true"
+// +kubebuilder:validation:XValidation:message="exactly one of source or sources must be specified",rule="has(self.source) != has(self.sources)"
+// +kubebuilder:validation:XValidation:message="rules with 'CEL' authorization type cannot combine an 'OIDC' source with other sources",rule="has(self.sources) && self.sources.size() > 1 && has(self.authorization) && self.authorization.type == 'CEL' ? !self.sources.exists(s, s.type == 'OIDC') : true"
+// +kubebuilder:validation:XValidation:message="notBefore must be before notAfter",rule="has(self.notBefore) && has(self.notAfter) ? self.notBefore < self.notAfter : true"
 type AccessRule struct {
 	// Name specifies the name of the rule.
 	// +required
@@ -55,17 +127,113 @@ type AccessRule struct {
 	// +kubebuilder:validation:MaxLength=253
 	Name string `json:"name"`
 	// Source specifies the source of the request.
-	// +required
-	Source Source `json:"source"`
+	// Exactly one of Source or Sources must be specified.
+	// +optional
+	Source *Source `json:"source,omitempty"`
+	// Sources specifies several sources of the request. The rule matches the requests from any of
+	// them, so that the same authorization can be granted to several identities with a single rule.
+	//
+	// The identity of a CEL rule depends on the type of its source, so the sources of a CEL rule
+	// cannot combine an OIDC source with other sources.
+	//
+	// Exactly one of Source or Sources must be specified.
+	// +optional
+	// +listType=atomic
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=16
+	Sources []Source `json:"sources,omitempty"`
 	// Authorization specifies the authorization rule to be applied to requests from the source.
 	// +optional
 	Authorization *AuthorizationRule `json:"authorization,omitempty"`
+	// Action specifies whether requests from the source matching the authorization rule
+	// are allowed or denied.
+	//
+	// Deny rules are evaluated before Allow rules. A request that matches a Deny rule is
+	// rejected even if it also matches an Allow rule. A Deny rule without authorization
+	// denies all tool calls from the source.
+	//
+	// An AccessPolicy with Allow rules only allows the requests matching them, along with the
+	// requests allowed by default (see DefaultAllowances). An AccessPolicy with only Deny rules
+	// does not restrict the requests they do not match, whether it targets an XBackend or a
+	// Gateway: e.g. a single Deny rule for the tool delete_repo lets everyone call any other tool.
+	//
+	// Defaults to Allow.
+	//
+	// +optional
+	// +kubebuilder:default=Allow
+	Action AccessRuleAction `json:"action,omitempty"`
+	// NotBefore is the time from which the rule is in force. Before it, an Allow rule authorizes
+	// no request and a Deny rule denies none.
+	// +optional
+	NotBefore *metav1.Time `json:"notBefore,omitempty"`
+	// NotAfter is the time from which the rule is no longer in force, e.g. the end of a break-glass
+	// access granted to an on-call agent.
+	// +optional
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+	// Windows restricts the rule to recurring time windows. The rule is in force during any of
+	// them, between NotBefore and NotAfter if specified. Without windows, the rule is in force
+	// at any time between NotBefore and NotAfter.
+	// +optional
+	// +listType=atomic
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=8
+	Windows []AccessRuleTimeWindow `json:"windows,omitempty"`
+}
+
+// AccessRuleTimeWindow specifies a recurring time window during which an AccessRule is in force.
+type AccessRuleTimeWindow struct {
+	// Days specifies the days of the week on which the window opens.
+	// Defaults to every day.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=7
+	Days []Weekday `json:"days,omitempty"`
+	// Start is the time of day at which the window opens, in the HH:MM format.
+	// +required
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+	// End is the time of day at which the window closes, in the HH:MM format. If it is not
+	// after Start, the window closes on the next day, e.g. a window from 22:00 to 06:00 spans
+	// the night.
+	// +required
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+	// TimeZone is the IANA name of the time zone of Start and End, such as "Europe/Paris".
+	// A rule with an unknown time zone is invalid.
+	// Defaults to UTC.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=64
+	TimeZone *string `json:"timeZone,omitempty"`
 }
 
+// Weekday is a day of the week.
+// +kubebuilder:validation:Enum=Monday;Tuesday;Wednesday;Thursday;Friday;Saturday;Sunday
+type Weekday string
+
+// AccessRuleAction specifies the action taken on requests matching an AccessRule.
+// +kubebuilder:validation:Enum=Allow;Deny
+type AccessRuleAction string
+
+const (
+	// AccessRuleActionAllow is used to allow requests matching the rule.
+	AccessRuleActionAllow AccessRuleAction = "Allow"
+
+	// AccessRuleActionDeny is used to deny requests matching the rule.
+	AccessRuleActionDeny AccessRuleAction = "Deny"
+)
+
 // Source specifies the source of a request.
 //
 // Type must be set to indicate the type of source type.
-// Similarly, either SPIFFE or Serviceaccount can be set based on the type.
+// Similarly, either SPIFFE, Serviceaccount, OIDC, Namespace or NamespaceSelector can be set based on the type.
+// +kubebuilder:validation:XValidation:message="oidc must be specified when type is set to 'OIDC'",rule="self.type == 'OIDC' ? has(self.oidc) : true"
+// +kubebuilder:validation:XValidation:message="oidc can only be specified when type is set to 'OIDC'",rule="has(self.oidc) ? self.type == 'OIDC' : true"
+// +kubebuilder:validation:XValidation:message="namespace must be specified when type is set to 'Namespace'",rule="self.type == 'Namespace' ? has(self.namespace) : true"
+// +kubebuilder:validation:XValidation:message="namespace can only be specified when type is set to 'Namespace'",rule="has(self.namespace) ? self.type == 'Namespace' : true"
+// +kubebuilder:validation:XValidation:message="namespaceSelector must be specified when type is set to 'NamespaceSelector'",rule="self.type == 'NamespaceSelector' ? has(self.namespaceSelector) : true"
+// +kubebuilder:validation:XValidation:message="namespaceSelector can only be specified when type is set to 'NamespaceSelector'",rule="has(self.namespaceSelector) ? self.type == 'NamespaceSelector' : true"
 type Source struct {
 	// +unionDiscriminator
 	// +required
@@ -78,6 +246,10 @@ type Source struct {
 	//
 	// The exact workload identifier structure is implementation-specific.
 	//
+	// A spiffe identity ending with `/*` matches all the identities starting with it, e.g.
+	// `spiffe://cluster.local/ns/team-a/*` matches all the workloads of the namespace team-a,
+	// and `spiffe://example.org/*` all the workloads of the trust domain example.org.
+	//
 	// spiffe identities for authorization can be derived in various ways by the underlying
 	// implementation. Common methods include:
 	// - From peer mTLS certificates: The identity is extracted from the client's
@@ -104,10 +276,29 @@ type Source struct {
 	// instead be expressed using the `SPIFFE` field.
 	// +optional
 	ServiceAccount *AuthorizationSourceServiceAccount `json:"serviceAccount,omitempty"`
+
+	// OIDC specifies a trusted OpenID Connect (OIDC) issuer that is matched by this rule.
+	// A request matches the rule if it carries a JSON Web Token (JWT) issued by the issuer
+	// in the Authorization header, and the token can be verified with the issuer's keys.
+	// +optional
+	OIDC *AuthorizationSourceOIDC `json:"oidc,omitempty"`
+
+	// Namespace specifies a Kubernetes namespace that is matched by this rule. A request
+	// originating from a pod associated with any ServiceAccount of this namespace will
+	// match the rule.
+	// +optional
+	Namespace *gwapiv1.Namespace `json:"namespace,omitempty"`
+
+	// NamespaceSelector selects the Kubernetes namespaces that are matched by this rule.
+	// A request originating from a pod associated with any ServiceAccount of a namespace
+	// whose labels match the selector will match the rule. An empty selector matches all
+	// namespaces.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
 }
 
 // AuthorizationSourceType identifies a type of source for authorization.
-// +kubebuilder:validation:Enum=ServiceAccount;SPIFFE
+// +kubebuilder:validation:Enum=ServiceAccount;SPIFFE;OIDC;Namespace;NamespaceSelector
 type AuthorizationSourceType string
 
 const (
@@ -116,9 +307,19 @@ const (
 
 	// AuthorizationSourceTypeServiceAccount is used to identify a request matches a ServiceAccount from within the cluster.
 	AuthorizationSourceTypeServiceAccount AuthorizationSourceType = "ServiceAccount"
+
+	// AuthorizationSourceTypeOIDC is used to identify a request bears a token issued by a trusted OIDC issuer.
+	AuthorizationSourceTypeOIDC AuthorizationSourceType = "OIDC"
+
+	// AuthorizationSourceTypeNamespace is used to identify a request matches any ServiceAccount of a namespace.
+	AuthorizationSourceTypeNamespace AuthorizationSourceType = "Namespace"
+
+	// AuthorizationSourceTypeNamespaceSelector is used to identify a request matches any ServiceAccount of the
+	// namespaces selected by their labels.
+	AuthorizationSourceTypeNamespaceSelector AuthorizationSourceType = "NamespaceSelector"
 )
 
-// +kubebuilder:validation:Pattern=`^spiffe://[a-z0-9._-]+(?:/[A-Za-z0-9._-]+)*$`
+// +kubebuilder:validation:Pattern=`^spiffe://[a-z0-9._-]+(?:/[A-Za-z0-9._-]+)*(?:/\*)?$`
 type AuthorizationSourceSPIFFE string
 
 type AuthorizationSourceServiceAccount struct {
@@ -132,9 +333,92 @@ type AuthorizationSourceServiceAccount struct {
 	Name string `json:"name"`
 }
 
-// +kubebuilder:validation:XValidation:message="tools must be specified when type is set to 'InlineTools'",rule="self.type == 'InlineTools' ? has(self.tools) : true"
+// AuthorizationSourceOIDC specifies a trusted OpenID Connect (OIDC) issuer.
+type AuthorizationSourceOIDC struct {
+	// Issuer is the URL of the trusted OIDC issuer.
+	// The `iss` claim of the tokens must be equal to it.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=2048
+	// +kubebuilder:validation:Pattern=`^https://`
+	Issuer string `json:"issuer"`
+
+	// Audiences is a list of acceptable audiences for the tokens.
+	// If specified, the `aud` claim of the tokens must contain at least one of them.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=16
+	Audiences []string `json:"audiences,omitempty"`
+
+	// JWKS specifies the JSON Web Key Set (JWKS) used to verify the signature of the tokens.
+	// +required
+	JWKS JWKS `json:"jwks"`
+
+	// Claims specifies claims the verified tokens must carry for the request to match the rule.
+	// A request matches if all claims match.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=16
+	Claims []JWTClaimMatch `json:"claims,omitempty"`
+}
+
+// JWKS specifies where a JSON Web Key Set is obtained from.
+// +kubebuilder:validation:ExactlyOneOf=inline;backendRef
+// +kubebuilder:validation:XValidation:message="path can only be specified when backendRef is set",rule="has(self.path) ? has(self.backendRef) : true"
+type JWKS struct {
+	// Inline specifies the JSON Web Key Set as a JSON document.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=65536
+	Inline *string `json:"inline,omitempty"`
+
+	// BackendRef references the Service or XBackend serving the JSON Web Key Set.
+	// Port must be specified when referencing a Service. A referenced XBackend must be an in-cluster
+	// backend specified by serviceName: the keys are not fetched from external hostnames, since the
+	// certificates of their servers are not verified.
+	// +optional
+	// +kubebuilder:validation:XValidation:message="backendRef must reference a Service or an XBackend",rule="(self.group == '' && self.kind == 'Service') || (self.group == 'agentic.prototype.x-k8s.io' && self.kind == 'XBackend')"
+	BackendRef *gwapiv1.BackendObjectReference `json:"backendRef,omitempty"`
+
+	// Path is the HTTP path the JSON Web Key Set is served at by the referenced backend.
+	// Defaults to /.well-known/jwks.json.
+	// +optional
+	// +kubebuilder:validation:MaxLength=1024
+	// +kubebuilder:validation:Pattern=`^/`
+	Path string `json:"path,omitempty"`
+}
+
+// JWTClaimMatch specifies a claim a verified token must carry.
+type JWTClaimMatch struct {
+	// Name is the name of a top-level claim of the token, e.g. `sub` or `groups`.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	Name string `json:"name"`
+
+	// Values is the list of accepted values of the claim. A string claim matches if it is equal
+	// to one of the values, and a list claim matches if it contains one of the values.
+	// +required
+	// +listType=set
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=64
+	Values []string `json:"values"`
+}
+
+// +kubebuilder:validation:XValidation:message="tools or toolPatterns must be specified when type is set to 'InlineTools'",rule="self.type == 'InlineTools' ? (has(self.tools) || has(self.toolPatterns)) : true"
 // +kubebuilder:validation:XValidation:message="externalAuth must be specified when type is set to 'ExternalAuth'",rule="self.type == 'ExternalAuth' ? has(self.externalAuth) : true"
 // +kubebuilder:validation:XValidation:message="only one of tools or externalAuth can be specified",rule="!(has(self.tools) && has(self.externalAuth))"
+// +kubebuilder:validation:XValidation:message="only one of toolPatterns or externalAuth can be specified",rule="!(has(self.toolPatterns) && has(self.externalAuth))"
+// +kubebuilder:validation:XValidation:message="arguments can only be specified when type is set to 'InlineTools'",rule="has(self.arguments) ? self.type == 'InlineTools' : true"
+// +kubebuilder:validation:XValidation:message="resources must be specified when type is set to 'InlineResources'",rule="self.type == 'InlineResources' ? has(self.resources) : true"
+// +kubebuilder:validation:XValidation:message="resources can only be specified when type is set to 'InlineResources'",rule="has(self.resources) ? self.type == 'InlineResources' : true"
+// +kubebuilder:validation:XValidation:message="prompts must be specified when type is set to 'InlinePrompts'",rule="self.type == 'InlinePrompts' ? has(self.prompts) : true"
+// +kubebuilder:validation:XValidation:message="prompts can only be specified when type is set to 'InlinePrompts'",rule="has(self.prompts) ? self.type == 'InlinePrompts' : true"
+// +kubebuilder:validation:XValidation:message="cel must be specified when type is set to 'CEL'",rule="self.type == 'CEL' ? has(self.cel) : true"
+// +kubebuilder:validation:XValidation:message="cel can only be specified when type is set to 'CEL'",rule="has(self.cel) ? self.type == 'CEL' : true"
+// +kubebuilder:validation:XValidation:message="externalAuthSettings can only be specified when type is set to 'ExternalAuth'",rule="has(self.externalAuthSettings) ? self.type == 'ExternalAuth' : true"
+// +kubebuilder:validation:XValidation:message="allowedClientHeaders can only be specified when the externalAuth protocol is 'HTTP'",rule="has(self.externalAuthSettings) && has(self.externalAuthSettings.allowedClientHeaders) ? has(self.externalAuth) && self.externalAuth.protocol == 'HTTP' : true"
 type AuthorizationRule struct {
 	// +unionDiscriminator
 	// +required
@@ -145,16 +429,279 @@ type AuthorizationRule struct {
 	// +optional
 	Tools []string `json:"tools,omitempty"`
 
+	// ToolPatterns specifies a list of patterns matching tool names.
+	// A tool is authorized if its name is listed in Tools or matches any of the patterns.
+	// Responses to tools/list are filtered down to the tools the caller is authorized to call,
+	// except that RegularExpression patterns cannot be evaluated there: all tools are listed to
+	// the callers authorized by a rule using them, while their calls remain restricted.
+	// +listType=atomic
+	// +kubebuilder:validation:MaxItems=16
+	// +optional
+	ToolPatterns []ToolNameMatch `json:"toolPatterns,omitempty"`
+
+	// Arguments specifies constraints on the arguments of the authorized tool calls.
+	// A tool call is authorized only if all of the constraints are satisfied.
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=16
+	// +optional
+	Arguments []ToolArgumentMatch `json:"arguments,omitempty"`
+
+	// Resources specifies a list of patterns matching the URIs of the MCP resources
+	// that can be read, subscribed to and completed.
+	// +listType=atomic
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=16
+	// +optional
+	Resources []ResourceURIMatch `json:"resources,omitempty"`
+
+	// Prompts specifies a list of names of the MCP prompts that can be retrieved and completed.
+	// +listType=set
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=64
+	// +optional
+	Prompts []string `json:"prompts,omitempty"`
+
+	// CEL specifies a Common Expression Language (CEL) expression that must evaluate to true
+	// for a request to be authorized.
+	//
+	// The expression can refer to the following variables:
+	//
+	// * `request.mcp.method`: the JSON-RPC method of the MCP request, e.g. `tools/call`.
+	// * `request.mcp.tool_name`: the name of the called tool, for `tools/call` requests.
+	// * `request.mcp.params`: the parameters of the MCP request, e.g. `request.mcp.params.arguments`.
+	// * `identity`: the identity of the source. For OIDC sources, it holds the claims of the verified
+	//   token, e.g. `identity.sub`. For other sources, `identity.sub` is the SPIFFE ID of the source.
+	//
+	// For example: `request.mcp.tool_name.startsWith("read_")`.
+	//
+	// An Allow rule with an invalid expression authorizes no request, and a Deny rule with an invalid
+	// expression denies all tool calls from the source.
+	//
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=4096
+	CEL string `json:"cel,omitempty"`
+
 	// ExternalAuth specifies an external auth filter to be used for authorization.
+	// Each rule can use its own external authorizer: a request matching several
+	// ExternalAuth rules is checked by each of their authorizers.
+	//
+	// The authorizer receives the namespace/name of the XBackend in the `xbackend`
+	// context extension and, when it checks a single rule of the XBackend, the
+	// namespace/name of the AccessPolicy and the name of the rule in the
+	// `access_policy` and `access_rule` ones. The metadata context carries the
+	// parsed MCP request, such as the tool called, under `mcp_proxy`.
 	//
 	// Support: Extended
 	//
 	// +optional
 	ExternalAuth *gwapiv1.HTTPExternalAuthFilter `json:"externalAuth,omitempty"`
+
+	// ExternalAuthSettings specifies how the external authorizer of ExternalAuth is called.
+	//
+	// Support: Extended
+	//
+	// +optional
+	ExternalAuthSettings *ExternalAuthSettings `json:"externalAuthSettings,omitempty"`
+}
+
+// ExternalAuthSettings specifies how an external authorizer is called.
+type ExternalAuthSettings struct {
+	// FailureMode specifies whether requests are allowed or denied when the external
+	// authorizer cannot be reached, times out or returns an error. Fail-open is meant
+	// for non-critical authorizers, such as the ones only auditing requests.
+	//
+	// Defaults to Deny.
+	//
+	// +optional
+	FailureMode *ExternalAuthFailureMode `json:"failureMode,omitempty"`
+
+	// Timeout is the maximum time to wait for a decision of the external authorizer,
+	// e.g. `30s` for guardrail checks backed by an LLM.
+	//
+	// Defaults to 200ms for the GRPC protocol and to 5s for the HTTP protocol.
+	//
+	// +optional
+	Timeout *gwapiv1.Duration `json:"timeout,omitempty"`
+
+	// StatusOnError is the HTTP status code returned to the client when the external
+	// authorizer cannot be reached, times out or returns an error, and FailureMode is
+	// Deny.
+	//
+	// Defaults to 403.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=400
+	// +kubebuilder:validation:Maximum=599
+	StatusOnError *int32 `json:"statusOnError,omitempty"`
+
+	// IncludePeerCertificate specifies whether the client certificate, which carries
+	// the SPIFFE ID of the source, is sent to the external authorizer.
+	//
+	// Defaults to false.
+	//
+	// +optional
+	IncludePeerCertificate *bool `json:"includePeerCertificate,omitempty"`
+
+	// AllowedUpstreamHeaders specifies the headers the external authorizer can set on
+	// the request forwarded to the backend when it allows the request, e.g. a downscoped
+	// token for the backend.
+	//
+	// For the HTTP protocol, these headers of the authorization response are copied
+	// into the request in addition to the AllowedResponseHeaders of ExternalAuth. For the
+	// GRPC protocol, the header mutations of the authorizer are restricted to these
+	// headers, and it can set any header when unset.
+	//
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=64
+	AllowedUpstreamHeaders []string `json:"allowedUpstreamHeaders,omitempty"`
+
+	// AllowedClientHeaders specifies the headers of the authorization response returned
+	// to the client when the external authorizer denies the request, e.g. the reason of
+	// the denial. When unset, all the headers of the authorization response are returned.
+	//
+	// Only supported with the HTTP protocol: a GRPC authorizer chooses the headers of
+	// its denied response, which are all returned to the client.
+	//
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=64
+	AllowedClientHeaders []string `json:"allowedClientHeaders,omitempty"`
+}
+
+// ExternalAuthFailureMode specifies how requests are handled when an external
+// authorizer fails.
+// +kubebuilder:validation:Enum=Allow;Deny
+type ExternalAuthFailureMode string
+
+const (
+	// ExternalAuthFailureModeAllow allows the requests when the external authorizer fails.
+	ExternalAuthFailureModeAllow ExternalAuthFailureMode = "Allow"
+
+	// ExternalAuthFailureModeDeny denies the requests when the external authorizer fails.
+	ExternalAuthFailureModeDeny ExternalAuthFailureMode = "Deny"
+)
+
+// ToolNameMatch describes how to match the name of a tool.
+// +kubebuilder:validation:XValidation:message="regular expression must not be longer than 100 characters",rule="self.type == 'RegularExpression' ? self.value.size() <= 100 : true"
+// +kubebuilder:validation:XValidation:message="regular expression must not use counted repetition",rule="self.type == 'RegularExpression' ? !self.value.matches('[{][0-9]') : true"
+type ToolNameMatch struct {
+	// Type specifies how to match against the name of the tool.
+	// +required
+	Type ToolNameMatchType `json:"type"`
+
+	// Value is the value of the tool name to be matched.
+	//
+	// Regular expressions use the RE2 syntax and must match the whole tool name.
+	//
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	Value string `json:"value"`
+}
+
+// ToolNameMatchType specifies the semantics of how a tool name is matched.
+// +kubebuilder:validation:Enum=Prefix;Suffix;RegularExpression
+type ToolNameMatchType string
+
+const (
+	// ToolNameMatchPrefix matches tool names starting with the given value.
+	ToolNameMatchPrefix ToolNameMatchType = "Prefix"
+
+	// ToolNameMatchSuffix matches tool names ending with the given value.
+	ToolNameMatchSuffix ToolNameMatchType = "Suffix"
+
+	// ToolNameMatchRegularExpression matches tool names against the given RE2 regular expression.
+	ToolNameMatchRegularExpression ToolNameMatchType = "RegularExpression"
+)
+
+// ToolArgumentMatch describes a constraint on the value of a tool call argument.
+// +kubebuilder:validation:XValidation:message="value must be specified when type is set to 'Exact' or 'Prefix'",rule="self.type in ['Exact', 'Prefix'] ? has(self.value) : true"
+// +kubebuilder:validation:XValidation:message="values must be specified when type is set to 'OneOf'",rule="self.type == 'OneOf' ? has(self.values) : true"
+// +kubebuilder:validation:XValidation:message="only one of value or values can be specified",rule="!(has(self.value) && has(self.values))"
+type ToolArgumentMatch struct {
+	// Name is the name of the argument, i.e. the key under `params.arguments` of the tool call.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	Name string `json:"name"`
+
+	// Type specifies how to match against the value of the argument.
+	// +required
+	Type ToolArgumentMatchType `json:"type"`
+
+	// Value is the value to be matched when type is Exact or Prefix.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=1024
+	Value string `json:"value,omitempty"`
+
+	// Values is the list of allowed values when type is OneOf.
+	// +listType=set
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=64
+	// +optional
+	Values []string `json:"values,omitempty"`
+}
+
+// ToolArgumentMatchType specifies the semantics of how a tool call argument is matched.
+// +kubebuilder:validation:Enum=Exact;Prefix;OneOf
+type ToolArgumentMatchType string
+
+const (
+	// ToolArgumentMatchExact matches arguments equal to the given value.
+	ToolArgumentMatchExact ToolArgumentMatchType = "Exact"
+
+	// ToolArgumentMatchPrefix matches arguments starting with the given value.
+	// Arguments containing a ".." path segment are not matched, so that a path prefix
+	// such as "/workspace/" cannot be escaped with "/workspace/../etc/passwd".
+	// Other forms of path aliasing, e.g. symbolic links, are not taken into account.
+	ToolArgumentMatchPrefix ToolArgumentMatchType = "Prefix"
+
+	// ToolArgumentMatchOneOf matches arguments equal to any of the given values.
+	ToolArgumentMatchOneOf ToolArgumentMatchType = "OneOf"
+)
+
+// ResourceURIMatch describes how to match the URI of an MCP resource.
+// +kubebuilder:validation:XValidation:message="regular expression must not be longer than 100 characters",rule="self.type == 'RegularExpression' ? self.value.size() <= 100 : true"
+// +kubebuilder:validation:XValidation:message="regular expression must not use counted repetition",rule="self.type == 'RegularExpression' ? !self.value.matches('[{][0-9]') : true"
+type ResourceURIMatch struct {
+	// Type specifies how to match against the URI of the resource.
+	// +required
+	Type ResourceURIMatchType `json:"type"`
+
+	// Value is the value of the resource URI to be matched.
+	//
+	// Regular expressions use the RE2 syntax and must match the whole URI.
+	//
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=2048
+	Value string `json:"value"`
 }
 
+// ResourceURIMatchType specifies the semantics of how a resource URI is matched.
+// +kubebuilder:validation:Enum=Exact;Prefix;RegularExpression
+type ResourceURIMatchType string
+
+const (
+	// ResourceURIMatchExact matches resource URIs equal to the given value.
+	ResourceURIMatchExact ResourceURIMatchType = "Exact"
+
+	// ResourceURIMatchPrefix matches resource URIs starting with the given value.
+	ResourceURIMatchPrefix ResourceURIMatchType = "Prefix"
+
+	// ResourceURIMatchRegularExpression matches resource URIs against the given RE2 regular expression.
+	ResourceURIMatchRegularExpression ResourceURIMatchType = "RegularExpression"
+)
+
 // AuthorizationRuleType identifies a type of authorization rule.
-// +kubebuilder:validation:Enum=InlineTools;ExternalAuth
+// +kubebuilder:validation:Enum=InlineTools;InlineResources;InlinePrompts;CEL;ExternalAuth
 type AuthorizationRuleType string
 
 const (
@@ -162,6 +709,18 @@ const (
 	// declared as an inline list of authorized tools.
 	AuthorizationRuleTypeInlineTools AuthorizationRuleType = "InlineTools"
 
+	// AuthorizationRuleTypeInlineResources is used to identify authorization rules
+	// declared as an inline list of patterns matching authorized MCP resource URIs.
+	AuthorizationRuleTypeInlineResources AuthorizationRuleType = "InlineResources"
+
+	// AuthorizationRuleTypeInlinePrompts is used to identify authorization rules
+	// declared as an inline list of authorized MCP prompts.
+	AuthorizationRuleTypeInlinePrompts AuthorizationRuleType = "InlinePrompts"
+
+	// AuthorizationRuleTypeCEL is used to identify authorization rules
+	// declared as a CEL expression.
+	AuthorizationRuleTypeCEL AuthorizationRuleType = "CEL"
+
 	// AuthorizationRuleTypeExternalAuth is used to identify authorization rules
 	// evaluated by an external auth service.
 	AuthorizationRuleTypeExternalAuth AuthorizationRuleType = "ExternalAuth"
@@ -173,6 +732,9 @@ type AccessPolicyStatus struct {
 	// associated with the policy, and the status of the policy with respect to
 	// each ancestor.
 	//
+	// The controller reports one ancestor for each targeted XBackend or Gateway, and
+	// one for each Gateway a targeted XBackend is reachable through.
+	//
 	// This field is inherited from the Gateway API Policy status definition.
 	// For more details, see the upstream documentation:
 	// https://gateway-api.sigs.k8s.io/reference/spec/#policyancestorstatus
@@ -183,6 +745,127 @@ type AccessPolicyStatus struct {
 	Ancestors []gwapiv1.PolicyAncestorStatus `json:"ancestors"`
 }
 
+// AccessPolicyConditionType is a type of condition reported for an ancestor of an AccessPolicy.
+type AccessPolicyConditionType string
+
+// AccessPolicyConditionReason is a reason for an AccessPolicy ancestor condition.
+type AccessPolicyConditionReason string
+
+const (
+	// AccessPolicyConditionAccepted indicates whether the AccessPolicy is in force for the ancestor.
+	//
+	// Possible reasons for this condition to be True are:
+	//
+	// * "Accepted"
+	//
+	// Possible reasons for this condition to be False are:
+	//
+	// * "TargetNotFound"
+	AccessPolicyConditionAccepted AccessPolicyConditionType = "Accepted"
+
+	// AccessPolicyReasonAccepted is used with the "Accepted" condition when the AccessPolicy
+	// is in force for the ancestor.
+	AccessPolicyReasonAccepted AccessPolicyConditionReason = "Accepted"
+
+	// AccessPolicyReasonTargetNotFound is used with the "Accepted" condition when the targeted
+	// resource does not exist.
+	AccessPolicyReasonTargetNotFound AccessPolicyConditionReason = "TargetNotFound"
+
+	// AccessPolicyConditionResolvedRefs indicates whether the backends referenced by the AccessPolicy,
+	// such as external authorization services and JSON Web Key Set servers, can be resolved.
+	//
+	// Possible reasons for this condition to be True are:
+	//
+	// * "ResolvedRefs"
+	//
+	// Possible reasons for this condition to be False are:
+	//
+	// * "BackendNotFound"
+	// * "InvalidKind"
+	// * "UnsupportedBackend"
+	AccessPolicyConditionResolvedRefs AccessPolicyConditionType = "ResolvedRefs"
+
+	// AccessPolicyReasonResolvedRefs is used with the "ResolvedRefs" condition when all the
+	// backends referenced by the AccessPolicy can be resolved.
+	AccessPolicyReasonResolvedRefs AccessPolicyConditionReason = "ResolvedRefs"
+
+	// AccessPolicyReasonBackendNotFound is used with the "ResolvedRefs" condition when a backend
+	// referenced by the AccessPolicy does not exist.
+	AccessPolicyReasonBackendNotFound AccessPolicyConditionReason = "BackendNotFound"
+
+	// AccessPolicyReasonInvalidKind is used with the "ResolvedRefs" condition when a backend
+	// referenced by the AccessPolicy is of an unsupported kind.
+	AccessPolicyReasonInvalidKind AccessPolicyConditionReason = "InvalidKind"
+
+	// AccessPolicyReasonUnsupportedBackend is used with the "ResolvedRefs" condition when a backend
+	// referenced by the AccessPolicy cannot be used for its purpose, such as an external XBackend
+	// serving a JSON Web Key Set.
+	AccessPolicyReasonUnsupportedBackend AccessPolicyConditionReason = "UnsupportedBackend"
+
+	// AccessPolicyConditionInvalidRules indicates whether some rules of the AccessPolicy are invalid,
+	// such as rules with a CEL expression that fails to compile or a window in an unknown time zone.
+	// The AccessPolicy remains accepted: its valid rules are in force, invalid Allow rules authorize
+	// no request and invalid Deny rules deny all tool calls from their source.
+	//
+	// Possible reasons for this condition to be True are:
+	//
+	// * "Invalid"
+	//
+	// Possible reasons for this condition to be False are:
+	//
+	// * "Valid"
+	AccessPolicyConditionInvalidRules AccessPolicyConditionType = "InvalidRules"
+
+	// AccessPolicyReasonInvalid is used with the "InvalidRules" condition when some rules of the
+	// AccessPolicy are invalid.
+	AccessPolicyReasonInvalid AccessPolicyConditionReason = "Invalid"
+
+	// AccessPolicyReasonValid is used with the "InvalidRules" condition when all the rules of the
+	// AccessPolicy are valid.
+	AccessPolicyReasonValid AccessPolicyConditionReason = "Valid"
+
+	// AccessPolicyConditionInactiveRules indicates whether some rules of the AccessPolicy are not in
+	// force at the moment because of their NotBefore, NotAfter or Windows. The condition is updated
+	// when a rule comes into or goes out of force.
+	//
+	// Possible reasons for this condition to be True are:
+	//
+	// * "Inactive"
+	//
+	// Possible reasons for this condition to be False are:
+	//
+	// * "Active"
+	AccessPolicyConditionInactiveRules AccessPolicyConditionType = "InactiveRules"
+
+	// AccessPolicyReasonInactive is used with the "InactiveRules" condition when some rules of the
+	// AccessPolicy are not in force at the moment.
+	AccessPolicyReasonInactive AccessPolicyConditionReason = "Inactive"
+
+	// AccessPolicyReasonActive is used with the "InactiveRules" condition when all the rules of the
+	// AccessPolicy are in force.
+	AccessPolicyReasonActive AccessPolicyConditionReason = "Active"
+
+	// AccessPolicyConditionMerged indicates whether the rules of the AccessPolicy were merged
+	// with the rules of other AccessPolicies targeting the same ancestor.
+	//
+	// Possible reasons for this condition to be True are:
+	//
+	// * "Merged"
+	//
+	// Possible reasons for this condition to be False are:
+	//
+	// * "NotMerged"
+	AccessPolicyConditionMerged AccessPolicyConditionType = "Merged"
+
+	// AccessPolicyReasonMerged is used with the "Merged" condition when the rules of the
+	// AccessPolicy were merged with those of other AccessPolicies targeting the same ancestor.
+	AccessPolicyReasonMerged AccessPolicyConditionReason = "Merged"
+
+	// AccessPolicyReasonNotMerged is used with the "Merged" condition when no other AccessPolicy
+	// contributes rules to the same ancestor.
+	AccessPolicyReasonNotMerged AccessPolicyConditionReason = "NotMerged"
+)
+
 // +genclient
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
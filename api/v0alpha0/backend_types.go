@@ -20,6 +20,7 @@ package v0alpha0
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
 // BackendSpec defines the desired state of Backend.
@@ -32,6 +33,8 @@ type BackendSpec struct {
 // MCPBackend describes a MCP Backend.
 // ServiceName and Hostname cannot be defined at the same time.
 // +kubebuilder:validation:ExactlyOneOf=serviceName;hostname
+// +kubebuilder:validation:XValidation:message="tls mode 'SPIFFE' can only be specified with serviceName",rule="has(self.tls) && self.tls.mode == 'SPIFFE' ? has(self.serviceName) : true"
+// +kubebuilder:validation:XValidation:message="credentialInjection and tokenExchange cannot be specified at the same time",rule="!(has(self.credentialInjection) && has(self.tokenExchange))"
 type MCPBackend struct {
 	// ServiceName defines the Kubernetes Service name of a MCP backend.
 	// +optional
@@ -53,8 +56,315 @@ type MCPBackend struct {
 	// +optional
 	// +kubebuilder:default:=/mcp
 	Path string `json:"path,omitempty"`
+
+	// TLS configures the connection to the backend.
+	// If not specified, the connection to a backend specified by Hostname uses TLS
+	// without validating the certificate of the backend, and the connection to a
+	// backend specified by ServiceName is plaintext.
+	// +optional
+	TLS *BackendTLS `json:"tls,omitempty"`
+
+	// CredentialInjection configures the credential the Gateway sets on the
+	// requests it forwards to the backend, such as the API key or bearer token of
+	// an external MCP server, so that agents never hold it.
+	// +optional
+	CredentialInjection *BackendCredentialInjection `json:"credentialInjection,omitempty"`
+
+	// TokenExchange configures the exchange of the identity of the calling agent
+	// for a token of the backend, which the Gateway sets on the requests it
+	// forwards to the backend, so that the backend authorizes each agent with a
+	// token scoped to it.
+	// +optional
+	TokenExchange *BackendTokenExchange `json:"tokenExchange,omitempty"`
+
+	// HealthCheck configures the active health checking of the endpoints of the
+	// backend by the Gateway, which stops routing requests to the endpoints failing
+	// it. The endpoints of a backend specified by ServiceName are the addresses its
+	// DNS name resolves to, so its Service must be headless for its Pods to be
+	// checked individually.
+	// If not specified, the endpoints are not actively health checked.
+	// +optional
+	HealthCheck *BackendHealthCheck `json:"healthCheck,omitempty"`
+
+	// OutlierDetection configures the passive health checking of the endpoints of
+	// the backend by the Gateway, which temporarily ejects the endpoints failing
+	// consecutive requests.
+	// If not specified, an endpoint is ejected for 30s after 5 consecutive
+	// gateway errors (502, 503 or 504) or connection failures.
+	// +optional
+	OutlierDetection *BackendOutlierDetection `json:"outlierDetection,omitempty"`
+}
+
+// BackendTLSMode defines whether the connection to a backend uses TLS.
+// +kubebuilder:validation:Enum=TLS;SPIFFE;Plaintext
+type BackendTLSMode string
+
+const (
+	// BackendTLSModeTLS connects to the backend over TLS.
+	BackendTLSModeTLS BackendTLSMode = "TLS"
+	// BackendTLSModeSPIFFE connects to the backend over mutual TLS with the SPIFFE
+	// identity of the Gateway, and validates the SPIFFE ID of the backend with the
+	// trust bundle of the agentic identity trust domain.
+	BackendTLSModeSPIFFE BackendTLSMode = "SPIFFE"
+	// BackendTLSModePlaintext connects to the backend without TLS.
+	BackendTLSModePlaintext BackendTLSMode = "Plaintext"
+)
+
+// BackendTLS configures the TLS connection to a backend.
+// +kubebuilder:validation:XValidation:message="caCertificateRefs, clientCertificateRef and sni cannot be specified when mode is 'Plaintext'",rule="self.mode == 'Plaintext' ? !has(self.caCertificateRefs) && !has(self.clientCertificateRef) && !has(self.sni) : true"
+// +kubebuilder:validation:XValidation:message="caCertificateRefs and clientCertificateRef cannot be specified when mode is 'SPIFFE'",rule="self.mode == 'SPIFFE' ? !has(self.caCertificateRefs) && !has(self.clientCertificateRef) : true"
+// +kubebuilder:validation:XValidation:message="serverSPIFFEIDs must be specified if and only if mode is 'SPIFFE'",rule="(self.mode == 'SPIFFE') == has(self.serverSPIFFEIDs)"
+type BackendTLS struct {
+	// Mode defines whether the connection to the backend uses TLS, and whether the
+	// TLS connection uses the SPIFFE identities of the Gateway and of the backend.
+	// +optional
+	// +kubebuilder:default=TLS
+	Mode BackendTLSMode `json:"mode,omitempty"`
+
+	// CACertificateRefs references the ConfigMaps or Secrets in the namespace of the
+	// XBackend containing the PEM-encoded CA certificates, under the `ca.crt` key,
+	// that validate the certificate of the backend. The certificate must also be
+	// valid for the SNI.
+	// If not specified, the certificate of the backend is not validated.
+	// +optional
+	// +listType=atomic
+	// +kubebuilder:validation:MaxItems=8
+	// +kubebuilder:validation:XValidation:message="caCertificateRefs must reference ConfigMaps or Secrets",rule="self.all(ref, ref.group == '' && (ref.kind == 'ConfigMap' || ref.kind == 'Secret'))"
+	CACertificateRefs []gwapiv1.LocalObjectReference `json:"caCertificateRefs,omitempty"`
+
+	// ClientCertificateRef references the Secret of type kubernetes.io/tls in the
+	// namespace of the XBackend containing the client certificate presented to the
+	// backend, under the `tls.crt` and `tls.key` keys.
+	// +optional
+	// +kubebuilder:validation:XValidation:message="clientCertificateRef must reference a Secret",rule="self.group == '' && self.kind == 'Secret'"
+	ClientCertificateRef *gwapiv1.LocalObjectReference `json:"clientCertificateRef,omitempty"`
+
+	// SNI is the server name sent to the backend in the TLS handshake.
+	// If not specified, it is the Hostname of the backend, or the fully qualified
+	// domain name of its Service.
+	// +optional
+	SNI *gwapiv1.PreciseHostname `json:"sni,omitempty"`
+
+	// ServerSPIFFEIDs are the SPIFFE IDs the backend may present when mode is
+	// 'SPIFFE'. A SPIFFE ID ending with `/*` matches all the SPIFFE IDs it prefixes,
+	// e.g. spiffe://cluster.local/ns/mcp/* matches the ServiceAccounts of the mcp
+	// namespace.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=16
+	ServerSPIFFEIDs []AuthorizationSourceSPIFFE `json:"serverSPIFFEIDs,omitempty"`
+}
+
+// BackendCredentialInjection configures the credential set on the requests forwarded to a backend.
+type BackendCredentialInjection struct {
+	// SecretRef references the Secret in the namespace of the XBackend containing
+	// the credential.
+	// +required
+	// +kubebuilder:validation:XValidation:message="secretRef must reference a Secret",rule="self.group == '' && self.kind == 'Secret'"
+	SecretRef gwapiv1.LocalObjectReference `json:"secretRef"`
+
+	// Key is the key of the credential in the Secret. Its value is the complete
+	// value of the header, e.g. `Bearer <token>` for the Authorization header.
+	// +optional
+	// +kubebuilder:default=credential
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[-._a-zA-Z0-9]+$`
+	Key string `json:"key,omitempty"`
+
+	// Header is the name of the request header the credential is set in. Any
+	// value of the header sent by the agent is replaced.
+	// +optional
+	// +kubebuilder:default=Authorization
+	Header gwapiv1.HTTPHeaderName `json:"header,omitempty"`
+}
+
+// TokenExchangeSubjectToken defines the identity of the calling agent exchanged for
+// a token of the backend.
+// +kubebuilder:validation:Enum=JWT;SPIFFE
+type TokenExchangeSubjectToken string
+
+const (
+	// TokenExchangeSubjectTokenJWT exchanges the bearer token of the Authorization
+	// header of the request of the agent, with the token type
+	// urn:ietf:params:oauth:token-type:jwt unless specified otherwise.
+	TokenExchangeSubjectTokenJWT TokenExchangeSubjectToken = "JWT"
+	// TokenExchangeSubjectTokenSPIFFE exchanges the SPIFFE ID of the client
+	// certificate of the agent, as verified by the Gateway. Since a SPIFFE ID is
+	// not a credential, the security token service must trust the Gateway to
+	// assert it, based on the client credentials of the Gateway.
+	TokenExchangeSubjectTokenSPIFFE TokenExchangeSubjectToken = "SPIFFE"
+)
+
+// BackendTokenExchange configures the OAuth 2.0 Token Exchange (RFC 8693) of the
+// identity of the calling agent for a token of a backend.
+// +kubebuilder:validation:XValidation:message="subjectTokenType and clientSecretRef must be specified when subjectToken is 'SPIFFE'",rule="self.subjectToken == 'SPIFFE' ? has(self.subjectTokenType) && has(self.clientSecretRef) : true"
+type BackendTokenExchange struct {
+	// TokenEndpoint is the URL of the token endpoint of the security token service.
+	// +required
+	// +kubebuilder:validation:MaxLength=2048
+	// +kubebuilder:validation:Pattern=`^https?://[^\s]+$`
+	TokenEndpoint string `json:"tokenEndpoint"`
+
+	// SubjectToken defines the identity of the calling agent that is exchanged.
+	// +optional
+	// +kubebuilder:default=JWT
+	SubjectToken TokenExchangeSubjectToken `json:"subjectToken,omitempty"`
+
+	// SubjectTokenType is the URI identifying the type of the subject token sent to
+	// the security token service.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	SubjectTokenType *string `json:"subjectTokenType,omitempty"`
+
+	// Audience is the logical name of the backend the token is requested for.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	Audience *string `json:"audience,omitempty"`
+
+	// Scopes are the scopes of the token requested for the backend.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=16
+	Scopes []string `json:"scopes,omitempty"`
+
+	// ClientSecretRef references the Secret in the namespace of the XBackend
+	// containing the client credentials authenticating the Gateway to the security
+	// token service, under the `client_id` and `client_secret` keys.
+	// +optional
+	// +kubebuilder:validation:XValidation:message="clientSecretRef must reference a Secret",rule="self.group == '' && self.kind == 'Secret'"
+	ClientSecretRef *gwapiv1.LocalObjectReference `json:"clientSecretRef,omitempty"`
+}
+
+// BackendHealthCheckType defines the request sent to check the health of the
+// endpoints of a backend.
+// +kubebuilder:validation:Enum=MCPPing;HTTP
+type BackendHealthCheckType string
+
+const (
+	// BackendHealthCheckTypeMCPPing sends a JSON-RPC `ping` request to the MCP path
+	// of the backend, and expects a 200 response with a result. The request is sent
+	// without the credential of the backend, so backends requiring one should be
+	// checked with an HTTP health check instead.
+	BackendHealthCheckTypeMCPPing BackendHealthCheckType = "MCPPing"
+	// BackendHealthCheckTypeHTTP sends a GET request to the health check path of
+	// the backend, and expects a 200 response.
+	BackendHealthCheckTypeHTTP BackendHealthCheckType = "HTTP"
+)
+
+// BackendHealthCheck configures the active health checking of the endpoints of a
+// backend.
+// +kubebuilder:validation:XValidation:message="path must be specified if and only if type is 'HTTP'",rule="(self.type == 'HTTP') == has(self.path)"
+type BackendHealthCheck struct {
+	// Type defines the request sent to check the health of the endpoints.
+	// +optional
+	// +kubebuilder:default=MCPPing
+	Type BackendHealthCheckType `json:"type,omitempty"`
+
+	// Path is the URL path of the health check requests when type is 'HTTP', e.g.
+	// /healthz.
+	// +optional
+	// +kubebuilder:validation:MaxLength=1024
+	// +kubebuilder:validation:Pattern=`^/[^\s]*$`
+	Path *string `json:"path,omitempty"`
+
+	// Interval is the time between two health checks of an endpoint.
+	// If not specified, the default is 10s.
+	// +optional
+	Interval *gwapiv1.Duration `json:"interval,omitempty"`
+
+	// Timeout is the maximum time to wait for the response to a health check.
+	// If not specified, the default is 1s.
+	// +optional
+	Timeout *gwapiv1.Duration `json:"timeout,omitempty"`
+
+	// HealthyThreshold is the number of consecutive successful health checks
+	// after which an unhealthy endpoint is healthy again.
+	// +optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	HealthyThreshold *int32 `json:"healthyThreshold,omitempty"`
+
+	// UnhealthyThreshold is the number of consecutive failed health checks after
+	// which an endpoint is unhealthy.
+	// +optional
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	UnhealthyThreshold *int32 `json:"unhealthyThreshold,omitempty"`
 }
 
+// BackendOutlierDetection configures the ejection of the endpoints of a backend
+// failing consecutive requests.
+type BackendOutlierDetection struct {
+	// ConsecutiveGatewayErrors is the number of consecutive gateway errors (502,
+	// 503 or 504) or connection failures after which an endpoint is ejected.
+	// +optional
+	// +kubebuilder:default=5
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	ConsecutiveGatewayErrors *int32 `json:"consecutiveGatewayErrors,omitempty"`
+
+	// Interval is the time between two analyses of the endpoints for ejection.
+	// If not specified, the default is 10s.
+	// +optional
+	Interval *gwapiv1.Duration `json:"interval,omitempty"`
+
+	// BaseEjectionTime is the time an endpoint is ejected for, multiplied by the
+	// number of times it has been ejected.
+	// If not specified, the default is 30s.
+	// +optional
+	BaseEjectionTime *gwapiv1.Duration `json:"baseEjectionTime,omitempty"`
+
+	// MaxEjectionPercent is the maximum percentage of the endpoints of the backend
+	// that can be ejected at the same time. At least one endpoint can always be
+	// ejected.
+	// +optional
+	// +kubebuilder:default=50
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MaxEjectionPercent *int32 `json:"maxEjectionPercent,omitempty"`
+}
+
+// BackendConditionType is a type of condition of an XBackend.
+type BackendConditionType string
+
+// BackendConditionReason is a reason for an XBackend condition.
+type BackendConditionReason string
+
+const (
+	// BackendConditionHealthy summarizes the health of the endpoints of the
+	// XBackend, as seen by the proxies of the Gateways routing to it. It is only
+	// reported while such proxies are connected to the controller.
+	//
+	// Possible reasons for this condition to be True are:
+	//
+	// * "Healthy"
+	// * "PartiallyHealthy"
+	//
+	// Possible reasons for this condition to be False are:
+	//
+	// * "Unhealthy"
+	BackendConditionHealthy BackendConditionType = "Healthy"
+
+	// BackendReasonHealthy is used with the "Healthy" condition when all the
+	// endpoints of the XBackend are healthy.
+	BackendReasonHealthy BackendConditionReason = "Healthy"
+
+	// BackendReasonPartiallyHealthy is used with the "Healthy" condition when
+	// some, but not all, of the endpoints of the XBackend are healthy.
+	BackendReasonPartiallyHealthy BackendConditionReason = "PartiallyHealthy"
+
+	// BackendReasonUnhealthy is used with the "Healthy" condition when none of the
+	// endpoints of the XBackend are healthy, or it has no endpoints.
+	BackendReasonUnhealthy BackendConditionReason = "Unhealthy"
+)
+
 // BackendStatus defines the observed state of Backend.
 type BackendStatus struct {
 	// For Kubernetes API conventions, see:
@@ -44,6 +44,11 @@ func (in *AccessPolicySpec) DeepCopyInto(out *AccessPolicySpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DefaultAllowances != nil {
+		in, out := &in.DefaultAllowances, &out.DefaultAllowances
+		*out = new(DefaultAllowances)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessPolicySpec.
@@ -81,12 +86,38 @@ func (in *AccessPolicyStatus) DeepCopy() *AccessPolicyStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AccessRule) DeepCopyInto(out *AccessRule) {
 	*out = *in
-	in.Source.DeepCopyInto(&out.Source)
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(Source)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]Source, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Authorization != nil {
 		in, out := &in.Authorization, &out.Authorization
 		*out = new(AuthorizationRule)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NotBefore != nil {
+		in, out := &in.NotBefore, &out.NotBefore
+		*out = (*in).DeepCopy()
+	}
+	if in.NotAfter != nil {
+		in, out := &in.NotAfter, &out.NotAfter
+		*out = (*in).DeepCopy()
+	}
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]AccessRuleTimeWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessRule.
@@ -99,6 +130,31 @@ func (in *AccessRule) DeepCopy() *AccessRule {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessRuleTimeWindow) DeepCopyInto(out *AccessRuleTimeWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]Weekday, len(*in))
+		copy(*out, *in)
+	}
+	if in.TimeZone != nil {
+		in, out := &in.TimeZone, &out.TimeZone
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessRuleTimeWindow.
+func (in *AccessRuleTimeWindow) DeepCopy() *AccessRuleTimeWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessRuleTimeWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AuthorizationRule) DeepCopyInto(out *AuthorizationRule) {
 	*out = *in
@@ -107,11 +163,38 @@ func (in *AuthorizationRule) DeepCopyInto(out *AuthorizationRule) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ToolPatterns != nil {
+		in, out := &in.ToolPatterns, &out.ToolPatterns
+		*out = make([]ToolNameMatch, len(*in))
+		copy(*out, *in)
+	}
+	if in.Arguments != nil {
+		in, out := &in.Arguments, &out.Arguments
+		*out = make([]ToolArgumentMatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]ResourceURIMatch, len(*in))
+		copy(*out, *in)
+	}
+	if in.Prompts != nil {
+		in, out := &in.Prompts, &out.Prompts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.ExternalAuth != nil {
 		in, out := &in.ExternalAuth, &out.ExternalAuth
 		*out = new(v1.HTTPExternalAuthFilter)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ExternalAuthSettings != nil {
+		in, out := &in.ExternalAuthSettings, &out.ExternalAuthSettings
+		*out = new(ExternalAuthSettings)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthorizationRule.
@@ -124,6 +207,34 @@ func (in *AuthorizationRule) DeepCopy() *AuthorizationRule {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthorizationSourceOIDC) DeepCopyInto(out *AuthorizationSourceOIDC) {
+	*out = *in
+	if in.Audiences != nil {
+		in, out := &in.Audiences, &out.Audiences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.JWKS.DeepCopyInto(&out.JWKS)
+	if in.Claims != nil {
+		in, out := &in.Claims, &out.Claims
+		*out = make([]JWTClaimMatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthorizationSourceOIDC.
+func (in *AuthorizationSourceOIDC) DeepCopy() *AuthorizationSourceOIDC {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthorizationSourceOIDC)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AuthorizationSourceServiceAccount) DeepCopyInto(out *AuthorizationSourceServiceAccount) {
 	*out = *in
@@ -139,6 +250,97 @@ func (in *AuthorizationSourceServiceAccount) DeepCopy() *AuthorizationSourceServ
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackendCredentialInjection) DeepCopyInto(out *BackendCredentialInjection) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendCredentialInjection.
+func (in *BackendCredentialInjection) DeepCopy() *BackendCredentialInjection {
+	if in == nil {
+		return nil
+	}
+	out := new(BackendCredentialInjection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackendHealthCheck) DeepCopyInto(out *BackendHealthCheck) {
+	*out = *in
+	if in.Path != nil {
+		in, out := &in.Path, &out.Path
+		*out = new(string)
+		**out = **in
+	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.HealthyThreshold != nil {
+		in, out := &in.HealthyThreshold, &out.HealthyThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.UnhealthyThreshold != nil {
+		in, out := &in.UnhealthyThreshold, &out.UnhealthyThreshold
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendHealthCheck.
+func (in *BackendHealthCheck) DeepCopy() *BackendHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(BackendHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackendOutlierDetection) DeepCopyInto(out *BackendOutlierDetection) {
+	*out = *in
+	if in.ConsecutiveGatewayErrors != nil {
+		in, out := &in.ConsecutiveGatewayErrors, &out.ConsecutiveGatewayErrors
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.BaseEjectionTime != nil {
+		in, out := &in.BaseEjectionTime, &out.BaseEjectionTime
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxEjectionPercent != nil {
+		in, out := &in.MaxEjectionPercent, &out.MaxEjectionPercent
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendOutlierDetection.
+func (in *BackendOutlierDetection) DeepCopy() *BackendOutlierDetection {
+	if in == nil {
+		return nil
+	}
+	out := new(BackendOutlierDetection)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackendSpec) DeepCopyInto(out *BackendSpec) {
 	*out = *in
@@ -177,6 +379,196 @@ func (in *BackendStatus) DeepCopy() *BackendStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackendTLS) DeepCopyInto(out *BackendTLS) {
+	*out = *in
+	if in.CACertificateRefs != nil {
+		in, out := &in.CACertificateRefs, &out.CACertificateRefs
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClientCertificateRef != nil {
+		in, out := &in.ClientCertificateRef, &out.ClientCertificateRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.SNI != nil {
+		in, out := &in.SNI, &out.SNI
+		*out = new(v1.PreciseHostname)
+		**out = **in
+	}
+	if in.ServerSPIFFEIDs != nil {
+		in, out := &in.ServerSPIFFEIDs, &out.ServerSPIFFEIDs
+		*out = make([]AuthorizationSourceSPIFFE, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendTLS.
+func (in *BackendTLS) DeepCopy() *BackendTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(BackendTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackendTokenExchange) DeepCopyInto(out *BackendTokenExchange) {
+	*out = *in
+	if in.SubjectTokenType != nil {
+		in, out := &in.SubjectTokenType, &out.SubjectTokenType
+		*out = new(string)
+		**out = **in
+	}
+	if in.Audience != nil {
+		in, out := &in.Audience, &out.Audience
+		*out = new(string)
+		**out = **in
+	}
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClientSecretRef != nil {
+		in, out := &in.ClientSecretRef, &out.ClientSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendTokenExchange.
+func (in *BackendTokenExchange) DeepCopy() *BackendTokenExchange {
+	if in == nil {
+		return nil
+	}
+	out := new(BackendTokenExchange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultAllowances) DeepCopyInto(out *DefaultAllowances) {
+	*out = *in
+	if in.InitializeAndListTools != nil {
+		in, out := &in.InitializeAndListTools, &out.InitializeAndListTools
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CloseSession != nil {
+		in, out := &in.CloseSession, &out.CloseSession
+		*out = new(bool)
+		**out = **in
+	}
+	if in.HTTPGet != nil {
+		in, out := &in.HTTPGet, &out.HTTPGet
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultAllowances.
+func (in *DefaultAllowances) DeepCopy() *DefaultAllowances {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultAllowances)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalAuthSettings) DeepCopyInto(out *ExternalAuthSettings) {
+	*out = *in
+	if in.FailureMode != nil {
+		in, out := &in.FailureMode, &out.FailureMode
+		*out = new(ExternalAuthFailureMode)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.StatusOnError != nil {
+		in, out := &in.StatusOnError, &out.StatusOnError
+		*out = new(int32)
+		**out = **in
+	}
+	if in.IncludePeerCertificate != nil {
+		in, out := &in.IncludePeerCertificate, &out.IncludePeerCertificate
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowedUpstreamHeaders != nil {
+		in, out := &in.AllowedUpstreamHeaders, &out.AllowedUpstreamHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedClientHeaders != nil {
+		in, out := &in.AllowedClientHeaders, &out.AllowedClientHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalAuthSettings.
+func (in *ExternalAuthSettings) DeepCopy() *ExternalAuthSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalAuthSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JWKS) DeepCopyInto(out *JWKS) {
+	*out = *in
+	if in.Inline != nil {
+		in, out := &in.Inline, &out.Inline
+		*out = new(string)
+		**out = **in
+	}
+	if in.BackendRef != nil {
+		in, out := &in.BackendRef, &out.BackendRef
+		*out = new(v1.BackendObjectReference)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JWKS.
+func (in *JWKS) DeepCopy() *JWKS {
+	if in == nil {
+		return nil
+	}
+	out := new(JWKS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JWTClaimMatch) DeepCopyInto(out *JWTClaimMatch) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JWTClaimMatch.
+func (in *JWTClaimMatch) DeepCopy() *JWTClaimMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(JWTClaimMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MCPBackend) DeepCopyInto(out *MCPBackend) {
 	*out = *in
@@ -190,6 +582,31 @@ func (in *MCPBackend) DeepCopyInto(out *MCPBackend) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(BackendTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CredentialInjection != nil {
+		in, out := &in.CredentialInjection, &out.CredentialInjection
+		*out = new(BackendCredentialInjection)
+		**out = **in
+	}
+	if in.TokenExchange != nil {
+		in, out := &in.TokenExchange, &out.TokenExchange
+		*out = new(BackendTokenExchange)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(BackendHealthCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OutlierDetection != nil {
+		in, out := &in.OutlierDetection, &out.OutlierDetection
+		*out = new(BackendOutlierDetection)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPBackend.
@@ -202,6 +619,21 @@ func (in *MCPBackend) DeepCopy() *MCPBackend {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceURIMatch) DeepCopyInto(out *ResourceURIMatch) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceURIMatch.
+func (in *ResourceURIMatch) DeepCopy() *ResourceURIMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceURIMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Source) DeepCopyInto(out *Source) {
 	*out = *in
@@ -215,6 +647,21 @@ func (in *Source) DeepCopyInto(out *Source) {
 		*out = new(AuthorizationSourceServiceAccount)
 		**out = **in
 	}
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		*out = new(AuthorizationSourceOIDC)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(v1.Namespace)
+		**out = **in
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Source.
@@ -227,6 +674,41 @@ func (in *Source) DeepCopy() *Source {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ToolArgumentMatch) DeepCopyInto(out *ToolArgumentMatch) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ToolArgumentMatch.
+func (in *ToolArgumentMatch) DeepCopy() *ToolArgumentMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(ToolArgumentMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ToolNameMatch) DeepCopyInto(out *ToolNameMatch) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ToolNameMatch.
+func (in *ToolNameMatch) DeepCopy() *ToolNameMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(ToolNameMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *XAccessPolicy) DeepCopyInto(out *XAccessPolicy) {
 	*out = *in
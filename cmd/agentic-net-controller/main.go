@@ -125,6 +125,7 @@ func main() {
 		sharedKubeInformers.Core().V1().Namespaces(),
 		sharedKubeInformers.Core().V1().Services(),
 		sharedKubeInformers.Core().V1().Secrets(),
+		sharedKubeInformers.Core().V1().ConfigMaps(),
 		sharedGwInformers.Gateway().V1().GatewayClasses(),
 		sharedGwInformers.Gateway().V1().Gateways(),
 		sharedGwInformers.Gateway().V1().HTTPRoutes(),
@@ -180,6 +181,12 @@ func main() {
 				klog.ErrorS(err, "Error running the agentic identity signer")
 			}
 		})
+
+		// The token exchange server authenticates the Gateway proxies with the agentic identities of the signer.
+		if err := c.RunTokenExchange(ctx, impl); err != nil {
+			klog.ErrorS(err, "Error running the token exchange server")
+			klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+		}
 	}
 
 	// notice that there is no need to run Start methods in a separate goroutine. (i.e. go kubeInformerFactory.Start(ctx.done())
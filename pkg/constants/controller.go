@@ -26,8 +26,14 @@ const (
 	// AgenticNetSystemNamespace is the namespace where agentic-networking system components are deployed.
 	AgenticNetSystemNamespace = "agentic-net-system"
 
-	// XDSServerServiceName is the name of the Service that exposes the xDS server.
+	// XDSServerServiceName is the name of the Service that exposes the xDS server and the token exchange server.
 	XDSServerServiceName = "agentic-net-xds-server"
+	// TokenExchangePort is the port of the token exchange server, which only accepts connections authenticated with
+	// the agentic identity of a Gateway proxy.
+	TokenExchangePort = 15002
+	// ControllerServiceAccountName is the name of the ServiceAccount of the controller, whose agentic identity the
+	// token exchange server presents to the Gateway proxies.
+	ControllerServiceAccountName = "agentic-net-controller-sa"
 
 	// Finalizers: block deletion until no dependents reference the resource.
 
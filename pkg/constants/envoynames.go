@@ -33,6 +33,22 @@ const (
 	VHostNameFormat = "%s-vh-%d-%s"
 	// ClusterNameFormat is the format string for Envoy cluster names, becoming `<namespace>-<backend-name>`.
 	ClusterNameFormat = "%s-%s"
+	// CredentialSecretNameFormat is the format string for the names of the Envoy secrets delivered over SDS holding
+	// the credentials injected into the requests to XBackends, becoming `credential-<namespace>-<backend-name>`.
+	CredentialSecretNameFormat = "credential-%s-%s"
+	// ClientCertificateSecretNameFormat is the format string for the names of the Envoy secrets delivered over SDS
+	// holding the client certificates presented to XBackends, becoming `client-certificate-<namespace>-<backend-name>`.
+	ClientCertificateSecretNameFormat = "client-certificate-%s-%s"
+
+	// XDSClusterName is the name of the cluster of the Envoy bootstrap configuration connecting to the controller,
+	// which serves xDS and the metrics service receiving the stats of the proxies.
+	XDSClusterName = "xds_cluster"
+	// TokenExchangeClusterName is the name of the cluster connecting to the token exchange server of the controller
+	// with mutual TLS, authenticating the Gateway proxy with its agentic identity.
+	TokenExchangeClusterName = "token_exchange_cluster"
+	// TokenExchangeBackendContextKey is the key of the ext_authz context extension holding the
+	// `<namespace>/<name>` key of the XBackend whose token is requested from the token exchange server.
+	TokenExchangeBackendContextKey = "xbackend"
 
 	// EnvoyBootstrapMountPath is the path where the Envoy bootstrap configuration is mounted.
 	EnvoyBootstrapMountPath = "/etc/envoy/bootstrap"
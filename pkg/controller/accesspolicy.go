@@ -17,21 +17,35 @@ limitations under the License.
 package controller
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"slices"
+	"strings"
+	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 
 	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
 	agenticinformers "sigs.k8s.io/kube-agentic-networking/k8s/client/informers/externalversions/api/v0alpha0"
+	"sigs.k8s.io/kube-agentic-networking/pkg/constants"
+	"sigs.k8s.io/kube-agentic-networking/pkg/translator"
 )
 
+// maxAccessPolicyAncestors is the maximum number of ancestors in the status of an XAccessPolicy.
+const maxAccessPolicyAncestors = 16
+
 func (c *Controller) setupAccessPolicyEventHandlers(accessPolicyInformer agenticinformers.XAccessPolicyInformer) error {
 	_, err := accessPolicyInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc:    c.onAccessPolicyAdd,
@@ -45,6 +59,7 @@ func (c *Controller) onAccessPolicyAdd(obj interface{}) {
 	policy := obj.(*agenticv0alpha0.XAccessPolicy)
 	klog.V(4).InfoS("Adding AccessPolicy", "accesspolicy", klog.KObj(policy))
 	c.enqueueGatewaysForAccessPolicy(policy)
+	c.enqueueAccessPolicyStatuses(policy)
 }
 
 func (c *Controller) onAccessPolicyUpdate(old, newObj interface{}) {
@@ -53,6 +68,13 @@ func (c *Controller) onAccessPolicyUpdate(old, newObj interface{}) {
 	if newPolicy.Generation != oldPolicy.Generation || newPolicy.DeletionTimestamp != oldPolicy.DeletionTimestamp || !reflect.DeepEqual(newPolicy.Annotations, oldPolicy.Annotations) {
 		klog.V(4).InfoS("Updating AccessPolicy", "accesspolicy", klog.KObj(oldPolicy))
 		c.enqueueGatewaysForAccessPolicy(newPolicy)
+		c.enqueueAccessPolicyStatuses(newPolicy)
+		// Policies no longer sharing an XBackend with this one are no longer merged with it.
+		for _, targetRef := range oldPolicy.Spec.TargetRefs {
+			if isXBackendTargetRef(targetRef) {
+				c.enqueueAccessPoliciesForBackend(oldPolicy.Namespace, string(targetRef.Name))
+			}
+		}
 	}
 }
 
@@ -72,12 +94,25 @@ func (c *Controller) onAccessPolicyDelete(obj interface{}) {
 	}
 	klog.V(4).InfoS("Deleting AccessPolicy", "accesspolicy", klog.KObj(policy))
 	c.enqueueGatewaysForAccessPolicy(policy)
+	// The status of the other policies targeting the same XBackends may depend on this one.
+	for _, targetRef := range policy.Spec.TargetRefs {
+		if isXBackendTargetRef(targetRef) {
+			c.enqueueAccessPoliciesForBackend(policy.Namespace, string(targetRef.Name))
+		}
+	}
 }
 
 // enqueueGatewaysForAccessPolicy enqueues Gateways so they are reconciled.
-// It also enqueues the targeted XBackend for finalizer reconciliation.
+// Gateways targeted directly by the policy are enqueued as is. For XBackend
+// targets, it also enqueues the targeted XBackend for finalizer reconciliation.
 func (c *Controller) enqueueGatewaysForAccessPolicy(policy *agenticv0alpha0.XAccessPolicy) {
 	for _, targetRef := range policy.Spec.TargetRefs {
+		if translator.IsGatewayTargetRef(targetRef) {
+			key := policy.Namespace + "/" + string(targetRef.Name)
+			klog.V(4).InfoS("Enqueuing gateway for access policy change", "gateway", key, "accesspolicy", klog.KObj(policy))
+			c.gatewayqueue.Add(key)
+			continue
+		}
 		if !isXBackendTargetRef(targetRef) {
 			// TODO: Set status condition on AccessPolicy to indicate unsupported targetRef
 			klog.InfoS("AccessPolicy targets an unsupported resource", "accesspolicy", klog.KObj(policy), "targetRef", targetRef)
@@ -87,7 +122,6 @@ func (c *Controller) enqueueGatewaysForAccessPolicy(policy *agenticv0alpha0.XAcc
 		backend, err := c.agentic.backendLister.XBackends(policy.Namespace).Get(string(targetRef.Name))
 		if err != nil {
 			if apierrors.IsNotFound(err) {
-				// TODO: Set status condition on AccessPolicy to indicate missing backend
 				klog.InfoS("AccessPolicy targets a non-existent Backend", "accesspolicy", klog.KObj(policy), "backend", types.NamespacedName{Namespace: policy.Namespace, Name: string(targetRef.Name)})
 			} else {
 				runtime.HandleError(fmt.Errorf("failed to get backend %s/%s targeted by access policy %s: %w", policy.Namespace, targetRef.Name, policy.Name, err))
@@ -102,3 +136,522 @@ func (c *Controller) enqueueGatewaysForAccessPolicy(policy *agenticv0alpha0.XAcc
 func isXBackendTargetRef(targetRef gwapiv1.LocalPolicyTargetReferenceWithSectionName) bool {
 	return targetRef.Group == agenticv0alpha0.GroupName && targetRef.Kind == "XBackend"
 }
+
+// enqueueAccessPolicyForStatus enqueues the XAccessPolicy for status reconciliation.
+func (c *Controller) enqueueAccessPolicyForStatus(policy *agenticv0alpha0.XAccessPolicy) {
+	c.accessPolicyStatusQueue.Add(policy.Namespace + "/" + policy.Name)
+}
+
+// enqueueAccessPolicyStatuses enqueues the given XAccessPolicy and the other XAccessPolicies targeting the same
+// XBackends for status reconciliation, since whether a policy is merged depends on the others.
+func (c *Controller) enqueueAccessPolicyStatuses(policy *agenticv0alpha0.XAccessPolicy) {
+	c.enqueueAccessPolicyForStatus(policy)
+	for _, targetRef := range policy.Spec.TargetRefs {
+		if !isXBackendTargetRef(targetRef) {
+			continue
+		}
+		c.enqueueAccessPoliciesForBackend(policy.Namespace, string(targetRef.Name))
+	}
+}
+
+// enqueueAccessPoliciesForBackend enqueues the XAccessPolicies targeting the given XBackend for status reconciliation.
+func (c *Controller) enqueueAccessPoliciesForBackend(namespace, name string) {
+	policies, err := c.agentic.accessPolicyLister.XAccessPolicies(namespace).List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("failed to list access policies in namespace %s: %w", namespace, err))
+		return
+	}
+	for _, policy := range policies {
+		if targetsBackend(policy, name) {
+			c.enqueueAccessPolicyForStatus(policy)
+		}
+	}
+}
+
+// enqueueAccessPoliciesForGateway enqueues for status reconciliation the XAccessPolicies that target the given
+// Gateway, that target an XBackend reachable through it, or whose status still reports it as an ancestor.
+func (c *Controller) enqueueAccessPoliciesForGateway(namespace, name string) {
+	policies, err := c.agentic.accessPolicyLister.List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("failed to list access policies: %w", err))
+		return
+	}
+	backends := c.backendsReachableThroughGateway(namespace, name)
+	gatewayRef := gatewayAncestorRef(namespace, name, nil)
+	for _, policy := range policies {
+		if slices.ContainsFunc(policy.Status.Ancestors, func(a gwapiv1.PolicyAncestorStatus) bool {
+			return a.ControllerName == gwapiv1.GatewayController(constants.ControllerName) && sameAncestorRef(withoutSectionName(a.AncestorRef), gatewayRef)
+		}) {
+			c.enqueueAccessPolicyForStatus(policy)
+			continue
+		}
+		for _, targetRef := range policy.Spec.TargetRefs {
+			if translator.IsGatewayTargetRef(targetRef) && policy.Namespace == namespace && string(targetRef.Name) == name {
+				c.enqueueAccessPolicyForStatus(policy)
+				break
+			}
+			if _, ok := backends[types.NamespacedName{Namespace: policy.Namespace, Name: string(targetRef.Name)}]; ok && isXBackendTargetRef(targetRef) {
+				c.enqueueAccessPolicyForStatus(policy)
+				break
+			}
+		}
+	}
+}
+
+// enqueueAccessPoliciesForService enqueues for status reconciliation the XAccessPolicies referencing the given
+// Service as an external authorization service or JSON Web Key Set server.
+func (c *Controller) enqueueAccessPoliciesForService(namespace, name string) {
+	policies, err := c.agentic.accessPolicyLister.List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("failed to list access policies: %w", err))
+		return
+	}
+	for _, policy := range policies {
+		for _, ref := range accessPolicyBackendRefs(policy) {
+			if isServiceBackendRef(ref.BackendObjectReference) && backendRefNamespace(ref.BackendObjectReference, policy.Namespace) == namespace && string(ref.Name) == name {
+				c.enqueueAccessPolicyForStatus(policy)
+				break
+			}
+		}
+	}
+}
+
+// syncAccessPolicyStatus sets the status ancestors of an XAccessPolicy reported by this controller.
+func (c *Controller) syncAccessPolicyStatus(ctx context.Context, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("invalid access policy key %s: %w", key, err))
+		return nil
+	}
+	policy, err := c.agentic.accessPolicyLister.XAccessPolicies(namespace).Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	now := time.Now()
+	ancestors, err := c.accessPolicyAncestors(policy, now)
+	if err != nil {
+		return err
+	}
+	if err := c.setAccessPolicyAncestors(ctx, policy, ancestors); err != nil {
+		return err
+	}
+
+	// When a rule comes into or goes out of force, the Gateways enforcing the policy must be translated again
+	// and its status updated.
+	if next, ok := translator.NextRuleTransition(policy, now); ok {
+		delay := next.Sub(now)
+		klog.V(4).InfoS("Scheduling the next rule transition of access policy", "accesspolicy", klog.KObj(policy), "at", next)
+		c.accessPolicyStatusQueue.AddAfter(key, delay)
+		c.enqueueGatewaysForAccessPolicyAfter(policy, delay)
+	}
+	return nil
+}
+
+// enqueueGatewaysForAccessPolicyAfter enqueues the Gateways enforcing the given XAccessPolicy after the given
+// delay: those it targets and those its targeted XBackends are reachable through.
+func (c *Controller) enqueueGatewaysForAccessPolicyAfter(policy *agenticv0alpha0.XAccessPolicy, delay time.Duration) {
+	for _, targetRef := range policy.Spec.TargetRefs {
+		if translator.IsGatewayTargetRef(targetRef) {
+			c.gatewayqueue.AddAfter(policy.Namespace+"/"+string(targetRef.Name), delay)
+			continue
+		}
+		if !isXBackendTargetRef(targetRef) {
+			continue
+		}
+		backend, err := c.agentic.backendLister.XBackends(policy.Namespace).Get(string(targetRef.Name))
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				runtime.HandleError(fmt.Errorf("failed to get backend %s/%s targeted by access policy %s: %w", policy.Namespace, targetRef.Name, policy.Name, err))
+			}
+			continue
+		}
+		gatewayKeys, err := c.gatewayKeysForBackend(backend)
+		if err != nil {
+			runtime.HandleError(err)
+			continue
+		}
+		for key := range gatewayKeys {
+			c.gatewayqueue.AddAfter(key, delay)
+		}
+	}
+}
+
+// accessPolicyAncestors computes the status ancestors of an XAccessPolicy: one for each targeted XBackend or
+// Gateway, and one for each Gateway a targeted XBackend is reachable through, with the same conditions as the
+// XBackend. A Gateway through which several targeted XBackends are reachable is reported once, for the first
+// of them.
+func (c *Controller) accessPolicyAncestors(policy *agenticv0alpha0.XAccessPolicy, now time.Time) ([]gwapiv1.PolicyAncestorStatus, error) {
+	resolvedRefs := c.resolvedRefsCondition(policy)
+
+	var ancestors []gwapiv1.PolicyAncestorStatus
+	addAncestor := func(ref gwapiv1.ParentReference, conditions []metav1.Condition) {
+		for _, a := range ancestors {
+			if sameAncestorRef(a.AncestorRef, ref) {
+				return
+			}
+		}
+		ancestors = append(ancestors, gwapiv1.PolicyAncestorStatus{
+			AncestorRef:    ref,
+			ControllerName: gwapiv1.GatewayController(constants.ControllerName),
+			Conditions:     conditions,
+		})
+	}
+
+	for _, targetRef := range policy.Spec.TargetRefs {
+		switch {
+		case translator.IsGatewayTargetRef(targetRef):
+			ref := gatewayAncestorRef(policy.Namespace, string(targetRef.Name), targetRef.SectionName)
+			if _, err := c.gateway.gatewayLister.Gateways(policy.Namespace).Get(string(targetRef.Name)); err != nil {
+				if !apierrors.IsNotFound(err) {
+					return nil, err
+				}
+				addAncestor(ref, []metav1.Condition{targetNotFoundCondition("Gateway", policy.Namespace, string(targetRef.Name)), resolvedRefs})
+				continue
+			}
+			addAncestor(ref, []metav1.Condition{acceptedCondition(policy), invalidRulesCondition(policy), inactiveRulesCondition(policy, now), resolvedRefs})
+		case isXBackendTargetRef(targetRef):
+			ref := xBackendAncestorRef(policy.Namespace, string(targetRef.Name))
+			backend, err := c.agentic.backendLister.XBackends(policy.Namespace).Get(string(targetRef.Name))
+			if err != nil {
+				if !apierrors.IsNotFound(err) {
+					return nil, err
+				}
+				addAncestor(ref, []metav1.Condition{targetNotFoundCondition("XBackend", policy.Namespace, string(targetRef.Name)), resolvedRefs})
+				continue
+			}
+			conditions, err := c.backendAncestorConditions(policy, backend, resolvedRefs, now)
+			if err != nil {
+				return nil, err
+			}
+			addAncestor(ref, conditions)
+			for _, gateway := range c.gatewaysForBackend(backend) {
+				addAncestor(gatewayAncestorRef(gateway.Namespace, gateway.Name, nil), conditions)
+			}
+		}
+	}
+	return ancestors, nil
+}
+
+// backendAncestorConditions returns the conditions of an XAccessPolicy with respect to a targeted XBackend,
+// reporting whether the policy is in force and whether its rules were merged with the rules of other policies
+// targeting the same XBackend.
+func (c *Controller) backendAncestorConditions(policy *agenticv0alpha0.XAccessPolicy, backend *agenticv0alpha0.XBackend, resolvedRefs metav1.Condition, now time.Time) ([]metav1.Condition, error) {
+	policies, err := translator.AccessPoliciesForBackend(backend, c.agentic.accessPolicyLister)
+	if err != nil {
+		return nil, err
+	}
+	return []metav1.Condition{
+		acceptedCondition(policy),
+		invalidRulesCondition(policy),
+		inactiveRulesCondition(policy, now),
+		resolvedRefs,
+		mergedCondition(policy, policies),
+	}, nil
+}
+
+// acceptedCondition returns the Accepted condition for the given policy, which is in force for the ancestor.
+// Invalid rules do not prevent the other rules from being enforced and are reported by invalidRulesCondition.
+func acceptedCondition(policy *agenticv0alpha0.XAccessPolicy) metav1.Condition {
+	message := "AccessPolicy is in force"
+	if policy.Spec.Mode == agenticv0alpha0.AccessPolicyModeAudit {
+		message = "AccessPolicy is in Audit mode, its decisions are recorded in the access log but not enforced"
+	}
+	return metav1.Condition{
+		Type:    string(agenticv0alpha0.AccessPolicyConditionAccepted),
+		Status:  metav1.ConditionTrue,
+		Reason:  string(agenticv0alpha0.AccessPolicyReasonAccepted),
+		Message: message,
+	}
+}
+
+// invalidRulesCondition returns the InvalidRules condition for the given policy. Rules with invalid CEL
+// expressions or schedules are reported, since they are not in force as specified.
+func invalidRulesCondition(policy *agenticv0alpha0.XAccessPolicy) metav1.Condition {
+	var messages []string
+	for _, err := range []error{translator.ValidateCELRules(policy), translator.ValidateRuleSchedules(policy)} {
+		if err != nil {
+			messages = append(messages, err.Error())
+		}
+	}
+	if len(messages) > 0 {
+		return metav1.Condition{
+			Type:    string(agenticv0alpha0.AccessPolicyConditionInvalidRules),
+			Status:  metav1.ConditionTrue,
+			Reason:  string(agenticv0alpha0.AccessPolicyReasonInvalid),
+			Message: fmt.Sprintf("AccessPolicy has invalid rules, Allow rules among them authorize no request and Deny rules deny all tool calls from their source; its other rules are in force: %s", strings.Join(messages, "; ")),
+		}
+	}
+	return metav1.Condition{
+		Type:    string(agenticv0alpha0.AccessPolicyConditionInvalidRules),
+		Status:  metav1.ConditionFalse,
+		Reason:  string(agenticv0alpha0.AccessPolicyReasonValid),
+		Message: "All rules of the AccessPolicy are valid",
+	}
+}
+
+// inactiveRulesCondition returns the InactiveRules condition for the given policy at the given time, listing the
+// rules that are not in force because of their schedule and when they may come into force. Rules with an invalid
+// schedule are reported by invalidRulesCondition.
+func inactiveRulesCondition(policy *agenticv0alpha0.XAccessPolicy, now time.Time) metav1.Condition {
+	var inactive []string
+	for _, rule := range policy.Spec.Rules {
+		inForce, next, err := translator.RuleSchedule(rule, now)
+		switch {
+		case err != nil || inForce:
+			continue
+		case next.IsZero() && rule.NotAfter != nil && !now.Before(rule.NotAfter.Time):
+			inactive = append(inactive, fmt.Sprintf("rule %s expired at %s", rule.Name, rule.NotAfter.UTC().Format(time.RFC3339)))
+		case next.IsZero():
+			inactive = append(inactive, fmt.Sprintf("rule %s is not in force", rule.Name))
+		default:
+			inactive = append(inactive, fmt.Sprintf("rule %s is not in force until %s", rule.Name, next.UTC().Format(time.RFC3339)))
+		}
+	}
+	if len(inactive) > 0 {
+		return metav1.Condition{
+			Type:    string(agenticv0alpha0.AccessPolicyConditionInactiveRules),
+			Status:  metav1.ConditionTrue,
+			Reason:  string(agenticv0alpha0.AccessPolicyReasonInactive),
+			Message: fmt.Sprintf("Some rules of the AccessPolicy are not in force: %s", strings.Join(inactive, "; ")),
+		}
+	}
+	return metav1.Condition{
+		Type:    string(agenticv0alpha0.AccessPolicyConditionInactiveRules),
+		Status:  metav1.ConditionFalse,
+		Reason:  string(agenticv0alpha0.AccessPolicyReasonActive),
+		Message: "All rules of the AccessPolicy are in force",
+	}
+}
+
+// targetNotFoundCondition returns the Accepted condition for a policy whose target does not exist.
+func targetNotFoundCondition(kind, namespace, name string) metav1.Condition {
+	return metav1.Condition{
+		Type:    string(agenticv0alpha0.AccessPolicyConditionAccepted),
+		Status:  metav1.ConditionFalse,
+		Reason:  string(agenticv0alpha0.AccessPolicyReasonTargetNotFound),
+		Message: fmt.Sprintf("Targeted %s %s/%s does not exist", kind, namespace, name),
+	}
+}
+
+// mergedCondition returns the Merged condition for the given policy, listing the other merged policies.
+func mergedCondition(policy *agenticv0alpha0.XAccessPolicy, merged []*agenticv0alpha0.XAccessPolicy) metav1.Condition {
+	var others []string
+	for _, other := range merged {
+		if other.Namespace == policy.Namespace && other.Name == policy.Name {
+			continue
+		}
+		others = append(others, other.Namespace+"/"+other.Name)
+	}
+	if len(others) == 0 {
+		return metav1.Condition{
+			Type:    string(agenticv0alpha0.AccessPolicyConditionMerged),
+			Status:  metav1.ConditionFalse,
+			Reason:  string(agenticv0alpha0.AccessPolicyReasonNotMerged),
+			Message: "No other XAccessPolicy targets the XBackend",
+		}
+	}
+	return metav1.Condition{
+		Type:    string(agenticv0alpha0.AccessPolicyConditionMerged),
+		Status:  metav1.ConditionTrue,
+		Reason:  string(agenticv0alpha0.AccessPolicyReasonMerged),
+		Message: fmt.Sprintf("Rules merged with XAccessPolicies %s", strings.Join(others, ", ")),
+	}
+}
+
+// accessPolicyBackendRef is a backend referenced by an XAccessPolicy, along with the kinds it can refer to.
+type accessPolicyBackendRef struct {
+	gwapiv1.BackendObjectReference
+	field         string
+	allowXBackend bool
+}
+
+// accessPolicyBackendRefs returns the backends referenced by the ExternalAuth rules and the OIDC sources of an
+// XAccessPolicy.
+func accessPolicyBackendRefs(policy *agenticv0alpha0.XAccessPolicy) []accessPolicyBackendRef {
+	var refs []accessPolicyBackendRef
+	for _, rule := range policy.Spec.Rules {
+		if rule.Authorization != nil && rule.Authorization.ExternalAuth != nil {
+			refs = append(refs, accessPolicyBackendRef{
+				BackendObjectReference: rule.Authorization.ExternalAuth.BackendRef,
+				field:                  fmt.Sprintf("rule %s externalAuth", rule.Name),
+			})
+		}
+		for _, source := range translator.RuleSources(rule) {
+			if source.OIDC != nil && source.OIDC.JWKS.BackendRef != nil {
+				refs = append(refs, accessPolicyBackendRef{
+					BackendObjectReference: *source.OIDC.JWKS.BackendRef,
+					field:                  fmt.Sprintf("rule %s jwks", rule.Name),
+					allowXBackend:          true,
+				})
+			}
+		}
+	}
+	return refs
+}
+
+// resolvedRefsCondition returns the ResolvedRefs condition of an XAccessPolicy, reporting whether the backends
+// referenced by the policy exist.
+func (c *Controller) resolvedRefsCondition(policy *agenticv0alpha0.XAccessPolicy) metav1.Condition {
+	for _, ref := range accessPolicyBackendRefs(policy) {
+		namespace := backendRefNamespace(ref.BackendObjectReference, policy.Namespace)
+		var err error
+		switch {
+		case isServiceBackendRef(ref.BackendObjectReference):
+			_, err = c.core.svcLister.Services(namespace).Get(string(ref.Name))
+		case ref.allowXBackend && ptr.Deref(ref.Group, "") == agenticv0alpha0.GroupName && ptr.Deref(ref.Kind, "") == "XBackend":
+			var backend *agenticv0alpha0.XBackend
+			backend, err = c.agentic.backendLister.XBackends(namespace).Get(string(ref.Name))
+			if err == nil && backend.Spec.MCP.Hostname != nil {
+				return metav1.Condition{
+					Type:    string(agenticv0alpha0.AccessPolicyConditionResolvedRefs),
+					Status:  metav1.ConditionFalse,
+					Reason:  string(agenticv0alpha0.AccessPolicyReasonUnsupportedBackend),
+					Message: fmt.Sprintf("The backendRef of %s refers to XBackend %s/%s, which is external: JSON Web Key Sets can only be fetched from in-cluster XBackends", ref.field, namespace, ref.Name),
+				}
+			}
+		default:
+			return metav1.Condition{
+				Type:    string(agenticv0alpha0.AccessPolicyConditionResolvedRefs),
+				Status:  metav1.ConditionFalse,
+				Reason:  string(agenticv0alpha0.AccessPolicyReasonInvalidKind),
+				Message: fmt.Sprintf("The backendRef of %s refers to an unsupported kind %s", ref.field, ptr.Deref(ref.Kind, "")),
+			}
+		}
+		if err != nil {
+			return metav1.Condition{
+				Type:    string(agenticv0alpha0.AccessPolicyConditionResolvedRefs),
+				Status:  metav1.ConditionFalse,
+				Reason:  string(agenticv0alpha0.AccessPolicyReasonBackendNotFound),
+				Message: fmt.Sprintf("The backendRef of %s refers to %s/%s, which cannot be found", ref.field, namespace, ref.Name),
+			}
+		}
+	}
+	return metav1.Condition{
+		Type:    string(agenticv0alpha0.AccessPolicyConditionResolvedRefs),
+		Status:  metav1.ConditionTrue,
+		Reason:  string(agenticv0alpha0.AccessPolicyReasonResolvedRefs),
+		Message: "All backendRefs are resolved",
+	}
+}
+
+// setAccessPolicyAncestors replaces the status ancestors of the given policy reported by this controller.
+// Ancestors reported by other controllers are preserved.
+func (c *Controller) setAccessPolicyAncestors(ctx context.Context, policy *agenticv0alpha0.XAccessPolicy, ancestors []gwapiv1.PolicyAncestorStatus) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		// GET the latest version of the policy from the cache.
+		originalPolicy, err := c.agentic.accessPolicyLister.XAccessPolicies(policy.Namespace).Get(policy.Name)
+		if apierrors.IsNotFound(err) {
+			// Policy has been deleted, nothing to do.
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		policyToUpdate := originalPolicy.DeepCopy()
+		var newAncestors []gwapiv1.PolicyAncestorStatus
+		for _, a := range policyToUpdate.Status.Ancestors {
+			if a.ControllerName != gwapiv1.GatewayController(constants.ControllerName) {
+				newAncestors = append(newAncestors, a)
+			}
+		}
+		for _, ancestor := range ancestors {
+			if len(newAncestors) >= maxAccessPolicyAncestors {
+				klog.InfoS("XAccessPolicy has too many ancestors, not reporting status for all of them", "accesspolicy", klog.KObj(policyToUpdate), "ancestor", ancestor.AncestorRef.Name)
+				break
+			}
+			// Keep the conditions of the existing ancestor, so that unchanged conditions keep their transition time.
+			var conditions []metav1.Condition
+			for _, a := range policyToUpdate.Status.Ancestors {
+				if a.ControllerName == gwapiv1.GatewayController(constants.ControllerName) && sameAncestorRef(a.AncestorRef, ancestor.AncestorRef) {
+					conditions = a.Conditions
+					break
+				}
+			}
+			var newConditions []metav1.Condition
+			for _, condition := range ancestor.Conditions {
+				condition.ObservedGeneration = policyToUpdate.Generation
+				if existing := meta.FindStatusCondition(conditions, condition.Type); existing != nil {
+					newConditions = append(newConditions, *existing)
+				}
+				meta.SetStatusCondition(&newConditions, condition)
+			}
+			ancestor.Conditions = newConditions
+			newAncestors = append(newAncestors, ancestor)
+		}
+		policyToUpdate.Status.Ancestors = newAncestors
+
+		// Only make an API call if the status has actually changed.
+		if !semanticIgnoreLastTransitionTime.DeepEqual(originalPolicy.Status, policyToUpdate.Status) {
+			_, updateErr := c.agentic.client.AgenticV0alpha0().XAccessPolicies(policyToUpdate.Namespace).UpdateStatus(ctx, policyToUpdate, metav1.UpdateOptions{})
+			return updateErr
+		}
+
+		// Status is already up-to-date.
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update status for XAccessPolicy %s/%s: %w", policy.Namespace, policy.Name, err)
+	}
+	return nil
+}
+
+// xBackendAncestorRef returns the reference to the given XBackend used in XAccessPolicy status ancestors.
+func xBackendAncestorRef(namespace, name string) gwapiv1.ParentReference {
+	return gwapiv1.ParentReference{
+		Group:     ptr.To(gwapiv1.Group(agenticv0alpha0.GroupName)),
+		Kind:      ptr.To(gwapiv1.Kind("XBackend")),
+		Namespace: ptr.To(gwapiv1.Namespace(namespace)),
+		Name:      gwapiv1.ObjectName(name),
+	}
+}
+
+// gatewayAncestorRef returns the reference to the given Gateway, or one of its listeners, used in XAccessPolicy
+// status ancestors.
+func gatewayAncestorRef(namespace, name string, sectionName *gwapiv1.SectionName) gwapiv1.ParentReference {
+	return gwapiv1.ParentReference{
+		Group:       ptr.To(gwapiv1.Group(gwapiv1.GroupName)),
+		Kind:        ptr.To(gwapiv1.Kind("Gateway")),
+		Namespace:   ptr.To(gwapiv1.Namespace(namespace)),
+		Name:        gwapiv1.ObjectName(name),
+		SectionName: sectionName,
+	}
+}
+
+func withoutSectionName(ref gwapiv1.ParentReference) gwapiv1.ParentReference {
+	ref.SectionName = nil
+	return ref
+}
+
+func sameAncestorRef(a, b gwapiv1.ParentReference) bool {
+	return ptr.Deref(a.Group, "") == ptr.Deref(b.Group, "") &&
+		ptr.Deref(a.Kind, "") == ptr.Deref(b.Kind, "") &&
+		ptr.Deref(a.Namespace, "") == ptr.Deref(b.Namespace, "") &&
+		a.Name == b.Name &&
+		ptr.Deref(a.SectionName, "") == ptr.Deref(b.SectionName, "")
+}
+
+// targetsBackend returns true if the given policy has a targetRef to the XBackend with the given name.
+func targetsBackend(policy *agenticv0alpha0.XAccessPolicy, backendName string) bool {
+	for _, targetRef := range policy.Spec.TargetRefs {
+		if isXBackendTargetRef(targetRef) && string(targetRef.Name) == backendName {
+			return true
+		}
+	}
+	return false
+}
+
+// isServiceBackendRef returns true if the given backendRef refers to a Service, which is the default kind.
+func isServiceBackendRef(ref gwapiv1.BackendObjectReference) bool {
+	return ptr.Deref(ref.Group, "") == "" && ptr.Deref(ref.Kind, "Service") == "Service"
+}
+
+func backendRefNamespace(ref gwapiv1.BackendObjectReference, defaultNamespace string) string {
+	if ref.Namespace != nil {
+		return string(*ref.Namespace)
+	}
+	return defaultNamespace
+}
@@ -0,0 +1,590 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewaylisters "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1"
+
+	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
+	agenticclientfake "sigs.k8s.io/kube-agentic-networking/k8s/client/clientset/versioned/fake"
+	agenticlisters "sigs.k8s.io/kube-agentic-networking/k8s/client/listers/api/v0alpha0"
+	"sigs.k8s.io/kube-agentic-networking/pkg/constants"
+)
+
+func TestSyncAccessPolicyStatus(t *testing.T) {
+	ns := "default"
+	backend := &agenticv0alpha0.XBackend{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "my-backend"},
+	}
+	otherBackend := &agenticv0alpha0.XBackend{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "other-backend"},
+	}
+	externalBackend := &agenticv0alpha0.XBackend{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "external-idp"},
+		Spec: agenticv0alpha0.BackendSpec{
+			MCP: agenticv0alpha0.MCPBackend{Hostname: ptr.To("idp.example.com"), Port: 443},
+		},
+	}
+	gatewayClass := &gatewayv1.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "agentic"},
+		Spec:       gatewayv1.GatewayClassSpec{ControllerName: constants.ControllerName},
+	}
+	gateway := &gatewayv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "gw"},
+		Spec:       gatewayv1.GatewaySpec{GatewayClassName: "agentic"},
+	}
+	route := &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "route"},
+		Spec: gatewayv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1.CommonRouteSpec{
+				ParentRefs: []gatewayv1.ParentReference{{Name: "gw"}},
+			},
+			Rules: []gatewayv1.HTTPRouteRule{{
+				BackendRefs: []gatewayv1.HTTPBackendRef{{
+					BackendRef: gatewayv1.BackendRef{
+						BackendObjectReference: gatewayv1.BackendObjectReference{
+							Group: ptr.To(gatewayv1.Group(agenticv0alpha0.GroupName)),
+							Kind:  ptr.To(gatewayv1.Kind("XBackend")),
+							Name:  gatewayv1.ObjectName(backend.Name),
+						},
+					},
+				}},
+			}},
+		},
+	}
+
+	created := metav1.NewTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	newPolicy := func(name string, createdAt metav1.Time, targetKind, target, extAuthBackend string) *agenticv0alpha0.XAccessPolicy {
+		group := gatewayv1.Group(agenticv0alpha0.GroupName)
+		if targetKind == "Gateway" {
+			group = gatewayv1.GroupName
+		}
+		policy := &agenticv0alpha0.XAccessPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name, CreationTimestamp: createdAt, Generation: 2},
+			Spec: agenticv0alpha0.AccessPolicySpec{
+				TargetRefs: []gatewayv1.LocalPolicyTargetReferenceWithSectionName{
+					{
+						LocalPolicyTargetReference: gatewayv1.LocalPolicyTargetReference{
+							Group: group,
+							Kind:  gatewayv1.Kind(targetKind),
+							Name:  gatewayv1.ObjectName(target),
+						},
+					},
+				},
+				Rules: []agenticv0alpha0.AccessRule{{Name: "rule1"}},
+			},
+		}
+		if extAuthBackend != "" {
+			policy.Spec.Rules[0].Authorization = &agenticv0alpha0.AuthorizationRule{
+				Type: agenticv0alpha0.AuthorizationRuleTypeExternalAuth,
+				ExternalAuth: &gatewayv1.HTTPExternalAuthFilter{
+					ExternalAuthProtocol: gatewayv1.HTTPRouteExternalAuthGRPCProtocol,
+					BackendRef:           gatewayv1.BackendObjectReference{Name: gatewayv1.ObjectName(extAuthBackend)},
+				},
+			}
+		}
+		return policy
+	}
+
+	platform := newPolicy("platform", created, "XBackend", backend.Name, "authz-1")
+	team := newPolicy("team", metav1.NewTime(created.Add(time.Hour)), "XBackend", backend.Name, "")
+	late := newPolicy("late", metav1.NewTime(created.Add(2*time.Hour)), "XBackend", backend.Name, "authz-2")
+	missing := newPolicy("missing", created, "XBackend", "does-not-exist", "")
+	unresolved := newPolicy("unresolved", created, "XBackend", otherBackend.Name, "does-not-exist")
+	gatewayPolicy := newPolicy("gateway", created, "Gateway", gateway.Name, "")
+	missingGateway := newPolicy("missing-gateway", created, "Gateway", "does-not-exist", "")
+	externalJWKS := newPolicy("external-jwks", created, "Gateway", gateway.Name, "")
+	externalJWKS.Spec.Rules[0].Source = &agenticv0alpha0.Source{
+		Type: agenticv0alpha0.AuthorizationSourceTypeOIDC,
+		OIDC: &agenticv0alpha0.AuthorizationSourceOIDC{
+			Issuer: "https://idp.example.com",
+			JWKS: agenticv0alpha0.JWKS{
+				BackendRef: &gatewayv1.BackendObjectReference{
+					Group: ptr.To(gatewayv1.Group(agenticv0alpha0.GroupName)),
+					Kind:  ptr.To(gatewayv1.Kind("XBackend")),
+					Name:  gatewayv1.ObjectName(externalBackend.Name),
+				},
+			},
+		},
+	}
+	// Ancestors reported by other controllers are preserved.
+	team.Status.Ancestors = []gatewayv1.PolicyAncestorStatus{{
+		AncestorRef:    gatewayv1.ParentReference{Name: "other"},
+		ControllerName: "example.com/other-controller",
+	}}
+	policies := []*agenticv0alpha0.XAccessPolicy{platform, team, late, missing, unresolved, gatewayPolicy, missingGateway, externalJWKS}
+
+	newIndexer := func(objs ...interface{}) cache.Indexer {
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		for _, obj := range objs {
+			if err := indexer.Add(obj); err != nil {
+				t.Fatalf("indexer.Add: %v", err)
+			}
+		}
+		return indexer
+	}
+	var policyObjs []interface{}
+	var clientObjs []runtime.Object
+	for _, policy := range policies {
+		policyObjs = append(policyObjs, policy)
+		clientObjs = append(clientObjs, policy)
+	}
+	client := agenticclientfake.NewSimpleClientset(clientObjs...)
+	c := &Controller{
+		core: coreResources{
+			svcLister: corev1listers.NewServiceLister(newIndexer(
+				&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "authz-1"}},
+				&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "authz-2"}},
+			)),
+		},
+		gateway: gatewayResources{
+			gatewayClassLister: gatewaylisters.NewGatewayClassLister(newIndexer(gatewayClass)),
+			gatewayLister:      gatewaylisters.NewGatewayLister(newIndexer(gateway)),
+			httprouteLister:    gatewaylisters.NewHTTPRouteLister(newIndexer(route)),
+		},
+		agentic: agenticNetResources{
+			client:             client,
+			backendLister:      agenticlisters.NewXBackendLister(newIndexer(backend, otherBackend, externalBackend)),
+			accessPolicyLister: agenticlisters.NewXAccessPolicyLister(newIndexer(policyObjs...)),
+		},
+	}
+
+	for _, policy := range policies {
+		if err := c.syncAccessPolicyStatus(context.Background(), policy.Namespace+"/"+policy.Name); err != nil {
+			t.Fatalf("syncAccessPolicyStatus(%s): %v", policy.Name, err)
+		}
+	}
+
+	tests := []struct {
+		policy           string
+		wantAncestors    []string
+		wantAccepted     metav1.ConditionStatus
+		wantReason       agenticv0alpha0.AccessPolicyConditionReason
+		wantResolvedRefs agenticv0alpha0.AccessPolicyConditionReason
+		wantMerged       metav1.ConditionStatus
+	}{
+		{
+			policy:           "platform",
+			wantAncestors:    []string{"XBackend/my-backend", "Gateway/gw"},
+			wantAccepted:     metav1.ConditionTrue,
+			wantReason:       agenticv0alpha0.AccessPolicyReasonAccepted,
+			wantResolvedRefs: agenticv0alpha0.AccessPolicyReasonResolvedRefs,
+			wantMerged:       metav1.ConditionTrue,
+		},
+		{
+			policy:           "team",
+			wantAncestors:    []string{"Gateway/other", "XBackend/my-backend", "Gateway/gw"},
+			wantAccepted:     metav1.ConditionTrue,
+			wantReason:       agenticv0alpha0.AccessPolicyReasonAccepted,
+			wantResolvedRefs: agenticv0alpha0.AccessPolicyReasonResolvedRefs,
+			wantMerged:       metav1.ConditionTrue,
+		},
+		{
+			policy:           "late",
+			wantAncestors:    []string{"XBackend/my-backend", "Gateway/gw"},
+			wantAccepted:     metav1.ConditionTrue,
+			wantReason:       agenticv0alpha0.AccessPolicyReasonAccepted,
+			wantResolvedRefs: agenticv0alpha0.AccessPolicyReasonResolvedRefs,
+			wantMerged:       metav1.ConditionTrue,
+		},
+		{
+			policy:           "missing",
+			wantAncestors:    []string{"XBackend/does-not-exist"},
+			wantAccepted:     metav1.ConditionFalse,
+			wantReason:       agenticv0alpha0.AccessPolicyReasonTargetNotFound,
+			wantResolvedRefs: agenticv0alpha0.AccessPolicyReasonResolvedRefs,
+		},
+		{
+			policy:           "unresolved",
+			wantAncestors:    []string{"XBackend/other-backend"},
+			wantAccepted:     metav1.ConditionTrue,
+			wantReason:       agenticv0alpha0.AccessPolicyReasonAccepted,
+			wantResolvedRefs: agenticv0alpha0.AccessPolicyReasonBackendNotFound,
+			wantMerged:       metav1.ConditionFalse,
+		},
+		{
+			policy:           "gateway",
+			wantAncestors:    []string{"Gateway/gw"},
+			wantAccepted:     metav1.ConditionTrue,
+			wantReason:       agenticv0alpha0.AccessPolicyReasonAccepted,
+			wantResolvedRefs: agenticv0alpha0.AccessPolicyReasonResolvedRefs,
+		},
+		{
+			policy:           "missing-gateway",
+			wantAncestors:    []string{"Gateway/does-not-exist"},
+			wantAccepted:     metav1.ConditionFalse,
+			wantReason:       agenticv0alpha0.AccessPolicyReasonTargetNotFound,
+			wantResolvedRefs: agenticv0alpha0.AccessPolicyReasonResolvedRefs,
+		},
+		{
+			policy:           "external-jwks",
+			wantAncestors:    []string{"Gateway/gw"},
+			wantAccepted:     metav1.ConditionTrue,
+			wantReason:       agenticv0alpha0.AccessPolicyReasonAccepted,
+			wantResolvedRefs: agenticv0alpha0.AccessPolicyReasonUnsupportedBackend,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.policy, func(t *testing.T) {
+			policy, err := client.AgenticV0alpha0().XAccessPolicies(ns).Get(context.Background(), tc.policy, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed to get policy: %v", err)
+			}
+			var ancestors []string
+			for _, ancestor := range policy.Status.Ancestors {
+				ancestors = append(ancestors, string(ptr.Deref(ancestor.AncestorRef.Kind, "Gateway"))+"/"+string(ancestor.AncestorRef.Name))
+			}
+			if !slices.Equal(ancestors, tc.wantAncestors) {
+				t.Fatalf("expected ancestors %v, got %v", tc.wantAncestors, ancestors)
+			}
+			for _, ancestor := range policy.Status.Ancestors {
+				if ancestor.ControllerName != constants.ControllerName {
+					continue
+				}
+				for _, condition := range ancestor.Conditions {
+					if condition.ObservedGeneration != policy.Generation {
+						t.Errorf("expected observedGeneration %d, got %+v", policy.Generation, condition)
+					}
+				}
+				accepted := meta.FindStatusCondition(ancestor.Conditions, string(agenticv0alpha0.AccessPolicyConditionAccepted))
+				if accepted == nil || accepted.Status != tc.wantAccepted || accepted.Reason != string(tc.wantReason) {
+					t.Errorf("unexpected Accepted condition: %+v", accepted)
+				}
+				resolvedRefs := meta.FindStatusCondition(ancestor.Conditions, string(agenticv0alpha0.AccessPolicyConditionResolvedRefs))
+				if resolvedRefs == nil || resolvedRefs.Reason != string(tc.wantResolvedRefs) {
+					t.Errorf("unexpected ResolvedRefs condition: %+v", resolvedRefs)
+				}
+				merged := meta.FindStatusCondition(ancestor.Conditions, string(agenticv0alpha0.AccessPolicyConditionMerged))
+				if tc.wantMerged == "" {
+					if merged != nil {
+						t.Errorf("unexpected Merged condition: %+v", merged)
+					}
+				} else if merged == nil || merged.Status != tc.wantMerged {
+					t.Errorf("unexpected Merged condition: %+v", merged)
+				}
+			}
+		})
+	}
+}
+
+func TestEnqueueAccessPoliciesForGateway(t *testing.T) {
+	ns := "default"
+	route := &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "route"},
+		Spec: gatewayv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1.CommonRouteSpec{
+				ParentRefs: []gatewayv1.ParentReference{{Name: "gw"}},
+			},
+			Rules: []gatewayv1.HTTPRouteRule{{
+				BackendRefs: []gatewayv1.HTTPBackendRef{{
+					BackendRef: gatewayv1.BackendRef{
+						BackendObjectReference: gatewayv1.BackendObjectReference{
+							Group: ptr.To(gatewayv1.Group(agenticv0alpha0.GroupName)),
+							Kind:  ptr.To(gatewayv1.Kind("XBackend")),
+							Name:  "routed",
+						},
+					},
+				}},
+			}},
+		},
+	}
+	newPolicy := func(name, kind, target string) *agenticv0alpha0.XAccessPolicy {
+		group := gatewayv1.Group(agenticv0alpha0.GroupName)
+		if kind == "Gateway" {
+			group = gatewayv1.GroupName
+		}
+		return &agenticv0alpha0.XAccessPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+			Spec: agenticv0alpha0.AccessPolicySpec{
+				TargetRefs: []gatewayv1.LocalPolicyTargetReferenceWithSectionName{{
+					LocalPolicyTargetReference: gatewayv1.LocalPolicyTargetReference{
+						Group: group,
+						Kind:  gatewayv1.Kind(kind),
+						Name:  gatewayv1.ObjectName(target),
+					},
+				}},
+			},
+		}
+	}
+	stale := newPolicy("stale", "XBackend", "unrouted")
+	stale.Status.Ancestors = []gatewayv1.PolicyAncestorStatus{{
+		AncestorRef:    gatewayAncestorRef(ns, "gw", nil),
+		ControllerName: constants.ControllerName,
+	}}
+
+	policyIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, policy := range []*agenticv0alpha0.XAccessPolicy{
+		newPolicy("gateway", "Gateway", "gw"),
+		newPolicy("other-gateway", "Gateway", "other"),
+		newPolicy("routed", "XBackend", "routed"),
+		newPolicy("unrouted", "XBackend", "unrouted"),
+		stale,
+	} {
+		if err := policyIndexer.Add(policy); err != nil {
+			t.Fatalf("indexer.Add: %v", err)
+		}
+	}
+	routeIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := routeIndexer.Add(route); err != nil {
+		t.Fatalf("indexer.Add: %v", err)
+	}
+	c := &Controller{
+		gateway: gatewayResources{
+			httprouteLister: gatewaylisters.NewHTTPRouteLister(routeIndexer),
+		},
+		agentic: agenticNetResources{
+			accessPolicyLister: agenticlisters.NewXAccessPolicyLister(policyIndexer),
+		},
+		accessPolicyStatusQueue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			workqueue.DefaultTypedControllerRateLimiter[string](),
+			workqueue.TypedRateLimitingQueueConfig[string]{Name: "accesspolicy-status"},
+		),
+	}
+
+	c.enqueueAccessPoliciesForGateway(ns, "gw")
+
+	var keys []string
+	for c.accessPolicyStatusQueue.Len() > 0 {
+		key, _ := c.accessPolicyStatusQueue.Get()
+		keys = append(keys, key)
+		c.accessPolicyStatusQueue.Done(key)
+	}
+	slices.Sort(keys)
+	want := []string{"default/gateway", "default/routed", "default/stale"}
+	if !slices.Equal(keys, want) {
+		t.Errorf("expected keys %v, got %v", want, keys)
+	}
+}
+
+func TestEnqueueGatewaysForAccessPolicy_GatewayTarget(t *testing.T) {
+	c := &Controller{
+		gatewayqueue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			workqueue.DefaultTypedControllerRateLimiter[string](),
+			workqueue.TypedRateLimitingQueueConfig[string]{Name: "gateway"},
+		),
+	}
+
+	sectionName := gatewayv1.SectionName("http")
+	policy := &agenticv0alpha0.XAccessPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test-ns", Name: "gateway-policy"},
+		Spec: agenticv0alpha0.AccessPolicySpec{
+			TargetRefs: []gatewayv1.LocalPolicyTargetReferenceWithSectionName{
+				{
+					LocalPolicyTargetReference: gatewayv1.LocalPolicyTargetReference{
+						Group: gatewayv1.GroupName,
+						Kind:  "Gateway",
+						Name:  "gw-1",
+					},
+				},
+				{
+					LocalPolicyTargetReference: gatewayv1.LocalPolicyTargetReference{
+						Group: gatewayv1.GroupName,
+						Kind:  "Gateway",
+						Name:  "gw-2",
+					},
+					SectionName: &sectionName,
+				},
+			},
+			Rules: []agenticv0alpha0.AccessRule{{Name: "rule1"}},
+		},
+	}
+
+	c.enqueueGatewaysForAccessPolicy(policy)
+
+	if c.gatewayqueue.Len() != 2 {
+		t.Fatalf("expected queue length 2, got %d", c.gatewayqueue.Len())
+	}
+	expectedKeys := map[string]bool{
+		"test-ns/gw-1": true,
+		"test-ns/gw-2": true,
+	}
+	for i := 0; i < len(expectedKeys); i++ {
+		key, shutdown := c.gatewayqueue.Get()
+		if shutdown {
+			t.Fatal("queue unexpectedly shut down")
+		}
+		if !expectedKeys[key] {
+			t.Errorf("unexpected key in queue: %s", key)
+		}
+		c.gatewayqueue.Done(key)
+	}
+}
+
+func TestInvalidRulesCondition_CELRules(t *testing.T) {
+	newPolicy := func(expression string) *agenticv0alpha0.XAccessPolicy {
+		return &agenticv0alpha0.XAccessPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "policy"},
+			Spec: agenticv0alpha0.AccessPolicySpec{
+				Rules: []agenticv0alpha0.AccessRule{{
+					Name: "rule1",
+					Authorization: &agenticv0alpha0.AuthorizationRule{
+						Type: agenticv0alpha0.AuthorizationRuleTypeCEL,
+						CEL:  expression,
+					},
+				}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		wantStatus metav1.ConditionStatus
+		wantReason agenticv0alpha0.AccessPolicyConditionReason
+	}{
+		{
+			name:       "valid expression",
+			expression: `request.mcp.tool_name.startsWith("read_")`,
+			wantStatus: metav1.ConditionFalse,
+			wantReason: agenticv0alpha0.AccessPolicyReasonValid,
+		},
+		{
+			name:       "syntax error",
+			expression: `request.mcp.tool_name ==`,
+			wantStatus: metav1.ConditionTrue,
+			wantReason: agenticv0alpha0.AccessPolicyReasonInvalid,
+		},
+		{
+			name:       "undeclared variable",
+			expression: `tool_name == "read"`,
+			wantStatus: metav1.ConditionTrue,
+			wantReason: agenticv0alpha0.AccessPolicyReasonInvalid,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := newPolicy(tc.expression)
+			condition := invalidRulesCondition(policy)
+			if condition.Status != tc.wantStatus || condition.Reason != string(tc.wantReason) {
+				t.Errorf("unexpected InvalidRules condition: %+v", condition)
+			}
+			// Invalid rules do not prevent the other rules of the policy from being enforced.
+			if accepted := acceptedCondition(policy); accepted.Status != metav1.ConditionTrue {
+				t.Errorf("unexpected Accepted condition: %+v", accepted)
+			}
+		})
+	}
+}
+
+func TestInactiveRulesCondition(t *testing.T) {
+	now := time.Date(2025, time.June, 2, 10, 0, 0, 0, time.UTC)
+	newPolicy := func(rules ...agenticv0alpha0.AccessRule) *agenticv0alpha0.XAccessPolicy {
+		return &agenticv0alpha0.XAccessPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "policy"},
+			Spec:       agenticv0alpha0.AccessPolicySpec{Rules: rules},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		policy      *agenticv0alpha0.XAccessPolicy
+		wantStatus  metav1.ConditionStatus
+		wantReason  agenticv0alpha0.AccessPolicyConditionReason
+		wantMessage string
+	}{
+		{
+			name:       "rules in force",
+			policy:     newPolicy(agenticv0alpha0.AccessRule{Name: "always"}, agenticv0alpha0.AccessRule{Name: "on-call", NotAfter: &metav1.Time{Time: now.Add(time.Hour)}}),
+			wantStatus: metav1.ConditionFalse,
+			wantReason: agenticv0alpha0.AccessPolicyReasonActive,
+		},
+		{
+			name:        "expired rule",
+			policy:      newPolicy(agenticv0alpha0.AccessRule{Name: "on-call", NotAfter: &metav1.Time{Time: now.Add(-time.Hour)}}),
+			wantStatus:  metav1.ConditionTrue,
+			wantReason:  agenticv0alpha0.AccessPolicyReasonInactive,
+			wantMessage: "rule on-call expired at 2025-06-02T09:00:00Z",
+		},
+		{
+			name: "rule outside its windows",
+			policy: newPolicy(agenticv0alpha0.AccessRule{Name: "nightly", Windows: []agenticv0alpha0.AccessRuleTimeWindow{
+				{Start: "22:00", End: "06:00"},
+			}}),
+			wantStatus:  metav1.ConditionTrue,
+			wantReason:  agenticv0alpha0.AccessPolicyReasonInactive,
+			wantMessage: "rule nightly is not in force until 2025-06-02T22:00:00Z",
+		},
+		{
+			name: "rule with an invalid schedule",
+			policy: newPolicy(agenticv0alpha0.AccessRule{Name: "nightly", Windows: []agenticv0alpha0.AccessRuleTimeWindow{
+				{Start: "22:00", End: "06:00", TimeZone: ptr.To("Mars/Olympus_Mons")},
+			}}),
+			wantStatus: metav1.ConditionFalse,
+			wantReason: agenticv0alpha0.AccessPolicyReasonActive,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			condition := inactiveRulesCondition(tc.policy, now)
+			if condition.Status != tc.wantStatus || condition.Reason != string(tc.wantReason) {
+				t.Errorf("unexpected InactiveRules condition: %+v", condition)
+			}
+			if !strings.Contains(condition.Message, tc.wantMessage) {
+				t.Errorf("expected message to contain %q, got %q", tc.wantMessage, condition.Message)
+			}
+		})
+	}
+}
+
+func TestInvalidRulesCondition_RuleSchedules(t *testing.T) {
+	policy := &agenticv0alpha0.XAccessPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "policy"},
+		Spec: agenticv0alpha0.AccessPolicySpec{
+			Rules: []agenticv0alpha0.AccessRule{{
+				Name:    "nightly",
+				Windows: []agenticv0alpha0.AccessRuleTimeWindow{{Start: "22:00", End: "06:00", TimeZone: ptr.To("Mars/Olympus_Mons")}},
+			}},
+		},
+	}
+	condition := invalidRulesCondition(policy)
+	if condition.Status != metav1.ConditionTrue || condition.Reason != string(agenticv0alpha0.AccessPolicyReasonInvalid) {
+		t.Fatalf("unexpected InvalidRules condition: %+v", condition)
+	}
+	if !strings.Contains(condition.Message, `rule nightly: unknown time zone "Mars/Olympus_Mons"`) {
+		t.Errorf("expected message to report the unknown time zone, got %q", condition.Message)
+	}
+}
+
+func TestAcceptedCondition_AuditMode(t *testing.T) {
+	policy := &agenticv0alpha0.XAccessPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "policy"},
+		Spec:       agenticv0alpha0.AccessPolicySpec{Mode: agenticv0alpha0.AccessPolicyModeAudit},
+	}
+	condition := acceptedCondition(policy)
+	if condition.Status != metav1.ConditionTrue || condition.Reason != string(agenticv0alpha0.AccessPolicyReasonAccepted) {
+		t.Fatalf("unexpected Accepted condition: %+v", condition)
+	}
+	if !strings.Contains(condition.Message, "Audit mode") {
+		t.Errorf("expected message to mention the Audit mode, got %q", condition.Message)
+	}
+}
@@ -19,13 +19,18 @@ package controller
 import (
 	"context"
 	"fmt"
+	"maps"
 	"reflect"
+	"slices"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
@@ -33,6 +38,7 @@ import (
 	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
 	agenticinformers "sigs.k8s.io/kube-agentic-networking/k8s/client/informers/externalversions/api/v0alpha0"
 	"sigs.k8s.io/kube-agentic-networking/pkg/constants"
+	"sigs.k8s.io/kube-agentic-networking/pkg/infra/clusterhealth"
 )
 
 func (c *Controller) setupBackendEventHandlers(backendInformer agenticinformers.XBackendInformer) error {
@@ -48,7 +54,9 @@ func (c *Controller) onBackendAdd(obj interface{}) {
 	backend := obj.(*agenticv0alpha0.XBackend)
 	klog.V(4).InfoS("Adding Backend", "backend", klog.KObj(backend))
 	c.enqueueBackendForFinalizer(backend)
+	c.enqueueBackendForStatus(backend)
 	c.enqueueGatewaysForBackend(backend)
+	c.enqueueAccessPoliciesForBackend(backend.Namespace, backend.Name)
 }
 
 func (c *Controller) onBackendUpdate(old, newObj interface{}) {
@@ -57,7 +65,9 @@ func (c *Controller) onBackendUpdate(old, newObj interface{}) {
 	if newBackend.Generation != oldBackend.Generation || newBackend.DeletionTimestamp != oldBackend.DeletionTimestamp || !reflect.DeepEqual(newBackend.Annotations, oldBackend.Annotations) {
 		klog.V(4).InfoS("Updating Backend", "backend", klog.KObj(oldBackend))
 		c.enqueueBackendForFinalizer(newBackend)
+		c.enqueueBackendForStatus(newBackend)
 		c.enqueueGatewaysForBackend(newBackend)
+		c.enqueueAccessPoliciesForBackend(newBackend.Namespace, newBackend.Name)
 	}
 }
 
@@ -78,6 +88,7 @@ func (c *Controller) onBackendDelete(obj interface{}) {
 	klog.V(4).InfoS("Deleting Backend", "backend", klog.KObj(backend))
 	c.enqueueBackendForFinalizer(backend)
 	c.enqueueGatewaysForBackend(backend)
+	c.enqueueAccessPoliciesForBackend(backend.Namespace, backend.Name)
 }
 
 // enqueueBackendForFinalizer enqueues the XBackend for finalizer sync only (add/remove finalizer based on XAccessPolicy targetRefs). It does not enqueue Gateways.
@@ -146,13 +157,67 @@ func hasAccessPoliciesTargetingBackend(c *Controller, backend *agenticv0alpha0.X
 
 // enqueueGatewaysForBackend enqueues Gateways that reference this backend
 func (c *Controller) enqueueGatewaysForBackend(backend *agenticv0alpha0.XBackend) {
-	routes, err := c.gateway.httprouteLister.List(labels.Everything())
+	gatewaysToEnqueue, err := c.gatewayKeysForBackend(backend)
 	if err != nil {
-		runtime.HandleError(fmt.Errorf("failed to list httproutes: %w", err))
+		runtime.HandleError(err)
 		return
 	}
 
-	gatewaysToEnqueue := make(map[string]struct{})
+	for key := range gatewaysToEnqueue {
+		klog.V(4).InfoS("Enqueuing gateway for backend change", "gateway", key, "backend", klog.KObj(backend))
+		c.gatewayqueue.Add(key)
+	}
+}
+
+// enqueueGatewaysForReferencedObject enqueues the Gateways of the XBackends whose TLS, credential injection or token
+// exchange configuration references the ConfigMap or Secret with the given kind, namespace and name.
+func (c *Controller) enqueueGatewaysForReferencedObject(kind, namespace, name string) {
+	backends, err := c.agentic.backendLister.XBackends(namespace).List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	for _, backend := range backends {
+		if !backendReferencesObject(backend, kind, name) {
+			continue
+		}
+		klog.V(4).InfoS("XBackend references changed "+kind, "backend", klog.KObj(backend), "name", name)
+		c.enqueueGatewaysForBackend(backend)
+	}
+}
+
+// backendReferencesObject returns true if the TLS, credential injection or token exchange configuration of the
+// XBackend references the ConfigMap or Secret with the given kind and name.
+func backendReferencesObject(backend *agenticv0alpha0.XBackend, kind, name string) bool {
+	var refs []gatewayv1.LocalObjectReference
+	if tls := backend.Spec.MCP.TLS; tls != nil {
+		refs = append(refs, tls.CACertificateRefs...)
+		if tls.ClientCertificateRef != nil {
+			refs = append(refs, *tls.ClientCertificateRef)
+		}
+	}
+	if credentialInjection := backend.Spec.MCP.CredentialInjection; credentialInjection != nil {
+		refs = append(refs, credentialInjection.SecretRef)
+	}
+	if tokenExchange := backend.Spec.MCP.TokenExchange; tokenExchange != nil && tokenExchange.ClientSecretRef != nil {
+		refs = append(refs, *tokenExchange.ClientSecretRef)
+	}
+	for _, ref := range refs {
+		if ref.Group == "" && string(ref.Kind) == kind && string(ref.Name) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// gatewayKeysForBackend returns the keys of the Gateways that are parents of HTTPRoutes referencing this backend.
+func (c *Controller) gatewayKeysForBackend(backend *agenticv0alpha0.XBackend) (map[string]struct{}, error) {
+	routes, err := c.gateway.httprouteLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list httproutes: %w", err)
+	}
+
+	gatewayKeys := make(map[string]struct{})
 
 	for _, route := range routes {
 		referencesBackend := false
@@ -188,15 +253,84 @@ func (c *Controller) enqueueGatewaysForBackend(backend *agenticv0alpha0.XBackend
 					namespace = string(*parentRef.Namespace)
 				}
 				key := namespace + "/" + string(parentRef.Name)
-				gatewaysToEnqueue[key] = struct{}{}
+				gatewayKeys[key] = struct{}{}
 			}
 		}
 	}
+	return gatewayKeys, nil
+}
 
-	for key := range gatewaysToEnqueue {
-		klog.V(4).InfoS("Enqueuing gateway for backend change", "gateway", key, "backend", klog.KObj(backend))
-		c.gatewayqueue.Add(key)
+// gatewaysForBackend returns the Gateways owned by this controller the backend is reachable through, sorted by
+// namespace and name.
+func (c *Controller) gatewaysForBackend(backend *agenticv0alpha0.XBackend) []*gatewayv1.Gateway {
+	gatewayKeys, err := c.gatewayKeysForBackend(backend)
+	if err != nil {
+		runtime.HandleError(err)
+		return nil
+	}
+
+	var gateways []*gatewayv1.Gateway
+	for _, key := range slices.Sorted(maps.Keys(gatewayKeys)) {
+		namespace, name, err := cache.SplitMetaNamespaceKey(key)
+		if err != nil {
+			continue
+		}
+		gateway, err := c.gateway.gatewayLister.Gateways(namespace).Get(name)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				runtime.HandleError(fmt.Errorf("failed to get gateway %s: %w", key, err))
+			}
+			continue
+		}
+		if !c.isGatewayOwnedByController(gateway) {
+			continue
+		}
+		gateways = append(gateways, gateway)
+	}
+	return gateways
+}
+
+// backendsReachableThroughGateway returns the XBackends referenced by the HTTPRoutes attached to the given Gateway.
+func (c *Controller) backendsReachableThroughGateway(namespace, name string) map[types.NamespacedName]struct{} {
+	routes, err := c.gateway.httprouteLister.List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("failed to list httproutes: %w", err))
+		return nil
+	}
+
+	backends := make(map[types.NamespacedName]struct{})
+	for _, route := range routes {
+		attached := false
+		for _, parentRef := range route.Spec.ParentRefs {
+			if !isGatewayParentRef(parentRef) {
+				continue
+			}
+			parentNamespace := route.Namespace
+			if parentRef.Namespace != nil {
+				parentNamespace = string(*parentRef.Namespace)
+			}
+			if parentNamespace == namespace && string(parentRef.Name) == name {
+				attached = true
+				break
+			}
+		}
+		if !attached {
+			continue
+		}
+		for _, rule := range route.Spec.Rules {
+			for _, ref := range rule.BackendRefs {
+				if !isXBackendRef(ref.BackendRef) {
+					continue
+				}
+				refNamespace := route.Namespace
+				if ref.Namespace != nil {
+					refNamespace = string(*ref.Namespace)
+				}
+				backends[types.NamespacedName{Namespace: refNamespace, Name: string(ref.Name)}] = struct{}{}
+			}
+		}
 	}
+	return backends
 }
 
 // isXBackendRef checks if a given BackendRef refers to an XBackend resource.
@@ -210,3 +344,87 @@ func isGatewayParentRef(parentRef gatewayv1.ParentReference) bool {
 	return (parentRef.Group == nil || string(*parentRef.Group) == gatewayv1.GroupName) &&
 		(parentRef.Kind == nil || string(*parentRef.Kind) == "Gateway")
 }
+
+// enqueueBackendForStatus enqueues the XBackend for the reconciliation of its Healthy condition.
+func (c *Controller) enqueueBackendForStatus(backend *agenticv0alpha0.XBackend) {
+	c.backendStatusQueue.Add(backend.Namespace + "/" + backend.Name)
+}
+
+// enqueueBackendsForCluster enqueues for status reconciliation the XBackends whose cluster has the given name. It is
+// called by the metrics service when the health of a cluster reported by a proxy changes.
+func (c *Controller) enqueueBackendsForCluster(cluster string) {
+	backends, err := c.agentic.backendLister.List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("failed to list backends: %w", err))
+		return
+	}
+	for _, backend := range backends {
+		// Cluster names are not split into namespace and name, since both may contain the separator.
+		if fmt.Sprintf(constants.ClusterNameFormat, backend.Namespace, backend.Name) == cluster {
+			c.enqueueBackendForStatus(backend)
+		}
+	}
+}
+
+// syncBackendStatus sets the Healthy condition of an XBackend from the health of its endpoints reported by the
+// proxies, or removes it if no connected proxy routes to the XBackend.
+func (c *Controller) syncBackendStatus(ctx context.Context, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("invalid backend key %s: %w", key, err))
+		return nil
+	}
+	health, reported := c.clusterHealth.Health(fmt.Sprintf(constants.ClusterNameFormat, namespace, name))
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		// GET the latest version of the backend from the cache.
+		originalBackend, err := c.agentic.backendLister.XBackends(namespace).Get(name)
+		if apierrors.IsNotFound(err) {
+			// Backend has been deleted, nothing to do.
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		backendToUpdate := originalBackend.DeepCopy()
+		if reported {
+			condition := backendHealthyCondition(health)
+			condition.ObservedGeneration = backendToUpdate.Generation
+			meta.SetStatusCondition(&backendToUpdate.Status.Conditions, condition)
+		} else {
+			meta.RemoveStatusCondition(&backendToUpdate.Status.Conditions, string(agenticv0alpha0.BackendConditionHealthy))
+		}
+
+		// Only make an API call if the status has actually changed.
+		if !semanticIgnoreLastTransitionTime.DeepEqual(originalBackend.Status, backendToUpdate.Status) {
+			_, updateErr := c.agentic.client.AgenticV0alpha0().XBackends(namespace).UpdateStatus(ctx, backendToUpdate, metav1.UpdateOptions{})
+			return updateErr
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update status for XBackend %s: %w", key, err)
+	}
+	return nil
+}
+
+// backendHealthyCondition returns the Healthy condition of an XBackend whose endpoints have the given health.
+func backendHealthyCondition(health clusterhealth.Health) metav1.Condition {
+	condition := metav1.Condition{
+		Type:    string(agenticv0alpha0.BackendConditionHealthy),
+		Status:  metav1.ConditionTrue,
+		Reason:  string(agenticv0alpha0.BackendReasonHealthy),
+		Message: fmt.Sprintf("%d of %d endpoints are healthy", health.Healthy, health.Total),
+	}
+	switch {
+	case health.Healthy == 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = string(agenticv0alpha0.BackendReasonUnhealthy)
+		if health.Total == 0 {
+			condition.Message = "The backend has no endpoints"
+		}
+	case health.Healthy < health.Total:
+		condition.Reason = string(agenticv0alpha0.BackendReasonPartiallyHealthy)
+	}
+	return condition
+}
@@ -17,16 +17,23 @@ limitations under the License.
 package controller
 
 import (
+	"context"
+	"slices"
 	"testing"
 
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
+	agenticclientfake "sigs.k8s.io/kube-agentic-networking/k8s/client/clientset/versioned/fake"
 	agenticlisters "sigs.k8s.io/kube-agentic-networking/k8s/client/listers/api/v0alpha0"
+	"sigs.k8s.io/kube-agentic-networking/pkg/infra/clusterhealth"
 )
 
 func TestHasAccessPoliciesTargetingBackend(t *testing.T) {
@@ -187,3 +194,134 @@ func TestHasAccessPoliciesTargetingBackend(t *testing.T) {
 		}
 	})
 }
+
+// fakeClusterHealth is the health of the endpoints of the clusters reported by the proxies.
+type fakeClusterHealth map[string]clusterhealth.Health
+
+func (f fakeClusterHealth) Health(cluster string) (clusterhealth.Health, bool) {
+	health, ok := f[cluster]
+	return health, ok
+}
+
+func TestSyncBackendStatus(t *testing.T) {
+	ns := "default"
+	newBackend := func(name string, conditions ...metav1.Condition) *agenticv0alpha0.XBackend {
+		return &agenticv0alpha0.XBackend{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name, Generation: 2},
+			Status:     agenticv0alpha0.BackendStatus{Conditions: conditions},
+		}
+	}
+	healthyCondition := metav1.Condition{
+		Type:               string(agenticv0alpha0.BackendConditionHealthy),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(agenticv0alpha0.BackendReasonHealthy),
+		LastTransitionTime: metav1.Now(),
+	}
+	otherCondition := metav1.Condition{
+		Type:               "Other",
+		Status:             metav1.ConditionTrue,
+		Reason:             "Other",
+		LastTransitionTime: metav1.Now(),
+	}
+	backends := []*agenticv0alpha0.XBackend{
+		newBackend("healthy"),
+		newBackend("partially-healthy"),
+		newBackend("unhealthy"),
+		newBackend("no-endpoints"),
+		newBackend("not-routed", healthyCondition, otherCondition),
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	var clientObjs []runtime.Object
+	for _, backend := range backends {
+		if err := indexer.Add(backend); err != nil {
+			t.Fatalf("indexer.Add: %v", err)
+		}
+		clientObjs = append(clientObjs, backend)
+	}
+	client := agenticclientfake.NewSimpleClientset(clientObjs...)
+	c := &Controller{
+		agentic: agenticNetResources{
+			client:        client,
+			backendLister: agenticlisters.NewXBackendLister(indexer),
+		},
+		clusterHealth: fakeClusterHealth{
+			"default-healthy":           {Healthy: 3, Total: 3},
+			"default-partially-healthy": {Healthy: 1, Total: 3},
+			"default-unhealthy":         {Healthy: 0, Total: 2},
+			"default-no-endpoints":      {},
+		},
+	}
+
+	tests := []struct {
+		backend    string
+		wantStatus metav1.ConditionStatus
+		wantReason agenticv0alpha0.BackendConditionReason
+	}{
+		{backend: "healthy", wantStatus: metav1.ConditionTrue, wantReason: agenticv0alpha0.BackendReasonHealthy},
+		{backend: "partially-healthy", wantStatus: metav1.ConditionTrue, wantReason: agenticv0alpha0.BackendReasonPartiallyHealthy},
+		{backend: "unhealthy", wantStatus: metav1.ConditionFalse, wantReason: agenticv0alpha0.BackendReasonUnhealthy},
+		{backend: "no-endpoints", wantStatus: metav1.ConditionFalse, wantReason: agenticv0alpha0.BackendReasonUnhealthy},
+		{backend: "not-routed"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.backend, func(t *testing.T) {
+			if err := c.syncBackendStatus(context.Background(), ns+"/"+tc.backend); err != nil {
+				t.Fatalf("syncBackendStatus: %v", err)
+			}
+			backend, err := client.AgenticV0alpha0().XBackends(ns).Get(context.Background(), tc.backend, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed to get backend: %v", err)
+			}
+			condition := meta.FindStatusCondition(backend.Status.Conditions, string(agenticv0alpha0.BackendConditionHealthy))
+			if tc.wantStatus == "" {
+				if condition != nil {
+					t.Errorf("expected no Healthy condition, got %v", condition)
+				}
+				if meta.FindStatusCondition(backend.Status.Conditions, otherCondition.Type) == nil {
+					t.Errorf("expected the other conditions to be preserved, got %v", backend.Status.Conditions)
+				}
+				return
+			}
+			if condition == nil {
+				t.Fatalf("expected a Healthy condition, got %v", backend.Status.Conditions)
+			}
+			if condition.Status != tc.wantStatus || condition.Reason != string(tc.wantReason) || condition.ObservedGeneration != 2 {
+				t.Errorf("expected Healthy=%s with reason %s for generation 2, got %v", tc.wantStatus, tc.wantReason, condition)
+			}
+		})
+	}
+}
+
+func TestEnqueueBackendsForCluster(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, backend := range []*agenticv0alpha0.XBackend{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: "a-mcp"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "mcp"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: "other"}},
+	} {
+		if err := indexer.Add(backend); err != nil {
+			t.Fatalf("indexer.Add: %v", err)
+		}
+	}
+	c := &Controller{
+		agentic: agenticNetResources{
+			backendLister: agenticlisters.NewXBackendLister(indexer),
+		},
+		backendStatusQueue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			workqueue.DefaultTypedControllerRateLimiter[string](),
+			workqueue.TypedRateLimitingQueueConfig[string]{Name: "backend-status"},
+		),
+	}
+
+	c.enqueueBackendsForCluster("team-a-mcp")
+	var keys []string
+	for c.backendStatusQueue.Len() > 0 {
+		key, _ := c.backendStatusQueue.Get()
+		keys = append(keys, key)
+		c.backendStatusQueue.Done(key)
+	}
+	slices.Sort(keys)
+	if want := []string{"team-a/mcp", "team/a-mcp"}; !slices.Equal(keys, want) {
+		t.Errorf("expected the XBackends of cluster team-a-mcp %v to be enqueued, got %v", want, keys)
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+func (c *Controller) setupConfigMapEventHandlers(informer corev1informers.ConfigMapInformer) error {
+	_, err := informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.onConfigMapAdd,
+		UpdateFunc: c.onConfigMapUpdate,
+		DeleteFunc: c.onConfigMapDelete,
+	})
+	return err
+}
+
+func (c *Controller) onConfigMapAdd(obj interface{}) {
+	configMap := obj.(*corev1.ConfigMap)
+	c.enqueueGatewaysForReferencedObject("ConfigMap", configMap.Namespace, configMap.Name)
+}
+
+func (c *Controller) onConfigMapUpdate(old, newObj interface{}) {
+	oldConfigMap := old.(*corev1.ConfigMap)
+	newConfigMap := newObj.(*corev1.ConfigMap)
+	if !reflect.DeepEqual(oldConfigMap.Data, newConfigMap.Data) {
+		klog.V(4).InfoS("ConfigMap updated", "configMap", klog.KObj(newConfigMap))
+		c.enqueueGatewaysForReferencedObject("ConfigMap", newConfigMap.Namespace, newConfigMap.Name)
+	}
+}
+
+func (c *Controller) onConfigMapDelete(obj interface{}) {
+	configMap, ok := obj.(*corev1.ConfigMap)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			runtime.HandleError(fmt.Errorf("couldn't get object from tombstone %#v", obj))
+			return
+		}
+		configMap, ok = tombstone.Obj.(*corev1.ConfigMap)
+		if !ok {
+			runtime.HandleError(fmt.Errorf("tombstone contained object that is not a ConfigMap %#v", obj))
+			return
+		}
+	}
+	c.enqueueGatewaysForReferencedObject("ConfigMap", configMap.Namespace, configMap.Name)
+}
@@ -35,6 +35,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 
 	gatewayclient "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
 	gatewayinformers "sigs.k8s.io/gateway-api/pkg/client/informers/externalversions/apis/v1"
@@ -44,6 +45,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
@@ -51,7 +53,9 @@ import (
 	agenticinformers "sigs.k8s.io/kube-agentic-networking/k8s/client/informers/externalversions/api/v0alpha0"
 	agenticlisters "sigs.k8s.io/kube-agentic-networking/k8s/client/listers/api/v0alpha0"
 	"sigs.k8s.io/kube-agentic-networking/pkg/constants"
+	"sigs.k8s.io/kube-agentic-networking/pkg/infra/clusterhealth"
 	"sigs.k8s.io/kube-agentic-networking/pkg/infra/envoy"
+	"sigs.k8s.io/kube-agentic-networking/pkg/infra/tokenexchange"
 	"sigs.k8s.io/kube-agentic-networking/pkg/infra/xds"
 	"sigs.k8s.io/kube-agentic-networking/pkg/translator"
 )
@@ -67,6 +71,9 @@ type coreResources struct {
 
 	secretLister corev1listers.SecretLister
 	secretSynced cache.InformerSynced
+
+	configMapLister corev1listers.ConfigMapLister
+	configMapSynced cache.InformerSynced
 }
 
 type gatewayResources struct {
@@ -104,10 +111,19 @@ type Controller struct {
 	agenticIdentityTrustDomain string
 	envoyImage                 string
 
-	gatewayqueue          workqueue.TypedRateLimitingInterface[string]
-	backendFinalizerQueue workqueue.TypedRateLimitingInterface[string]
-	xdsServer             *xds.Server
-	translator            *translator.Translator
+	gatewayqueue            workqueue.TypedRateLimitingInterface[string]
+	backendFinalizerQueue   workqueue.TypedRateLimitingInterface[string]
+	accessPolicyStatusQueue workqueue.TypedRateLimitingInterface[string]
+	backendStatusQueue      workqueue.TypedRateLimitingInterface[string]
+	xdsServer               *xds.Server
+	tokenExchange           *tokenexchange.Server
+	translator              *translator.Translator
+
+	// clusterHealth returns the health of the endpoints of the clusters reported by the proxies, see
+	// clusterhealth.Server.
+	clusterHealth interface {
+		Health(cluster string) (clusterhealth.Health, bool)
+	}
 }
 
 // New returns a new *Controller with the event handlers setup for types we are interested in.
@@ -121,6 +137,7 @@ func New(
 	namespaceInformer corev1informers.NamespaceInformer,
 	serviceInformer corev1informers.ServiceInformer,
 	secretInformer corev1informers.SecretInformer,
+	configMapInformer corev1informers.ConfigMapInformer,
 	gatewayClassInformer gatewayinformers.GatewayClassInformer,
 	gatewayInformer gatewayinformers.GatewayInformer,
 	httprouteInformer gatewayinformers.HTTPRouteInformer,
@@ -130,13 +147,15 @@ func New(
 ) (*Controller, error) {
 	c := &Controller{
 		core: coreResources{
-			client:       kubeClientSet,
-			nsLister:     namespaceInformer.Lister(),
-			nsSynced:     namespaceInformer.Informer().HasSynced,
-			svcLister:    serviceInformer.Lister(),
-			svcSynced:    serviceInformer.Informer().HasSynced,
-			secretLister: secretInformer.Lister(),
-			secretSynced: secretInformer.Informer().HasSynced,
+			client:          kubeClientSet,
+			nsLister:        namespaceInformer.Lister(),
+			nsSynced:        namespaceInformer.Informer().HasSynced,
+			svcLister:       serviceInformer.Lister(),
+			svcSynced:       serviceInformer.Informer().HasSynced,
+			secretLister:    secretInformer.Lister(),
+			secretSynced:    secretInformer.Informer().HasSynced,
+			configMapLister: configMapInformer.Lister(),
+			configMapSynced: configMapInformer.Informer().HasSynced,
 		},
 		gateway: gatewayResources{
 			client:               gwClientSet,
@@ -167,8 +186,19 @@ func New(
 			workqueue.DefaultTypedControllerRateLimiter[string](),
 			workqueue.TypedRateLimitingQueueConfig[string]{Name: "backend-finalizer"},
 		),
-		xdsServer: xds.NewServer(ctx),
+		accessPolicyStatusQueue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			workqueue.DefaultTypedControllerRateLimiter[string](),
+			workqueue.TypedRateLimitingQueueConfig[string]{Name: "accesspolicy-status"},
+		),
+		backendStatusQueue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			workqueue.DefaultTypedControllerRateLimiter[string](),
+			workqueue.TypedRateLimitingQueueConfig[string]{Name: "backend-status"},
+		),
 	}
+	clusterHealth := clusterhealth.NewServer(c.enqueueBackendsForCluster)
+	c.clusterHealth = clusterHealth
+	c.xdsServer = xds.NewServer(ctx, clusterHealth)
+	c.tokenExchange = tokenexchange.NewServer(agenticIdentityTrustDomain, backendInformer.Lister(), secretInformer.Lister(), clock.RealClock{})
 
 	c.translator = translator.New(
 		agenticIdentityTrustDomain,
@@ -177,6 +207,7 @@ func New(
 		namespaceInformer.Lister(),
 		serviceInformer.Lister(),
 		secretInformer.Lister(),
+		configMapInformer.Lister(),
 		gatewayInformer.Lister(),
 		httprouteInformer.Lister(),
 		referenceGrantInformer.Lister(),
@@ -203,6 +234,15 @@ func New(
 	if err := c.setupServiceEventHandlers(serviceInformer); err != nil {
 		return nil, err
 	}
+	if err := c.setupNamespaceEventHandlers(namespaceInformer); err != nil {
+		return nil, err
+	}
+	if err := c.setupSecretEventHandlers(secretInformer); err != nil {
+		return nil, err
+	}
+	if err := c.setupConfigMapEventHandlers(configMapInformer); err != nil {
+		return nil, err
+	}
 
 	return c, nil
 }
@@ -214,6 +254,8 @@ func (c *Controller) Run(ctx context.Context, workers int) error {
 	defer runtime.HandleCrashWithContext(ctx)
 	defer c.gatewayqueue.ShutDown()
 	defer c.backendFinalizerQueue.ShutDown()
+	defer c.accessPolicyStatusQueue.ShutDown()
+	defer c.backendStatusQueue.ShutDown()
 
 	// start the xDS server
 	klog.Info("Starting the Envoy xDS server")
@@ -226,6 +268,7 @@ func (c *Controller) Run(ctx context.Context, workers int) error {
 		c.core.nsSynced,
 		c.core.svcSynced,
 		c.core.secretSynced,
+		c.core.configMapSynced,
 		c.gateway.gatewayClassSynced,
 		c.gateway.gatewaySynced,
 		c.gateway.httprouteSynced,
@@ -243,6 +286,14 @@ func (c *Controller) Run(ctx context.Context, workers int) error {
 	for i := 0; i < workers; i++ {
 		go wait.UntilWithContext(ctx, c.runBackendFinalizerWorker, time.Second)
 	}
+	klog.InfoS("Starting access policy status workers", "count", workers)
+	for i := 0; i < workers; i++ {
+		go wait.UntilWithContext(ctx, c.runAccessPolicyStatusWorker, time.Second)
+	}
+	klog.InfoS("Starting backend status workers", "count", workers)
+	for i := 0; i < workers; i++ {
+		go wait.UntilWithContext(ctx, c.runBackendStatusWorker, time.Second)
+	}
 
 	klog.Info("Started workers")
 	<-ctx.Done()
@@ -251,6 +302,16 @@ func (c *Controller) Run(ctx context.Context, workers int) error {
 	return nil
 }
 
+// RunTokenExchange starts the token exchange server, which authenticates the Gateway proxies with their agentic
+// identity and presents the agentic identity of the controller, issued by the given issuer.
+func (c *Controller) RunTokenExchange(ctx context.Context, issuer tokenexchange.CertificateIssuer) error {
+	klog.Info("Starting the token exchange server")
+	if err := c.tokenExchange.Run(ctx, issuer); err != nil {
+		return fmt.Errorf("failed to start token exchange server: %w", err)
+	}
+	return nil
+}
+
 // runWorker is a long-running function that will continually call the
 // processNextGatewayItem function in order to read and process a message on the
 // workqueue.
@@ -303,6 +364,46 @@ func (c *Controller) processNextBackendFinalizerItem(ctx context.Context) bool {
 	return true
 }
 
+func (c *Controller) runAccessPolicyStatusWorker(ctx context.Context) {
+	for c.processNextAccessPolicyStatusItem(ctx) {
+	}
+}
+
+func (c *Controller) processNextAccessPolicyStatusItem(ctx context.Context) bool {
+	obj, shutdown := c.accessPolicyStatusQueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.accessPolicyStatusQueue.Done(obj)
+	if err := c.syncAccessPolicyStatus(ctx, obj); err != nil {
+		c.accessPolicyStatusQueue.AddRateLimited(obj)
+		klog.ErrorS(err, "Error syncing access policy status", "key", obj)
+		return true
+	}
+	c.accessPolicyStatusQueue.Forget(obj)
+	return true
+}
+
+func (c *Controller) runBackendStatusWorker(ctx context.Context) {
+	for c.processNextBackendStatusItem(ctx) {
+	}
+}
+
+func (c *Controller) processNextBackendStatusItem(ctx context.Context) bool {
+	obj, shutdown := c.backendStatusQueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.backendStatusQueue.Done(obj)
+	if err := c.syncBackendStatus(ctx, obj); err != nil {
+		c.backendStatusQueue.AddRateLimited(obj)
+		klog.ErrorS(err, "Error syncing backend status", "key", obj)
+		return true
+	}
+	c.backendStatusQueue.Forget(obj)
+	return true
+}
+
 // syncGateway compares the actual state with the desired, and attempts to
 // converge the two.
 func (c *Controller) syncGateway(ctx context.Context, key string) error {
@@ -325,6 +426,8 @@ func (c *Controller) syncGateway(ctx context.Context, key string) error {
 	gateway, err := c.gateway.gatewayLister.Gateways(namespace).Get(name)
 	if apierrors.IsNotFound(err) {
 		logger.Info("Gateway deleted, cleaning up associated resources.")
+		c.enqueueAccessPoliciesForGateway(namespace, name)
+		c.tokenExchange.SetProxyBackends(types.NamespacedName{Namespace: namespace, Name: envoy.ProxyName(namespace, name)}, nil)
 		return envoy.DeleteProxy(ctx, c.core.client, namespace, name)
 	}
 	if err != nil {
@@ -345,6 +448,7 @@ func (c *Controller) syncGateway(ctx context.Context, key string) error {
 			logger.V(4).Info("Gateway has HTTPRoutes still referencing it, blocking deletion")
 			return nil
 		}
+		c.tokenExchange.SetProxyBackends(types.NamespacedName{Namespace: namespace, Name: envoy.ProxyName(namespace, name)}, nil)
 		if errDel := envoy.DeleteProxy(ctx, c.core.client, namespace, name); errDel != nil {
 			return errDel
 		}
@@ -399,6 +503,11 @@ func (c *Controller) syncGateway(ctx context.Context, key string) error {
 	newGW.Status.Listeners = listenerStatuses
 	// Update the xDS server with the new resources.
 	err = c.xdsServer.UpdateXDSServer(ctx, rm.NodeID(), resources)
+	if err == nil {
+		// The proxy runs as the ServiceAccount named after its node ID, and only gets tokens for the XBackends it
+		// routes to.
+		c.tokenExchange.SetProxyBackends(types.NamespacedName{Namespace: namespace, Name: rm.NodeID()}, translator.TokenExchangeBackends(resources))
+	}
 
 	setGatewayConditions(newGW, listenerStatuses, err)
 
@@ -410,6 +519,8 @@ func (c *Controller) syncGateway(ctx context.Context, key string) error {
 		}
 		logger.Info("Updated gateway status")
 	}
+	// The XBackends reachable through the Gateway may have changed along with its HTTPRoutes.
+	c.enqueueAccessPoliciesForGateway(namespace, name)
 	return c.updateHTTPRouteStatuses(ctx, httpRouteStatuses)
 }
 
@@ -0,0 +1,100 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
+	"sigs.k8s.io/kube-agentic-networking/pkg/translator"
+)
+
+func (c *Controller) setupNamespaceEventHandlers(informer corev1informers.NamespaceInformer) error {
+	_, err := informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.onNamespaceAdd,
+		UpdateFunc: c.onNamespaceUpdate,
+		DeleteFunc: c.onNamespaceDelete,
+	})
+	return err
+}
+
+func (c *Controller) onNamespaceAdd(obj interface{}) {
+	ns := obj.(*corev1.Namespace)
+	klog.V(4).InfoS("Namespace added", "namespace", klog.KObj(ns))
+	c.enqueueGatewaysForNamespaceSelectors()
+}
+
+func (c *Controller) onNamespaceUpdate(old, newObj interface{}) {
+	oldNs := old.(*corev1.Namespace)
+	newNs := newObj.(*corev1.Namespace)
+
+	if !reflect.DeepEqual(oldNs.Labels, newNs.Labels) {
+		klog.V(4).InfoS("Namespace labels updated", "namespace", klog.KObj(newNs))
+		c.enqueueGatewaysForNamespaceSelectors()
+	}
+}
+
+func (c *Controller) onNamespaceDelete(obj interface{}) {
+	ns, ok := obj.(*corev1.Namespace)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			runtime.HandleError(fmt.Errorf("couldn't get object from tombstone %#v", obj))
+			return
+		}
+		ns, ok = tombstone.Obj.(*corev1.Namespace)
+		if !ok {
+			runtime.HandleError(fmt.Errorf("tombstone contained object that is not a Namespace %#v", obj))
+			return
+		}
+	}
+	klog.V(4).InfoS("Deleting Namespace", "namespace", klog.KObj(ns))
+	c.enqueueGatewaysForNamespaceSelectors()
+}
+
+// enqueueGatewaysForNamespaceSelectors enqueues the Gateways affected by the XAccessPolicies with a
+// NamespaceSelector source, since the namespaces matched by their selectors may have changed.
+func (c *Controller) enqueueGatewaysForNamespaceSelectors() {
+	policies, err := c.agentic.accessPolicyLister.List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("failed to list access policies: %w", err))
+		return
+	}
+	for _, policy := range policies {
+		if hasNamespaceSelectorSource(policy) {
+			c.enqueueGatewaysForAccessPolicy(policy)
+		}
+	}
+}
+
+// hasNamespaceSelectorSource returns true if any rule of the XAccessPolicy selects its source namespaces by labels.
+func hasNamespaceSelectorSource(policy *agenticv0alpha0.XAccessPolicy) bool {
+	return slices.ContainsFunc(policy.Spec.Rules, func(rule agenticv0alpha0.AccessRule) bool {
+		return slices.ContainsFunc(translator.RuleSources(rule), func(source agenticv0alpha0.Source) bool {
+			return source.Type == agenticv0alpha0.AuthorizationSourceTypeNamespaceSelector
+		})
+	})
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
+	agenticlisters "sigs.k8s.io/kube-agentic-networking/k8s/client/listers/api/v0alpha0"
+)
+
+func TestOnNamespaceAddUpdateDelete(t *testing.T) {
+	newPolicy := func(name, gateway string, source agenticv0alpha0.Source) *agenticv0alpha0.XAccessPolicy {
+		return &agenticv0alpha0.XAccessPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: agenticv0alpha0.AccessPolicySpec{
+				TargetRefs: []gatewayv1.LocalPolicyTargetReferenceWithSectionName{{
+					LocalPolicyTargetReference: gatewayv1.LocalPolicyTargetReference{
+						Group: gatewayv1.GroupName,
+						Kind:  "Gateway",
+						Name:  gatewayv1.ObjectName(gateway),
+					},
+				}},
+				Rules: []agenticv0alpha0.AccessRule{{Name: "rule", Source: &source}},
+			},
+		}
+	}
+	policyIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, policy := range []*agenticv0alpha0.XAccessPolicy{
+		newPolicy("selector", "gw-selector", agenticv0alpha0.Source{
+			Type:              agenticv0alpha0.AuthorizationSourceTypeNamespaceSelector,
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "agents"}},
+		}),
+		newPolicy("namespace", "gw-namespace", agenticv0alpha0.Source{
+			Type:      agenticv0alpha0.AuthorizationSourceTypeNamespace,
+			Namespace: ptr.To(gatewayv1.Namespace("team-a")),
+		}),
+	} {
+		if err := policyIndexer.Add(policy); err != nil {
+			t.Fatalf("indexer.Add: %v", err)
+		}
+	}
+	c := &Controller{
+		agentic: agenticNetResources{
+			accessPolicyLister: agenticlisters.NewXAccessPolicyLister(policyIndexer),
+		},
+		gatewayqueue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			workqueue.DefaultTypedControllerRateLimiter[string](),
+			workqueue.TypedRateLimitingQueueConfig[string]{Name: "gateway"},
+		),
+	}
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"team": "agents"}}}
+	relabeled := ns.DeepCopy()
+	relabeled.Labels["team"] = "ops"
+	annotated := ns.DeepCopy()
+	annotated.Annotations = map[string]string{"foo": "bar"}
+
+	tests := []struct {
+		name  string
+		event func()
+		want  []string
+	}{
+		{
+			name:  "add",
+			event: func() { c.onNamespaceAdd(ns) },
+			want:  []string{"default/gw-selector"},
+		},
+		{
+			name:  "labels updated",
+			event: func() { c.onNamespaceUpdate(ns, relabeled) },
+			want:  []string{"default/gw-selector"},
+		},
+		{
+			name:  "labels unchanged",
+			event: func() { c.onNamespaceUpdate(ns, annotated) },
+		},
+		{
+			name:  "delete",
+			event: func() { c.onNamespaceDelete(cache.DeletedFinalStateUnknown{Key: ns.Name, Obj: ns}) },
+			want:  []string{"default/gw-selector"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.event()
+			if keys := drainGatewayQueue(c); !slices.Equal(keys, tc.want) {
+				t.Errorf("expected keys %v, got %v", tc.want, keys)
+			}
+		})
+	}
+}
+
+func TestHasNamespaceSelectorSource_Sources(t *testing.T) {
+	policy := &agenticv0alpha0.XAccessPolicy{
+		Spec: agenticv0alpha0.AccessPolicySpec{
+			Rules: []agenticv0alpha0.AccessRule{{
+				Name: "rule",
+				Sources: []agenticv0alpha0.Source{
+					{Type: agenticv0alpha0.AuthorizationSourceTypeNamespace, Namespace: ptr.To(gatewayv1.Namespace("team-a"))},
+					{Type: agenticv0alpha0.AuthorizationSourceTypeNamespaceSelector, NamespaceSelector: &metav1.LabelSelector{}},
+				},
+			}},
+		},
+	}
+	if !hasNamespaceSelectorSource(policy) {
+		t.Error("expected a namespace selector among the sources of a rule to be found")
+	}
+	policy.Spec.Rules[0].Sources = policy.Spec.Rules[0].Sources[:1]
+	if hasNamespaceSelectorSource(policy) {
+		t.Error("expected no namespace selector source")
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+func (c *Controller) setupSecretEventHandlers(informer corev1informers.SecretInformer) error {
+	_, err := informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.onSecretAdd,
+		UpdateFunc: c.onSecretUpdate,
+		DeleteFunc: c.onSecretDelete,
+	})
+	return err
+}
+
+func (c *Controller) onSecretAdd(obj interface{}) {
+	secret := obj.(*corev1.Secret)
+	c.enqueueGatewaysForReferencedObject("Secret", secret.Namespace, secret.Name)
+}
+
+func (c *Controller) onSecretUpdate(old, newObj interface{}) {
+	oldSecret := old.(*corev1.Secret)
+	newSecret := newObj.(*corev1.Secret)
+	if !reflect.DeepEqual(oldSecret.Data, newSecret.Data) {
+		klog.V(4).InfoS("Secret updated", "secret", klog.KObj(newSecret))
+		c.enqueueGatewaysForReferencedObject("Secret", newSecret.Namespace, newSecret.Name)
+	}
+}
+
+func (c *Controller) onSecretDelete(obj interface{}) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			runtime.HandleError(fmt.Errorf("couldn't get object from tombstone %#v", obj))
+			return
+		}
+		secret, ok = tombstone.Obj.(*corev1.Secret)
+		if !ok {
+			runtime.HandleError(fmt.Errorf("tombstone contained object that is not a Secret %#v", obj))
+			return
+		}
+	}
+	c.enqueueGatewaysForReferencedObject("Secret", secret.Namespace, secret.Name)
+}
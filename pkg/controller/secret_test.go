@@ -0,0 +1,146 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
+)
+
+func TestEnqueueGatewaysForReferencedObject(t *testing.T) {
+	ns := "default"
+	gwName := "my-gateway"
+
+	httpRouteIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	refGrantIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	backendIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+
+	backend := &agenticv0alpha0.XBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-backend", Namespace: ns},
+		Spec: agenticv0alpha0.BackendSpec{
+			MCP: agenticv0alpha0.MCPBackend{
+				Hostname: ptr.To("mcp.example.com"),
+				Port:     443,
+				TLS: &agenticv0alpha0.BackendTLS{
+					CACertificateRefs:    []gatewayv1.LocalObjectReference{{Kind: "ConfigMap", Name: "ca"}},
+					ClientCertificateRef: &gatewayv1.LocalObjectReference{Kind: "Secret", Name: "client"},
+				},
+				CredentialInjection: &agenticv0alpha0.BackendCredentialInjection{
+					SecretRef: gatewayv1.LocalObjectReference{Kind: "Secret", Name: "api-key"},
+				},
+			},
+		},
+	}
+	_ = backendIndexer.Add(backend)
+
+	route := &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "route1", Namespace: ns},
+		Spec: gatewayv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1.CommonRouteSpec{
+				ParentRefs: []gatewayv1.ParentReference{
+					{Name: gatewayv1.ObjectName(gwName)},
+				},
+			},
+			Rules: []gatewayv1.HTTPRouteRule{
+				{
+					BackendRefs: []gatewayv1.HTTPBackendRef{
+						{
+							BackendRef: gatewayv1.BackendRef{
+								BackendObjectReference: gatewayv1.BackendObjectReference{
+									Name:  gatewayv1.ObjectName("my-backend"),
+									Group: ptr.To(gatewayv1.Group(agenticv0alpha0.GroupName)),
+									Kind:  ptr.To(gatewayv1.Kind("XBackend")),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	_ = httpRouteIndexer.Add(route)
+
+	c := testControllerForEnqueueGatewaysForService(httpRouteIndexer, refGrantIndexer, backendIndexer)
+
+	tests := []struct {
+		name     string
+		event    func()
+		wantKeys []string
+	}{
+		{
+			name: "client certificate Secret updated",
+			event: func() {
+				c.onSecretUpdate(
+					&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "client", Namespace: ns}, Data: map[string][]byte{"tls.crt": []byte("old")}},
+					&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "client", Namespace: ns}, Data: map[string][]byte{"tls.crt": []byte("new")}},
+				)
+			},
+			wantKeys: []string{ns + "/" + gwName},
+		},
+		{
+			name: "client certificate Secret resynced",
+			event: func() {
+				secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "client", Namespace: ns}, Data: map[string][]byte{"tls.crt": []byte("old")}}
+				c.onSecretUpdate(secret, secret)
+			},
+		},
+		{
+			name: "CA certificate ConfigMap added",
+			event: func() {
+				c.onConfigMapAdd(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "ca", Namespace: ns}})
+			},
+			wantKeys: []string{ns + "/" + gwName},
+		},
+		{
+			name: "credential Secret deleted",
+			event: func() {
+				c.onSecretDelete(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "api-key", Namespace: ns}})
+			},
+			wantKeys: []string{ns + "/" + gwName},
+		},
+		{
+			name: "Secret with the name of the CA certificate ConfigMap deleted",
+			event: func() {
+				c.onSecretDelete(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "ca", Namespace: ns}})
+			},
+		},
+		{
+			name: "client certificate Secret in another namespace added",
+			event: func() {
+				c.onSecretAdd(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "client", Namespace: "other"}})
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.event()
+			keys := drainGatewayQueue(c)
+			if len(keys) != len(tc.wantKeys) || (len(keys) > 0 && keys[0] != tc.wantKeys[0]) {
+				t.Errorf("expected gateway keys %v, got %v", tc.wantKeys, keys)
+			}
+		})
+	}
+}
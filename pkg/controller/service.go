@@ -72,6 +72,7 @@ func (c *Controller) onServiceAdd(obj interface{}) {
 	svc := obj.(*corev1.Service)
 	klog.V(4).InfoS("Service added", "service", klog.KObj(svc))
 	c.enqueueGatewaysForService(svc)
+	c.enqueueAccessPoliciesForService(svc.Namespace, svc.Name)
 }
 
 func (c *Controller) onServiceUpdate(old, newObj interface{}) {
@@ -102,6 +103,7 @@ func (c *Controller) onServiceDelete(obj interface{}) {
 	}
 	klog.V(4).InfoS("Deleting Service", "service", klog.KObj(svc))
 	c.enqueueGatewaysForService(svc)
+	c.enqueueAccessPoliciesForService(svc.Namespace, svc.Name)
 }
 
 func (c *Controller) enqueueGatewaysForService(svc *corev1.Service) {
@@ -19,7 +19,10 @@ package agenticidentitysigner
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
@@ -123,21 +126,6 @@ func (h *Impl) MakeCert(_ context.Context, pcr *certsv1beta1.PodCertificateReque
 	notAfter := notBefore.Add(lifetime)
 	beginRefreshAt := notAfter.Add(-12 * time.Hour)
 
-	spiffeURI := &url.URL{
-		Scheme: "spiffe",
-		Host:   h.spiffeTrustDomain,
-		Path:   path.Join("ns", pcr.ObjectMeta.Namespace, "sa", pcr.Spec.ServiceAccountName),
-	}
-
-	template := &x509.Certificate{
-		BasicConstraintsValid: true,
-		NotBefore:             notBefore,
-		NotAfter:              notAfter,
-		URIs:                  []*url.URL{spiffeURI},
-		KeyUsage:              x509.KeyUsageDigitalSignature,
-		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
-	}
-
 	// TODO: Once Kubernetes 1.36 releases, try to read Spec.StubPKCS10Request
 	// first, then fall back to PKIXPublicKey.  PKIXPublicKey will not be
 	// carried forward to v1 PodCertificateRequest.
@@ -146,20 +134,9 @@ func (h *Impl) MakeCert(_ context.Context, pcr *certsv1beta1.PodCertificateReque
 		return nil, fmt.Errorf("while parsing PKIX public key: %w", err)
 	}
 
-	subjectCertDER, err := x509.CreateCertificate(
-		rand.Reader,
-		template,
-		curPool.CAs[0].RootCertificate,
-		subjectPublicKey,
-		curPool.CAs[0].SigningKey,
-	)
+	chainDER, err := h.signCert(curPool, pcr.ObjectMeta.Namespace, pcr.Spec.ServiceAccountName, subjectPublicKey, notBefore, notAfter)
 	if err != nil {
-		return nil, fmt.Errorf("while signing subject cert: %w", err)
-	}
-
-	chainDER := [][]byte{subjectCertDER}
-	for _, intermed := range curPool.CAs[0].IntermediateCertificates {
-		chainDER = append(chainDER, intermed.Raw)
+		return nil, err
 	}
 
 	chainPEM := &bytes.Buffer{}
@@ -190,3 +167,74 @@ func (h *Impl) MakeCert(_ context.Context, pcr *certsv1beta1.PodCertificateReque
 
 	return pcr, nil
 }
+
+// IssueCert issues a certificate of the SPIFFE identity of a ServiceAccount, valid for the given lifetime, to a
+// workload of the signer itself, which cannot get its certificate from a PodCertificateRequest.
+func (h *Impl) IssueCert(namespace, serviceAccountName string, lifetime time.Duration) (*tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("while generating key: %w", err)
+	}
+
+	notBefore := h.clock.Now().Add(-2 * time.Minute)
+	chainDER, err := h.signCert(h.caSource.Pool(), namespace, serviceAccountName, key.Public(), notBefore, notBefore.Add(lifetime))
+	if err != nil {
+		return nil, err
+	}
+
+	leaf, err := x509.ParseCertificate(chainDER[0])
+	if err != nil {
+		return nil, fmt.Errorf("while parsing subject cert: %w", err)
+	}
+	return &tls.Certificate{
+		Certificate: chainDER,
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}, nil
+}
+
+// RootCAs returns the root certificates of the CA pool, which the peers of the workloads of the signer itself must
+// be signed by.
+func (h *Impl) RootCAs() *x509.CertPool {
+	roots := x509.NewCertPool()
+	for _, ca := range h.caSource.Pool().CAs {
+		roots.AddCert(ca.RootCertificate)
+	}
+	return roots
+}
+
+// signCert signs a certificate of the SPIFFE identity of a ServiceAccount for a public key with the first CA of the
+// pool, and returns the DER-encoded certificate chain.
+func (h *Impl) signCert(pool *localca.Pool, namespace, serviceAccountName string, publicKey any, notBefore, notAfter time.Time) ([][]byte, error) {
+	spiffeURI := &url.URL{
+		Scheme: "spiffe",
+		Host:   h.spiffeTrustDomain,
+		Path:   path.Join("ns", namespace, "sa", serviceAccountName),
+	}
+
+	template := &x509.Certificate{
+		BasicConstraintsValid: true,
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		URIs:                  []*url.URL{spiffeURI},
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}
+
+	subjectCertDER, err := x509.CreateCertificate(
+		rand.Reader,
+		template,
+		pool.CAs[0].RootCertificate,
+		publicKey,
+		pool.CAs[0].SigningKey,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("while signing subject cert: %w", err)
+	}
+
+	chainDER := [][]byte{subjectCertDER}
+	for _, intermed := range pool.CAs[0].IntermediateCertificates {
+		chainDER = append(chainDER, intermed.Raw)
+	}
+	return chainDER, nil
+}
@@ -187,6 +187,39 @@ func TestCertificateIssuance(t *testing.T) {
 	}
 }
 
+func TestIssueCert(t *testing.T) {
+	_, caCert, caPrivKey := mustMakeCA(t)
+	signer := &Impl{
+		spiffeTrustDomain: "cluster1.myorg.example",
+		caSource: &fakeCASource{
+			pool: &localca.Pool{
+				CAs: []*localca.CA{{ID: "1", SigningKey: caPrivKey, RootCertificate: caCert}},
+			},
+		},
+		clock: testclock.NewFakePassiveClock(mustRFC3339(t, "1970-01-01T00:00:00Z")),
+	}
+
+	cert, err := signer.IssueCert("agentic-net-system", "controller", time.Hour)
+	if err != nil {
+		t.Fatalf("Unexpected error issuing certificate: %v", err)
+	}
+	if want := "spiffe://cluster1.myorg.example/ns/agentic-net-system/sa/controller"; len(cert.Leaf.URIs) != 1 || cert.Leaf.URIs[0].String() != want {
+		t.Errorf("Got SPIFFE IDs %v, want %s", cert.Leaf.URIs, want)
+	}
+	if want := mustRFC3339(t, "1969-12-31T23:58:00Z").Add(time.Hour); !cert.Leaf.NotAfter.Equal(want) {
+		t.Errorf("Got NotAfter %v, want %v", cert.Leaf.NotAfter, want)
+	}
+
+	// The certificate is verified with the roots of the CA pool, for both client and server authentication.
+	if _, err := cert.Leaf.Verify(x509.VerifyOptions{
+		Roots:       signer.RootCAs(),
+		CurrentTime: mustRFC3339(t, "1970-01-01T00:00:00Z"),
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}); err != nil {
+		t.Errorf("Failed to verify issued certificate: %v", err)
+	}
+}
+
 func mustRFC3339(t *testing.T, ts string) time.Time {
 	t.Helper()
 	parsed, err := time.Parse(time.RFC3339, ts)
@@ -0,0 +1,160 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clusterhealth implements the metrics service the Envoy proxies stream their stats to, from which it keeps
+// the health of the endpoints of their clusters.
+package clusterhealth
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	metricsv3 "github.com/envoyproxy/go-control-plane/envoy/service/metrics/v3"
+	prometheusv1 "github.com/prometheus/client_model/go"
+	"k8s.io/klog/v2"
+)
+
+const (
+	clusterStatPrefix       = "cluster."
+	membershipHealthySuffix = ".membership_healthy"
+	membershipTotalSuffix   = ".membership_total"
+)
+
+// Health is the health of the endpoints of a cluster.
+type Health struct {
+	// Healthy is the number of endpoints that are neither failing their active health checks nor ejected.
+	Healthy uint64
+	// Total is the number of endpoints.
+	Total uint64
+}
+
+// Server is the Envoy metrics service receiving the stats of the proxies. Each proxy streams its stats every stats
+// flush interval, see the bootstrap configuration of the proxies.
+type Server struct {
+	metricsv3.UnimplementedMetricsServiceServer
+
+	// onChange is called with the name of the clusters whose health reported by a proxy changed.
+	onChange func(cluster string)
+
+	mu         sync.Mutex
+	nextStream uint64
+	// streams holds the health of the clusters last reported on each open stream, i.e. by each proxy.
+	streams map[uint64]map[string]Health
+}
+
+// NewServer creates a metrics service calling onChange with the name of the clusters whose health reported by a
+// proxy changed, including when the proxy disconnects.
+func NewServer(onChange func(cluster string)) *Server {
+	return &Server{
+		onChange: onChange,
+		streams:  make(map[uint64]map[string]Health),
+	}
+}
+
+// StreamMetrics implements the metrics service, keeping the health of the clusters reported by a proxy until it
+// disconnects.
+func (s *Server) StreamMetrics(stream metricsv3.MetricsService_StreamMetricsServer) error {
+	s.mu.Lock()
+	id := s.nextStream
+	s.nextStream++
+	s.mu.Unlock()
+	defer s.report(id, nil)
+
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&metricsv3.StreamMetricsResponse{})
+		}
+		if err != nil {
+			return err
+		}
+		if node := msg.GetIdentifier().GetNode(); node != nil {
+			klog.V(4).InfoS("Proxy streaming metrics", "node", node.GetId(), "stream", id)
+		}
+		s.report(id, clusterHealth(msg.GetEnvoyMetrics()))
+	}
+}
+
+// Health returns the health of the endpoints of a cluster, as reported by the proxy seeing the fewest healthy
+// endpoints, or false if no connected proxy reports the cluster.
+func (s *Server) Health(cluster string) (Health, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var health Health
+	found := false
+	for _, clusters := range s.streams {
+		h, ok := clusters[cluster]
+		if !ok {
+			continue
+		}
+		if !found || h.Healthy < health.Healthy {
+			health = h
+		}
+		found = true
+	}
+	return health, found
+}
+
+// report replaces the health of the clusters reported on a stream, and notifies the clusters whose health changed.
+// A nil report removes the stream.
+func (s *Server) report(id uint64, clusters map[string]Health) {
+	s.mu.Lock()
+	previous := s.streams[id]
+	if clusters == nil {
+		delete(s.streams, id)
+	} else {
+		s.streams[id] = clusters
+	}
+	s.mu.Unlock()
+
+	if s.onChange == nil {
+		return
+	}
+	for cluster, health := range clusters {
+		if previousHealth, ok := previous[cluster]; !ok || previousHealth != health {
+			s.onChange(cluster)
+		}
+	}
+	for cluster := range previous {
+		if _, ok := clusters[cluster]; !ok {
+			s.onChange(cluster)
+		}
+	}
+}
+
+// clusterHealth extracts the health of the clusters from the membership gauges of the stats of a proxy.
+func clusterHealth(families []*prometheusv1.MetricFamily) map[string]Health {
+	clusters := make(map[string]Health)
+	for _, family := range families {
+		name := family.GetName()
+		if family.GetType() != prometheusv1.MetricType_GAUGE || !strings.HasPrefix(name, clusterStatPrefix) || len(family.GetMetric()) == 0 {
+			continue
+		}
+		value := uint64(family.GetMetric()[0].GetGauge().GetValue())
+		if cluster, ok := strings.CutSuffix(strings.TrimPrefix(name, clusterStatPrefix), membershipHealthySuffix); ok {
+			health := clusters[cluster]
+			health.Healthy = value
+			clusters[cluster] = health
+		} else if cluster, ok := strings.CutSuffix(strings.TrimPrefix(name, clusterStatPrefix), membershipTotalSuffix); ok {
+			health := clusters[cluster]
+			health.Total = value
+			clusters[cluster] = health
+		}
+	}
+	return clusters
+}
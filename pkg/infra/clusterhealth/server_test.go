@@ -0,0 +1,128 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterhealth
+
+import (
+	"io"
+	"slices"
+	"testing"
+
+	metricsv3 "github.com/envoyproxy/go-control-plane/envoy/service/metrics/v3"
+	prometheusv1 "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+	"k8s.io/utils/ptr"
+)
+
+// fakeStream is a stream of metrics messages sent by a proxy, which then closes it.
+type fakeStream struct {
+	grpc.ServerStream
+	messages []*metricsv3.StreamMetricsMessage
+	closed   bool
+}
+
+func (f *fakeStream) Recv() (*metricsv3.StreamMetricsMessage, error) {
+	if len(f.messages) == 0 {
+		return nil, io.EOF
+	}
+	msg := f.messages[0]
+	f.messages = f.messages[1:]
+	return msg, nil
+}
+
+func (f *fakeStream) SendAndClose(*metricsv3.StreamMetricsResponse) error {
+	f.closed = true
+	return nil
+}
+
+func gauge(name string, value float64) *prometheusv1.MetricFamily {
+	return &prometheusv1.MetricFamily{
+		Name:   ptr.To(name),
+		Type:   prometheusv1.MetricType_GAUGE.Enum(),
+		Metric: []*prometheusv1.Metric{{Gauge: &prometheusv1.Gauge{Value: ptr.To(value)}}},
+	}
+}
+
+func metrics(families ...*prometheusv1.MetricFamily) *metricsv3.StreamMetricsMessage {
+	return &metricsv3.StreamMetricsMessage{EnvoyMetrics: families}
+}
+
+func TestStreamMetrics(t *testing.T) {
+	server := NewServer(nil)
+	var changed []string
+	var healthDuringStream Health
+	server.onChange = func(cluster string) {
+		changed = append(changed, cluster)
+		if cluster == "default-mcp" && healthDuringStream == (Health{}) {
+			healthDuringStream, _ = server.Health(cluster)
+		}
+	}
+
+	stream := &fakeStream{messages: []*metricsv3.StreamMetricsMessage{
+		metrics(
+			gauge("cluster.default-mcp.membership_healthy", 2),
+			gauge("cluster.default-mcp.membership_total", 3),
+			gauge("cluster.default-mcp.upstream_cx_active", 5),
+			gauge("server.live", 1),
+		),
+		// The health of a cluster is only notified when it changes.
+		metrics(
+			gauge("cluster.default-mcp.membership_healthy", 2),
+			gauge("cluster.default-mcp.membership_total", 3),
+			gauge("cluster.default-other.membership_healthy", 1),
+			gauge("cluster.default-other.membership_total", 1),
+		),
+	}}
+	if err := server.StreamMetrics(stream); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !stream.closed {
+		t.Errorf("expected the stream to be closed")
+	}
+	if want := (Health{Healthy: 2, Total: 3}); healthDuringStream != want {
+		t.Errorf("expected the health %v to be reported for default-mcp, got %v", want, healthDuringStream)
+	}
+
+	// The clusters are notified when they are reported, and when the proxy disconnects.
+	slices.Sort(changed)
+	if want := []string{"default-mcp", "default-mcp", "default-other", "default-other"}; !slices.Equal(changed, want) {
+		t.Errorf("expected changes %v, got %v", want, changed)
+	}
+	if health, ok := server.Health("default-mcp"); ok {
+		t.Errorf("expected no health once the proxy disconnected, got %v", health)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	server := NewServer(nil)
+	server.report(0, map[string]Health{"default-mcp": {Healthy: 3, Total: 3}})
+	server.report(1, map[string]Health{"default-mcp": {Healthy: 1, Total: 3}, "default-other": {Healthy: 0, Total: 0}})
+
+	if health, ok := server.Health("default-mcp"); !ok || health != (Health{Healthy: 1, Total: 3}) {
+		t.Errorf("expected the health seen by the proxy with the fewest healthy endpoints, got %v, %t", health, ok)
+	}
+	if health, ok := server.Health("default-other"); !ok || health != (Health{}) {
+		t.Errorf("expected a cluster without endpoints, got %v, %t", health, ok)
+	}
+	if _, ok := server.Health("default-missing"); ok {
+		t.Errorf("expected no health for a cluster no proxy reports")
+	}
+
+	server.report(1, nil)
+	if health, ok := server.Health("default-mcp"); !ok || health != (Health{Healthy: 3, Total: 3}) {
+		t.Errorf("expected the health seen by the remaining proxy, got %v, %t", health, ok)
+	}
+}
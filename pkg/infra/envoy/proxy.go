@@ -53,15 +53,16 @@ func NewResourceManager(client kubernetes.Interface, gw *gatewayv1.Gateway, envo
 	return &ResourceManager{
 		client:                     client,
 		gw:                         gw,
-		nodeID:                     proxyName(gw.Namespace, gw.Name),
+		nodeID:                     ProxyName(gw.Namespace, gw.Name),
 		envoyImage:                 envoyImage,
 		namespace:                  gw.Namespace,
 		agenticIdentityTrustDomain: agenticIdentityTrustDomain,
 	}
 }
 
-// proxyName generates a deterministic name for the Envoy proxy resources.
-func proxyName(namespace, name string) string {
+// ProxyName generates a deterministic name for the Envoy proxy resources of a Gateway, which is also the node ID and
+// the name of the ServiceAccount of the proxy.
+func ProxyName(namespace, name string) string {
 	namespacedName := types.NamespacedName{
 		Namespace: namespace,
 		Name:      name,
@@ -232,7 +233,7 @@ func waitForDeploymentAvailable(ctx context.Context, client kubernetes.Interface
 }
 
 func DeleteProxy(ctx context.Context, client kubernetes.Interface, namespace, name string) error {
-	nodeID := proxyName(namespace, name)
+	nodeID := ProxyName(namespace, name)
 	logger := klog.FromContext(ctx).WithValues("resourceName", klog.KRef(namespace, nodeID))
 
 	// Delete Deployment
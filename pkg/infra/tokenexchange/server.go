@@ -0,0 +1,465 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tokenexchange implements the external authorization server the Gateways call to exchange the identity
+// of the calling agent for a token of the XBackend a request is routed to, with the OAuth 2.0 Token Exchange
+// (RFC 8693).
+package tokenexchange
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
+	agenticlisters "sigs.k8s.io/kube-agentic-networking/k8s/client/listers/api/v0alpha0"
+	"sigs.k8s.io/kube-agentic-networking/pkg/constants"
+)
+
+const (
+	// GrantType is the grant type of the OAuth 2.0 Token Exchange.
+	GrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+	// JWTTokenType is the default type of the subject tokens of XBackends exchanging the bearer token of the agent.
+	JWTTokenType = "urn:ietf:params:oauth:token-type:jwt"
+	// AccessTokenType is the type of the tokens requested for XBackends.
+	AccessTokenType = "urn:ietf:params:oauth:token-type:access_token"
+
+	// ClientIDKey and ClientSecretKey are the keys of the client credentials in the Secrets referenced by XBackends.
+	ClientIDKey     = "client_id"
+	ClientSecretKey = "client_secret"
+
+	// requestTimeout is the timeout of the requests to the security token services. It is shorter than the timeout
+	// of the ext_authz filter calling the server, so that it gets the reason of the failure.
+	requestTimeout = 4 * time.Second
+	// expirySkew is subtracted from the lifetime of the issued tokens, so that no token expires on its way to the
+	// backend.
+	expirySkew = 30 * time.Second
+	// maxCachedTokens bounds the number of tokens cached by the server.
+	maxCachedTokens = 4096
+	// maxResponseBytes bounds the size of the responses of the security token services.
+	maxResponseBytes = 1 << 20
+	// certificateLifetime is the lifetime of the certificate of the server, which is renewed halfway through.
+	certificateLifetime = 24 * time.Hour
+)
+
+// CertificateIssuer issues the certificate of the agentic identity the server presents to the Gateway proxies, and
+// returns the roots of the certificates of the agentic identities of the Gateway proxies, see
+// agenticidentitysigner.Impl.
+type CertificateIssuer interface {
+	IssueCert(namespace, serviceAccountName string, lifetime time.Duration) (*tls.Certificate, error)
+	RootCAs() *x509.CertPool
+}
+
+// tokenKey identifies the token of an XBackend issued for a subject token.
+type tokenKey struct {
+	backend      string
+	subjectToken string
+}
+
+// cachedToken is a token of an XBackend with the time it must no longer be used.
+type cachedToken struct {
+	accessToken string
+	expiry      time.Time
+}
+
+// tokenResponse is the successful response of a security token service, see RFC 8693 section 2.2.1.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// exchangeError is a failure of the token exchange with the status returned to the agent.
+type exchangeError struct {
+	code       codes.Code
+	httpStatus typev3.StatusCode
+	message    string
+}
+
+func (e *exchangeError) Error() string { return e.message }
+
+// Server is the external authorization server exchanging the identity of the calling agent for a token of the
+// XBackend of the request, named by the TokenExchangeBackendContextKey context extension set by the Gateway on its
+// cluster. The token is set in the Authorization header of the request forwarded to the XBackend.
+//
+// The server only serves the Gateway proxies authenticated with their agentic identity, see Run, and only for the
+// XBackends their Gateway routes to, see SetProxyBackends: the identity of the agent is read from the request the
+// proxy checks, so that it can only be trusted when the proxy is.
+type Server struct {
+	trustDomain   string
+	backendLister agenticlisters.XBackendLister
+	secretLister  corev1listers.SecretLister
+	httpClient    *http.Client
+	clock         clock.PassiveClock
+
+	mu     sync.Mutex
+	tokens map[tokenKey]cachedToken
+	// proxyBackends holds the keys of the XBackends with a token exchange each Gateway proxy routes to, by the
+	// namespace and name of the ServiceAccount of the proxy.
+	proxyBackends map[types.NamespacedName]sets.Set[string]
+	// certificate is the certificate the server presents to the Gateway proxies.
+	certificate *tls.Certificate
+}
+
+var _ authv3.AuthorizationServer = &Server{}
+
+// NewServer returns a token exchange server serving the Gateway proxies with an agentic identity of the given trust
+// domain, and reading the XBackends and the Secrets holding their client credentials from the given listers.
+func NewServer(trustDomain string, backendLister agenticlisters.XBackendLister, secretLister corev1listers.SecretLister, clock clock.PassiveClock) *Server {
+	return &Server{
+		trustDomain:   trustDomain,
+		backendLister: backendLister,
+		secretLister:  secretLister,
+		httpClient:    &http.Client{Timeout: requestTimeout},
+		clock:         clock,
+		tokens:        make(map[tokenKey]cachedToken),
+		proxyBackends: make(map[types.NamespacedName]sets.Set[string]),
+	}
+}
+
+// Run serves the token exchange on constants.TokenExchangePort with mutual TLS: the server presents the agentic
+// identity of the controller, issued by the given issuer, and only accepts the clients presenting an agentic
+// identity.
+func (s *Server) Run(ctx context.Context, issuer CertificateIssuer) error {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// The certificate and the roots are read on each connection, so that the rotations of the CA pool apply.
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			certificate, err := s.serverCertificate(issuer)
+			if err != nil {
+				return nil, err
+			}
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*certificate},
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				ClientCAs:    issuer.RootCAs(),
+			}, nil
+		},
+	}
+	grpcServer := grpc.NewServer(grpc.Creds(credentials.NewTLS(tlsConfig)))
+	authv3.RegisterAuthorizationServer(grpcServer, s)
+
+	lc := net.ListenConfig{}
+	listener, err := lc.Listen(ctx, "tcp", fmt.Sprintf("0.0.0.0:%d", constants.TokenExchangePort))
+	if err != nil {
+		return err
+	}
+
+	klog.Infof("Token exchange server listening on %s", listener.Addr().String())
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil {
+			klog.Errorln("Token exchange server error:", err)
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		grpcServer.Stop()
+	}()
+
+	return nil
+}
+
+// SetProxyBackends sets the keys of the XBackends with a token exchange the Gateway proxy with the given
+// ServiceAccount routes to, which are the only XBackends the proxy gets tokens for. Nil backends forget the proxy.
+func (s *Server) SetProxyBackends(serviceAccount types.NamespacedName, backends sets.Set[string]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if backends == nil {
+		delete(s.proxyBackends, serviceAccount)
+		return
+	}
+	s.proxyBackends[serviceAccount] = backends
+}
+
+// Check implements the ext_authz Authorization service.
+func (s *Server) Check(ctx context.Context, req *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+	accessToken, err := s.authorizedToken(ctx, req.GetAttributes())
+	if err != nil {
+		var exchangeErr *exchangeError
+		if !errors.As(err, &exchangeErr) {
+			exchangeErr = &exchangeError{code: codes.Internal, httpStatus: typev3.StatusCode_InternalServerError, message: err.Error()}
+		}
+		klog.V(2).Infof("Token exchange failed: %v", exchangeErr)
+		return deniedResponse(exchangeErr), nil
+	}
+	return &authv3.CheckResponse{
+		Status: status.New(codes.OK, "").Proto(),
+		HttpResponse: &authv3.CheckResponse_OkResponse{
+			OkResponse: &authv3.OkHttpResponse{
+				Headers: []*corev3.HeaderValueOption{{
+					Header:       &corev3.HeaderValue{Key: "authorization", Value: "Bearer " + accessToken},
+					AppendAction: corev3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD,
+				}},
+			},
+		},
+	}, nil
+}
+
+// authorizedToken returns the token of the XBackend of the request for the identity of the calling agent, if the
+// Gateway proxy calling the server routes to the XBackend.
+func (s *Server) authorizedToken(ctx context.Context, attributes *authv3.AttributeContext) (string, error) {
+	proxy, err := s.proxyServiceAccount(ctx)
+	if err != nil {
+		return "", &exchangeError{code: codes.Unauthenticated, httpStatus: typev3.StatusCode_Unauthorized, message: fmt.Sprintf("the caller is not a Gateway proxy: %v", err)}
+	}
+	backendKey := attributes.GetContextExtensions()[constants.TokenExchangeBackendContextKey]
+	s.mu.Lock()
+	authorized := s.proxyBackends[proxy].Has(backendKey)
+	s.mu.Unlock()
+	if !authorized {
+		return "", &exchangeError{code: codes.PermissionDenied, httpStatus: typev3.StatusCode_Forbidden, message: fmt.Sprintf("the Gateway proxy %s does not route to XBackend %q", proxy, backendKey)}
+	}
+	return s.token(ctx, backendKey, attributes)
+}
+
+// proxyServiceAccount returns the namespace and name of the ServiceAccount of the peer of the request, from the
+// agentic identity of the client certificate it was authenticated with, see Run.
+func (s *Server) proxyServiceAccount(ctx context.Context) (types.NamespacedName, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return types.NamespacedName{}, errors.New("no peer")
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return types.NamespacedName{}, errors.New("no verified client certificate")
+	}
+	for _, uri := range tlsInfo.State.VerifiedChains[0][0].URIs {
+		if uri.Scheme != "spiffe" || uri.Host != s.trustDomain {
+			continue
+		}
+		// The agentic identities are the SPIFFE IDs `spiffe://<trust-domain>/ns/<namespace>/sa/<service-account>`.
+		segments := strings.Split(strings.TrimPrefix(uri.Path, "/"), "/")
+		if len(segments) == 4 && segments[0] == "ns" && segments[2] == "sa" && segments[1] != "" && segments[3] != "" {
+			return types.NamespacedName{Namespace: segments[1], Name: segments[3]}, nil
+		}
+	}
+	return types.NamespacedName{}, fmt.Errorf("no agentic identity of trust domain %s in the client certificate", s.trustDomain)
+}
+
+// serverCertificate returns the certificate the server presents to the Gateway proxies, issuing a new one halfway
+// through the lifetime of the current one.
+func (s *Server) serverCertificate(issuer CertificateIssuer) (*tls.Certificate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.certificate != nil && s.clock.Now().Before(s.certificate.Leaf.NotBefore.Add(certificateLifetime/2)) {
+		return s.certificate, nil
+	}
+	certificate, err := issuer.IssueCert(constants.AgenticNetSystemNamespace, constants.ControllerServiceAccountName, certificateLifetime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue the certificate of the token exchange server: %w", err)
+	}
+	s.certificate = certificate
+	return certificate, nil
+}
+
+// token returns the token of an XBackend for the identity of the calling agent, from the cache or from the security
+// token service of the XBackend.
+func (s *Server) token(ctx context.Context, backendKey string, attributes *authv3.AttributeContext) (string, error) {
+	namespace, name, err := cache.SplitMetaNamespaceKey(backendKey)
+	if err != nil || namespace == "" || name == "" {
+		return "", fmt.Errorf("invalid XBackend %q", backendKey)
+	}
+	backend, err := s.backendLister.XBackends(namespace).Get(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to get XBackend %s: %w", backendKey, err)
+	}
+	tokenExchange := backend.Spec.MCP.TokenExchange
+	if tokenExchange == nil {
+		return "", fmt.Errorf("XBackend %s has no token exchange", backendKey)
+	}
+
+	subjectToken, err := subjectToken(tokenExchange, attributes)
+	if err != nil {
+		return "", err
+	}
+	key := tokenKey{backend: backendKey, subjectToken: subjectToken}
+	if accessToken, ok := s.cachedToken(key); ok {
+		return accessToken, nil
+	}
+
+	response, err := s.exchange(ctx, backend, subjectToken)
+	if err != nil {
+		return "", err
+	}
+	if response.ExpiresIn > 0 {
+		s.cacheToken(key, cachedToken{
+			accessToken: response.AccessToken,
+			expiry:      s.clock.Now().Add(time.Duration(response.ExpiresIn)*time.Second - expirySkew),
+		})
+	}
+	return response.AccessToken, nil
+}
+
+// subjectToken returns the identity of the calling agent exchanged for a token of an XBackend.
+func subjectToken(tokenExchange *agenticv0alpha0.BackendTokenExchange, attributes *authv3.AttributeContext) (string, error) {
+	if tokenExchange.SubjectToken == agenticv0alpha0.TokenExchangeSubjectTokenSPIFFE {
+		// The principal is the URI SAN of the client certificate verified by the Gateway, which is trusted since the
+		// Gateway proxy is authenticated.
+		principal := attributes.GetSource().GetPrincipal()
+		if !strings.HasPrefix(principal, "spiffe://") {
+			return "", &exchangeError{code: codes.Unauthenticated, httpStatus: typev3.StatusCode_Unauthorized, message: "the request has no SPIFFE ID"}
+		}
+		return principal, nil
+	}
+	authorization := attributes.GetRequest().GetHttp().GetHeaders()["authorization"]
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", &exchangeError{code: codes.Unauthenticated, httpStatus: typev3.StatusCode_Unauthorized, message: "the request has no bearer token"}
+	}
+	return token, nil
+}
+
+// exchange requests a token of an XBackend for the subject token from its security token service.
+func (s *Server) exchange(ctx context.Context, backend *agenticv0alpha0.XBackend, subjectToken string) (*tokenResponse, error) {
+	tokenExchange := backend.Spec.MCP.TokenExchange
+	subjectTokenType := JWTTokenType
+	if tokenExchange.SubjectTokenType != nil {
+		subjectTokenType = *tokenExchange.SubjectTokenType
+	}
+	form := url.Values{
+		"grant_type":           {GrantType},
+		"subject_token":        {subjectToken},
+		"subject_token_type":   {subjectTokenType},
+		"requested_token_type": {AccessTokenType},
+	}
+	if tokenExchange.Audience != nil {
+		form.Set("audience", *tokenExchange.Audience)
+	}
+	if len(tokenExchange.Scopes) > 0 {
+		form.Set("scope", strings.Join(tokenExchange.Scopes, " "))
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenExchange.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("invalid token endpoint of XBackend %s/%s: %w", backend.Namespace, backend.Name, err)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("Accept", "application/json")
+	if ref := tokenExchange.ClientSecretRef; ref != nil {
+		clientID, clientSecret, err := s.clientCredentials(backend.Namespace, string(ref.Name))
+		if err != nil {
+			return nil, err
+		}
+		// The client credentials are form-encoded before being used as the user name and password, see
+		// RFC 6749 section 2.3.1.
+		httpReq.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	}
+
+	resp, err := s.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, &exchangeError{code: codes.Unavailable, httpStatus: typev3.StatusCode_ServiceUnavailable, message: fmt.Sprintf("failed to call the token endpoint of XBackend %s/%s: %v", backend.Namespace, backend.Name, err)}
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, &exchangeError{code: codes.Unavailable, httpStatus: typev3.StatusCode_ServiceUnavailable, message: fmt.Sprintf("failed to read the response of the token endpoint of XBackend %s/%s: %v", backend.Namespace, backend.Name, err)}
+	}
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		// The security token service refuses to issue a token for the agent, see RFC 6749 section 5.2.
+		return nil, &exchangeError{code: codes.PermissionDenied, httpStatus: typev3.StatusCode_Forbidden, message: fmt.Sprintf("the token endpoint of XBackend %s/%s refused the token exchange with status %d: %s", backend.Namespace, backend.Name, resp.StatusCode, body)}
+	default:
+		return nil, &exchangeError{code: codes.Unavailable, httpStatus: typev3.StatusCode_ServiceUnavailable, message: fmt.Sprintf("the token endpoint of XBackend %s/%s failed with status %d", backend.Namespace, backend.Name, resp.StatusCode)}
+	}
+
+	response := &tokenResponse{}
+	if err := json.Unmarshal(body, response); err != nil || response.AccessToken == "" {
+		return nil, &exchangeError{code: codes.Unavailable, httpStatus: typev3.StatusCode_ServiceUnavailable, message: fmt.Sprintf("invalid response of the token endpoint of XBackend %s/%s", backend.Namespace, backend.Name)}
+	}
+	return response, nil
+}
+
+// clientCredentials returns the client ID and secret of a Secret referenced by an XBackend.
+func (s *Server) clientCredentials(namespace, name string) (string, string, error) {
+	secret, err := s.secretLister.Secrets(namespace).Get(name)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get Secret %s/%s: %w", namespace, name, err)
+	}
+	clientID, clientSecret := secret.Data[ClientIDKey], secret.Data[ClientSecretKey]
+	if len(clientID) == 0 || len(clientSecret) == 0 {
+		return "", "", fmt.Errorf("secret %s/%s has no %s or %s key", namespace, name, ClientIDKey, ClientSecretKey)
+	}
+	return string(clientID), string(clientSecret), nil
+}
+
+// cachedToken returns the cached token with the given key, if it has not expired.
+func (s *Server) cachedToken(key tokenKey) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, ok := s.tokens[key]
+	if !ok || !s.clock.Now().Before(token.expiry) {
+		return "", false
+	}
+	return token.accessToken, true
+}
+
+// cacheToken caches a token, evicting the expired tokens, or all of them, if the cache is full.
+func (s *Server) cacheToken(key tokenKey, token cachedToken) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.tokens) >= maxCachedTokens {
+		now := s.clock.Now()
+		for k, t := range s.tokens {
+			if !now.Before(t.expiry) {
+				delete(s.tokens, k)
+			}
+		}
+		if len(s.tokens) >= maxCachedTokens {
+			clear(s.tokens)
+		}
+	}
+	s.tokens[key] = token
+}
+
+// deniedResponse returns the response denying a request whose token exchange failed.
+func deniedResponse(err *exchangeError) *authv3.CheckResponse {
+	return &authv3.CheckResponse{
+		Status: status.New(err.code, err.message).Proto(),
+		HttpResponse: &authv3.CheckResponse_DeniedResponse{
+			DeniedResponse: &authv3.DeniedHttpResponse{
+				Status: &typev3.HttpStatus{Code: err.httpStatus},
+			},
+		},
+	}
+}
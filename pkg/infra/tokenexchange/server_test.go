@@ -0,0 +1,371 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tokenexchange
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	testingclock "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
+	agenticlisters "sigs.k8s.io/kube-agentic-networking/k8s/client/listers/api/v0alpha0"
+	"sigs.k8s.io/kube-agentic-networking/pkg/constants"
+)
+
+// fakeTokenServer is a security token service issuing the token `<audience>:<scope>:<subject token>` to the
+// client `gateway` for the subject tokens other than `revoked`.
+type fakeTokenServer struct {
+	*httptest.Server
+	requests atomic.Int32
+	// lastForm is the form of the last token exchange request.
+	lastForm map[string]string
+}
+
+func newFakeTokenServer(t *testing.T) *fakeTokenServer {
+	ts := &fakeTokenServer{}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ts.requests.Add(1)
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("failed to parse the token exchange request: %v", err)
+		}
+		ts.lastForm = make(map[string]string)
+		for key := range r.PostForm {
+			ts.lastForm[key] = r.PostForm.Get(key)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if clientID, clientSecret, ok := r.BasicAuth(); !ok || clientID != "gateway" || clientSecret != "s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+			return
+		}
+		if r.PostForm.Get("grant_type") != GrantType || r.PostForm.Get("subject_token") == "revoked" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":      r.PostForm.Get("audience") + ":" + r.PostForm.Get("scope") + ":" + r.PostForm.Get("subject_token"),
+			"issued_token_type": AccessTokenType,
+			"token_type":        "Bearer",
+			"expires_in":        300,
+		})
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func newTestServer(t *testing.T, tokenEndpoint string, clock *testingclock.FakeClock) *Server {
+	backendIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	secretIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	clientSecretRef := &gatewayv1.LocalObjectReference{Kind: "Secret", Name: "sts-client"}
+	newBackend := func(name string, tokenExchange *agenticv0alpha0.BackendTokenExchange) *agenticv0alpha0.XBackend {
+		return &agenticv0alpha0.XBackend{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: agenticv0alpha0.BackendSpec{MCP: agenticv0alpha0.MCPBackend{
+				Hostname:      ptr.To("mcp.example.com"),
+				Port:          443,
+				TokenExchange: tokenExchange,
+			}},
+		}
+	}
+	_ = backendIndexer.Add(newBackend("jwt", &agenticv0alpha0.BackendTokenExchange{
+		TokenEndpoint:   tokenEndpoint,
+		SubjectToken:    agenticv0alpha0.TokenExchangeSubjectTokenJWT,
+		Audience:        ptr.To("github"),
+		Scopes:          []string{"repo", "read:org"},
+		ClientSecretRef: clientSecretRef,
+	}))
+	_ = backendIndexer.Add(newBackend("spiffe", &agenticv0alpha0.BackendTokenExchange{
+		TokenEndpoint:    tokenEndpoint,
+		SubjectToken:     agenticv0alpha0.TokenExchangeSubjectTokenSPIFFE,
+		SubjectTokenType: ptr.To("urn:example:token-type:spiffe-id"),
+		ClientSecretRef:  clientSecretRef,
+	}))
+	_ = backendIndexer.Add(newBackend("unauthenticated", &agenticv0alpha0.BackendTokenExchange{
+		TokenEndpoint: tokenEndpoint,
+	}))
+	_ = backendIndexer.Add(newBackend("unreachable", &agenticv0alpha0.BackendTokenExchange{
+		TokenEndpoint: "http://127.0.0.1:1/token",
+	}))
+	_ = backendIndexer.Add(newBackend("static", nil))
+	_ = secretIndexer.Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "sts-client"},
+		Data:       map[string][]byte{ClientIDKey: []byte("gateway"), ClientSecretKey: []byte("s3cr3t")},
+	})
+	server := NewServer("cluster.local", agenticlisters.NewXBackendLister(backendIndexer), corev1listers.NewSecretLister(secretIndexer), clock)
+	server.SetProxyBackends(types.NamespacedName{Namespace: "default", Name: "gateway-proxy"}, sets.New("default/jwt", "default/spiffe", "default/unauthenticated", "default/unreachable", "default/static", "default/missing"))
+	server.SetProxyBackends(types.NamespacedName{Namespace: "default", Name: "other-gateway-proxy"}, sets.New("default/jwt"))
+	return server
+}
+
+// peerContext returns the context of a request of a peer authenticated with a client certificate with the given
+// URI SAN.
+func peerContext(t *testing.T, uri string) context.Context {
+	t.Helper()
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", uri, err)
+	}
+	cert := &x509.Certificate{URIs: []*url.URL{parsed}}
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}},
+	})
+}
+
+func checkRequest(backend, authorization, principal string) *authv3.CheckRequest {
+	headers := map[string]string{}
+	if authorization != "" {
+		headers["authorization"] = authorization
+	}
+	return &authv3.CheckRequest{
+		Attributes: &authv3.AttributeContext{
+			Source:            &authv3.AttributeContext_Peer{Principal: principal},
+			Request:           &authv3.AttributeContext_Request{Http: &authv3.AttributeContext_HttpRequest{Headers: headers}},
+			ContextExtensions: map[string]string{constants.TokenExchangeBackendContextKey: backend},
+		},
+	}
+}
+
+func TestCheck(t *testing.T) {
+	ts := newFakeTokenServer(t)
+	server := newTestServer(t, ts.URL, testingclock.NewFakeClock(time.Now()))
+	proxyContext := peerContext(t, "spiffe://cluster.local/ns/default/sa/gateway-proxy")
+
+	tests := []struct {
+		name string
+		// ctx is the context of the request of the Gateway proxy, if not the default gateway-proxy.
+		ctx            context.Context
+		req            *authv3.CheckRequest
+		wantToken      string
+		wantCode       codes.Code
+		wantHTTPStatus typev3.StatusCode
+		wantForm       map[string]string
+	}{
+		{
+			name:      "bearer token exchanged",
+			req:       checkRequest("default/jwt", "Bearer agent-token", ""),
+			wantToken: "Bearer github:repo read:org:agent-token",
+			wantForm: map[string]string{
+				"grant_type":           GrantType,
+				"subject_token":        "agent-token",
+				"subject_token_type":   JWTTokenType,
+				"requested_token_type": AccessTokenType,
+				"audience":             "github",
+				"scope":                "repo read:org",
+			},
+		},
+		{
+			name:      "SPIFFE ID exchanged",
+			req:       checkRequest("default/spiffe", "", "spiffe://cluster.local/ns/default/sa/agent"),
+			wantToken: "Bearer ::spiffe://cluster.local/ns/default/sa/agent",
+			wantForm: map[string]string{
+				"grant_type":           GrantType,
+				"subject_token":        "spiffe://cluster.local/ns/default/sa/agent",
+				"subject_token_type":   "urn:example:token-type:spiffe-id",
+				"requested_token_type": AccessTokenType,
+			},
+		},
+		{
+			name:           "no bearer token",
+			req:            checkRequest("default/jwt", "Basic Zm9vOmJhcg==", ""),
+			wantCode:       codes.Unauthenticated,
+			wantHTTPStatus: typev3.StatusCode_Unauthorized,
+		},
+		{
+			name:           "no SPIFFE ID",
+			req:            checkRequest("default/spiffe", "Bearer agent-token", ""),
+			wantCode:       codes.Unauthenticated,
+			wantHTTPStatus: typev3.StatusCode_Unauthorized,
+		},
+		{
+			name:           "token exchange refused",
+			req:            checkRequest("default/jwt", "Bearer revoked", ""),
+			wantCode:       codes.PermissionDenied,
+			wantHTTPStatus: typev3.StatusCode_Forbidden,
+		},
+		{
+			name:           "client not authenticated",
+			req:            checkRequest("default/unauthenticated", "Bearer agent-token", ""),
+			wantCode:       codes.PermissionDenied,
+			wantHTTPStatus: typev3.StatusCode_Forbidden,
+		},
+		{
+			name:           "token endpoint unreachable",
+			req:            checkRequest("default/unreachable", "Bearer agent-token", ""),
+			wantCode:       codes.Unavailable,
+			wantHTTPStatus: typev3.StatusCode_ServiceUnavailable,
+		},
+		{
+			name:           "caller without client certificate",
+			ctx:            context.Background(),
+			req:            checkRequest("default/jwt", "Bearer agent-token", ""),
+			wantCode:       codes.Unauthenticated,
+			wantHTTPStatus: typev3.StatusCode_Unauthorized,
+		},
+		{
+			name:           "caller of another trust domain",
+			ctx:            peerContext(t, "spiffe://example.com/ns/default/sa/gateway-proxy"),
+			req:            checkRequest("default/jwt", "Bearer agent-token", ""),
+			wantCode:       codes.Unauthenticated,
+			wantHTTPStatus: typev3.StatusCode_Unauthorized,
+		},
+		{
+			name:           "XBackend not routed by the Gateway",
+			ctx:            peerContext(t, "spiffe://cluster.local/ns/default/sa/other-gateway-proxy"),
+			req:            checkRequest("default/spiffe", "", "spiffe://cluster.local/ns/default/sa/agent"),
+			wantCode:       codes.PermissionDenied,
+			wantHTTPStatus: typev3.StatusCode_Forbidden,
+		},
+		{
+			name:           "XBackend without token exchange",
+			req:            checkRequest("default/static", "Bearer agent-token", ""),
+			wantCode:       codes.Internal,
+			wantHTTPStatus: typev3.StatusCode_InternalServerError,
+		},
+		{
+			name:           "unknown XBackend",
+			req:            checkRequest("default/missing", "Bearer agent-token", ""),
+			wantCode:       codes.Internal,
+			wantHTTPStatus: typev3.StatusCode_InternalServerError,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts.lastForm = nil
+			ctx := tc.ctx
+			if ctx == nil {
+				ctx = proxyContext
+			}
+			resp, err := server.Check(ctx, tc.req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := codes.Code(resp.GetStatus().GetCode()); got != tc.wantCode {
+				t.Fatalf("expected status %v, got %v: %s", tc.wantCode, got, resp.GetStatus().GetMessage())
+			}
+			if tc.wantCode != codes.OK {
+				if got := resp.GetDeniedResponse().GetStatus().GetCode(); got != tc.wantHTTPStatus {
+					t.Errorf("expected HTTP status %v, got %v", tc.wantHTTPStatus, got)
+				}
+				return
+			}
+			headers := resp.GetOkResponse().GetHeaders()
+			if len(headers) != 1 || headers[0].GetHeader().GetKey() != "authorization" || headers[0].GetHeader().GetValue() != tc.wantToken {
+				t.Fatalf("expected the authorization header %q, got %v", tc.wantToken, headers)
+			}
+			for key, want := range tc.wantForm {
+				if got := ts.lastForm[key]; got != want {
+					t.Errorf("expected %s=%q in the token exchange request, got %q", key, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCheck_CachesTokens(t *testing.T) {
+	ts := newFakeTokenServer(t)
+	clock := testingclock.NewFakeClock(time.Now())
+	server := newTestServer(t, ts.URL, clock)
+	proxyContext := peerContext(t, "spiffe://cluster.local/ns/default/sa/gateway-proxy")
+
+	check := func(authorization string) {
+		t.Helper()
+		resp, err := server.Check(proxyContext, checkRequest("default/jwt", authorization, ""))
+		if err != nil || codes.Code(resp.GetStatus().GetCode()) != codes.OK {
+			t.Fatalf("expected the token exchange to succeed, got %v, %v", resp.GetStatus(), err)
+		}
+	}
+
+	check("Bearer agent-token")
+	check("Bearer agent-token")
+	if got := ts.requests.Load(); got != 1 {
+		t.Errorf("expected the token to be cached, got %d token exchange requests", got)
+	}
+
+	check("Bearer other-agent-token")
+	if got := ts.requests.Load(); got != 2 {
+		t.Errorf("expected a token exchange request for another agent, got %d token exchange requests", got)
+	}
+
+	// The token expires in 300s, and is no longer used 30s before.
+	clock.Step(270 * time.Second)
+	check("Bearer agent-token")
+	if got := ts.requests.Load(); got != 3 {
+		t.Errorf("expected the token to be exchanged again before it expires, got %d token exchange requests", got)
+	}
+}
+
+// fakeIssuer issues certificates valid from the time of the given clock.
+type fakeIssuer struct {
+	clock  *testingclock.FakeClock
+	issued int
+}
+
+func (f *fakeIssuer) IssueCert(_, _ string, lifetime time.Duration) (*tls.Certificate, error) {
+	f.issued++
+	now := f.clock.Now()
+	return &tls.Certificate{Leaf: &x509.Certificate{NotBefore: now, NotAfter: now.Add(lifetime)}}, nil
+}
+
+func (f *fakeIssuer) RootCAs() *x509.CertPool { return x509.NewCertPool() }
+
+func TestServerCertificate(t *testing.T) {
+	clock := testingclock.NewFakeClock(time.Now())
+	server := newTestServer(t, "http://127.0.0.1:1/token", clock)
+	issuer := &fakeIssuer{clock: clock}
+
+	for range 2 {
+		if _, err := server.serverCertificate(issuer); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if issuer.issued != 1 {
+		t.Errorf("expected the certificate to be reused, got %d certificates", issuer.issued)
+	}
+
+	// The certificate is renewed halfway through its lifetime.
+	clock.Step(certificateLifetime / 2)
+	if _, err := server.serverCertificate(issuer); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issuer.issued != 2 {
+		t.Errorf("expected the certificate to be renewed, got %d certificates", issuer.issued)
+	}
+}
@@ -30,6 +30,7 @@ import (
 	discoveryv3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
 	endpointv3 "github.com/envoyproxy/go-control-plane/envoy/service/endpoint/v3"
 	listenerv3service "github.com/envoyproxy/go-control-plane/envoy/service/listener/v3"
+	metricsv3 "github.com/envoyproxy/go-control-plane/envoy/service/metrics/v3"
 	routev3service "github.com/envoyproxy/go-control-plane/envoy/service/route/v3"
 	runtimev3 "github.com/envoyproxy/go-control-plane/envoy/service/runtime/v3"
 	secretv3 "github.com/envoyproxy/go-control-plane/envoy/service/secret/v3"
@@ -52,15 +53,19 @@ type Server struct {
 	cache   cachev3.SnapshotCache
 	server  serverv3.Server
 	version atomic.Uint64
+	// metrics receives the stats the proxies stream to the cluster of their bootstrap configuration connecting to
+	// the controller.
+	metrics metricsv3.MetricsServiceServer
 }
 
-// NewServer creates a new xDS server.
-func NewServer(ctx context.Context) *Server {
+// NewServer creates a new xDS server, also serving the metrics service, if not nil.
+func NewServer(ctx context.Context, metrics metricsv3.MetricsServiceServer) *Server {
 	cache := cachev3.NewSnapshotCache(false, cachev3.IDHash{}, nil)
 	server := serverv3.NewServer(ctx, cache, &callbacks{})
 	return &Server{
-		cache:  cache,
-		server: server,
+		cache:   cache,
+		server:  server,
+		metrics: metrics,
 	}
 }
 
@@ -87,6 +92,9 @@ func (s *Server) Run(ctx context.Context) error {
 	listenerv3service.RegisterListenerDiscoveryServiceServer(grpcServer, s.server)
 	secretv3.RegisterSecretDiscoveryServiceServer(grpcServer, s.server)
 	runtimev3.RegisterRuntimeDiscoveryServiceServer(grpcServer, s.server)
+	if s.metrics != nil {
+		metricsv3.RegisterMetricsServiceServer(grpcServer, s.metrics)
+	}
 
 	// The xDS server listens on a fixed port (15001) on all interfaces.
 	lc := net.ListenConfig{}
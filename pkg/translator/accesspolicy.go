@@ -17,15 +17,25 @@ limitations under the License.
 package translator
 
 import (
+	"cmp"
 	"fmt"
+	"regexp/syntax"
+	"slices"
+	"strings"
 
 	rbacconfigv3 "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
 	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
 	rbacv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/rbac/v3"
+	hcm "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
 	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	"google.golang.org/protobuf/types/known/anypb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
 	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
 	agenticlisters "sigs.k8s.io/kube-agentic-networking/k8s/client/listers/api/v0alpha0"
 )
@@ -47,13 +57,57 @@ const (
 	toolsCallMethod    = "tools/call"
 	mcpProxyFilterName = "mcp_proxy"
 
+	resourcesReadMethod        = "resources/read"
+	resourcesSubscribeMethod   = "resources/subscribe"
+	resourcesUnsubscribeMethod = "resources/unsubscribe"
+	promptsGetMethod           = "prompts/get"
+	completionCompleteMethod   = "completion/complete"
+
+	// completionRefTypeResource and completionRefTypePrompt are the types of reference in the params
+	// of a completion/complete request, identifying what is being completed.
+	completionRefTypeResource = "ref/resource"
+	completionRefTypePrompt   = "ref/prompt"
+
+	// envoyRegexMaxProgramSize is the default maximum program size of the regular expressions of Envoy's safe_regex
+	// matchers, above which Envoy rejects the configuration (see the re2.max_program_size.error_level runtime key).
+	envoyRegexMaxProgramSize = 100
+
 	// spiffeIDFormat is the standard SPIFFE ID format for Kubernetes workloads.
 	// Format: spiffe://<trust-domain>/ns/<namespace>/sa/<service-account>
 	spiffeIDFormat = "spiffe://%s/ns/%s/sa/%s"
 
+	// spiffeNamespacePrefixFormat is the prefix of the standard SPIFFE IDs of all the ServiceAccounts of a namespace.
+	spiffeNamespacePrefixFormat = "spiffe://%s/ns/%s/sa/"
+
+	// denyRBACFilterName is the name of the RBAC filter that enforces the Deny rules of AccessPolicies.
+	// It is placed before the RBAC filter enforcing the Allow rules, so that Deny rules are evaluated first.
+	denyRBACFilterName = "envoy.filters.http.rbac.deny"
+
+	// gatewayRBACFilterNamePrefix is the prefix of the names of the listener-level RBAC filters that enforce the
+	// rules of AccessPolicies targeting a Gateway. Each such AccessPolicy gets its own filters, so that a request
+	// must be allowed by all of them.
+	gatewayRBACFilterNamePrefix = "envoy.filters.http.rbac.gateway"
+
 	// externalAuthzShadowRulePrefix is the prefix for stat names of shadow rules generated from AccessPolicies with ExternalAuthz.
 	// This allows us to monitor the presence of RBAC rules that are evaluated (though not enforced), with the purpose of signaling the need to call an ext_authz service.
 	externalAuthzShadowRulePrefix = "access_policy_ext_authz"
+
+	// externalAuthzRBACFilterNamePrefix is the prefix of the names of the RBAC filters that trigger the ext_authz filters.
+	// Each ExternalAuth configuration gets its own filter, whose shadow rules match the requests of the rules using it,
+	// so that different rules can be checked by different external authorizers.
+	externalAuthzRBACFilterNamePrefix = "envoy.filters.http.rbac.ext_authz"
+
+	// auditRBACFilterName is the name of the RBAC filter that evaluates the Allow rules of AccessPolicies in Audit mode
+	// as shadow rules. It is placed before the enforcing RBAC filters, so that its decision is recorded even for
+	// requests they deny.
+	auditRBACFilterName = "envoy.filters.http.rbac.audit"
+
+	// auditShadowRulePrefix is the prefix for stat names of shadow rules generated from AccessPolicies in Audit mode.
+	// The RBAC filters also emit the shadow decisions as dynamic metadata keyed with this prefix, which is written to the access log.
+	auditShadowRulePrefix = "access_policy_audit"
+
+	// dotDotSegmentRegex matches the values containing a ".." path segment, which Prefix argument constraints reject.
+	dotDotSegmentRegex = `(^|[/\\])\.\.([/\\]|$)`
 )
 
 // rbacConfigFromAccessPolicy generates all RBAC policies for a given backend, including common policies
@@ -61,50 +115,379 @@ const (
 func (t *Translator) rbacConfigFromAccessPolicy(accessPolicyLister agenticlisters.XAccessPolicyLister, backend *agenticv0alpha0.XBackend) (*rbacv3.RBAC, error) {
 	rbacConfig := &rbacv3.RBAC{}
 
-	// Add AccessPolicy-derived RBAC policies when one targets this backend.
-	// Currently, we assume only one AccessPolicy targets a given backend.
-	accessPolicy, err := findAccessPolicyForBackend(backend, accessPolicyLister)
+	// Add AccessPolicy-derived RBAC policies from every AccessPolicy that targets this backend.
+	accessPolicies, err := AccessPoliciesForBackend(backend, accessPolicyLister)
 	if err != nil {
 		return nil, err
 	}
-	if accessPolicy != nil {
-		rbacConfig = t.translatesAccessPolicyToRBAC(accessPolicy)
-	} else {
+	if len(accessPolicies) == 0 {
 		// No AccessPolicy targets this backend. Per Envoy RBAC docs, when rules are absent, no RBAC enforcement occurs (allow all).
 		// See https://www.envoyproxy.io/docs/envoy/latest/api-v3/extensions/filters/http/rbac/v3/rbac.proto
 		return rbacConfig, nil
 	}
 
+	enforced, _ := splitAccessPoliciesByMode(accessPolicies)
+	enforced = slices.DeleteFunc(enforced, func(accessPolicy *agenticv0alpha0.XAccessPolicy) bool { return !hasAllowRules(accessPolicy) })
+	if len(enforced) == 0 {
+		// Only AccessPolicies in Audit mode or without Allow rules target this backend. The latter only deny,
+		// see denyRBACConfigFromAccessPolicy, and the former are evaluated by auditRBACConfigFromAccessPolicy.
+		return rbacConfig, nil
+	}
+	for _, accessPolicy := range enforced {
+		mergeRBACConfig(rbacConfig, t.translatesAccessPolicyToRBAC(accessPolicy))
+	}
+
 	// It's deny-by-default (a.k.a ALLOW action), we explicitly allow necessary
 	// MCP operations for all backends. These policies are essential for MCP
 	// session management and tool initialization.
-	addPolicyToRBACRules(rbacConfig, allowMCPSessionClosePolicyName, buildAllowMCPSessionClosePolicy())
-	addPolicyToRBACRules(rbacConfig, allowAnyoneToInitializeAndListToolsPolicyName, buildAllowAnyoneToInitializeAndListToolsPolicy())
-	addPolicyToRBACRules(rbacConfig, allowHTTPGet, buildAllowHTTPGetPolicy())
+	t.addImplicitPolicies(rbacConfig, enforced)
+
+	return rbacConfig, nil
+}
+
+// auditRBACConfigFromAccessPolicy generates the RBAC config evaluating the Allow rules of the AccessPolicies in
+// Audit mode targeting a given backend, along with the implicit policies, as shadow rules. It returns nil if no
+// AccessPolicy in Audit mode with Allow rules targets the backend.
+func (t *Translator) auditRBACConfigFromAccessPolicy(accessPolicyLister agenticlisters.XAccessPolicyLister, backend *agenticv0alpha0.XBackend) (*rbacv3.RBAC, error) {
+	accessPolicies, err := AccessPoliciesForBackend(backend, accessPolicyLister)
+	if err != nil {
+		return nil, err
+	}
+	_, audited := splitAccessPoliciesByMode(accessPolicies)
+	audited = slices.DeleteFunc(audited, func(accessPolicy *agenticv0alpha0.XAccessPolicy) bool { return !hasAllowRules(accessPolicy) })
+	if len(audited) == 0 {
+		return nil, nil
+	}
 
+	rbacConfig := &rbacv3.RBAC{}
+	for _, accessPolicy := range audited {
+		mergeRBACConfig(rbacConfig, t.translatesAccessPolicyToRBAC(accessPolicy))
+	}
+	t.addImplicitPolicies(rbacConfig, audited)
+	rbacConfig.ShadowRulesStatPrefix = auditShadowRulePrefix + "_"
 	return rbacConfig, nil
 }
 
-// findAccessPolicyForBackend finds the AccessPolicy that targets the given backend.
-// It assumes that there is only one AccessPolicy for each backend.
-func findAccessPolicyForBackend(backend *agenticv0alpha0.XBackend, accessPolicyLister agenticlisters.XAccessPolicyLister) (*agenticv0alpha0.XAccessPolicy, error) {
+// splitAccessPoliciesByMode splits AccessPolicies into the enforced ones and the ones in Audit mode, preserving
+// their order.
+func splitAccessPoliciesByMode(accessPolicies []*agenticv0alpha0.XAccessPolicy) (enforced, audited []*agenticv0alpha0.XAccessPolicy) {
+	for _, accessPolicy := range accessPolicies {
+		if isAuditMode(accessPolicy) {
+			audited = append(audited, accessPolicy)
+			continue
+		}
+		enforced = append(enforced, accessPolicy)
+	}
+	return enforced, audited
+}
+
+// hasAllowRules returns true if the AccessPolicy has Allow rules. An AccessPolicy without Allow rules only denies
+// requests, it does not restrict the requests its Deny rules do not match to any allow list.
+func hasAllowRules(accessPolicy *agenticv0alpha0.XAccessPolicy) bool {
+	return slices.ContainsFunc(accessPolicy.Spec.Rules, func(rule agenticv0alpha0.AccessRule) bool {
+		return rule.Action != agenticv0alpha0.AccessRuleActionDeny
+	})
+}
+
+// isAuditMode returns true if the rules of the AccessPolicy are only audited, not enforced.
+func isAuditMode(accessPolicy *agenticv0alpha0.XAccessPolicy) bool {
+	return accessPolicy.Spec.Mode == agenticv0alpha0.AccessPolicyModeAudit
+}
+
+// gatewayAuditShadowRulePrefix returns the shadow rule stat prefix of the listener-level RBAC filters of an
+// AccessPolicy in Audit mode targeting a Gateway. Each such AccessPolicy gets its own filters, so the prefix is
+// qualified with the policy's namespace and name to keep their shadow decisions apart.
+func gatewayAuditShadowRulePrefix(accessPolicy *agenticv0alpha0.XAccessPolicy) string {
+	return fmt.Sprintf("%s_%s_%s_", auditShadowRulePrefix, accessPolicy.Namespace, accessPolicy.Name)
+}
+
+// addImplicitPolicies adds the implicit policies to an RBAC config derived from the given AccessPolicies with
+// Allow rules. Each of them can be disabled by the DefaultAllowances of the AccessPolicies, in which case it only
+// applies to the sources of the Allow rules. If the AccessPolicies are in Audit mode, the implicit policies are
+// added to the shadow rules, like their rules.
+//
+// The ALLOW-action section is always created, even without any policy: when none of the Allow rules is in force
+// or valid and all the default allowances are disabled, the AccessPolicies must allow no request, whereas an RBAC
+// filter without rules allows every request.
+func (t *Translator) addImplicitPolicies(rbacConfig *rbacv3.RBAC, accessPolicies []*agenticv0alpha0.XAccessPolicy) {
+	addPolicy := addPolicyToRBACRules
+	if _, audited := splitAccessPoliciesByMode(accessPolicies); len(audited) > 0 && len(audited) == len(accessPolicies) {
+		addPolicy = addPolicyToRBACAuditRules
+		if rbacConfig.GetShadowRules() == nil {
+			rbacConfig.ShadowRules = &rbacconfigv3.RBAC{Action: rbacconfigv3.RBAC_ALLOW, Policies: map[string]*rbacconfigv3.Policy{}}
+		}
+	} else if rbacConfig.GetRules() == nil {
+		rbacConfig.Rules = &rbacconfigv3.RBAC{Action: rbacconfigv3.RBAC_ALLOW, Policies: map[string]*rbacconfigv3.Policy{}}
+	}
+	sourcePrincipals := t.allowRulePrincipals(accessPolicies)
+	for _, implicitPolicy := range implicitPolicies {
+		policy := implicitPolicy.build()
+		if !defaultAllowanceEnabled(accessPolicies, implicitPolicy.allowance) {
+			if len(sourcePrincipals) == 0 {
+				continue
+			}
+			policy = restrictPolicyToPrincipals(policy, sourcePrincipals)
+		}
+		addPolicy(rbacConfig, implicitPolicy.name, policy)
+	}
+}
+
+// implicitPolicies are the RBAC policies added for every backend targeted by AccessPolicies, along with
+// the DefaultAllowances setting that toggles each of them.
+var implicitPolicies = []struct {
+	name      string
+	build     func() *rbacconfigv3.Policy
+	allowance func(*agenticv0alpha0.DefaultAllowances) *bool
+}{
+	{
+		name:      allowMCPSessionClosePolicyName,
+		build:     buildAllowMCPSessionClosePolicy,
+		allowance: func(a *agenticv0alpha0.DefaultAllowances) *bool { return a.CloseSession },
+	},
+	{
+		name:      allowAnyoneToInitializeAndListToolsPolicyName,
+		build:     buildAllowAnyoneToInitializeAndListToolsPolicy,
+		allowance: func(a *agenticv0alpha0.DefaultAllowances) *bool { return a.InitializeAndListTools },
+	},
+	{
+		name:      allowHTTPGet,
+		build:     buildAllowHTTPGetPolicy,
+		allowance: func(a *agenticv0alpha0.DefaultAllowances) *bool { return a.HTTPGet },
+	},
+}
+
+// defaultAllowanceEnabled returns false if any of the AccessPolicies disables the given allowance.
+func defaultAllowanceEnabled(accessPolicies []*agenticv0alpha0.XAccessPolicy, allowance func(*agenticv0alpha0.DefaultAllowances) *bool) bool {
+	for _, accessPolicy := range accessPolicies {
+		if accessPolicy.Spec.DefaultAllowances == nil {
+			continue
+		}
+		if enabled := allowance(accessPolicy.Spec.DefaultAllowances); enabled != nil && !*enabled {
+			return false
+		}
+	}
+	return true
+}
+
+// allowRulePrincipals returns the principals of the Allow rules of the given AccessPolicies that authorize requests,
+// i.e. that are in force and, for CEL rules, whose expression compiles.
+func (t *Translator) allowRulePrincipals(accessPolicies []*agenticv0alpha0.XAccessPolicy) []*rbacconfigv3.Principal {
+	var principals []*rbacconfigv3.Principal
+	for _, accessPolicy := range accessPolicies {
+		for _, rule := range accessPolicy.Spec.Rules {
+			if rule.Action == agenticv0alpha0.AccessRuleActionDeny {
+				continue
+			}
+			if inForce, _ := t.ruleInForce(accessPolicy, rule); !inForce {
+				continue
+			}
+			if rule.Authorization != nil && rule.Authorization.Type == agenticv0alpha0.AuthorizationRuleTypeCEL {
+				// An Allow rule with an invalid expression authorizes no request, see translatesAccessPolicyToRBAC.
+				if _, err := buildCELCondition(accessPolicy, rule); err != nil {
+					continue
+				}
+			}
+			principals = append(principals, t.buildRulePrincipals(accessPolicy, rule)...)
+		}
+	}
+	return principals
+}
+
+// restrictPolicyToPrincipals returns a copy of the policy whose principals must also match any of the given principals.
+func restrictPolicyToPrincipals(policy *rbacconfigv3.Policy, principals []*rbacconfigv3.Principal) *rbacconfigv3.Policy {
+	restricted := &rbacconfigv3.Policy{Permissions: policy.Permissions}
+	for _, principal := range policy.Principals {
+		restricted.Principals = append(restricted.Principals, &rbacconfigv3.Principal{
+			Identifier: &rbacconfigv3.Principal_AndIds{
+				AndIds: &rbacconfigv3.Principal_Set{
+					Ids: []*rbacconfigv3.Principal{
+						principal,
+						{Identifier: &rbacconfigv3.Principal_OrIds{OrIds: &rbacconfigv3.Principal_Set{Ids: principals}}},
+					},
+				},
+			},
+		})
+	}
+	return restricted
+}
+
+// AccessPoliciesForBackend returns all AccessPolicies that target the given backend, in the order in
+// which they are merged: oldest first, with ties broken by namespace/name.
+func AccessPoliciesForBackend(backend *agenticv0alpha0.XBackend, accessPolicyLister agenticlisters.XAccessPolicyLister) ([]*agenticv0alpha0.XAccessPolicy, error) {
 	// List all AccessPolicies in the Backend's namespace.
 	allAccessPolicies, err := accessPolicyLister.XAccessPolicies(backend.Namespace).List(labels.Everything())
 	if err != nil {
 		return nil, fmt.Errorf("failed to list AccessPolicies in namespace %s: %w", backend.Namespace, err)
 	}
 
-	// Find the first AuthPolicy that targets this specific backend.
-	// We assume only one AccessPolicy will target a given backend.
-	// TODO: Enforce this uniqueness constraint at the API level or merge multiple policies if needed.
+	var accessPolicies []*agenticv0alpha0.XAccessPolicy
 	for _, accessPolicy := range allAccessPolicies {
 		for _, targetRef := range accessPolicy.Spec.TargetRefs {
 			if targetRef.Kind == "XBackend" && string(targetRef.Name) == backend.Name {
-				return accessPolicy, nil
+				accessPolicies = append(accessPolicies, accessPolicy)
+				break
+			}
+		}
+	}
+	sortAccessPolicies(accessPolicies)
+	return accessPolicies, nil
+}
+
+// AccessPoliciesForGateway returns all AccessPolicies that target the given Gateway, in the order in which
+// they are evaluated: oldest first, with ties broken by namespace/name.
+// If sectionNames are given, only the AccessPolicies that target the whole Gateway or any of the named
+// listeners are returned.
+func AccessPoliciesForGateway(gateway *gatewayv1.Gateway, accessPolicyLister agenticlisters.XAccessPolicyLister, sectionNames ...gatewayv1.SectionName) ([]*agenticv0alpha0.XAccessPolicy, error) {
+	// List all AccessPolicies in the Gateway's namespace.
+	allAccessPolicies, err := accessPolicyLister.XAccessPolicies(gateway.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list AccessPolicies in namespace %s: %w", gateway.Namespace, err)
+	}
+
+	var accessPolicies []*agenticv0alpha0.XAccessPolicy
+	for _, accessPolicy := range allAccessPolicies {
+		for _, targetRef := range accessPolicy.Spec.TargetRefs {
+			if !IsGatewayTargetRef(targetRef) || string(targetRef.Name) != gateway.Name {
+				continue
+			}
+			if len(sectionNames) == 0 || targetRef.SectionName == nil || slices.Contains(sectionNames, *targetRef.SectionName) {
+				accessPolicies = append(accessPolicies, accessPolicy)
+				break
+			}
+		}
+	}
+	sortAccessPolicies(accessPolicies)
+	return accessPolicies, nil
+}
+
+// listenerAccessPolicies returns the AccessPolicies enforced by the filter chain of the given listeners of a
+// Gateway: those targeting the Gateway or any of the listeners, followed by those targeting the XBackends the
+// listeners route to.
+func (t *Translator) listenerAccessPolicies(gateway *gatewayv1.Gateway, backends []*agenticv0alpha0.XBackend, sectionNames ...gatewayv1.SectionName) ([]*agenticv0alpha0.XAccessPolicy, error) {
+	if t.accessPolicyLister == nil {
+		return nil, nil
+	}
+	accessPolicies, err := AccessPoliciesForGateway(gateway, t.accessPolicyLister, sectionNames...)
+	if err != nil {
+		return nil, err
+	}
+	for _, backend := range backends {
+		backendAccessPolicies, err := AccessPoliciesForBackend(backend, t.accessPolicyLister)
+		if err != nil {
+			return nil, err
+		}
+		for _, accessPolicy := range backendAccessPolicies {
+			// An AccessPolicy may target both the Gateway and some of its XBackends.
+			if !slices.Contains(accessPolicies, accessPolicy) {
+				accessPolicies = append(accessPolicies, accessPolicy)
+			}
+		}
+	}
+	return accessPolicies, nil
+}
+
+// IsGatewayTargetRef checks if a given AccessPolicy targetRef refers to a Gateway.
+func IsGatewayTargetRef(targetRef gatewayv1.LocalPolicyTargetReferenceWithSectionName) bool {
+	return targetRef.Group == gatewayv1.GroupName && targetRef.Kind == "Gateway"
+}
+
+// sortAccessPolicies sorts AccessPolicies by creation timestamp, oldest first. Policies created at the
+// same time are ordered by namespace/name.
+func sortAccessPolicies(accessPolicies []*agenticv0alpha0.XAccessPolicy) {
+	slices.SortStableFunc(accessPolicies, func(a, b *agenticv0alpha0.XAccessPolicy) int {
+		if c := a.CreationTimestamp.Time.Compare(b.CreationTimestamp.Time); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Namespace+"/"+a.Name, b.Namespace+"/"+b.Name)
+	})
+}
+
+// externalAuthzRBACFilterName returns the name of the RBAC filter triggering the ext_authz filter of the ExternalAuth
+// configuration with the given unique ID.
+func externalAuthzRBACFilterName(hash string) string {
+	return fmt.Sprintf("%s.%s", externalAuthzRBACFilterNamePrefix, hash)
+}
+
+// externalAuthzRule is an Allow rule in force of an enforced AccessPolicy, checked by an external authorizer.
+type externalAuthzRule struct {
+	accessPolicy *agenticv0alpha0.XAccessPolicy
+	rule         agenticv0alpha0.AccessRule
+}
+
+// externalAuthzRules groups the Allow rules in force of the given enforced AccessPolicies that are checked by an
+// external authorizer by the unique ID of their ExternalAuth configuration.
+func (t *Translator) externalAuthzRules(accessPolicies []*agenticv0alpha0.XAccessPolicy) map[string][]externalAuthzRule {
+	rules := make(map[string][]externalAuthzRule)
+	for _, accessPolicy := range accessPolicies {
+		for _, rule := range accessPolicy.Spec.Rules {
+			if rule.Action == agenticv0alpha0.AccessRuleActionDeny || rule.Authorization == nil || rule.Authorization.Type != agenticv0alpha0.AuthorizationRuleTypeExternalAuth || rule.Authorization.ExternalAuth == nil {
+				continue
+			}
+			if inForce, _ := t.ruleInForce(accessPolicy, rule); !inForce {
+				continue
 			}
+			hash, err := externalAuthUniqueID(rule.Authorization)
+			if err != nil {
+				klog.Errorf("Failed to generate unique ID for externalAuth config of rule %s of AccessPolicy %s/%s: %v", rule.Name, accessPolicy.Namespace, accessPolicy.Name, err)
+				continue
+			}
+			rules[hash] = append(rules[hash], externalAuthzRule{accessPolicy: accessPolicy, rule: rule})
+		}
+	}
+	return rules
+}
+
+// externalAuthzTriggerRBACConfigs builds, for each ExternalAuth configuration used by the Allow rules in force of the
+// given enforced AccessPolicies, the RBAC config of the filter triggering its ext_authz filter, keyed by the unique ID
+// of the configuration. A request matching rules that use different configurations is checked by each of their
+// external authorizers.
+func (t *Translator) externalAuthzTriggerRBACConfigs(accessPolicies []*agenticv0alpha0.XAccessPolicy) map[string]*rbacv3.RBAC {
+	configs := make(map[string]*rbacv3.RBAC)
+	for hash, rules := range t.externalAuthzRules(accessPolicies) {
+		configs[hash] = t.externalAuthzTriggerRBACConfig(hash, rules)
+	}
+	return configs
+}
+
+// externalAuthzTriggerRBACConfig builds the RBAC config of the filter triggering the ext_authz filter of the
+// ExternalAuth configuration with the given unique ID. It only has shadow rules, one per rule using the
+// configuration, whose stat prefix is the key that enables the ext_authz filter. The effective shadow policy ID
+// the filter records is the name of a rule matching the request, see accessRulePolicyName.
+func (t *Translator) externalAuthzTriggerRBACConfig(hash string, rules []externalAuthzRule) *rbacv3.RBAC {
+	rbacConfig := &rbacv3.RBAC{ShadowRulesStatPrefix: fmt.Sprintf("%s_%s_", externalAuthzShadowRulePrefix, hash)}
+	for _, r := range rules {
+		addPolicyToRBACShadowRules(rbacConfig, accessRulePolicyName(r.accessPolicy, r.rule.Name), &rbacconfigv3.Policy{
+			Principals:  t.buildRulePrincipals(r.accessPolicy, r.rule),
+			Permissions: []*rbacconfigv3.Permission{buildTooslCallMethodPermission()},
+		})
+	}
+	return rbacConfig
+}
+
+// accessRulePolicyName returns the name of the RBAC policy generated for a rule of an AccessPolicy.
+// Rule names are only unique within an AccessPolicy, so the name is qualified with the policy's
+// namespace and name to keep rules of different policies targeting the same backend apart.
+func accessRulePolicyName(accessPolicy *agenticv0alpha0.XAccessPolicy, ruleName string) string {
+	return fmt.Sprintf("%s/%s/%s", accessPolicy.Namespace, accessPolicy.Name, ruleName)
+}
+
+// mergeRBACConfig mutates the dst RBAC config by adding all rules and shadow rules of src.
+func mergeRBACConfig(dst, src *rbacv3.RBAC) {
+	for name, policy := range src.GetRules().GetPolicies() {
+		addPolicyToRBACRules(dst, name, policy)
+	}
+	if src.GetShadowRules() != nil && dst.GetShadowRules() == nil {
+		dst.ShadowRules = &rbacconfigv3.RBAC{
+			Action:   src.GetShadowRules().GetAction(),
+			Policies: map[string]*rbacconfigv3.Policy{},
 		}
 	}
-	return nil, nil // No AccessPolicy found for the backend.
+	for name, policy := range src.GetShadowRules().GetPolicies() {
+		addPolicyToRBACShadowRules(dst, name, policy)
+	}
+	if src.GetShadowRulesStatPrefix() != "" {
+		dst.ShadowRulesStatPrefix = src.GetShadowRulesStatPrefix()
+	}
 }
 
 // convertSAtoSPIFFEID constructs a standard SPIFFE ID for a Kubernetes ServiceAccount.
@@ -113,76 +496,389 @@ func convertSAtoSPIFFEID(trustDomain, namespace, saName string) string {
 	return fmt.Sprintf(spiffeIDFormat, trustDomain, namespace, saName)
 }
 
+// translatesAccessPolicyToRBAC translates the Allow rules of an AccessPolicy into an ALLOW-action RBAC config.
+// The rules of an AccessPolicy in Audit mode are placed in the shadow rules instead, so that they are evaluated
+// without being enforced.
 func (t *Translator) translatesAccessPolicyToRBAC(accessPolicy *agenticv0alpha0.XAccessPolicy) *rbacv3.RBAC {
 	rbacConfig := &rbacv3.RBAC{}
 
 	for _, rule := range accessPolicy.Spec.Rules {
-		policyName := rule.Name
-		var principalIDs []*rbacconfigv3.Principal
-
-		var source string
-		switch rule.Source.Type {
-		case agenticv0alpha0.AuthorizationSourceTypeSPIFFE:
-			if rule.Source.SPIFFE != nil {
-				source = string(*rule.Source.SPIFFE)
-			}
-		case agenticv0alpha0.AuthorizationSourceTypeServiceAccount:
-			if rule.Source.ServiceAccount != nil {
-				ns := rule.Source.ServiceAccount.Namespace
-				if ns == "" {
-					ns = accessPolicy.Namespace
-				}
-				// Convert K8s ServiceAccount to SPIFFE ID
-				source = convertSAtoSPIFFEID(t.agenticIdentityTrustDomain, ns, rule.Source.ServiceAccount.Name)
-			}
+		if rule.Action == agenticv0alpha0.AccessRuleActionDeny {
+			// Deny rules are translated separately, see translateAccessPolicyToDenyRBAC.
+			continue
 		}
-
-		if source != "" {
-			principalIDs = append(principalIDs, &rbacconfigv3.Principal{
-				Identifier: &rbacconfigv3.Principal_Authenticated_{
-					Authenticated: &rbacconfigv3.Principal_Authenticated{
-						PrincipalName: &matcherv3.StringMatcher{
-							MatchPattern: &matcherv3.StringMatcher_Exact{Exact: source},
-						},
-					},
-				},
-			})
-		}
-
-		if len(principalIDs) == 0 {
-			principalIDs = []*rbacconfigv3.Principal{buildAnyPrincipal()}
+		if inForce, _ := t.ruleInForce(accessPolicy, rule); !inForce {
+			// An Allow rule out of force authorizes no request.
+			continue
 		}
 
+		policyName := accessRulePolicyName(accessPolicy, rule.Name)
 		policy := &rbacconfigv3.Policy{
-			Principals: principalIDs,
+			Principals: t.buildRulePrincipals(accessPolicy, rule),
 		}
 
 		if rule.Authorization != nil {
 			switch rule.Authorization.Type {
-			case agenticv0alpha0.AuthorizationRuleTypeInlineTools:
-				if permission := translateInlineToolsToRBACPermission(rule.Authorization.Tools); permission != nil {
+			case agenticv0alpha0.AuthorizationRuleTypeInlineTools, agenticv0alpha0.AuthorizationRuleTypeInlineResources, agenticv0alpha0.AuthorizationRuleTypeInlinePrompts:
+				if permission := translateInlineAuthorizationToRBACPermission(rule.Authorization); permission != nil {
 					policy.Permissions = []*rbacconfigv3.Permission{permission}
+				} else {
+					klog.Errorf("Rule %s of AccessPolicy %s/%s has no valid authorization, it authorizes no request", rule.Name, accessPolicy.Namespace, accessPolicy.Name)
 				}
+			case agenticv0alpha0.AuthorizationRuleTypeCEL:
+				condition, err := buildCELCondition(accessPolicy, rule)
+				if err != nil {
+					// An Allow rule with an invalid expression authorizes no request.
+					klog.Errorf("Failed to compile CEL expression of rule %s of AccessPolicy %s/%s: %v", rule.Name, accessPolicy.Namespace, accessPolicy.Name, err)
+					continue
+				}
+				policy.Permissions = []*rbacconfigv3.Permission{buildAnyPermission()}
+				policy.Condition = condition
 			case agenticv0alpha0.AuthorizationRuleTypeExternalAuth:
+				if isAuditMode(accessPolicy) {
+					// Decisions of external authorizers cannot be recorded without being enforced.
+					klog.Errorf("Ignoring rule %s of AccessPolicy %s/%s: ExternalAuth is not supported in Audit mode", rule.Name, accessPolicy.Namespace, accessPolicy.Name)
+					continue
+				}
 				if rule.Authorization.ExternalAuth != nil {
-					hash, err := externalAuthUniqueID(rule.Authorization.ExternalAuth)
-					if err != nil {
-						klog.Errorf("Failed to generate unique ID for externalAuth config in AccessPolicy %s/%s: %v", accessPolicy.Namespace, accessPolicy.Name, err)
-						continue
-					}
-					rbacConfig.ShadowRulesStatPrefix = fmt.Sprintf("%s_%s_", externalAuthzShadowRulePrefix, hash) // a maximum of one ExternalAuth rule per policy is allowed, so we can safely set the shadow rule stat prefix at the RBAC config level
+					// The tool calls are allowed here and checked by the external authorizer, see externalAuthzTriggerRBACConfigs.
 					policy.Permissions = []*rbacconfigv3.Permission{buildTooslCallMethodPermission()}
-					addPolicyToRBACShadowRules(rbacConfig, policyName, policy)
 				}
 			}
 		}
 
+		if len(policy.Permissions) == 0 {
+			// Envoy rejects policies without permissions. A rule without any valid authorization authorizes no request.
+			policy.Permissions = []*rbacconfigv3.Permission{buildNoPermission()}
+		}
+
+		if isAuditMode(accessPolicy) {
+			addPolicyToRBACAuditRules(rbacConfig, policyName, policy)
+			rbacConfig.ShadowRulesStatPrefix = auditShadowRulePrefix + "_"
+			continue
+		}
 		addPolicyToRBACRules(rbacConfig, policyName, policy)
 	}
 
 	return rbacConfig
 }
 
+// translateAccessPolicyToDenyRBAC translates the Deny rules of an AccessPolicy into a DENY-action RBAC config.
+// It returns nil if the AccessPolicy has no Deny rules. The rules of an AccessPolicy in Audit mode are placed in
+// the shadow rules instead.
+func (t *Translator) translateAccessPolicyToDenyRBAC(accessPolicy *agenticv0alpha0.XAccessPolicy) *rbacv3.RBAC {
+	var rbacConfig *rbacv3.RBAC
+
+	for _, rule := range accessPolicy.Spec.Rules {
+		if rule.Action != agenticv0alpha0.AccessRuleActionDeny {
+			continue
+		}
+		inForce, scheduleErr := t.ruleInForce(accessPolicy, rule)
+		if scheduleErr == nil && !inForce {
+			continue
+		}
+
+		// Without an authorization, a Deny rule denies all tool calls from the source. So does a Deny rule
+		// with an invalid schedule.
+		policy := &rbacconfigv3.Policy{
+			Principals:  t.buildRulePrincipals(accessPolicy, rule),
+			Permissions: []*rbacconfigv3.Permission{buildTooslCallMethodPermission()},
+		}
+		if rule.Authorization != nil && scheduleErr == nil {
+			if p := translateInlineAuthorizationToRBACPermission(rule.Authorization); p != nil {
+				policy.Permissions = []*rbacconfigv3.Permission{p}
+			}
+			if rule.Authorization.Type == agenticv0alpha0.AuthorizationRuleTypeCEL {
+				// A Deny rule with an invalid expression denies all tool calls from the source.
+				if condition, err := buildCELCondition(accessPolicy, rule); err != nil {
+					klog.Errorf("Failed to compile CEL expression of rule %s of AccessPolicy %s/%s: %v", rule.Name, accessPolicy.Namespace, accessPolicy.Name, err)
+				} else {
+					policy.Permissions = []*rbacconfigv3.Permission{buildAnyPermission()}
+					policy.Condition = condition
+				}
+			}
+		}
+
+		if rbacConfig == nil {
+			rbacConfig = &rbacv3.RBAC{}
+		}
+		if isAuditMode(accessPolicy) {
+			addPolicyToRBACShadowRules(rbacConfig, accessRulePolicyName(accessPolicy, rule.Name), policy)
+			rbacConfig.ShadowRulesStatPrefix = auditShadowRulePrefix + "_deny_"
+			continue
+		}
+		addPolicyToRBACDenyRules(rbacConfig, accessRulePolicyName(accessPolicy, rule.Name), policy)
+	}
+
+	return rbacConfig
+}
+
+// denyRBACConfigFromAccessPolicy generates the DENY-action RBAC config for a given backend from the Deny rules
+// of all AccessPolicies targeting it. It returns nil if there are no Deny rules for the backend. The Deny rules
+// of AccessPolicies in Audit mode are shadow rules of the same config.
+func (t *Translator) denyRBACConfigFromAccessPolicy(accessPolicyLister agenticlisters.XAccessPolicyLister, backend *agenticv0alpha0.XBackend) (*rbacv3.RBAC, error) {
+	accessPolicies, err := AccessPoliciesForBackend(backend, accessPolicyLister)
+	if err != nil {
+		return nil, err
+	}
+
+	var rbacConfig *rbacv3.RBAC
+	for _, accessPolicy := range accessPolicies {
+		denyConfig := t.translateAccessPolicyToDenyRBAC(accessPolicy)
+		if denyConfig == nil {
+			continue
+		}
+		if rbacConfig == nil {
+			rbacConfig = &rbacv3.RBAC{}
+		}
+		for name, policy := range denyConfig.GetRules().GetPolicies() {
+			addPolicyToRBACDenyRules(rbacConfig, name, policy)
+		}
+		for name, policy := range denyConfig.GetShadowRules().GetPolicies() {
+			addPolicyToRBACShadowRules(rbacConfig, name, policy)
+		}
+		if denyConfig.GetShadowRulesStatPrefix() != "" {
+			rbacConfig.ShadowRulesStatPrefix = denyConfig.GetShadowRulesStatPrefix()
+		}
+	}
+	return rbacConfig, nil
+}
+
+// buildGatewayRBACFilters builds the listener-level RBAC filters enforcing the AccessPolicies that target the
+// given Gateway, or any of the listeners sharing a filter chain. The filters are ordered like the AccessPolicies,
+// so that they are evaluated in order before the per-backend RBAC filters, and a request must be allowed by all
+// of them.
+//
+// For each AccessPolicy, the Deny rules are enforced by a DENY-action filter and the Allow rules by an
+// ALLOW-action filter including the implicit policies. An AccessPolicy without Allow rules only denies.
+// The filters of AccessPolicies in Audit mode only have shadow rules, and come first so that their decisions
+// are recorded even for requests denied by the enforced ones.
+func (t *Translator) buildGatewayRBACFilters(gateway *gatewayv1.Gateway, sectionNames ...gatewayv1.SectionName) ([]*hcm.HttpFilter, error) {
+	if gateway == nil || t.accessPolicyLister == nil {
+		return nil, nil
+	}
+	accessPolicies, err := AccessPoliciesForGateway(gateway, t.accessPolicyLister, sectionNames...)
+	if err != nil {
+		return nil, err
+	}
+
+	enforced, audited := splitAccessPoliciesByMode(accessPolicies)
+
+	var filters []*hcm.HttpFilter
+	for _, accessPolicy := range append(audited, enforced...) {
+		filterName := gatewayRBACFilterName(accessPolicy)
+
+		if denyConfig := t.translateAccessPolicyToDenyRBAC(accessPolicy); denyConfig != nil {
+			if isAuditMode(accessPolicy) {
+				denyConfig.ShadowRulesStatPrefix = gatewayAuditShadowRulePrefix(accessPolicy) + "deny_"
+			}
+			denyFilter, err := buildRBACFilter(filterName+".deny", denyConfig)
+			if err != nil {
+				return nil, err
+			}
+			filters = append(filters, denyFilter)
+		}
+
+		if !hasAllowRules(accessPolicy) {
+			continue
+		}
+		allowConfig := t.translatesAccessPolicyToRBAC(accessPolicy)
+		t.addImplicitPolicies(allowConfig, []*agenticv0alpha0.XAccessPolicy{accessPolicy})
+		if isAuditMode(accessPolicy) {
+			allowConfig.ShadowRulesStatPrefix = gatewayAuditShadowRulePrefix(accessPolicy)
+		}
+		allowFilter, err := buildRBACFilter(filterName, allowConfig)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, allowFilter)
+	}
+	return filters, nil
+}
+
+// gatewayRBACFilterName returns the name of the listener-level RBAC filter enforcing the Allow rules of an
+// AccessPolicy targeting a Gateway. The filter enforcing its Deny rules has the same name suffixed with ".deny".
+func gatewayRBACFilterName(accessPolicy *agenticv0alpha0.XAccessPolicy) string {
+	return fmt.Sprintf("%s.%s.%s", gatewayRBACFilterNamePrefix, accessPolicy.Namespace, accessPolicy.Name)
+}
+
+// buildGatewayVirtualHostRBACConfig disables, for a virtual host, the listener-level RBAC filters of the
+// AccessPolicies that do not target any of the listeners serving the virtual host. The filter chain of a plain
+// HTTP port is shared by all its listeners, which are told apart by the hostnames of their virtual hosts.
+// If the hostnames of several listeners overlap, the AccessPolicies targeting any of them apply to the virtual host.
+func (t *Translator) buildGatewayVirtualHostRBACConfig(gateway *gatewayv1.Gateway, filters []*hcm.HttpFilter, sectionNames []gatewayv1.SectionName) (map[string]*anypb.Any, error) {
+	if len(filters) == 0 {
+		return nil, nil
+	}
+	accessPolicies, err := AccessPoliciesForGateway(gateway, t.accessPolicyLister, sectionNames...)
+	if err != nil {
+		return nil, err
+	}
+	applicable := sets.New[string]()
+	for _, accessPolicy := range accessPolicies {
+		applicable.Insert(gatewayRBACFilterName(accessPolicy))
+	}
+
+	perFilterConfig := make(map[string]*anypb.Any)
+	for _, filter := range filters {
+		if applicable.Has(strings.TrimSuffix(filter.GetName(), ".deny")) {
+			continue
+		}
+		// An RBACPerRoute without RBAC config disables the filter.
+		disabled, err := anypb.New(&rbacv3.RBACPerRoute{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal RBACPerRoute proto: %w", err)
+		}
+		perFilterConfig[filter.GetName()] = disabled
+	}
+	if len(perFilterConfig) == 0 {
+		return nil, nil
+	}
+	return perFilterConfig, nil
+}
+
+// RuleSources returns the sources of an AccessRule, whether it specifies a single source or several.
+func RuleSources(rule agenticv0alpha0.AccessRule) []agenticv0alpha0.Source {
+	if rule.Source != nil {
+		return []agenticv0alpha0.Source{*rule.Source}
+	}
+	return rule.Sources
+}
+
+// buildRulePrincipals builds the RBAC principals matching any of the sources of the given rule.
+func (t *Translator) buildRulePrincipals(accessPolicy *agenticv0alpha0.XAccessPolicy, rule agenticv0alpha0.AccessRule) []*rbacconfigv3.Principal {
+	var principals []*rbacconfigv3.Principal
+	for _, source := range RuleSources(rule) {
+		principals = append(principals, t.buildSourcePrincipals(accessPolicy, rule, source)...)
+	}
+	if len(principals) == 0 {
+		// A rule without any source matches no request.
+		principals = []*rbacconfigv3.Principal{buildNoPrincipal()}
+	}
+	return principals
+}
+
+// buildSourcePrincipals builds the RBAC principals matching a source of the given rule.
+func (t *Translator) buildSourcePrincipals(accessPolicy *agenticv0alpha0.XAccessPolicy, rule agenticv0alpha0.AccessRule, source agenticv0alpha0.Source) []*rbacconfigv3.Principal {
+	var principalIDs []*rbacconfigv3.Principal
+
+	if oidc := sourceOIDC(source); oidc != nil {
+		// Never fall back to any principal for OIDC sources, a request without a verified token must not match.
+		principal, err := buildOIDCPrincipal(accessPolicy.Namespace, oidc)
+		if err != nil {
+			klog.Errorf("Failed to build OIDC principal for rule %s of AccessPolicy %s/%s: %v", rule.Name, accessPolicy.Namespace, accessPolicy.Name, err)
+			principal = buildNoPrincipal()
+		}
+		return []*rbacconfigv3.Principal{principal}
+	}
+
+	sourceIDs, ok := t.ruleSourceIDs(accessPolicy, rule, source)
+	if !ok {
+		return []*rbacconfigv3.Principal{buildAnyPrincipal()}
+	}
+	for _, sourceID := range sourceIDs {
+		principalIDs = append(principalIDs, &rbacconfigv3.Principal{
+			Identifier: &rbacconfigv3.Principal_Authenticated_{
+				Authenticated: &rbacconfigv3.Principal_Authenticated{
+					PrincipalName: sourceID.stringMatcher(),
+				},
+			},
+		})
+	}
+
+	if len(principalIDs) == 0 {
+		// The source specifies identities, but none of them exists, e.g. no namespace matches the selector.
+		principalIDs = []*rbacconfigv3.Principal{buildNoPrincipal()}
+	}
+
+	return principalIDs
+}
+
+// sourceID is a SPIFFE ID, or a prefix of SPIFFE IDs, matched by the source of an AccessRule.
+type sourceID struct {
+	value  string
+	prefix bool
+}
+
+// stringMatcher returns the Envoy StringMatcher matching the SPIFFE IDs of the sourceID.
+func (s sourceID) stringMatcher() *matcherv3.StringMatcher {
+	if s.prefix {
+		return &matcherv3.StringMatcher{MatchPattern: &matcherv3.StringMatcher_Prefix{Prefix: s.value}}
+	}
+	return &matcherv3.StringMatcher{MatchPattern: &matcherv3.StringMatcher_Exact{Exact: s.value}}
+}
+
+// ruleSourceIDs returns the SPIFFE IDs matched by a source of an AccessRule. It returns false if the source
+// does not specify an identity, in which case any identity is matched.
+func (t *Translator) ruleSourceIDs(accessPolicy *agenticv0alpha0.XAccessPolicy, rule agenticv0alpha0.AccessRule, source agenticv0alpha0.Source) ([]sourceID, bool) {
+	switch source.Type {
+	case agenticv0alpha0.AuthorizationSourceTypeSPIFFE:
+		if source.SPIFFE != nil {
+			id := string(*source.SPIFFE)
+			if prefix, ok := strings.CutSuffix(id, "/*"); ok {
+				// Keep the trailing slash, so that spiffe://td/ns/a/* does not match spiffe://td/ns/ab/sa/b.
+				return []sourceID{{value: prefix + "/", prefix: true}}, true
+			}
+			return []sourceID{{value: id}}, true
+		}
+	case agenticv0alpha0.AuthorizationSourceTypeServiceAccount:
+		if source.ServiceAccount != nil {
+			ns := source.ServiceAccount.Namespace
+			if ns == "" {
+				ns = accessPolicy.Namespace
+			}
+			// Convert K8s ServiceAccount to SPIFFE ID
+			return []sourceID{{value: convertSAtoSPIFFEID(t.agenticIdentityTrustDomain, ns, source.ServiceAccount.Name)}}, true
+		}
+	case agenticv0alpha0.AuthorizationSourceTypeNamespace:
+		if source.Namespace != nil {
+			return []sourceID{{value: namespaceSPIFFEIDPrefix(t.agenticIdentityTrustDomain, string(*source.Namespace)), prefix: true}}, true
+		}
+	case agenticv0alpha0.AuthorizationSourceTypeNamespaceSelector:
+		if source.NamespaceSelector != nil {
+			namespaces, err := t.selectNamespaces(source.NamespaceSelector)
+			if err != nil {
+				// Never fall back to any principal, a selector that cannot be resolved matches no namespace.
+				klog.Errorf("Failed to resolve namespace selector of rule %s of AccessPolicy %s/%s: %v", rule.Name, accessPolicy.Namespace, accessPolicy.Name, err)
+				return []sourceID{}, true
+			}
+			sourceIDs := []sourceID{}
+			for _, namespace := range namespaces {
+				sourceIDs = append(sourceIDs, sourceID{value: namespaceSPIFFEIDPrefix(t.agenticIdentityTrustDomain, namespace), prefix: true})
+			}
+			return sourceIDs, true
+		}
+	}
+	return nil, false
+}
+
+// selectNamespaces returns the sorted names of the namespaces matching the given label selector.
+func (t *Translator) selectNamespaces(labelSelector *metav1.LabelSelector) ([]string, error) {
+	if t.namespaceLister == nil {
+		return nil, fmt.Errorf("namespace lister is not available")
+	}
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespace selector: %w", err)
+	}
+	namespaces, err := t.namespaceLister.List(selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	var names []string
+	for _, namespace := range namespaces {
+		names = append(names, namespace.Name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// namespaceSPIFFEIDPrefix returns the prefix of the standard SPIFFE IDs of all the ServiceAccounts of a namespace.
+// Format: spiffe://<trust-domain>/ns/<namespace>/sa/
+func namespaceSPIFFEIDPrefix(trustDomain, namespace string) string {
+	return fmt.Sprintf(spiffeNamespacePrefixFormat, trustDomain, namespace)
+}
+
 // addPolicyToRBACRules mutates the RBAC config by adding the given policy to the Rules section with the specified name.
 func addPolicyToRBACRules(rbacConfig *rbacv3.RBAC, policyName string, policy *rbacconfigv3.Policy) {
 	if rbacConfig.GetRules() == nil {
@@ -194,24 +890,58 @@ func addPolicyToRBACRules(rbacConfig *rbacv3.RBAC, policyName string, policy *rb
 	rbacConfig.Rules.Policies[policyName] = policy
 }
 
+// addPolicyToRBACDenyRules mutates the RBAC config by adding the given policy to the DENY-action Rules section with the specified name.
+func addPolicyToRBACDenyRules(rbacConfig *rbacv3.RBAC, policyName string, policy *rbacconfigv3.Policy) {
+	if rbacConfig.GetRules() == nil {
+		rbacConfig.Rules = &rbacconfigv3.RBAC{
+			Action:   rbacconfigv3.RBAC_DENY,
+			Policies: map[string]*rbacconfigv3.Policy{},
+		}
+	}
+	rbacConfig.Rules.Policies[policyName] = policy
+}
+
+// addPolicyToRBACAuditRules mutates the RBAC config by adding the given policy to the ALLOW-action ShadowRules section
+// with the specified name, so that it is evaluated without being enforced.
+func addPolicyToRBACAuditRules(rbacConfig *rbacv3.RBAC, policyName string, policy *rbacconfigv3.Policy) {
+	if rbacConfig.GetShadowRules() == nil {
+		rbacConfig.ShadowRules = &rbacconfigv3.RBAC{
+			Action:   rbacconfigv3.RBAC_ALLOW,
+			Policies: map[string]*rbacconfigv3.Policy{},
+		}
+	}
+	rbacConfig.ShadowRules.Policies[policyName] = policy
+}
+
 // addPolicyToRBACRules mutates the RBAC config by adding the given policy to the ShadowRules section with the specified name.
 func addPolicyToRBACShadowRules(rbacConfig *rbacv3.RBAC, policyName string, policy *rbacconfigv3.Policy) {
 	if rbacConfig.GetShadowRules() == nil {
 		rbacConfig.ShadowRules = &rbacconfigv3.RBAC{
-			Action:   rbacconfigv3.RBAC_DENY, // the action for the shadow rule doesn't really matter in this case since we only use it to trigger ext_authz from emitted stats
+			Action:   rbacconfigv3.RBAC_DENY, // the action doesn't matter for the shadow rules triggering ext_authz from emitted stats, and is the one of audited Deny rules
 			Policies: map[string]*rbacconfigv3.Policy{},
 		}
 	}
 	rbacConfig.ShadowRules.Policies[policyName] = policy
 }
 
-func translateInlineToolsToRBACPermission(tools []string) *rbacconfigv3.Permission {
-	if len(tools) == 0 {
+// translateInlineAuthorizationToRBACPermission translates an inline authorization rule (tools, resources or prompts)
+// into an RBAC permission. It returns nil for other types of authorization rules.
+func translateInlineAuthorizationToRBACPermission(authorization *agenticv0alpha0.AuthorizationRule) *rbacconfigv3.Permission {
+	switch authorization.Type {
+	case agenticv0alpha0.AuthorizationRuleTypeInlineTools:
+		return translateInlineToolsToRBACPermission(authorization)
+	case agenticv0alpha0.AuthorizationRuleTypeInlineResources:
+		return translateInlineResourcesToRBACPermission(authorization.Resources)
+	case agenticv0alpha0.AuthorizationRuleTypeInlinePrompts:
+		return translateInlinePromptsToRBACPermission(authorization.Prompts)
+	default:
 		return nil
 	}
+}
 
+func translateInlineToolsToRBACPermission(authorization *agenticv0alpha0.AuthorizationRule) *rbacconfigv3.Permission {
 	var toolValueMatchers []*matcherv3.ValueMatcher
-	for _, tool := range tools {
+	for _, tool := range authorization.Tools {
 		toolValueMatchers = append(toolValueMatchers, &matcherv3.ValueMatcher{
 			MatchPattern: &matcherv3.ValueMatcher_StringMatch{
 				StringMatch: &matcherv3.StringMatcher{
@@ -220,6 +950,19 @@ func translateInlineToolsToRBACPermission(tools []string) *rbacconfigv3.Permissi
 			},
 		})
 	}
+	for _, pattern := range authorization.ToolPatterns {
+		stringMatcher := translateToolNameMatch(pattern)
+		if stringMatcher == nil {
+			continue
+		}
+		toolValueMatchers = append(toolValueMatchers, &matcherv3.ValueMatcher{
+			MatchPattern: &matcherv3.ValueMatcher_StringMatch{StringMatch: stringMatcher},
+		})
+	}
+
+	if len(toolValueMatchers) == 0 {
+		return nil
+	}
 
 	var toolsMatcher *matcherv3.ValueMatcher
 	if len(toolValueMatchers) == 1 {
@@ -230,28 +973,254 @@ func translateInlineToolsToRBACPermission(tools []string) *rbacconfigv3.Permissi
 		}
 	}
 
+	rules := []*rbacconfigv3.Permission{
+		buildTooslCallMethodPermission(),
+		{
+			Rule: &rbacconfigv3.Permission_SourcedMetadata{
+				SourcedMetadata: &rbacconfigv3.SourcedMetadata{
+					MetadataMatcher: &matcherv3.MetadataMatcher{
+						Filter: mcpProxyFilterName,
+						Path:   []*matcherv3.MetadataMatcher_PathSegment{{Segment: &matcherv3.MetadataMatcher_PathSegment_Key{Key: "params"}}, {Segment: &matcherv3.MetadataMatcher_PathSegment_Key{Key: "name"}}},
+						Value:  toolsMatcher,
+					},
+				},
+			},
+		},
+	}
+	// Every argument constraint must hold in addition to the tool name matching.
+	for _, argument := range authorization.Arguments {
+		valueMatcher := translateToolArgumentMatch(argument)
+		if valueMatcher == nil {
+			// Dropping a single constraint would widen the permission, so drop the whole permission instead.
+			return nil
+		}
+		rules = append(rules, buildMCPMetadataPermission(valueMatcher, "params", "arguments", argument.Name))
+		if argument.Type == agenticv0alpha0.ToolArgumentMatchPrefix {
+			// A prefix alone does not contain paths, e.g. "/workspace/../etc/passwd" starts with "/workspace/".
+			rules = append(rules, &rbacconfigv3.Permission{
+				Rule: &rbacconfigv3.Permission_NotRule{
+					NotRule: buildMCPMetadataPermission(buildDotDotSegmentValueMatcher(), "params", "arguments", argument.Name),
+				},
+			})
+		}
+	}
+
 	return &rbacconfigv3.Permission{
 		Rule: &rbacconfigv3.Permission_AndRules{
 			AndRules: &rbacconfigv3.Permission_Set{
-				Rules: []*rbacconfigv3.Permission{
-					buildTooslCallMethodPermission(),
-					{
-						Rule: &rbacconfigv3.Permission_SourcedMetadata{
-							SourcedMetadata: &rbacconfigv3.SourcedMetadata{
-								MetadataMatcher: &matcherv3.MetadataMatcher{
-									Filter: mcpProxyFilterName,
-									Path:   []*matcherv3.MetadataMatcher_PathSegment{{Segment: &matcherv3.MetadataMatcher_PathSegment_Key{Key: "params"}}, {Segment: &matcherv3.MetadataMatcher_PathSegment_Key{Key: "name"}}},
-									Value:  toolsMatcher,
-								},
-							},
-						},
-					},
+				Rules: rules,
+			},
+		},
+	}
+}
+
+// translateToolArgumentMatch translates a tool argument constraint into an Envoy ValueMatcher.
+// It returns nil for constraints that cannot be translated.
+func translateToolArgumentMatch(argument agenticv0alpha0.ToolArgumentMatch) *matcherv3.ValueMatcher {
+	switch argument.Type {
+	case agenticv0alpha0.ToolArgumentMatchExact:
+		return buildExactValueMatcher(argument.Value)
+	case agenticv0alpha0.ToolArgumentMatchPrefix:
+		return &matcherv3.ValueMatcher{
+			MatchPattern: &matcherv3.ValueMatcher_StringMatch{
+				StringMatch: &matcherv3.StringMatcher{MatchPattern: &matcherv3.StringMatcher_Prefix{Prefix: argument.Value}},
+			},
+		}
+	case agenticv0alpha0.ToolArgumentMatchOneOf:
+		if len(argument.Values) == 0 {
+			return nil
+		}
+		var valueMatchers []*matcherv3.ValueMatcher
+		for _, value := range argument.Values {
+			valueMatchers = append(valueMatchers, buildExactValueMatcher(value))
+		}
+		return buildOrValueMatcher(valueMatchers)
+	default:
+		klog.Errorf("Ignoring unsupported tool argument match type %q for argument %q", argument.Type, argument.Name)
+		return nil
+	}
+}
+
+// buildDotDotSegmentValueMatcher returns a value matcher matching the values containing a ".." path segment.
+func buildDotDotSegmentValueMatcher() *matcherv3.ValueMatcher {
+	return &matcherv3.ValueMatcher{
+		MatchPattern: &matcherv3.ValueMatcher_StringMatch{
+			StringMatch: &matcherv3.StringMatcher{MatchPattern: &matcherv3.StringMatcher_SafeRegex{SafeRegex: &matcherv3.RegexMatcher{Regex: dotDotSegmentRegex}}},
+		},
+	}
+}
+
+// translateToolNameMatch translates a tool name pattern into an Envoy StringMatcher.
+// It returns nil for patterns that cannot be translated, e.g. invalid regular expressions, since Envoy
+// would reject the whole configuration otherwise.
+func translateToolNameMatch(pattern agenticv0alpha0.ToolNameMatch) *matcherv3.StringMatcher {
+	switch pattern.Type {
+	case agenticv0alpha0.ToolNameMatchPrefix:
+		return &matcherv3.StringMatcher{MatchPattern: &matcherv3.StringMatcher_Prefix{Prefix: pattern.Value}}
+	case agenticv0alpha0.ToolNameMatchSuffix:
+		return &matcherv3.StringMatcher{MatchPattern: &matcherv3.StringMatcher_Suffix{Suffix: pattern.Value}}
+	case agenticv0alpha0.ToolNameMatchRegularExpression:
+		if err := validateEnvoyRegex(pattern.Value); err != nil {
+			klog.Errorf("Ignoring invalid tool name regular expression %q: %v", pattern.Value, err)
+			return nil
+		}
+		return &matcherv3.StringMatcher{MatchPattern: &matcherv3.StringMatcher_SafeRegex{SafeRegex: &matcherv3.RegexMatcher{Regex: pattern.Value}}}
+	default:
+		klog.Errorf("Ignoring unsupported tool name match type %q", pattern.Type)
+		return nil
+	}
+}
+
+// validateEnvoyRegex returns an error if Envoy would reject the given regular expression of a safe_regex matcher.
+// Go's regexp package implements the RE2 syntax used by Envoy, but does not limit the size of the compiled
+// program, so the size is checked against Envoy's default limit. Go's program size approximates the one of RE2.
+func validateEnvoyRegex(pattern string) error {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return err
+	}
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return err
+	}
+	if len(prog.Inst) > envoyRegexMaxProgramSize {
+		return fmt.Errorf("regular expression program size %d exceeds the maximum of %d", len(prog.Inst), envoyRegexMaxProgramSize)
+	}
+	return nil
+}
+
+// translateInlineResourcesToRBACPermission builds a permission that allows reading, subscribing to and completing
+// arguments of the MCP resources whose URI matches any of the given patterns.
+func translateInlineResourcesToRBACPermission(resources []agenticv0alpha0.ResourceURIMatch) *rbacconfigv3.Permission {
+	var uriValueMatchers []*matcherv3.ValueMatcher
+	for _, resource := range resources {
+		stringMatcher := translateResourceURIMatch(resource)
+		if stringMatcher == nil {
+			continue
+		}
+		uriValueMatchers = append(uriValueMatchers, &matcherv3.ValueMatcher{
+			MatchPattern: &matcherv3.ValueMatcher_StringMatch{StringMatch: stringMatcher},
+		})
+	}
+	if len(uriValueMatchers) == 0 {
+		return nil
+	}
+	urisMatcher := buildOrValueMatcher(uriValueMatchers)
+
+	return buildOrPermission(
+		buildAndPermission(
+			buildMCPMethodPermission(resourcesReadMethod, resourcesSubscribeMethod, resourcesUnsubscribeMethod),
+			buildMCPMetadataPermission(urisMatcher, "params", "uri"),
+		),
+		buildAndPermission(
+			buildMCPMethodPermission(completionCompleteMethod),
+			buildMCPMetadataPermission(buildExactValueMatcher(completionRefTypeResource), "params", "ref", "type"),
+			buildMCPMetadataPermission(urisMatcher, "params", "ref", "uri"),
+		),
+	)
+}
+
+// translateInlinePromptsToRBACPermission builds a permission that allows getting and completing arguments of
+// the MCP prompts with any of the given names.
+func translateInlinePromptsToRBACPermission(prompts []string) *rbacconfigv3.Permission {
+	if len(prompts) == 0 {
+		return nil
+	}
+
+	var nameValueMatchers []*matcherv3.ValueMatcher
+	for _, prompt := range prompts {
+		nameValueMatchers = append(nameValueMatchers, buildExactValueMatcher(prompt))
+	}
+	namesMatcher := buildOrValueMatcher(nameValueMatchers)
+
+	return buildOrPermission(
+		buildAndPermission(
+			buildMCPMethodPermission(promptsGetMethod),
+			buildMCPMetadataPermission(namesMatcher, "params", "name"),
+		),
+		buildAndPermission(
+			buildMCPMethodPermission(completionCompleteMethod),
+			buildMCPMetadataPermission(buildExactValueMatcher(completionRefTypePrompt), "params", "ref", "type"),
+			buildMCPMetadataPermission(namesMatcher, "params", "ref", "name"),
+		),
+	)
+}
+
+// translateResourceURIMatch translates a resource URI pattern into an Envoy StringMatcher.
+// It returns nil for patterns that cannot be translated, e.g. invalid regular expressions.
+func translateResourceURIMatch(pattern agenticv0alpha0.ResourceURIMatch) *matcherv3.StringMatcher {
+	switch pattern.Type {
+	case agenticv0alpha0.ResourceURIMatchExact:
+		return &matcherv3.StringMatcher{MatchPattern: &matcherv3.StringMatcher_Exact{Exact: pattern.Value}}
+	case agenticv0alpha0.ResourceURIMatchPrefix:
+		return &matcherv3.StringMatcher{MatchPattern: &matcherv3.StringMatcher_Prefix{Prefix: pattern.Value}}
+	case agenticv0alpha0.ResourceURIMatchRegularExpression:
+		if err := validateEnvoyRegex(pattern.Value); err != nil {
+			klog.Errorf("Ignoring invalid resource URI regular expression %q: %v", pattern.Value, err)
+			return nil
+		}
+		return &matcherv3.StringMatcher{MatchPattern: &matcherv3.StringMatcher_SafeRegex{SafeRegex: &matcherv3.RegexMatcher{Regex: pattern.Value}}}
+	default:
+		klog.Errorf("Ignoring unsupported resource URI match type %q", pattern.Type)
+		return nil
+	}
+}
+
+// buildMCPMethodPermission builds a permission matching MCP requests with any of the given JSON-RPC methods.
+func buildMCPMethodPermission(methods ...string) *rbacconfigv3.Permission {
+	var methodValueMatchers []*matcherv3.ValueMatcher
+	for _, method := range methods {
+		methodValueMatchers = append(methodValueMatchers, buildExactValueMatcher(method))
+	}
+	return buildMCPMetadataPermission(buildOrValueMatcher(methodValueMatchers), "method")
+}
+
+// buildMCPMetadataPermission builds a permission matching the value at the given path of the metadata
+// emitted by the MCP filter.
+func buildMCPMetadataPermission(value *matcherv3.ValueMatcher, path ...string) *rbacconfigv3.Permission {
+	var segments []*matcherv3.MetadataMatcher_PathSegment
+	for _, key := range path {
+		segments = append(segments, &matcherv3.MetadataMatcher_PathSegment{Segment: &matcherv3.MetadataMatcher_PathSegment_Key{Key: key}})
+	}
+	return &rbacconfigv3.Permission{
+		Rule: &rbacconfigv3.Permission_SourcedMetadata{
+			SourcedMetadata: &rbacconfigv3.SourcedMetadata{
+				MetadataMatcher: &matcherv3.MetadataMatcher{
+					Filter: mcpProxyFilterName,
+					Path:   segments,
+					Value:  value,
 				},
 			},
 		},
 	}
 }
 
+func buildExactValueMatcher(value string) *matcherv3.ValueMatcher {
+	return &matcherv3.ValueMatcher{MatchPattern: &matcherv3.ValueMatcher_StringMatch{StringMatch: &matcherv3.StringMatcher{MatchPattern: &matcherv3.StringMatcher_Exact{Exact: value}}}}
+}
+
+// buildOrValueMatcher returns a value matcher matching any of the given matchers.
+func buildOrValueMatcher(matchers []*matcherv3.ValueMatcher) *matcherv3.ValueMatcher {
+	if len(matchers) == 1 {
+		return matchers[0]
+	}
+	return &matcherv3.ValueMatcher{
+		MatchPattern: &matcherv3.ValueMatcher_OrMatch{OrMatch: &matcherv3.OrMatcher{ValueMatchers: matchers}},
+	}
+}
+
+func buildAndPermission(rules ...*rbacconfigv3.Permission) *rbacconfigv3.Permission {
+	return &rbacconfigv3.Permission{
+		Rule: &rbacconfigv3.Permission_AndRules{AndRules: &rbacconfigv3.Permission_Set{Rules: rules}},
+	}
+}
+
+func buildOrPermission(rules ...*rbacconfigv3.Permission) *rbacconfigv3.Permission {
+	return &rbacconfigv3.Permission{
+		Rule: &rbacconfigv3.Permission_OrRules{OrRules: &rbacconfigv3.Permission_Set{Rules: rules}},
+	}
+}
+
 // buildAllowMCPSessionClosePolicy creates the RBAC policy that allows agents to close MCP sessions.
 func buildAllowMCPSessionClosePolicy() *rbacconfigv3.Policy {
 	return &rbacconfigv3.Policy{
@@ -294,6 +1263,15 @@ func buildAnyPermission() *rbacconfigv3.Permission {
 	}
 }
 
+// buildNoPermission returns a permission that matches no request.
+func buildNoPermission() *rbacconfigv3.Permission {
+	return &rbacconfigv3.Permission{
+		Rule: &rbacconfigv3.Permission_NotRule{
+			NotRule: buildAnyPermission(),
+		},
+	}
+}
+
 func buildTooslCallMethodPermission() *rbacconfigv3.Permission {
 	return &rbacconfigv3.Permission{
 		Rule: &rbacconfigv3.Permission_SourcedMetadata{
@@ -316,6 +1294,15 @@ func buildAnyPrincipal() *rbacconfigv3.Principal {
 	}
 }
 
+// buildNoPrincipal returns a principal that matches no request.
+func buildNoPrincipal() *rbacconfigv3.Principal {
+	return &rbacconfigv3.Principal{
+		Identifier: &rbacconfigv3.Principal_NotId{
+			NotId: buildAnyPrincipal(),
+		},
+	}
+}
+
 // buildAllowAnyoneToInitializeAndListToolsPolicy creates the RBAC policy that allows anyone to
 // initialize a session and list available tools.
 func buildAllowAnyoneToInitializeAndListToolsPolicy() *rbacconfigv3.Policy {
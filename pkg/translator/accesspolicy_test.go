@@ -18,15 +18,24 @@ package translator
 
 import (
 	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
 	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	rbacconfigv3 "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
 	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	ext_authzv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
+	rbacv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/rbac/v3"
 	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
 
 	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 
@@ -43,11 +52,10 @@ type expectedRule struct {
 
 func TestTranslateAccessPolicyToRBAC(t *testing.T) {
 	tests := []struct {
-		name                string
-		accessPolicy        *agenticv0alpha0.XAccessPolicy
-		backend             *agenticv0alpha0.XBackend
-		expectedRules       map[string]expectedRule
-		expectedShadowRules map[string]expectedRule
+		name          string
+		accessPolicy  *agenticv0alpha0.XAccessPolicy
+		backend       *agenticv0alpha0.XBackend
+		expectedRules map[string]expectedRule
 	}{
 		{
 			name: "single rule with specific name",
@@ -60,7 +68,7 @@ func TestTranslateAccessPolicyToRBAC(t *testing.T) {
 					Rules: []agenticv0alpha0.AccessRule{
 						{
 							Name: "allow-all",
-							Source: agenticv0alpha0.Source{
+							Source: &agenticv0alpha0.Source{
 								Type: agenticv0alpha0.AuthorizationSourceTypeSPIFFE,
 								SPIFFE: func() *agenticv0alpha0.AuthorizationSourceSPIFFE {
 									s := agenticv0alpha0.AuthorizationSourceSPIFFE("spiffe://example.com/ns/default/sa/default")
@@ -78,7 +86,7 @@ func TestTranslateAccessPolicyToRBAC(t *testing.T) {
 				},
 			},
 			expectedRules: map[string]expectedRule{
-				"allow-all": {principals: []string{"spiffe://example.com/ns/default/sa/default"}},
+				"default/policy-1/allow-all": {principals: []string{"spiffe://example.com/ns/default/sa/default"}, permissions: []string{"!(any)"}},
 			},
 		},
 		{
@@ -92,7 +100,7 @@ func TestTranslateAccessPolicyToRBAC(t *testing.T) {
 					Rules: []agenticv0alpha0.AccessRule{
 						{
 							Name: "rule-1",
-							Source: agenticv0alpha0.Source{
+							Source: &agenticv0alpha0.Source{
 								Type: agenticv0alpha0.AuthorizationSourceTypeSPIFFE,
 								SPIFFE: func() *agenticv0alpha0.AuthorizationSourceSPIFFE {
 									s := agenticv0alpha0.AuthorizationSourceSPIFFE("spiffe://example.com/ns/default/sa/foo")
@@ -102,7 +110,7 @@ func TestTranslateAccessPolicyToRBAC(t *testing.T) {
 						},
 						{
 							Name: "rule-2",
-							Source: agenticv0alpha0.Source{
+							Source: &agenticv0alpha0.Source{
 								Type: agenticv0alpha0.AuthorizationSourceTypeSPIFFE,
 								SPIFFE: func() *agenticv0alpha0.AuthorizationSourceSPIFFE {
 									s := agenticv0alpha0.AuthorizationSourceSPIFFE("spiffe://example.com/ns/default/sa/bar")
@@ -120,8 +128,54 @@ func TestTranslateAccessPolicyToRBAC(t *testing.T) {
 				},
 			},
 			expectedRules: map[string]expectedRule{
-				"rule-1": {principals: []string{"spiffe://example.com/ns/default/sa/foo"}},
-				"rule-2": {principals: []string{"spiffe://example.com/ns/default/sa/bar"}},
+				"default/policy-2/rule-1": {principals: []string{"spiffe://example.com/ns/default/sa/foo"}, permissions: []string{"!(any)"}},
+				"default/policy-2/rule-2": {principals: []string{"spiffe://example.com/ns/default/sa/bar"}, permissions: []string{"!(any)"}},
+			},
+		},
+		{
+			name: "multiple sources",
+			accessPolicy: &agenticv0alpha0.XAccessPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "policy-sources",
+				},
+				Spec: agenticv0alpha0.AccessPolicySpec{
+					Rules: []agenticv0alpha0.AccessRule{
+						{
+							Name: "rule-1",
+							Sources: []agenticv0alpha0.Source{
+								{
+									Type: agenticv0alpha0.AuthorizationSourceTypeSPIFFE,
+									SPIFFE: func() *agenticv0alpha0.AuthorizationSourceSPIFFE {
+										s := agenticv0alpha0.AuthorizationSourceSPIFFE("spiffe://example.com/ns/default/sa/foo")
+										return &s
+									}(),
+								},
+								{
+									Type: agenticv0alpha0.AuthorizationSourceTypeServiceAccount,
+									ServiceAccount: &agenticv0alpha0.AuthorizationSourceServiceAccount{
+										Name: "bar",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			backend: &agenticv0alpha0.XBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "backend-1",
+				},
+			},
+			expectedRules: map[string]expectedRule{
+				"default/policy-sources/rule-1": {
+					principals: []string{
+						"spiffe://example.com/ns/default/sa/foo",
+						convertSAtoSPIFFEID(testTrustDomain, "default", "bar"),
+					},
+					permissions: []string{"!(any)"},
+				},
 			},
 		},
 		{
@@ -135,7 +189,7 @@ func TestTranslateAccessPolicyToRBAC(t *testing.T) {
 					Rules: []agenticv0alpha0.AccessRule{
 						{
 							Name: "allow-sa",
-							Source: agenticv0alpha0.Source{
+							Source: &agenticv0alpha0.Source{
 								Type: agenticv0alpha0.AuthorizationSourceTypeServiceAccount,
 								ServiceAccount: &agenticv0alpha0.AuthorizationSourceServiceAccount{
 									Name:      "my-sa",
@@ -148,7 +202,7 @@ func TestTranslateAccessPolicyToRBAC(t *testing.T) {
 			},
 			backend: &agenticv0alpha0.XBackend{},
 			expectedRules: map[string]expectedRule{
-				"allow-sa": {principals: []string{convertSAtoSPIFFEID(testTrustDomain, "my-ns", "my-sa")}},
+				"ns-1/policy-sa/allow-sa": {principals: []string{convertSAtoSPIFFEID(testTrustDomain, "my-ns", "my-sa")}, permissions: []string{"!(any)"}},
 			},
 		},
 		{
@@ -162,7 +216,7 @@ func TestTranslateAccessPolicyToRBAC(t *testing.T) {
 					Rules: []agenticv0alpha0.AccessRule{
 						{
 							Name: "allow-tools-a-and-b",
-							Source: agenticv0alpha0.Source{
+							Source: &agenticv0alpha0.Source{
 								Type: agenticv0alpha0.AuthorizationSourceTypeSPIFFE,
 								SPIFFE: func() *agenticv0alpha0.AuthorizationSourceSPIFFE {
 									s := agenticv0alpha0.AuthorizationSourceSPIFFE("spiffe://example.com/ns/default/sa/default")
@@ -184,12 +238,233 @@ func TestTranslateAccessPolicyToRBAC(t *testing.T) {
 				},
 			},
 			expectedRules: map[string]expectedRule{
-				"allow-tools-a-and-b": {
+				"default/policy-1/allow-tools-a-and-b": {
 					principals:  []string{"spiffe://example.com/ns/default/sa/default"},
 					permissions: []string{`(metadata["mcp_proxy"]["method"] == "tools/call" && metadata["mcp_proxy"]["params"]["name"] (== "tool-a" || == "tool-b"))`},
 				},
 			},
 		},
+		{
+			name: "inline tool patterns",
+			accessPolicy: &agenticv0alpha0.XAccessPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "policy-1",
+				},
+				Spec: agenticv0alpha0.AccessPolicySpec{
+					Rules: []agenticv0alpha0.AccessRule{
+						{
+							Name: "allow-github-tools",
+							Source: &agenticv0alpha0.Source{
+								Type: agenticv0alpha0.AuthorizationSourceTypeSPIFFE,
+								SPIFFE: func() *agenticv0alpha0.AuthorizationSourceSPIFFE {
+									s := agenticv0alpha0.AuthorizationSourceSPIFFE("spiffe://example.com/ns/default/sa/default")
+									return &s
+								}(),
+							},
+							Authorization: &agenticv0alpha0.AuthorizationRule{
+								Type:  agenticv0alpha0.AuthorizationRuleTypeInlineTools,
+								Tools: []string{"tool-a"},
+								ToolPatterns: []agenticv0alpha0.ToolNameMatch{
+									{Type: agenticv0alpha0.ToolNameMatchPrefix, Value: "github_"},
+									{Type: agenticv0alpha0.ToolNameMatchSuffix, Value: "_read"},
+									{Type: agenticv0alpha0.ToolNameMatchRegularExpression, Value: "(get|list)_[a-z]+"},
+									{Type: agenticv0alpha0.ToolNameMatchRegularExpression, Value: "(invalid"},
+								},
+							},
+						},
+					},
+				},
+			},
+			backend: &agenticv0alpha0.XBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "backend-1",
+				},
+			},
+			expectedRules: map[string]expectedRule{
+				"default/policy-1/allow-github-tools": {
+					principals:  []string{"spiffe://example.com/ns/default/sa/default"},
+					permissions: []string{`(metadata["mcp_proxy"]["method"] == "tools/call" && metadata["mcp_proxy"]["params"]["name"] (== "tool-a" || startsWith("github_") || endsWith("_read") || matches("(get|list)_[a-z]+")))`},
+				},
+			},
+		},
+		{
+			name: "only invalid tool patterns",
+			accessPolicy: &agenticv0alpha0.XAccessPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "policy-1",
+				},
+				Spec: agenticv0alpha0.AccessPolicySpec{
+					Rules: []agenticv0alpha0.AccessRule{
+						{
+							Name: "allow-invalid-patterns",
+							Source: &agenticv0alpha0.Source{
+								Type:   agenticv0alpha0.AuthorizationSourceTypeSPIFFE,
+								SPIFFE: ptr.To(agenticv0alpha0.AuthorizationSourceSPIFFE("spiffe://example.com/ns/default/sa/default")),
+							},
+							Authorization: &agenticv0alpha0.AuthorizationRule{
+								Type: agenticv0alpha0.AuthorizationRuleTypeInlineTools,
+								ToolPatterns: []agenticv0alpha0.ToolNameMatch{
+									{Type: agenticv0alpha0.ToolNameMatchRegularExpression, Value: "(invalid"},
+									// Valid RE2 syntax, but exceeds the program size accepted by Envoy.
+									{Type: agenticv0alpha0.ToolNameMatchRegularExpression, Value: "(abc|def){20}"},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedRules: map[string]expectedRule{
+				"default/policy-1/allow-invalid-patterns": {
+					principals:  []string{"spiffe://example.com/ns/default/sa/default"},
+					permissions: []string{"!(any)"},
+				},
+			},
+		},
+		{
+			name: "untranslatable argument constraint",
+			accessPolicy: &agenticv0alpha0.XAccessPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "policy-1",
+				},
+				Spec: agenticv0alpha0.AccessPolicySpec{
+					Rules: []agenticv0alpha0.AccessRule{
+						{
+							Name: "allow-no-values",
+							Source: &agenticv0alpha0.Source{
+								Type:   agenticv0alpha0.AuthorizationSourceTypeSPIFFE,
+								SPIFFE: ptr.To(agenticv0alpha0.AuthorizationSourceSPIFFE("spiffe://example.com/ns/default/sa/default")),
+							},
+							Authorization: &agenticv0alpha0.AuthorizationRule{
+								Type:  agenticv0alpha0.AuthorizationRuleTypeInlineTools,
+								Tools: []string{"read_file"},
+								Arguments: []agenticv0alpha0.ToolArgumentMatch{
+									{Name: "path", Type: agenticv0alpha0.ToolArgumentMatchOneOf},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedRules: map[string]expectedRule{
+				"default/policy-1/allow-no-values": {
+					principals:  []string{"spiffe://example.com/ns/default/sa/default"},
+					permissions: []string{"!(any)"},
+				},
+			},
+		},
+		{
+			name: "inline tools with argument constraints",
+			accessPolicy: &agenticv0alpha0.XAccessPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "policy-1",
+				},
+				Spec: agenticv0alpha0.AccessPolicySpec{
+					Rules: []agenticv0alpha0.AccessRule{
+						{
+							Name: "allow-workspace-reads",
+							Source: &agenticv0alpha0.Source{
+								Type: agenticv0alpha0.AuthorizationSourceTypeSPIFFE,
+								SPIFFE: func() *agenticv0alpha0.AuthorizationSourceSPIFFE {
+									s := agenticv0alpha0.AuthorizationSourceSPIFFE("spiffe://example.com/ns/default/sa/default")
+									return &s
+								}(),
+							},
+							Authorization: &agenticv0alpha0.AuthorizationRule{
+								Type:  agenticv0alpha0.AuthorizationRuleTypeInlineTools,
+								Tools: []string{"read_file"},
+								Arguments: []agenticv0alpha0.ToolArgumentMatch{
+									{Name: "path", Type: agenticv0alpha0.ToolArgumentMatchPrefix, Value: "/workspace/"},
+									{Name: "encoding", Type: agenticv0alpha0.ToolArgumentMatchOneOf, Values: []string{"utf-8", "base64"}},
+									{Name: "repo", Type: agenticv0alpha0.ToolArgumentMatchExact, Value: "kube-agentic-networking"},
+								},
+							},
+						},
+					},
+				},
+			},
+			backend: &agenticv0alpha0.XBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "backend-1",
+				},
+			},
+			expectedRules: map[string]expectedRule{
+				"default/policy-1/allow-workspace-reads": {
+					principals: []string{"spiffe://example.com/ns/default/sa/default"},
+					permissions: []string{`(metadata["mcp_proxy"]["method"] == "tools/call" && metadata["mcp_proxy"]["params"]["name"] == "read_file" && ` +
+						`metadata["mcp_proxy"]["params"]["arguments"]["path"] startsWith("/workspace/") && ` +
+						`!(metadata["mcp_proxy"]["params"]["arguments"]["path"] matches("(^|[/\\\\])\\.\\.([/\\\\]|$)")) && ` +
+						`metadata["mcp_proxy"]["params"]["arguments"]["encoding"] (== "utf-8" || == "base64") && ` +
+						`metadata["mcp_proxy"]["params"]["arguments"]["repo"] == "kube-agentic-networking")`},
+				},
+			},
+		},
+		{
+			name: "inline resources and prompts rules",
+			accessPolicy: &agenticv0alpha0.XAccessPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "policy-1",
+				},
+				Spec: agenticv0alpha0.AccessPolicySpec{
+					Rules: []agenticv0alpha0.AccessRule{
+						{
+							Name: "allow-workspace-resources",
+							Source: &agenticv0alpha0.Source{
+								Type: agenticv0alpha0.AuthorizationSourceTypeSPIFFE,
+								SPIFFE: func() *agenticv0alpha0.AuthorizationSourceSPIFFE {
+									s := agenticv0alpha0.AuthorizationSourceSPIFFE("spiffe://example.com/ns/default/sa/default")
+									return &s
+								}(),
+							},
+							Authorization: &agenticv0alpha0.AuthorizationRule{
+								Type: agenticv0alpha0.AuthorizationRuleTypeInlineResources,
+								Resources: []agenticv0alpha0.ResourceURIMatch{
+									{Type: agenticv0alpha0.ResourceURIMatchPrefix, Value: "file:///workspace/"},
+								},
+							},
+						},
+						{
+							Name: "allow-summarize-prompt",
+							Source: &agenticv0alpha0.Source{
+								Type: agenticv0alpha0.AuthorizationSourceTypeSPIFFE,
+								SPIFFE: func() *agenticv0alpha0.AuthorizationSourceSPIFFE {
+									s := agenticv0alpha0.AuthorizationSourceSPIFFE("spiffe://example.com/ns/default/sa/default")
+									return &s
+								}(),
+							},
+							Authorization: &agenticv0alpha0.AuthorizationRule{
+								Type:    agenticv0alpha0.AuthorizationRuleTypeInlinePrompts,
+								Prompts: []string{"summarize"},
+							},
+						},
+					},
+				},
+			},
+			backend: &agenticv0alpha0.XBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "backend-1",
+				},
+			},
+			expectedRules: map[string]expectedRule{
+				"default/policy-1/allow-workspace-resources": {
+					principals: []string{"spiffe://example.com/ns/default/sa/default"},
+					permissions: []string{`((metadata["mcp_proxy"]["method"] (== "resources/read" || == "resources/subscribe" || == "resources/unsubscribe") && metadata["mcp_proxy"]["params"]["uri"] startsWith("file:///workspace/")) || ` +
+						`(metadata["mcp_proxy"]["method"] == "completion/complete" && metadata["mcp_proxy"]["params"]["ref"]["type"] == "ref/resource" && metadata["mcp_proxy"]["params"]["ref"]["uri"] startsWith("file:///workspace/")))`},
+				},
+				"default/policy-1/allow-summarize-prompt": {
+					principals: []string{"spiffe://example.com/ns/default/sa/default"},
+					permissions: []string{`((metadata["mcp_proxy"]["method"] == "prompts/get" && metadata["mcp_proxy"]["params"]["name"] == "summarize") || ` +
+						`(metadata["mcp_proxy"]["method"] == "completion/complete" && metadata["mcp_proxy"]["params"]["ref"]["type"] == "ref/prompt" && metadata["mcp_proxy"]["params"]["ref"]["name"] == "summarize"))`},
+				},
+			},
+		},
 		{
 			name: "ext_authz rule",
 			accessPolicy: &agenticv0alpha0.XAccessPolicy{
@@ -201,7 +476,7 @@ func TestTranslateAccessPolicyToRBAC(t *testing.T) {
 					Rules: []agenticv0alpha0.AccessRule{
 						{
 							Name: "ext-authz-rule",
-							Source: agenticv0alpha0.Source{
+							Source: &agenticv0alpha0.Source{
 								Type: agenticv0alpha0.AuthorizationSourceTypeSPIFFE,
 								SPIFFE: func() *agenticv0alpha0.AuthorizationSourceSPIFFE {
 									s := agenticv0alpha0.AuthorizationSourceSPIFFE("spiffe://example.com/ns/default/sa/default")
@@ -228,100 +503,1021 @@ func TestTranslateAccessPolicyToRBAC(t *testing.T) {
 				},
 			},
 			expectedRules: map[string]expectedRule{
-				"ext-authz-rule": {
+				"default/policy-1/ext-authz-rule": {
 					principals:  []string{"spiffe://example.com/ns/default/sa/default"},
 					permissions: []string{`metadata["mcp_proxy"]["method"] == "tools/call"`},
 				},
 			},
-			expectedShadowRules: map[string]expectedRule{
-				"ext-authz-rule": {
+		},
+		{
+			name: "deny rules are not part of the allow rules",
+			accessPolicy: &agenticv0alpha0.XAccessPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "policy-1",
+				},
+				Spec: agenticv0alpha0.AccessPolicySpec{
+					Rules: []agenticv0alpha0.AccessRule{
+						{
+							Name: "allow-tool-a",
+							Source: &agenticv0alpha0.Source{
+								Type: agenticv0alpha0.AuthorizationSourceTypeSPIFFE,
+								SPIFFE: func() *agenticv0alpha0.AuthorizationSourceSPIFFE {
+									s := agenticv0alpha0.AuthorizationSourceSPIFFE("spiffe://example.com/ns/default/sa/default")
+									return &s
+								}(),
+							},
+							Authorization: &agenticv0alpha0.AuthorizationRule{
+								Type:  agenticv0alpha0.AuthorizationRuleTypeInlineTools,
+								Tools: []string{"tool-a"},
+							},
+						},
+						{
+							Name:   "deny-tool-b",
+							Action: agenticv0alpha0.AccessRuleActionDeny,
+							Source: &agenticv0alpha0.Source{
+								Type: agenticv0alpha0.AuthorizationSourceTypeSPIFFE,
+								SPIFFE: func() *agenticv0alpha0.AuthorizationSourceSPIFFE {
+									s := agenticv0alpha0.AuthorizationSourceSPIFFE("spiffe://example.com/ns/default/sa/default")
+									return &s
+								}(),
+							},
+							Authorization: &agenticv0alpha0.AuthorizationRule{
+								Type:  agenticv0alpha0.AuthorizationRuleTypeInlineTools,
+								Tools: []string{"tool-b"},
+							},
+						},
+					},
+				},
+			},
+			backend: &agenticv0alpha0.XBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "backend-1",
+				},
+			},
+			expectedRules: map[string]expectedRule{
+				"default/policy-1/allow-tool-a": {
 					principals:  []string{"spiffe://example.com/ns/default/sa/default"},
-					permissions: []string{`metadata["mcp_proxy"]["method"] == "tools/call"`},
+					permissions: []string{`(metadata["mcp_proxy"]["method"] == "tools/call" && metadata["mcp_proxy"]["params"]["name"] == "tool-a")`},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := &Translator{agenticIdentityTrustDomain: testTrustDomain}
+			rbacConfig := tr.translatesAccessPolicyToRBAC(tc.accessPolicy)
+			verifyRBACConfigContainsRule(t, rbacConfig.GetRules(), tc.expectedRules)
+			if rbacConfig.GetShadowRules() != nil {
+				t.Errorf("expected no shadow rules, got %v", rbacConfig.GetShadowRules())
+			}
+		})
+	}
+}
+
+func TestTranslateAccessPolicyToDenyRBAC(t *testing.T) {
+	tests := []struct {
+		name          string
+		accessPolicy  *agenticv0alpha0.XAccessPolicy
+		expectNil     bool
+		expectedRules map[string]expectedRule
+	}{
+		{
+			name:         "no deny rules",
+			accessPolicy: newTestAccessPolicy("default", "policy-1", "backend-1", "spiffe://example.com/ns/default/sa/default"),
+			expectNil:    true,
+		},
+		{
+			name: "deny specific tools for a service account",
+			accessPolicy: &agenticv0alpha0.XAccessPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "team-x",
+					Name:      "policy-1",
 				},
+				Spec: agenticv0alpha0.AccessPolicySpec{
+					Rules: []agenticv0alpha0.AccessRule{
+						{
+							Name:   "deny-delete-repo",
+							Action: agenticv0alpha0.AccessRuleActionDeny,
+							Source: &agenticv0alpha0.Source{
+								Type: agenticv0alpha0.AuthorizationSourceTypeServiceAccount,
+								ServiceAccount: &agenticv0alpha0.AuthorizationSourceServiceAccount{
+									Name: "agent",
+								},
+							},
+							Authorization: &agenticv0alpha0.AuthorizationRule{
+								Type:  agenticv0alpha0.AuthorizationRuleTypeInlineTools,
+								Tools: []string{"delete_repo"},
+							},
+						},
+					},
+				},
+			},
+			expectedRules: map[string]expectedRule{
+				"team-x/policy-1/deny-delete-repo": {
+					principals:  []string{convertSAtoSPIFFEID(testTrustDomain, "team-x", "agent")},
+					permissions: []string{`(metadata["mcp_proxy"]["method"] == "tools/call" && metadata["mcp_proxy"]["params"]["name"] == "delete_repo")`},
+				},
+			},
+		},
+		{
+			name: "deny without authorization denies all tool calls",
+			accessPolicy: &agenticv0alpha0.XAccessPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "policy-1",
+				},
+				Spec: agenticv0alpha0.AccessPolicySpec{
+					Rules: []agenticv0alpha0.AccessRule{
+						{
+							Name:   "deny-all-tools",
+							Action: agenticv0alpha0.AccessRuleActionDeny,
+							Source: &agenticv0alpha0.Source{
+								Type: agenticv0alpha0.AuthorizationSourceTypeSPIFFE,
+								SPIFFE: func() *agenticv0alpha0.AuthorizationSourceSPIFFE {
+									s := agenticv0alpha0.AuthorizationSourceSPIFFE("spiffe://example.com/ns/default/sa/untrusted")
+									return &s
+								}(),
+							},
+						},
+					},
+				},
+			},
+			expectedRules: map[string]expectedRule{
+				"default/policy-1/deny-all-tools": {
+					principals:  []string{"spiffe://example.com/ns/default/sa/untrusted"},
+					permissions: []string{`metadata["mcp_proxy"]["method"] == "tools/call"`},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := &Translator{agenticIdentityTrustDomain: testTrustDomain}
+			rbacConfig := tr.translateAccessPolicyToDenyRBAC(tc.accessPolicy)
+			if tc.expectNil {
+				if rbacConfig != nil {
+					t.Fatalf("expected no deny RBAC config, got %v", rbacConfig)
+				}
+				return
+			}
+			if rbacConfig.GetRules().GetAction() != rbacconfigv3.RBAC_DENY {
+				t.Errorf("expected DENY action, got %v", rbacConfig.GetRules().GetAction())
+			}
+			verifyRBACConfigContainsRule(t, rbacConfig.GetRules(), tc.expectedRules)
+		})
+	}
+}
+
+func TestValidateEnvoyRegex(t *testing.T) {
+	tests := []struct {
+		pattern string
+		wantErr bool
+	}{
+		{pattern: "(get|list)_[a-z]+"},
+		{pattern: "(invalid", wantErr: true},
+		{pattern: "(abc|def){20}", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.pattern, func(t *testing.T) {
+			if err := validateEnvoyRegex(tc.pattern); (err != nil) != tc.wantErr {
+				t.Errorf("validateEnvoyRegex(%q) = %v, want error: %t", tc.pattern, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestDotDotSegmentRegex(t *testing.T) {
+	// RE2 and Go regular expressions agree on this syntax, so the Go engine stands in for Envoy here.
+	if err := validateEnvoyRegex(dotDotSegmentRegex); err != nil {
+		t.Fatalf("validateEnvoyRegex(%q) = %v, want nil", dotDotSegmentRegex, err)
+	}
+	re := regexp.MustCompile(dotDotSegmentRegex)
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{value: "/workspace/../etc/passwd", want: true},
+		{value: "/workspace/..", want: true},
+		{value: "../workspace", want: true},
+		{value: "..", want: true},
+		{value: `/workspace\..\etc`, want: true},
+		{value: "/workspace/notes.txt"},
+		{value: "/workspace/..hidden"},
+		{value: "/workspace/a..b/c"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.value, func(t *testing.T) {
+			if got := re.MatchString(tc.value); got != tc.want {
+				t.Errorf("MatchString(%q) = %t, want %t", tc.value, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestConvertSAtoSPIFFEID(t *testing.T) {
+	tests := []struct {
+		trustDomain string
+		namespace   string
+		name        string
+		want        string
+	}{
+		{
+			trustDomain: "cluster.local",
+			namespace:   "ns-1",
+			name:        "sa-1",
+			want:        "spiffe://cluster.local/ns/ns-1/sa/sa-1",
+		},
+		{
+			trustDomain: "example.com",
+			namespace:   "default",
+			name:        "builder",
+			want:        "spiffe://example.com/ns/default/sa/builder",
+		},
+	}
+
+	for _, tt := range tests {
+		got := convertSAtoSPIFFEID(tt.trustDomain, tt.namespace, tt.name)
+		if got != tt.want {
+			t.Errorf("convertSAtoSPIFFEID(%q, %q, %q) = %q, want %q", tt.trustDomain, tt.namespace, tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestRbacConfigFromAccessPolicy_DeletionBehaviour tests that when no XAccessPolicy
+// targets a backend (e.g. after the policy is deleted), the translator returns RBAC with
+// no rules so that Envoy does not enforce RBAC (allow all). See Envoy RBAC docs: when
+// rules are absent, no RBAC enforcement occurs.
+func TestRbacConfigFromAccessPolicy_DeletionBehaviour(t *testing.T) {
+	// Use a cache.Indexer to back the lister (simulates informer cache).
+	// Empty indexer = no AccessPolicies = "after deletion" behavior.
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+	lister := agenticlisters.NewXAccessPolicyLister(indexer)
+
+	backend := &agenticv0alpha0.XBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "my-backend",
+		},
+	}
+
+	tr := &Translator{}
+	rbacConfig, err := tr.rbacConfigFromAccessPolicy(lister, backend)
+	if err != nil {
+		t.Fatalf("rbacConfigFromAccessPolicy: %v", err)
+	}
+
+	if rbacConfig.GetRules() != nil {
+		t.Errorf("expected no RBAC rules when no XAccessPolicy targets backend (deletion behavior); rules present with %d policies", len(rbacConfig.GetRules().GetPolicies()))
+	}
+}
+
+// TestRbacConfigFromAccessPolicy_PolicyExists_NoAllowAll tests that when an XAccessPolicy
+// targets the backend, the config does not include the allow-all policy.
+func TestRbacConfigFromAccessPolicy_PolicyExists_NoAllowAll(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+
+	policy := &agenticv0alpha0.XAccessPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "policy-1",
+		},
+		Spec: agenticv0alpha0.AccessPolicySpec{
+			TargetRefs: []gwapiv1.LocalPolicyTargetReferenceWithSectionName{
+				{
+					LocalPolicyTargetReference: gwapiv1.LocalPolicyTargetReference{
+						Group: agenticv0alpha0.GroupName,
+						Kind:  "XBackend",
+						Name:  gwapiv1.ObjectName("my-backend"),
+					},
+				},
+			},
+			Rules: []agenticv0alpha0.AccessRule{
+				{
+					Name: "restrict-tools",
+					Source: &agenticv0alpha0.Source{
+						Type: agenticv0alpha0.AuthorizationSourceTypeSPIFFE,
+						SPIFFE: func() *agenticv0alpha0.AuthorizationSourceSPIFFE {
+							s := agenticv0alpha0.AuthorizationSourceSPIFFE("spiffe://example.com/ns/default/sa/default")
+							return &s
+						}(),
+					},
+				},
+			},
+		},
+	}
+	if err := indexer.Add(policy); err != nil {
+		t.Fatalf("indexer.Add: %v", err)
+	}
+
+	lister := agenticlisters.NewXAccessPolicyLister(indexer)
+	backend := &agenticv0alpha0.XBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "my-backend",
+		},
+	}
+
+	tr := &Translator{}
+	rbacConfig, err := tr.rbacConfigFromAccessPolicy(lister, backend)
+	if err != nil {
+		t.Fatalf("rbacConfigFromAccessPolicy: %v", err)
+	}
+
+	policies := rbacConfig.GetRules().GetPolicies()
+	if _, hasAllowAll := policies["allow-all"]; hasAllowAll {
+		var keys []string
+		for k := range policies {
+			keys = append(keys, k)
+		}
+		t.Errorf("expected no allow-all policy when XAccessPolicy targets backend; policies: %v", keys)
+	}
+	if _, ok := policies["default/policy-1/restrict-tools"]; !ok {
+		var keys []string
+		for k := range policies {
+			keys = append(keys, k)
+		}
+		t.Errorf("expected policy-derived rule %q; policies: %v", "default/policy-1/restrict-tools", keys)
+	}
+}
+
+// TestRbacConfigFromAccessPolicy_MergesPolicies tests that the rules of all XAccessPolicies
+// targeting the same backend are merged into a single RBAC config under name-qualified keys.
+func TestRbacConfigFromAccessPolicy_MergesPolicies(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+
+	platformPolicy := newTestAccessPolicy("default", "platform", "my-backend", "spiffe://example.com/ns/default/sa/platform")
+	platformPolicy.Spec.Rules[0].Name = "allow"
+	teamPolicy := newTestAccessPolicy("default", "team", "my-backend", "spiffe://example.com/ns/default/sa/team")
+	teamPolicy.Spec.Rules[0].Name = "allow"
+	otherPolicy := newTestAccessPolicy("default", "other", "other-backend", "spiffe://example.com/ns/default/sa/other")
+	for _, policy := range []*agenticv0alpha0.XAccessPolicy{platformPolicy, teamPolicy, otherPolicy} {
+		if err := indexer.Add(policy); err != nil {
+			t.Fatalf("indexer.Add: %v", err)
+		}
+	}
+
+	lister := agenticlisters.NewXAccessPolicyLister(indexer)
+	backend := &agenticv0alpha0.XBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "my-backend",
+		},
+	}
+
+	tr := &Translator{}
+	rbacConfig, err := tr.rbacConfigFromAccessPolicy(lister, backend)
+	if err != nil {
+		t.Fatalf("rbacConfigFromAccessPolicy: %v", err)
+	}
+
+	policies := rbacConfig.GetRules().GetPolicies()
+	for _, key := range []string{"default/platform/allow", "default/team/allow"} {
+		if _, ok := policies[key]; !ok {
+			t.Errorf("expected merged policy %q", key)
+		}
+	}
+	if _, ok := policies["default/other/rule"]; ok {
+		t.Errorf("unexpected policy from an XAccessPolicy targeting another backend")
+	}
+}
+
+// TestRbacConfigFromAccessPolicy_DenyOnly tests that an XAccessPolicy with only Deny rules targeting a backend
+// does not restrict the requests its Deny rules do not match, like one targeting a Gateway.
+func TestRbacConfigFromAccessPolicy_DenyOnly(t *testing.T) {
+	denyOnly := newTestAccessPolicy("default", "deny-only", "my-backend", "spiffe://example.com/ns/default/sa/b")
+	denyOnly.Spec.Rules[0].Action = agenticv0alpha0.AccessRuleActionDeny
+	denyOnly.Spec.Rules[0].Authorization = &agenticv0alpha0.AuthorizationRule{
+		Type:  agenticv0alpha0.AuthorizationRuleTypeInlineTools,
+		Tools: []string{"delete_repo"},
+	}
+	allow := newTestAccessPolicy("default", "allow", "my-backend", "spiffe://example.com/ns/default/sa/a")
+	backend := &agenticv0alpha0.XBackend{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-backend"}}
+
+	tests := []struct {
+		name         string
+		policies     []*agenticv0alpha0.XAccessPolicy
+		wantPolicies []string
+	}{
+		{
+			name:     "only deny rules",
+			policies: []*agenticv0alpha0.XAccessPolicy{denyOnly},
+		},
+		{
+			name:         "deny rules along with another policy's allow rules",
+			policies:     []*agenticv0alpha0.XAccessPolicy{denyOnly, allow},
+			wantPolicies: []string{allowAnyoneToInitializeAndListToolsPolicyName, allowHTTPGet, allowMCPSessionClosePolicyName, "default/allow/rule"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			for _, policy := range tc.policies {
+				if err := indexer.Add(policy); err != nil {
+					t.Fatalf("indexer.Add: %v", err)
+				}
+			}
+			lister := agenticlisters.NewXAccessPolicyLister(indexer)
+			tr := &Translator{}
+
+			rbacConfig, err := tr.rbacConfigFromAccessPolicy(lister, backend)
+			if err != nil {
+				t.Fatalf("rbacConfigFromAccessPolicy: %v", err)
+			}
+			var got []string
+			for name := range rbacConfig.GetRules().GetPolicies() {
+				got = append(got, name)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tc.wantPolicies) {
+				t.Errorf("expected allow policies %v, got %v", tc.wantPolicies, got)
+			}
+
+			denyConfig, err := tr.denyRBACConfigFromAccessPolicy(lister, backend)
+			if err != nil {
+				t.Fatalf("denyRBACConfigFromAccessPolicy: %v", err)
+			}
+			if _, ok := denyConfig.GetRules().GetPolicies()["default/deny-only/rule"]; !ok {
+				t.Errorf("expected deny policy %q", "default/deny-only/rule")
+			}
+		})
+	}
+}
+
+func TestAccessPoliciesForBackend_Order(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+
+	older := metav1.NewTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	newer := metav1.NewTime(older.Add(time.Hour))
+
+	policyC := newTestAccessPolicy("default", "c", "my-backend", "spiffe://example.com/ns/default/sa/c")
+	policyC.CreationTimestamp = older
+	policyA := newTestAccessPolicy("default", "a", "my-backend", "spiffe://example.com/ns/default/sa/a")
+	policyA.CreationTimestamp = newer
+	policyB := newTestAccessPolicy("default", "b", "my-backend", "spiffe://example.com/ns/default/sa/b")
+	policyB.CreationTimestamp = newer
+	for _, policy := range []*agenticv0alpha0.XAccessPolicy{policyA, policyB, policyC} {
+		if err := indexer.Add(policy); err != nil {
+			t.Fatalf("indexer.Add: %v", err)
+		}
+	}
+
+	backend := &agenticv0alpha0.XBackend{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-backend"}}
+	policies, err := AccessPoliciesForBackend(backend, agenticlisters.NewXAccessPolicyLister(indexer))
+	if err != nil {
+		t.Fatalf("AccessPoliciesForBackend: %v", err)
+	}
+
+	var got []string
+	for _, policy := range policies {
+		got = append(got, policy.Name)
+	}
+	if want := []string{"c", "a", "b"}; !slices.Equal(got, want) {
+		t.Errorf("expected policies in order %v, got %v", want, got)
+	}
+}
+
+func TestAccessPoliciesForGateway(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+
+	older := metav1.NewTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	newer := metav1.NewTime(older.Add(time.Hour))
+
+	wholeGateway := newTestGatewayAccessPolicy("default", "whole-gateway", "my-gateway", "", "spiffe://example.com/ns/default/sa/a")
+	wholeGateway.CreationTimestamp = newer
+	httpsListener := newTestGatewayAccessPolicy("default", "https-listener", "my-gateway", "https", "spiffe://example.com/ns/default/sa/b")
+	httpsListener.CreationTimestamp = older
+	otherGateway := newTestGatewayAccessPolicy("default", "other-gateway", "other-gateway", "", "spiffe://example.com/ns/default/sa/c")
+	backendPolicy := newTestAccessPolicy("default", "backend", "my-gateway", "spiffe://example.com/ns/default/sa/d")
+	otherNamespace := newTestGatewayAccessPolicy("other", "other-namespace", "my-gateway", "", "spiffe://example.com/ns/other/sa/e")
+	for _, policy := range []*agenticv0alpha0.XAccessPolicy{wholeGateway, httpsListener, otherGateway, backendPolicy, otherNamespace} {
+		if err := indexer.Add(policy); err != nil {
+			t.Fatalf("indexer.Add: %v", err)
+		}
+	}
+	lister := agenticlisters.NewXAccessPolicyLister(indexer)
+	gateway := &gwapiv1.Gateway{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-gateway"}}
+
+	tests := []struct {
+		name         string
+		sectionNames []gwapiv1.SectionName
+		expected     []string
+	}{
+		{
+			name:     "no section names",
+			expected: []string{"https-listener", "whole-gateway"},
+		},
+		{
+			name:         "targeted listener",
+			sectionNames: []gwapiv1.SectionName{"https"},
+			expected:     []string{"https-listener", "whole-gateway"},
+		},
+		{
+			name:         "other listener",
+			sectionNames: []gwapiv1.SectionName{"http"},
+			expected:     []string{"whole-gateway"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			policies, err := AccessPoliciesForGateway(gateway, lister, tc.sectionNames...)
+			if err != nil {
+				t.Fatalf("AccessPoliciesForGateway: %v", err)
+			}
+			var got []string
+			for _, policy := range policies {
+				got = append(got, policy.Name)
+			}
+			if !slices.Equal(got, tc.expected) {
+				t.Errorf("expected policies %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestBuildGatewayRBACFilters(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+
+	older := metav1.NewTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	newer := metav1.NewTime(older.Add(time.Hour))
+
+	mixed := newTestGatewayAccessPolicy("default", "mixed", "my-gateway", "", "spiffe://example.com/ns/default/sa/a")
+	mixed.CreationTimestamp = older
+	mixed.Spec.Rules = append(mixed.Spec.Rules, agenticv0alpha0.AccessRule{
+		Name:   "deny",
+		Action: agenticv0alpha0.AccessRuleActionDeny,
+		Source: mixed.Spec.Rules[0].Source,
+	})
+	denyOnly := newTestGatewayAccessPolicy("default", "deny-only", "my-gateway", "", "spiffe://example.com/ns/default/sa/b")
+	denyOnly.CreationTimestamp = newer
+	denyOnly.Spec.Rules[0].Action = agenticv0alpha0.AccessRuleActionDeny
+	for _, policy := range []*agenticv0alpha0.XAccessPolicy{mixed, denyOnly} {
+		if err := indexer.Add(policy); err != nil {
+			t.Fatalf("indexer.Add: %v", err)
+		}
+	}
+
+	tr := &Translator{accessPolicyLister: agenticlisters.NewXAccessPolicyLister(indexer)}
+	gateway := &gwapiv1.Gateway{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-gateway"}}
+	filters, err := tr.buildGatewayRBACFilters(gateway, "http")
+	if err != nil {
+		t.Fatalf("buildGatewayRBACFilters: %v", err)
+	}
+
+	var got []string
+	for _, filter := range filters {
+		got = append(got, filter.GetName())
+	}
+	expected := []string{
+		gatewayRBACFilterNamePrefix + ".default.mixed.deny",
+		gatewayRBACFilterNamePrefix + ".default.mixed",
+		gatewayRBACFilterNamePrefix + ".default.deny-only.deny",
+	}
+	if !slices.Equal(got, expected) {
+		t.Errorf("expected filters %v, got %v", expected, got)
+	}
+
+	if filters, err := (&Translator{}).buildGatewayRBACFilters(gateway); err != nil || filters != nil {
+		t.Errorf("expected no filters without an access policy lister, got %v (err: %v)", filters, err)
+	}
+}
+
+func TestBuildGatewayVirtualHostRBACConfig(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+	wholeGateway := newTestGatewayAccessPolicy("default", "whole-gateway", "my-gateway", "", "spiffe://example.com/ns/default/sa/a")
+	listenerA := newTestGatewayAccessPolicy("default", "listener-a", "my-gateway", "a", "spiffe://example.com/ns/default/sa/b")
+	listenerA.Spec.Rules = append(listenerA.Spec.Rules, agenticv0alpha0.AccessRule{
+		Name:   "deny",
+		Action: agenticv0alpha0.AccessRuleActionDeny,
+		Source: listenerA.Spec.Rules[0].Source,
+	})
+	for _, policy := range []*agenticv0alpha0.XAccessPolicy{wholeGateway, listenerA} {
+		if err := indexer.Add(policy); err != nil {
+			t.Fatalf("indexer.Add: %v", err)
+		}
+	}
+	tr := &Translator{accessPolicyLister: agenticlisters.NewXAccessPolicyLister(indexer)}
+	gateway := &gwapiv1.Gateway{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-gateway"}}
+	filters, err := tr.buildGatewayRBACFilters(gateway, "a", "b")
+	if err != nil {
+		t.Fatalf("buildGatewayRBACFilters: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		sectionNames []gwapiv1.SectionName
+		wantDisabled []string
+	}{
+		{
+			name:         "virtual host of the targeted listener",
+			sectionNames: []gwapiv1.SectionName{"a"},
+		},
+		{
+			name:         "virtual host of another listener",
+			sectionNames: []gwapiv1.SectionName{"b"},
+			wantDisabled: []string{gatewayRBACFilterNamePrefix + ".default.listener-a", gatewayRBACFilterNamePrefix + ".default.listener-a.deny"},
+		},
+		{
+			name:         "virtual host shared by both listeners",
+			sectionNames: []gwapiv1.SectionName{"a", "b"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			perFilterConfig, err := tr.buildGatewayVirtualHostRBACConfig(gateway, filters, tc.sectionNames)
+			if err != nil {
+				t.Fatalf("buildGatewayVirtualHostRBACConfig: %v", err)
+			}
+			var got []string
+			for name, config := range perFilterConfig {
+				rbacPerRoute := &rbacv3.RBACPerRoute{}
+				if err := config.UnmarshalTo(rbacPerRoute); err != nil {
+					t.Fatalf("failed to unmarshal RBACPerRoute: %v", err)
+				}
+				if rbacPerRoute.GetRbac() != nil {
+					t.Errorf("expected filter %s to be disabled, got %v", name, rbacPerRoute.GetRbac())
+				}
+				got = append(got, name)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tc.wantDisabled) {
+				t.Errorf("expected disabled filters %v, got %v", tc.wantDisabled, got)
+			}
+		})
+	}
+}
+
+// TestExternalAuthzTriggerRBACConfigs tests that each ExternalAuth configuration gets its own trigger RBAC config,
+// whose shadow rules are the rules in force using it.
+func TestExternalAuthzTriggerRBACConfigs(t *testing.T) {
+	extAuthRule := func(name, sa, backendName string) agenticv0alpha0.AccessRule {
+		return agenticv0alpha0.AccessRule{
+			Name: name,
+			Source: &agenticv0alpha0.Source{
+				Type:   agenticv0alpha0.AuthorizationSourceTypeSPIFFE,
+				SPIFFE: ptr.To(agenticv0alpha0.AuthorizationSourceSPIFFE("spiffe://example.com/ns/default/sa/" + sa)),
+			},
+			Authorization: &agenticv0alpha0.AuthorizationRule{
+				Type: agenticv0alpha0.AuthorizationRuleTypeExternalAuth,
+				ExternalAuth: &gwapiv1.HTTPExternalAuthFilter{
+					ExternalAuthProtocol: gwapiv1.HTTPRouteExternalAuthGRPCProtocol,
+					BackendRef:           gwapiv1.BackendObjectReference{Name: gwapiv1.ObjectName(backendName)},
+				},
+			},
+		}
+	}
+	expired := extAuthRule("expired", "c", "authz-3")
+	expired.NotAfter = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+	deny := extAuthRule("deny", "d", "authz-4")
+	deny.Action = agenticv0alpha0.AccessRuleActionDeny
+	policy := &agenticv0alpha0.XAccessPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "policy"},
+		Spec: agenticv0alpha0.AccessPolicySpec{
+			Rules: []agenticv0alpha0.AccessRule{
+				extAuthRule("authz-1-a", "a", "authz-1"),
+				extAuthRule("authz-1-b", "b", "authz-1"),
+				extAuthRule("authz-2", "a", "authz-2"),
+				expired,
+				deny,
 			},
 		},
 	}
+	hash := func(rule agenticv0alpha0.AccessRule) string {
+		h, err := externalAuthUniqueID(rule.Authorization)
+		if err != nil {
+			t.Fatalf("externalAuthUniqueID: %v", err)
+		}
+		return h
+	}
+	hash1, hash2 := hash(policy.Spec.Rules[0]), hash(policy.Spec.Rules[2])
 
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			tr := &Translator{agenticIdentityTrustDomain: testTrustDomain}
-			rbacConfig := tr.translatesAccessPolicyToRBAC(tc.accessPolicy)
-			verifyRBACConfigContainsRule(t, rbacConfig.GetRules(), tc.expectedRules)
-			verifyRBACConfigContainsRule(t, rbacConfig.GetShadowRules(), tc.expectedShadowRules)
-		})
+	tr := &Translator{agenticIdentityTrustDomain: testTrustDomain}
+	configs := tr.externalAuthzTriggerRBACConfigs([]*agenticv0alpha0.XAccessPolicy{policy})
+	if len(configs) != 2 {
+		t.Fatalf("expected 2 trigger RBAC configs, got %d", len(configs))
+	}
+	toolsCall := []string{`metadata["mcp_proxy"]["method"] == "tools/call"`}
+	for h, want := range map[string]map[string]expectedRule{
+		hash1: {
+			"default/policy/authz-1-a": {principals: []string{"spiffe://example.com/ns/default/sa/a"}, permissions: toolsCall},
+			"default/policy/authz-1-b": {principals: []string{"spiffe://example.com/ns/default/sa/b"}, permissions: toolsCall},
+		},
+		hash2: {
+			"default/policy/authz-2": {principals: []string{"spiffe://example.com/ns/default/sa/a"}, permissions: toolsCall},
+		},
+	} {
+		config, ok := configs[h]
+		if !ok {
+			t.Fatalf("expected a trigger RBAC config for %s", h)
+		}
+		if config.GetRules() != nil {
+			t.Errorf("expected no enforced rules in the trigger RBAC config for %s", h)
+		}
+		if want, got := fmt.Sprintf("%s_%s_", externalAuthzShadowRulePrefix, h), config.GetShadowRulesStatPrefix(); got != want {
+			t.Errorf("expected shadow rules stat prefix %q, got %q", want, got)
+		}
+		verifyRBACConfigContainsRule(t, config.GetShadowRules(), want)
 	}
 }
 
-func TestConvertSAtoSPIFFEID(t *testing.T) {
-	tests := []struct {
-		trustDomain string
-		namespace   string
-		name        string
-		want        string
-	}{
-		{
-			trustDomain: "cluster.local",
-			namespace:   "ns-1",
-			name:        "sa-1",
-			want:        "spiffe://cluster.local/ns/ns-1/sa/sa-1",
+// TestBuildPerClusterExtAuthzFilterConfig tests that a cluster gets the trigger RBAC configs and the ext_authz
+// configs of the ExternalAuth rules of the enforced AccessPolicies targeting its XBackend and the Gateway listener.
+func TestBuildPerClusterExtAuthzFilterConfig(t *testing.T) {
+	audited := withTestExternalAuth(newTestAccessPolicy("default", "audited", "my-backend", "spiffe://example.com/ns/default/sa/c"), "authz-3")
+	audited.Spec.Mode = agenticv0alpha0.AccessPolicyModeAudit
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, policy := range []*agenticv0alpha0.XAccessPolicy{
+		withTestExternalAuth(newTestAccessPolicy("default", "backend", "my-backend", "spiffe://example.com/ns/default/sa/a"), "authz-1"),
+		withTestExternalAuth(newTestAccessPolicy("default", "shared", "my-backend", "spiffe://example.com/ns/default/sa/d"), "authz-2"),
+		withTestExternalAuth(newTestAccessPolicy("default", "other-backend", "other-backend", "spiffe://example.com/ns/default/sa/a"), "authz-4"),
+		audited,
+	} {
+		if err := indexer.Add(policy); err != nil {
+			t.Fatalf("indexer.Add: %v", err)
+		}
+	}
+	gatewayPolicy := withTestExternalAuth(newTestGatewayAccessPolicy("default", "gateway", "my-gateway", "", "spiffe://example.com/ns/default/sa/b"), "authz-2")
+	tr := &Translator{agenticIdentityTrustDomain: testTrustDomain, accessPolicyLister: agenticlisters.NewXAccessPolicyLister(indexer)}
+	backend := &agenticv0alpha0.XBackend{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-backend"}}
+
+	hash := func(serviceName string) string {
+		hash, err := externalAuthUniqueID(withTestExternalAuth(newTestAccessPolicy("default", "policy", "my-backend", ""), serviceName).Spec.Rules[0].Authorization)
+		if err != nil {
+			t.Fatalf("externalAuthUniqueID: %v", err)
+		}
+		return hash
+	}
+	hash1, hash2 := hash("authz-1"), hash("authz-2")
+
+	perFilterConfig, err := tr.buildPerClusterExtAuthzFilterConfig(backend, []*agenticv0alpha0.XAccessPolicy{gatewayPolicy})
+	if err != nil {
+		t.Fatalf("buildPerClusterExtAuthzFilterConfig: %v", err)
+	}
+	var names []string
+	for name := range perFilterConfig {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	expected := []string{
+		externalAuthzRBACFilterName(hash1), externalAuthzFilterName(hash1),
+		externalAuthzRBACFilterName(hash2), externalAuthzFilterName(hash2),
+	}
+	slices.Sort(expected)
+	if !slices.Equal(names, expected) {
+		t.Fatalf("expected filter configs %v, got %v", expected, names)
+	}
+	perRoute := &rbacv3.RBACPerRoute{}
+	if err := perFilterConfig[externalAuthzRBACFilterName(hash2)].UnmarshalTo(perRoute); err != nil {
+		t.Fatalf("failed to unmarshal RBACPerRoute: %v", err)
+	}
+	toolsCall := []string{`metadata["mcp_proxy"]["method"] == "tools/call"`}
+	verifyRBACConfigContainsRule(t, perRoute.GetRbac().GetShadowRules(), map[string]expectedRule{
+		"default/shared/rule":  {principals: []string{"spiffe://example.com/ns/default/sa/d"}, permissions: toolsCall},
+		"default/gateway/rule": {principals: []string{"spiffe://example.com/ns/default/sa/b"}, permissions: toolsCall},
+	})
+
+	// The AccessPolicy and the rule are only sent to an external authorizer checking a single rule of the cluster.
+	for h, want := range map[string]map[string]string{
+		hash1: {
+			extAuthzContextExtensionXBackend:     "default/my-backend",
+			extAuthzContextExtensionAccessPolicy: "default/backend",
+			extAuthzContextExtensionAccessRule:   "rule",
 		},
-		{
-			trustDomain: "example.com",
-			namespace:   "default",
-			name:        "builder",
-			want:        "spiffe://example.com/ns/default/sa/builder",
+		hash2: {
+			extAuthzContextExtensionXBackend: "default/my-backend",
 		},
+	} {
+		extAuthzPerRoute := &ext_authzv3.ExtAuthzPerRoute{}
+		if err := perFilterConfig[externalAuthzFilterName(h)].UnmarshalTo(extAuthzPerRoute); err != nil {
+			t.Fatalf("failed to unmarshal ExtAuthzPerRoute: %v", err)
+		}
+		if got := extAuthzPerRoute.GetCheckSettings().GetContextExtensions(); !maps.Equal(got, want) {
+			t.Errorf("expected context extensions %v for %s, got %v", want, h, got)
+		}
 	}
 
-	for _, tt := range tests {
-		got := convertSAtoSPIFFEID(tt.trustDomain, tt.namespace, tt.name)
-		if got != tt.want {
-			t.Errorf("convertSAtoSPIFFEID(%q, %q, %q) = %q, want %q", tt.trustDomain, tt.namespace, tt.name, got, tt.want)
-		}
+	// A cluster without an XBackend only gets the configs of the Gateway listener.
+	perFilterConfig, err = tr.buildPerClusterExtAuthzFilterConfig(nil, nil)
+	if err != nil || perFilterConfig != nil {
+		t.Errorf("expected no filter configs, got %v, %v", perFilterConfig, err)
 	}
 }
 
-// TestRbacConfigFromAccessPolicy_DeletionBehaviour tests that when no XAccessPolicy
-// targets a backend (e.g. after the policy is deleted), the translator returns RBAC with
-// no rules so that Envoy does not enforce RBAC (allow all). See Envoy RBAC docs: when
-// rules are absent, no RBAC enforcement occurs.
-func TestRbacConfigFromAccessPolicy_DeletionBehaviour(t *testing.T) {
-	// Use a cache.Indexer to back the lister (simulates informer cache).
-	// Empty indexer = no AccessPolicies = "after deletion" behavior.
-	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
-		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+// TestRbacConfigFromAccessPolicy_MultipleExternalAuth tests that the ExternalAuth rules of all the AccessPolicies
+// targeting a backend are allowed by the RBAC filter, whatever their external authorizers, along with their Deny rules.
+func TestRbacConfigFromAccessPolicy_MultipleExternalAuth(t *testing.T) {
+	older := withTestExternalAuth(newTestAccessPolicy("default", "older", "my-backend", "spiffe://example.com/ns/default/sa/a"), "authz-1")
+	older.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	newer := withTestExternalAuth(newTestAccessPolicy("default", "newer", "my-backend", "spiffe://example.com/ns/default/sa/b"), "authz-2")
+	newer.CreationTimestamp = metav1.NewTime(time.Now())
+	newer.Spec.Rules = append(newer.Spec.Rules, agenticv0alpha0.AccessRule{
+		Name:   "deny-delete",
+		Action: agenticv0alpha0.AccessRuleActionDeny,
+		Source: &agenticv0alpha0.Source{
+			Type:   agenticv0alpha0.AuthorizationSourceTypeSPIFFE,
+			SPIFFE: ptr.To(agenticv0alpha0.AuthorizationSourceSPIFFE("spiffe://example.com/ns/default/sa/c")),
+		},
+		Authorization: &agenticv0alpha0.AuthorizationRule{
+			Type:  agenticv0alpha0.AuthorizationRuleTypeInlineTools,
+			Tools: []string{"delete_repo"},
+		},
 	})
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, policy := range []*agenticv0alpha0.XAccessPolicy{older, newer} {
+		if err := indexer.Add(policy); err != nil {
+			t.Fatalf("indexer.Add: %v", err)
+		}
+	}
+	backend := &agenticv0alpha0.XBackend{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-backend"}}
+	tr := &Translator{agenticIdentityTrustDomain: testTrustDomain}
 	lister := agenticlisters.NewXAccessPolicyLister(indexer)
 
-	backend := &agenticv0alpha0.XBackend{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: "default",
-			Name:      "my-backend",
-		},
+	denyConfig, err := tr.denyRBACConfigFromAccessPolicy(lister, backend)
+	if err != nil {
+		t.Fatalf("denyRBACConfigFromAccessPolicy: %v", err)
 	}
+	verifyRBACConfigContainsRule(t, denyConfig.GetRules(), map[string]expectedRule{
+		"default/newer/deny-delete": {
+			principals:  []string{"spiffe://example.com/ns/default/sa/c"},
+			permissions: []string{`(metadata["mcp_proxy"]["method"] == "tools/call" && metadata["mcp_proxy"]["params"]["name"] == "delete_repo")`},
+		},
+	})
 
-	tr := &Translator{}
-	rbacConfig, err := tr.rbacConfigFromAccessPolicy(lister, backend)
+	allowConfig, err := tr.rbacConfigFromAccessPolicy(lister, backend)
 	if err != nil {
 		t.Fatalf("rbacConfigFromAccessPolicy: %v", err)
 	}
+	for _, name := range []string{"default/older/rule", "default/newer/rule"} {
+		if _, ok := allowConfig.GetRules().GetPolicies()[name]; !ok {
+			t.Errorf("expected ExternalAuth rule %s in the allow rules", name)
+		}
+	}
+	if allowConfig.GetShadowRules() != nil || allowConfig.GetShadowRulesStatPrefix() != "" {
+		t.Errorf("expected no shadow rules in the allow RBAC config, got %v", allowConfig.GetShadowRules())
+	}
+}
 
-	if rbacConfig.GetRules() != nil {
-		t.Errorf("expected no RBAC rules when no XAccessPolicy targets backend (deletion behavior); rules present with %d policies", len(rbacConfig.GetRules().GetPolicies()))
+// TestRbacConfigFromAccessPolicy_DefaultAllowances tests that the implicit policies are toggled by the
+// DefaultAllowances of the XAccessPolicies, and restricted to the sources of their Allow rules when disabled.
+func TestRbacConfigFromAccessPolicy_DefaultAllowances(t *testing.T) {
+	tests := []struct {
+		name              string
+		defaultAllowances []*agenticv0alpha0.DefaultAllowances
+		denyOnly          bool
+		// invalidCELPolicy is the name of the policy whose rule has an invalid CEL expression, if any.
+		invalidCELPolicy string
+		// expected maps the name of each expected implicit policy to the principals it is restricted to,
+		// or nil if it applies to any principal.
+		expected map[string][]string
+	}{
+		{
+			name:              "default allowances unset",
+			defaultAllowances: []*agenticv0alpha0.DefaultAllowances{nil},
+			expected: map[string][]string{
+				allowMCPSessionClosePolicyName:                nil,
+				allowAnyoneToInitializeAndListToolsPolicyName: nil,
+				allowHTTPGet: nil,
+			},
+		},
+		{
+			name: "initialize and list tools disabled",
+			defaultAllowances: []*agenticv0alpha0.DefaultAllowances{
+				{InitializeAndListTools: ptr.To(false), CloseSession: ptr.To(true)},
+			},
+			expected: map[string][]string{
+				allowMCPSessionClosePolicyName:                nil,
+				allowAnyoneToInitializeAndListToolsPolicyName: {"spiffe://example.com/ns/default/sa/agent-0"},
+				allowHTTPGet: nil,
+			},
+		},
+		{
+			name: "disabled by one of the merged policies",
+			defaultAllowances: []*agenticv0alpha0.DefaultAllowances{
+				nil,
+				{HTTPGet: ptr.To(false)},
+			},
+			expected: map[string][]string{
+				allowMCPSessionClosePolicyName:                nil,
+				allowAnyoneToInitializeAndListToolsPolicyName: nil,
+				allowHTTPGet: {"spiffe://example.com/ns/default/sa/agent-0", "spiffe://example.com/ns/default/sa/agent-1"},
+			},
+		},
+		{
+			name: "Allow rule with an invalid CEL expression",
+			defaultAllowances: []*agenticv0alpha0.DefaultAllowances{
+				nil,
+				{HTTPGet: ptr.To(false)},
+			},
+			invalidCELPolicy: "policy-1",
+			expected: map[string][]string{
+				allowMCPSessionClosePolicyName:                nil,
+				allowAnyoneToInitializeAndListToolsPolicyName: nil,
+				allowHTTPGet: {"spiffe://example.com/ns/default/sa/agent-0"},
+			},
+		},
+		{
+			name: "all disabled without Allow rules",
+			defaultAllowances: []*agenticv0alpha0.DefaultAllowances{
+				{InitializeAndListTools: ptr.To(false), CloseSession: ptr.To(false), HTTPGet: ptr.To(false)},
+			},
+			denyOnly: true,
+			expected: map[string][]string{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+				cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+			})
+			for i, defaultAllowances := range tc.defaultAllowances {
+				policy := newTestAccessPolicy("default", fmt.Sprintf("policy-%d", i), "my-backend", fmt.Sprintf("spiffe://example.com/ns/default/sa/agent-%d", i))
+				policy.Spec.DefaultAllowances = defaultAllowances
+				if tc.denyOnly {
+					policy.Spec.Rules[0].Action = agenticv0alpha0.AccessRuleActionDeny
+				}
+				if policy.Name == tc.invalidCELPolicy {
+					policy.Spec.Rules[0].Authorization = &agenticv0alpha0.AuthorizationRule{
+						Type: agenticv0alpha0.AuthorizationRuleTypeCEL,
+						CEL:  "identity.sub ==",
+					}
+				}
+				if err := indexer.Add(policy); err != nil {
+					t.Fatalf("indexer.Add: %v", err)
+				}
+			}
+			backend := &agenticv0alpha0.XBackend{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-backend"},
+			}
+
+			tr := &Translator{}
+			rbacConfig, err := tr.rbacConfigFromAccessPolicy(agenticlisters.NewXAccessPolicyLister(indexer), backend)
+			if err != nil {
+				t.Fatalf("rbacConfigFromAccessPolicy: %v", err)
+			}
+
+			policies := rbacConfig.GetRules().GetPolicies()
+			for _, name := range []string{allowMCPSessionClosePolicyName, allowAnyoneToInitializeAndListToolsPolicyName, allowHTTPGet} {
+				expectedPrincipals, expected := tc.expected[name]
+				policy, found := policies[name]
+				if found != expected {
+					t.Errorf("expected policy %q to be present: %t, got %t", name, expected, found)
+					continue
+				}
+				if !found {
+					continue
+				}
+				for _, principal := range policy.GetPrincipals() {
+					if got := restrictedPrincipalNames(principal); !reflect.DeepEqual(got, expectedPrincipals) {
+						t.Errorf("expected policy %q to be restricted to %v, got %v", name, expectedPrincipals, got)
+					}
+				}
+			}
+		})
 	}
 }
 
-// TestRbacConfigFromAccessPolicy_PolicyExists_NoAllowAll tests that when an XAccessPolicy
-// targets the backend, the config does not include the allow-all policy.
-func TestRbacConfigFromAccessPolicy_PolicyExists_NoAllowAll(t *testing.T) {
-	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
-		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
-	})
+// restrictedPrincipalNames returns the names of the authenticated principals a principal was restricted to
+// by restrictPolicyToPrincipals, or nil if it was not restricted.
+func restrictedPrincipalNames(principal *rbacconfigv3.Principal) []string {
+	ids := principal.GetAndIds().GetIds()
+	if len(ids) != 2 || ids[1].GetOrIds() == nil {
+		return nil
+	}
+	var names []string
+	for _, id := range ids[1].GetOrIds().GetIds() {
+		names = append(names, id.GetAuthenticated().GetPrincipalName().GetExact())
+	}
+	return names
+}
 
-	policy := &agenticv0alpha0.XAccessPolicy{
+// newTestAccessPolicy returns an XAccessPolicy with a single rule named "rule" that targets the
+// given XBackend and matches the given SPIFFE ID.
+func newTestAccessPolicy(namespace, name, backendName, spiffeID string) *agenticv0alpha0.XAccessPolicy {
+	source := agenticv0alpha0.AuthorizationSourceSPIFFE(spiffeID)
+	return &agenticv0alpha0.XAccessPolicy{
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace: "default",
-			Name:      "policy-1",
+			Namespace: namespace,
+			Name:      name,
 		},
 		Spec: agenticv0alpha0.AccessPolicySpec{
 			TargetRefs: []gwapiv1.LocalPolicyTargetReferenceWithSectionName{
@@ -329,57 +1525,34 @@ func TestRbacConfigFromAccessPolicy_PolicyExists_NoAllowAll(t *testing.T) {
 					LocalPolicyTargetReference: gwapiv1.LocalPolicyTargetReference{
 						Group: agenticv0alpha0.GroupName,
 						Kind:  "XBackend",
-						Name:  gwapiv1.ObjectName("my-backend"),
+						Name:  gwapiv1.ObjectName(backendName),
 					},
 				},
 			},
 			Rules: []agenticv0alpha0.AccessRule{
 				{
-					Name: "restrict-tools",
-					Source: agenticv0alpha0.Source{
-						Type: agenticv0alpha0.AuthorizationSourceTypeSPIFFE,
-						SPIFFE: func() *agenticv0alpha0.AuthorizationSourceSPIFFE {
-							s := agenticv0alpha0.AuthorizationSourceSPIFFE("spiffe://example.com/ns/default/sa/default")
-							return &s
-						}(),
+					Name: "rule",
+					Source: &agenticv0alpha0.Source{
+						Type:   agenticv0alpha0.AuthorizationSourceTypeSPIFFE,
+						SPIFFE: &source,
 					},
 				},
 			},
 		},
 	}
-	if err := indexer.Add(policy); err != nil {
-		t.Fatalf("indexer.Add: %v", err)
-	}
+}
 
-	lister := agenticlisters.NewXAccessPolicyLister(indexer)
-	backend := &agenticv0alpha0.XBackend{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: "default",
-			Name:      "my-backend",
+// withTestExternalAuth makes the first rule of the AccessPolicy check tool calls with the gRPC external authorizer
+// of the given Service.
+func withTestExternalAuth(policy *agenticv0alpha0.XAccessPolicy, serviceName string) *agenticv0alpha0.XAccessPolicy {
+	policy.Spec.Rules[0].Authorization = &agenticv0alpha0.AuthorizationRule{
+		Type: agenticv0alpha0.AuthorizationRuleTypeExternalAuth,
+		ExternalAuth: &gwapiv1.HTTPExternalAuthFilter{
+			ExternalAuthProtocol: gwapiv1.HTTPRouteExternalAuthGRPCProtocol,
+			BackendRef:           gwapiv1.BackendObjectReference{Name: gwapiv1.ObjectName(serviceName)},
 		},
 	}
-
-	tr := &Translator{}
-	rbacConfig, err := tr.rbacConfigFromAccessPolicy(lister, backend)
-	if err != nil {
-		t.Fatalf("rbacConfigFromAccessPolicy: %v", err)
-	}
-
-	policies := rbacConfig.GetRules().GetPolicies()
-	if _, hasAllowAll := policies["allow-all"]; hasAllowAll {
-		var keys []string
-		for k := range policies {
-			keys = append(keys, k)
-		}
-		t.Errorf("expected no allow-all policy when XAccessPolicy targets backend; policies: %v", keys)
-	}
-	if _, ok := policies["restrict-tools"]; !ok {
-		var keys []string
-		for k := range policies {
-			keys = append(keys, k)
-		}
-		t.Errorf("expected policy-derived rule %q; policies: %v", "restrict-tools", keys)
-	}
+	return policy
 }
 
 func verifyRBACConfigContainsRule(t *testing.T, rules *rbacconfigv3.RBAC, expectedRules map[string]expectedRule) {
@@ -522,6 +1695,8 @@ func stringMatcherToExpr(sm *matcherv3.StringMatcher) string {
 		return fmt.Sprintf("startsWith(%q)", strPattern.Prefix)
 	case *matcherv3.StringMatcher_Suffix:
 		return fmt.Sprintf("endsWith(%q)", strPattern.Suffix)
+	case *matcherv3.StringMatcher_SafeRegex:
+		return fmt.Sprintf("matches(%q)", strPattern.SafeRegex.GetRegex())
 	default:
 		return "string_match"
 	}
@@ -551,3 +1726,238 @@ func valueMatcherToExpr(vm *matcherv3.ValueMatcher) string {
 		return "unknown_value_matcher"
 	}
 }
+
+// newTestGatewayAccessPolicy returns an XAccessPolicy with a single rule named "rule" that targets the
+// given Gateway, or one of its listeners if sectionName is set, and matches the given SPIFFE ID.
+func newTestGatewayAccessPolicy(namespace, name, gatewayName, sectionName, spiffeID string) *agenticv0alpha0.XAccessPolicy {
+	policy := newTestAccessPolicy(namespace, name, gatewayName, spiffeID)
+	policy.Spec.TargetRefs[0].Group = gwapiv1.GroupName
+	policy.Spec.TargetRefs[0].Kind = "Gateway"
+	if sectionName != "" {
+		policy.Spec.TargetRefs[0].SectionName = ptr.To(gwapiv1.SectionName(sectionName))
+	}
+	return policy
+}
+
+func TestAccessPolicy_AuditMode(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+
+	audited := newTestAccessPolicy("default", "audited", "my-backend", "spiffe://example.com/ns/default/sa/a")
+	audited.Spec.Mode = agenticv0alpha0.AccessPolicyModeAudit
+	audited.Spec.Rules = append(audited.Spec.Rules, agenticv0alpha0.AccessRule{
+		Name:   "deny",
+		Action: agenticv0alpha0.AccessRuleActionDeny,
+		Source: audited.Spec.Rules[0].Source,
+	})
+	if err := indexer.Add(audited); err != nil {
+		t.Fatalf("indexer.Add: %v", err)
+	}
+	lister := agenticlisters.NewXAccessPolicyLister(indexer)
+	backend := &agenticv0alpha0.XBackend{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-backend"}}
+	tr := &Translator{agenticIdentityTrustDomain: testTrustDomain}
+
+	t.Run("allow rules are shadow rules", func(t *testing.T) {
+		rbacConfig := tr.translatesAccessPolicyToRBAC(audited)
+		if rbacConfig.GetRules() != nil {
+			t.Errorf("expected no enforced rules, got %v", rbacConfig.GetRules())
+		}
+		if rbacConfig.GetShadowRules().GetAction() != rbacconfigv3.RBAC_ALLOW {
+			t.Errorf("expected ALLOW shadow rules, got %v", rbacConfig.GetShadowRules().GetAction())
+		}
+		if _, ok := rbacConfig.GetShadowRules().GetPolicies()["default/audited/rule"]; !ok {
+			t.Errorf("expected shadow policy %q", "default/audited/rule")
+		}
+		if rbacConfig.GetShadowRulesStatPrefix() != auditShadowRulePrefix+"_" {
+			t.Errorf("unexpected shadow rules stat prefix %q", rbacConfig.GetShadowRulesStatPrefix())
+		}
+	})
+
+	t.Run("deny rules are shadow rules", func(t *testing.T) {
+		rbacConfig := tr.translateAccessPolicyToDenyRBAC(audited)
+		if rbacConfig.GetRules() != nil {
+			t.Errorf("expected no enforced rules, got %v", rbacConfig.GetRules())
+		}
+		if rbacConfig.GetShadowRules().GetAction() != rbacconfigv3.RBAC_DENY {
+			t.Errorf("expected DENY shadow rules, got %v", rbacConfig.GetShadowRules().GetAction())
+		}
+		if rbacConfig.GetShadowRulesStatPrefix() != auditShadowRulePrefix+"_deny_" {
+			t.Errorf("unexpected shadow rules stat prefix %q", rbacConfig.GetShadowRulesStatPrefix())
+		}
+	})
+
+	t.Run("not enforced on the backend", func(t *testing.T) {
+		rbacConfig, err := tr.rbacConfigFromAccessPolicy(lister, backend)
+		if err != nil {
+			t.Fatalf("rbacConfigFromAccessPolicy: %v", err)
+		}
+		if rbacConfig.GetRules() != nil || rbacConfig.GetShadowRules() != nil {
+			t.Errorf("expected an empty RBAC config, got %v", rbacConfig)
+		}
+	})
+
+	t.Run("audited on the backend with the implicit policies", func(t *testing.T) {
+		rbacConfig, err := tr.auditRBACConfigFromAccessPolicy(lister, backend)
+		if err != nil {
+			t.Fatalf("auditRBACConfigFromAccessPolicy: %v", err)
+		}
+		policies := rbacConfig.GetShadowRules().GetPolicies()
+		if _, ok := policies["default/audited/rule"]; !ok {
+			t.Errorf("expected shadow policy %q", "default/audited/rule")
+		}
+		if len(policies) < 2 {
+			t.Errorf("expected the implicit policies among the shadow policies, got %d policies", len(policies))
+		}
+	})
+
+	t.Run("no audit config without audited policies", func(t *testing.T) {
+		emptyLister := agenticlisters.NewXAccessPolicyLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+			cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+		}))
+		rbacConfig, err := tr.auditRBACConfigFromAccessPolicy(emptyLister, backend)
+		if err != nil || rbacConfig != nil {
+			t.Errorf("expected no audit RBAC config, got %v (err: %v)", rbacConfig, err)
+		}
+	})
+}
+
+func TestBuildGatewayRBACFilters_AuditMode(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+
+	older := metav1.NewTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	newer := metav1.NewTime(older.Add(time.Hour))
+
+	enforced := newTestGatewayAccessPolicy("default", "enforced", "my-gateway", "", "spiffe://example.com/ns/default/sa/a")
+	enforced.CreationTimestamp = older
+	audited := newTestGatewayAccessPolicy("default", "audited", "my-gateway", "", "spiffe://example.com/ns/default/sa/b")
+	audited.CreationTimestamp = newer
+	audited.Spec.Mode = agenticv0alpha0.AccessPolicyModeAudit
+	for _, policy := range []*agenticv0alpha0.XAccessPolicy{enforced, audited} {
+		if err := indexer.Add(policy); err != nil {
+			t.Fatalf("indexer.Add: %v", err)
+		}
+	}
+
+	tr := &Translator{accessPolicyLister: agenticlisters.NewXAccessPolicyLister(indexer)}
+	gateway := &gwapiv1.Gateway{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-gateway"}}
+	filters, err := tr.buildGatewayRBACFilters(gateway)
+	if err != nil {
+		t.Fatalf("buildGatewayRBACFilters: %v", err)
+	}
+
+	var got []string
+	for _, filter := range filters {
+		got = append(got, filter.GetName())
+	}
+	expected := []string{
+		gatewayRBACFilterNamePrefix + ".default.audited",
+		gatewayRBACFilterNamePrefix + ".default.enforced",
+	}
+	if !slices.Equal(got, expected) {
+		t.Errorf("expected filters %v, got %v", expected, got)
+	}
+}
+
+func TestBuildRulePrincipals_NamespaceSources(t *testing.T) {
+	namespaceIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, ns := range []*corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{"team": "agents"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"team": "agents"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "ops", Labels: map[string]string{"team": "ops"}}},
+	} {
+		if err := namespaceIndexer.Add(ns); err != nil {
+			t.Fatalf("failed to add namespace: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		source agenticv0alpha0.Source
+		want   []string
+	}{
+		{
+			name: "exact SPIFFE ID",
+			source: agenticv0alpha0.Source{
+				Type:   agenticv0alpha0.AuthorizationSourceTypeSPIFFE,
+				SPIFFE: ptr.To(agenticv0alpha0.AuthorizationSourceSPIFFE("spiffe://example.org/ns/team-a/sa/agent")),
+			},
+			want: []string{`== "spiffe://example.org/ns/team-a/sa/agent"`},
+		},
+		{
+			name: "SPIFFE ID wildcard",
+			source: agenticv0alpha0.Source{
+				Type:   agenticv0alpha0.AuthorizationSourceTypeSPIFFE,
+				SPIFFE: ptr.To(agenticv0alpha0.AuthorizationSourceSPIFFE("spiffe://example.org/ns/team-a/*")),
+			},
+			want: []string{`startsWith("spiffe://example.org/ns/team-a/")`},
+		},
+		{
+			name: "trust domain wildcard",
+			source: agenticv0alpha0.Source{
+				Type:   agenticv0alpha0.AuthorizationSourceTypeSPIFFE,
+				SPIFFE: ptr.To(agenticv0alpha0.AuthorizationSourceSPIFFE("spiffe://example.org/*")),
+			},
+			want: []string{`startsWith("spiffe://example.org/")`},
+		},
+		{
+			name: "namespace",
+			source: agenticv0alpha0.Source{
+				Type:      agenticv0alpha0.AuthorizationSourceTypeNamespace,
+				Namespace: ptr.To(gwapiv1.Namespace("team-a")),
+			},
+			want: []string{`startsWith("spiffe://cluster.local/ns/team-a/sa/")`},
+		},
+		{
+			name: "namespace selector",
+			source: agenticv0alpha0.Source{
+				Type:              agenticv0alpha0.AuthorizationSourceTypeNamespaceSelector,
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "agents"}},
+			},
+			want: []string{`startsWith("spiffe://cluster.local/ns/team-a/sa/")`, `startsWith("spiffe://cluster.local/ns/team-b/sa/")`},
+		},
+		{
+			name: "namespace selector matching no namespace",
+			source: agenticv0alpha0.Source{
+				Type:              agenticv0alpha0.AuthorizationSourceTypeNamespaceSelector,
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "unknown"}},
+			},
+			want: []string{"none"},
+		},
+		{
+			name: "invalid namespace selector",
+			source: agenticv0alpha0.Source{
+				Type: agenticv0alpha0.AuthorizationSourceTypeNamespaceSelector,
+				NamespaceSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "team", Operator: "Unknown"},
+				}},
+			},
+			want: []string{"none"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := &Translator{
+				agenticIdentityTrustDomain: testTrustDomain,
+				namespaceLister:            corev1listers.NewNamespaceLister(namespaceIndexer),
+			}
+			policy := &agenticv0alpha0.XAccessPolicy{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "policy"}}
+			principals := tr.buildRulePrincipals(policy, agenticv0alpha0.AccessRule{Name: "rule", Source: &tc.source})
+
+			var got []string
+			for _, principal := range principals {
+				if principal.GetNotId().GetAny() {
+					got = append(got, "none")
+					continue
+				}
+				got = append(got, stringMatcherToExpr(principal.GetAuthenticated().GetPrincipalName()))
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("expected principals %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
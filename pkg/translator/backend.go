@@ -18,11 +18,13 @@ package translator
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
 	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/durationpb"
 	corev1 "k8s.io/api/core/v1"
@@ -38,6 +40,8 @@ const (
 	defaultConnectTimeout = 5 * time.Second
 	// Default service port when BackendRef.Port is not set.
 	defaultServicePort = 80
+	// The key of the PEM-encoded CA certificates in the ConfigMaps and Secrets referenced by an XBackend.
+	caCertificateKey = "ca.crt"
 )
 
 // routeBackend represents either an XBackend or a direct Service reference for HTTPRoute backendRefs.
@@ -45,6 +49,9 @@ const (
 type routeBackend struct {
 	clusterName string
 	xbackend    *agenticv0alpha0.XBackend // nil if this is a direct Service ref
+	tlsContext  *tlsv3.UpstreamTlsContext // nil if the connection to the XBackend is plaintext
+	clientCert  *tlsv3.Secret             // nil if no client certificate is presented to the XBackend
+	credential  *tlsv3.Secret             // nil if no credential is injected into the requests to the XBackend
 	svcNS       string
 	svcName     string
 	svcPort     int32
@@ -110,9 +117,24 @@ func (t *Translator) fetchBackend(namespace string, backendRef gatewayv1.Backend
 		}
 	}
 
+	tlsContext, clientCertificate, err := t.backendTLSContext(backend)
+	if err != nil {
+		return nil, err
+	}
+	credential, err := t.backendCredentialSecret(backend)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.validateBackendTokenExchange(backend); err != nil {
+		return nil, err
+	}
+
 	return &routeBackend{
 		clusterName: fmt.Sprintf(constants.ClusterNameFormat, backend.Namespace, backend.Name),
 		xbackend:    backend,
+		tlsContext:  tlsContext,
+		clientCert:  clientCertificate,
+		credential:  credential,
 	}, nil
 }
 
@@ -161,35 +183,31 @@ func resolveServicePort(svc *corev1.Service, backendPort *gatewayv1.PortNumber)
 	return defaultServicePort
 }
 
-func convertBackendToCluster(backend *agenticv0alpha0.XBackend) (*clusterv3.Cluster, error) {
+// convertBackendToCluster converts an XBackend into a cluster connecting to it with the given TLS context, see
+// backendTLSContext.
+func convertBackendToCluster(backend *agenticv0alpha0.XBackend, tlsContext *tlsv3.UpstreamTlsContext) (*clusterv3.Cluster, error) {
 	clusterName := fmt.Sprintf(constants.ClusterNameFormat, backend.Namespace, backend.Name)
 
 	// Create the base cluster configuration.
 	cluster := &clusterv3.Cluster{
-		Name:           clusterName,
-		ConnectTimeout: durationpb.New(defaultConnectTimeout),
+		Name:             clusterName,
+		ConnectTimeout:   durationpb.New(defaultConnectTimeout),
+		HealthChecks:     buildHealthChecks(backend),
+		OutlierDetection: buildOutlierDetection(backend.Spec.MCP.OutlierDetection),
 	}
 
 	if backend.Spec.MCP.ServiceName != nil {
 		// For in-cluster services, use the FQDN.
-		serviceFQDN := fmt.Sprintf("%s.%s.svc.cluster.local", *backend.Spec.MCP.ServiceName, backend.Namespace)
 		cluster.ClusterDiscoveryType = &clusterv3.Cluster_Type{Type: clusterv3.Cluster_STRICT_DNS}
-		//nolint:gosec // G115: port values are within valid uint32 bounds
-		cluster.LoadAssignment = createClusterLoadAssignment(clusterName, serviceFQDN, uint32(backend.Spec.MCP.Port))
-		return cluster, nil
+	} else {
+		// External MCP backend specified via backend.Spec.MCP.Hostname
+		cluster.ClusterDiscoveryType = &clusterv3.Cluster_Type{Type: clusterv3.Cluster_LOGICAL_DNS}
+		cluster.DnsLookupFamily = clusterv3.Cluster_ALL
 	}
-
-	// External MCP backend specified via backend.Spec.MCP.Hostname
-	cluster.ClusterDiscoveryType = &clusterv3.Cluster_Type{Type: clusterv3.Cluster_LOGICAL_DNS}
-	cluster.DnsLookupFamily = clusterv3.Cluster_ALL
 	//nolint:gosec // G115: port values are within valid uint32 bounds
-	cluster.LoadAssignment = createClusterLoadAssignment(clusterName, *backend.Spec.MCP.Hostname, uint32(backend.Spec.MCP.Port))
-	// TODO: A new field will probably be added to Backend to allow configuring TLS for external MCP backends.
-	// For now, we always enable TLS for external MCP backends.
-	if true {
-		tlsContext := &tlsv3.UpstreamTlsContext{
-			Sni: *backend.Spec.MCP.Hostname,
-		}
+	cluster.LoadAssignment = createClusterLoadAssignment(clusterName, backendHost(backend), uint32(backend.Spec.MCP.Port))
+
+	if tlsContext != nil {
 		tlsAny, err := anypb.New(tlsContext)
 		if err != nil {
 			return nil, err
@@ -205,6 +223,199 @@ func convertBackendToCluster(backend *agenticv0alpha0.XBackend) (*clusterv3.Clus
 	return cluster, nil
 }
 
+// backendHost returns the host of an XBackend: its hostname, or the FQDN of its Service.
+func backendHost(backend *agenticv0alpha0.XBackend) string {
+	if backend.Spec.MCP.ServiceName != nil {
+		return fmt.Sprintf("%s.%s.svc.cluster.local", *backend.Spec.MCP.ServiceName, backend.Namespace)
+	}
+	return *backend.Spec.MCP.Hostname
+}
+
+// backendTLSContext returns the TLS context of the connection to an XBackend, or nil if the connection is plaintext,
+// along with the SDS secret holding the client certificate the TLS context references, if any. The CA certificates
+// referenced by the XBackend are inlined in the TLS context, whereas the client certificate is delivered over SDS so
+// that its private key is never part of the cluster configurations. It returns a ControllerError if they cannot be
+// resolved. In SPIFFE mode, the TLS context uses the SPIFFE identity and trust bundle the Envoy proxy loads from its
+// SDS files instead.
+func (t *Translator) backendTLSContext(backend *agenticv0alpha0.XBackend) (*tlsv3.UpstreamTlsContext, *tlsv3.Secret, error) {
+	tls := backend.Spec.MCP.TLS
+	if tls == nil {
+		if backend.Spec.MCP.Hostname == nil {
+			return nil, nil, nil
+		}
+		// External MCP backends use TLS by default, without validating the certificate of the backend.
+		return &tlsv3.UpstreamTlsContext{Sni: *backend.Spec.MCP.Hostname}, nil, nil
+	}
+	if tls.Mode == agenticv0alpha0.BackendTLSModePlaintext {
+		return nil, nil, nil
+	}
+
+	sni := backendHost(backend)
+	if tls.SNI != nil {
+		sni = string(*tls.SNI)
+	}
+	tlsContext := &tlsv3.UpstreamTlsContext{
+		Sni:              sni,
+		CommonTlsContext: &tlsv3.CommonTlsContext{},
+	}
+	if tls.Mode == agenticv0alpha0.BackendTLSModeSPIFFE {
+		tlsContext.CommonTlsContext = spiffeUpstreamCommonTLSContext(tls.ServerSPIFFEIDs)
+		return tlsContext, nil, nil
+	}
+
+	if len(tls.CACertificateRefs) > 0 {
+		var caBundle []byte
+		for _, ref := range tls.CACertificateRefs {
+			caCertificates, err := t.backendCACertificates(backend.Namespace, ref)
+			if err != nil {
+				return nil, nil, &ControllerError{
+					Reason:  string(gatewayv1.RouteReasonBackendNotFound),
+					Message: fmt.Sprintf("invalid CA certificate reference of Backend %s/%s: %v", backend.Namespace, backend.Name, err),
+				}
+			}
+			caBundle = append(caBundle, caCertificates...)
+			caBundle = append(caBundle, '\n')
+		}
+		tlsContext.CommonTlsContext.ValidationContextType = &tlsv3.CommonTlsContext_ValidationContext{
+			ValidationContext: &tlsv3.CertificateValidationContext{
+				TrustedCa: &corev3.DataSource{
+					Specifier: &corev3.DataSource_InlineBytes{InlineBytes: caBundle},
+				},
+				MatchTypedSubjectAltNames: []*tlsv3.SubjectAltNameMatcher{
+					{
+						SanType: tlsv3.SubjectAltNameMatcher_DNS,
+						Matcher: &matcherv3.StringMatcher{
+							MatchPattern: &matcherv3.StringMatcher_Exact{Exact: sni},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	var clientCertificate *tlsv3.Secret
+	if ref := tls.ClientCertificateRef; ref != nil {
+		certificate, err := t.backendClientCertificate(backend.Namespace, *ref)
+		if err != nil {
+			return nil, nil, &ControllerError{
+				Reason:  string(gatewayv1.RouteReasonBackendNotFound),
+				Message: fmt.Sprintf("invalid client certificate reference of Backend %s/%s: %v", backend.Namespace, backend.Name, err),
+			}
+		}
+		clientCertificate = &tlsv3.Secret{
+			Name: clientCertificateSecretName(backend),
+			Type: &tlsv3.Secret_TlsCertificate{TlsCertificate: certificate},
+		}
+		tlsContext.CommonTlsContext.TlsCertificateSdsSecretConfigs = []*tlsv3.SdsSecretConfig{{
+			Name: clientCertificate.GetName(),
+			SdsConfig: &corev3.ConfigSource{
+				ResourceApiVersion:    corev3.ApiVersion_V3,
+				ConfigSourceSpecifier: &corev3.ConfigSource_Ads{Ads: &corev3.AggregatedConfigSource{}},
+			},
+		}}
+	}
+
+	return tlsContext, clientCertificate, nil
+}
+
+// spiffeUpstreamCommonTLSContext returns the TLS context presenting the SPIFFE identity of the Gateway to a backend,
+// and validating that the backend presents one of the given SPIFFE IDs with the trust bundle of the agentic
+// identity trust domain.
+func spiffeUpstreamCommonTLSContext(serverSPIFFEIDs []agenticv0alpha0.AuthorizationSourceSPIFFE) *tlsv3.CommonTlsContext {
+	var sanMatchers []*tlsv3.SubjectAltNameMatcher
+	for _, id := range serverSPIFFEIDs {
+		serverID := sourceID{value: string(id)}
+		if prefix, ok := strings.CutSuffix(string(id), "/*"); ok {
+			serverID = sourceID{value: prefix + "/", prefix: true}
+		}
+		sanMatchers = append(sanMatchers, &tlsv3.SubjectAltNameMatcher{
+			SanType: tlsv3.SubjectAltNameMatcher_URI,
+			Matcher: serverID.stringMatcher(),
+		})
+	}
+	return &tlsv3.CommonTlsContext{
+		TlsCertificateSdsSecretConfigs: []*tlsv3.SdsSecretConfig{
+			spiffeSdsSecretConfig(constants.SpiffeIdentitySdsConfigName, constants.SpiffeIdentitySdsFileName),
+		},
+		ValidationContextType: &tlsv3.CommonTlsContext_CombinedValidationContext{
+			CombinedValidationContext: &tlsv3.CommonTlsContext_CombinedCertificateValidationContext{
+				DefaultValidationContext: &tlsv3.CertificateValidationContext{
+					MatchTypedSubjectAltNames: sanMatchers,
+				},
+				ValidationContextSdsSecretConfig: spiffeSdsSecretConfig(constants.SpiffeTrustSdsConfigName, constants.SpiffeTrustSdsFileName),
+			},
+		},
+	}
+}
+
+// backendCACertificates returns the PEM-encoded CA certificates of the ConfigMap or Secret referenced by an XBackend.
+func (t *Translator) backendCACertificates(namespace string, ref gatewayv1.LocalObjectReference) ([]byte, error) {
+	if ref.Group != "" {
+		return nil, fmt.Errorf("unsupported group %q of %s %s", ref.Group, ref.Kind, ref.Name)
+	}
+	switch ref.Kind {
+	case "ConfigMap":
+		if t.configMapLister == nil {
+			return nil, fmt.Errorf("failed to get ConfigMap %s/%s: no ConfigMap lister", namespace, ref.Name)
+		}
+		configMap, err := t.configMapLister.ConfigMaps(namespace).Get(string(ref.Name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get ConfigMap %s/%s: %w", namespace, ref.Name, err)
+		}
+		caCertificates, ok := configMap.Data[caCertificateKey]
+		if !ok || caCertificates == "" {
+			return nil, fmt.Errorf("ConfigMap %s/%s has no %s key", namespace, ref.Name, caCertificateKey)
+		}
+		return []byte(caCertificates), nil
+	case "Secret":
+		return t.secretKey(namespace, string(ref.Name), caCertificateKey)
+	default:
+		return nil, fmt.Errorf("unsupported kind %q of %s", ref.Kind, ref.Name)
+	}
+}
+
+// clientCertificateSecretName returns the name of the SDS secret holding the client certificate presented to an
+// XBackend.
+func clientCertificateSecretName(backend *agenticv0alpha0.XBackend) string {
+	return fmt.Sprintf(constants.ClientCertificateSecretNameFormat, backend.Namespace, backend.Name)
+}
+
+// backendClientCertificate returns the client certificate of the Secret of type kubernetes.io/tls referenced by
+// an XBackend.
+func (t *Translator) backendClientCertificate(namespace string, ref gatewayv1.LocalObjectReference) (*tlsv3.TlsCertificate, error) {
+	if ref.Group != "" || ref.Kind != "Secret" {
+		return nil, fmt.Errorf("unsupported kind %q of %s, the client certificate must be a Secret", ref.Kind, ref.Name)
+	}
+	certificateChain, err := t.secretKey(namespace, string(ref.Name), corev1.TLSCertKey)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := t.secretKey(namespace, string(ref.Name), corev1.TLSPrivateKeyKey)
+	if err != nil {
+		return nil, err
+	}
+	return &tlsv3.TlsCertificate{
+		CertificateChain: &corev3.DataSource{Specifier: &corev3.DataSource_InlineBytes{InlineBytes: certificateChain}},
+		PrivateKey:       &corev3.DataSource{Specifier: &corev3.DataSource_InlineBytes{InlineBytes: privateKey}},
+	}, nil
+}
+
+// secretKey returns the value of a key of a Secret.
+func (t *Translator) secretKey(namespace, name, key string) ([]byte, error) {
+	if t.secretLister == nil {
+		return nil, fmt.Errorf("failed to get Secret %s/%s: no Secret lister", namespace, name)
+	}
+	secret, err := t.secretLister.Secrets(namespace).Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Secret %s/%s: %w", namespace, name, err)
+	}
+	value, ok := secret.Data[key]
+	if !ok || len(value) == 0 {
+		return nil, fmt.Errorf("secret %s/%s has no %s key", namespace, name, key)
+	}
+	return value, nil
+}
+
 // buildClustersFromRouteBackends builds Envoy clusters from a mix of XBackend and direct Service refs.
 func buildClustersFromRouteBackends(backends []*routeBackend) ([]*clusterv3.Cluster, error) {
 	var clusters []*clusterv3.Cluster
@@ -212,7 +423,7 @@ func buildClustersFromRouteBackends(backends []*routeBackend) ([]*clusterv3.Clus
 		var cluster *clusterv3.Cluster
 		var err error
 		if rb.xbackend != nil {
-			cluster, err = convertBackendToCluster(rb.xbackend)
+			cluster, err = convertBackendToCluster(rb.xbackend, rb.tlsContext)
 		} else {
 			cluster = convertServiceRefToCluster(rb.svcNS, rb.svcName, rb.svcPort)
 		}
@@ -232,7 +443,8 @@ func convertServiceRefToCluster(ns, name string, port int32) *clusterv3.Cluster
 		ConnectTimeout:       durationpb.New(defaultConnectTimeout),
 		ClusterDiscoveryType: &clusterv3.Cluster_Type{Type: clusterv3.Cluster_STRICT_DNS},
 		//nolint:gosec // G115: port values are within valid uint32 bounds
-		LoadAssignment: createClusterLoadAssignment(clusterName, serviceFQDN, uint32(port)),
+		LoadAssignment:   createClusterLoadAssignment(clusterName, serviceFQDN, uint32(port)),
+		OutlierDetection: buildOutlierDetection(nil),
 	}
 	return cluster
 }
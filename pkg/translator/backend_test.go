@@ -0,0 +1,256 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package translator
+
+import (
+	"errors"
+	"testing"
+
+	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	"google.golang.org/protobuf/proto"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
+	"sigs.k8s.io/kube-agentic-networking/pkg/constants"
+)
+
+func TestBackendTLSContext(t *testing.T) {
+	secretIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	_ = configMapIndexer.Add(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ca"},
+		Data:       map[string]string{caCertificateKey: "configmap-ca"},
+	})
+	_ = secretIndexer.Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ca"},
+		Data:       map[string][]byte{caCertificateKey: []byte("secret-ca")},
+	})
+	_ = secretIndexer.Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "client"},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("client-cert"), corev1.TLSPrivateKeyKey: []byte("client-key")},
+	})
+	tr := &Translator{
+		secretLister:    corev1listers.NewSecretLister(secretIndexer),
+		configMapLister: corev1listers.NewConfigMapLister(configMapIndexer),
+	}
+
+	newBackend := func(hostname bool, tls *agenticv0alpha0.BackendTLS) *agenticv0alpha0.XBackend {
+		backend := &agenticv0alpha0.XBackend{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "backend"},
+			Spec:       agenticv0alpha0.BackendSpec{MCP: agenticv0alpha0.MCPBackend{Port: 443, TLS: tls}},
+		}
+		if hostname {
+			backend.Spec.MCP.Hostname = ptr.To("mcp.example.com")
+		} else {
+			backend.Spec.MCP.ServiceName = ptr.To("mcp")
+		}
+		return backend
+	}
+	ref := func(kind, name string) gatewayv1.LocalObjectReference {
+		return gatewayv1.LocalObjectReference{Kind: gatewayv1.Kind(kind), Name: gatewayv1.ObjectName(name)}
+	}
+
+	tests := []struct {
+		name              string
+		backend           *agenticv0alpha0.XBackend
+		wantTLS           bool
+		wantSNI           string
+		wantCA            string
+		wantClientCert    string
+		wantControllerErr bool
+	}{
+		{
+			name:    "hostname without TLS configuration",
+			backend: newBackend(true, nil),
+			wantTLS: true,
+			wantSNI: "mcp.example.com",
+		},
+		{
+			name:    "service without TLS configuration",
+			backend: newBackend(false, nil),
+		},
+		{
+			name:    "plaintext hostname",
+			backend: newBackend(true, &agenticv0alpha0.BackendTLS{Mode: agenticv0alpha0.BackendTLSModePlaintext}),
+		},
+		{
+			name:    "service with TLS",
+			backend: newBackend(false, &agenticv0alpha0.BackendTLS{Mode: agenticv0alpha0.BackendTLSModeTLS}),
+			wantTLS: true,
+			wantSNI: "mcp.default.svc.cluster.local",
+		},
+		{
+			name: "CA certificates, client certificate and SNI",
+			backend: newBackend(true, &agenticv0alpha0.BackendTLS{
+				CACertificateRefs:    []gatewayv1.LocalObjectReference{ref("ConfigMap", "ca"), ref("Secret", "ca")},
+				ClientCertificateRef: ptr.To(ref("Secret", "client")),
+				SNI:                  ptr.To(gatewayv1.PreciseHostname("mcp.internal")),
+			}),
+			wantTLS:        true,
+			wantSNI:        "mcp.internal",
+			wantCA:         "configmap-ca\nsecret-ca\n",
+			wantClientCert: "client-cert",
+		},
+		{
+			name: "missing CA certificate",
+			backend: newBackend(true, &agenticv0alpha0.BackendTLS{
+				CACertificateRefs: []gatewayv1.LocalObjectReference{ref("ConfigMap", "missing")},
+			}),
+			wantControllerErr: true,
+		},
+		{
+			name: "client certificate without a private key",
+			backend: newBackend(true, &agenticv0alpha0.BackendTLS{
+				ClientCertificateRef: ptr.To(ref("Secret", "ca")),
+			}),
+			wantControllerErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tlsContext, clientCertificate, err := tr.backendTLSContext(tc.backend)
+			if tc.wantControllerErr {
+				var controllerErr *ControllerError
+				if !errors.As(err, &controllerErr) {
+					t.Fatalf("expected a ControllerError, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("backendTLSContext: %v", err)
+			}
+			if (tlsContext != nil) != tc.wantTLS {
+				t.Fatalf("expected TLS %v, got TLS context %v", tc.wantTLS, tlsContext)
+			}
+			if tlsContext.GetSni() != tc.wantSNI {
+				t.Errorf("expected SNI %q, got %q", tc.wantSNI, tlsContext.GetSni())
+			}
+			validationContext := tlsContext.GetCommonTlsContext().GetValidationContext()
+			if got := string(validationContext.GetTrustedCa().GetInlineBytes()); got != tc.wantCA {
+				t.Errorf("expected trusted CA %q, got %q", tc.wantCA, got)
+			}
+			if tc.wantCA != "" {
+				sans := validationContext.GetMatchTypedSubjectAltNames()
+				if len(sans) != 1 || sans[0].GetSanType() != tlsv3.SubjectAltNameMatcher_DNS || sans[0].GetMatcher().GetExact() != tc.wantSNI {
+					t.Errorf("expected the certificate to be validated for %q, got %v", tc.wantSNI, sans)
+				}
+			}
+			// The client certificate is delivered over SDS, so that its private key is not part of the cluster.
+			if certificates := tlsContext.GetCommonTlsContext().GetTlsCertificates(); len(certificates) > 0 {
+				t.Errorf("expected no inline client certificate, got %v", certificates)
+			}
+			if got := string(clientCertificate.GetTlsCertificate().GetCertificateChain().GetInlineBytes()); got != tc.wantClientCert {
+				t.Errorf("expected client certificate %q, got %q", tc.wantClientCert, got)
+			}
+			sdsSecretConfigs := tlsContext.GetCommonTlsContext().GetTlsCertificateSdsSecretConfigs()
+			if tc.wantClientCert == "" {
+				if len(sdsSecretConfigs) > 0 {
+					t.Errorf("expected no client certificate, got %v", sdsSecretConfigs)
+				}
+			} else {
+				if clientCertificate.GetName() != "client-certificate-default-backend" {
+					t.Errorf("expected the client certificate secret client-certificate-default-backend, got %q", clientCertificate.GetName())
+				}
+				if got := string(clientCertificate.GetTlsCertificate().GetPrivateKey().GetInlineBytes()); got != "client-key" {
+					t.Errorf("expected the private key of the client certificate, got %q", got)
+				}
+				if len(sdsSecretConfigs) != 1 || sdsSecretConfigs[0].GetName() != clientCertificate.GetName() || sdsSecretConfigs[0].GetSdsConfig().GetAds() == nil {
+					t.Errorf("expected the client certificate to be fetched over ADS, got %v", sdsSecretConfigs)
+				}
+			}
+
+			cluster, err := convertBackendToCluster(tc.backend, tlsContext)
+			if err != nil {
+				t.Fatalf("convertBackendToCluster: %v", err)
+			}
+			if (cluster.GetTransportSocket() != nil) != tc.wantTLS {
+				t.Errorf("expected TLS %v, got transport socket %v", tc.wantTLS, cluster.GetTransportSocket())
+			}
+		})
+	}
+}
+
+func TestBackendTLSContext_SPIFFE(t *testing.T) {
+	backend := &agenticv0alpha0.XBackend{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "mcp", Name: "backend"},
+		Spec: agenticv0alpha0.BackendSpec{MCP: agenticv0alpha0.MCPBackend{
+			ServiceName: ptr.To("mcp"),
+			Port:        8443,
+			TLS: &agenticv0alpha0.BackendTLS{
+				Mode: agenticv0alpha0.BackendTLSModeSPIFFE,
+				ServerSPIFFEIDs: []agenticv0alpha0.AuthorizationSourceSPIFFE{
+					"spiffe://cluster.local/ns/mcp/sa/mcp",
+					"spiffe://cluster.local/ns/tools/*",
+				},
+			},
+		}},
+	}
+
+	tlsContext, clientCertificate, err := (&Translator{}).backendTLSContext(backend)
+	if err != nil {
+		t.Fatalf("backendTLSContext: %v", err)
+	}
+	if clientCertificate != nil {
+		t.Errorf("expected no client certificate secret, got %v", clientCertificate)
+	}
+	if got, want := tlsContext.GetSni(), "mcp.mcp.svc.cluster.local"; got != want {
+		t.Errorf("expected SNI %q, got %q", want, got)
+	}
+	commonTLSContext := tlsContext.GetCommonTlsContext()
+	identity := commonTLSContext.GetTlsCertificateSdsSecretConfigs()
+	if len(identity) != 1 || identity[0].GetName() != constants.SpiffeIdentitySdsConfigName {
+		t.Errorf("expected the SPIFFE identity of the Gateway as client certificate, got %v", identity)
+	}
+	validationContext := commonTLSContext.GetCombinedValidationContext()
+	if got := validationContext.GetValidationContextSdsSecretConfig().GetName(); got != constants.SpiffeTrustSdsConfigName {
+		t.Errorf("expected the SPIFFE trust bundle to validate the backend, got %q", got)
+	}
+	sans := validationContext.GetDefaultValidationContext().GetMatchTypedSubjectAltNames()
+	if len(sans) != 2 {
+		t.Fatalf("expected 2 SAN matchers, got %v", sans)
+	}
+	for _, san := range sans {
+		if san.GetSanType() != tlsv3.SubjectAltNameMatcher_URI {
+			t.Errorf("expected URI SAN matcher, got %v", san.GetSanType())
+		}
+	}
+	if got := sans[0].GetMatcher().GetExact(); got != "spiffe://cluster.local/ns/mcp/sa/mcp" {
+		t.Errorf("expected exact match of the SPIFFE ID of the backend, got %v", sans[0].GetMatcher())
+	}
+	if got := sans[1].GetMatcher().GetPrefix(); got != "spiffe://cluster.local/ns/tools/" {
+		t.Errorf("expected prefix match of the SPIFFE IDs of the tools namespace, got %v", sans[1].GetMatcher())
+	}
+
+	cluster, err := convertBackendToCluster(backend, tlsContext)
+	if err != nil {
+		t.Fatalf("convertBackendToCluster: %v", err)
+	}
+	upstreamTLSContext := &tlsv3.UpstreamTlsContext{}
+	if err := cluster.GetTransportSocket().GetTypedConfig().UnmarshalTo(upstreamTLSContext); err != nil {
+		t.Fatalf("failed to unmarshal UpstreamTlsContext: %v", err)
+	}
+	if !proto.Equal(upstreamTLSContext, tlsContext) {
+		t.Errorf("expected the cluster to originate mTLS with %v, got %v", tlsContext, upstreamTLSContext)
+	}
+}
@@ -0,0 +1,267 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package translator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+	"google.golang.org/protobuf/proto"
+
+	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
+)
+
+const (
+	celRequestVariable  = "request"
+	celIdentityVariable = "identity"
+	celMCPField         = "mcp"
+)
+
+// celMCPFields maps the fields of request.mcp to their path in the dynamic metadata of the MCP filter.
+var celMCPFields = map[string][]string{
+	"method":    {"method"},
+	"tool_name": {"params", "name"},
+	"params":    {"params"},
+}
+
+// celEnv returns the environment CEL authorization rules are type-checked in, declaring the variables
+// documented in the API.
+var celEnv = sync.OnceValues(func() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable(celRequestVariable, cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable(celIdentityVariable, cel.MapType(cel.StringType, cel.DynType)),
+	)
+})
+
+// ValidateCELRules type-checks the expressions of the CEL rules of an AccessPolicy. It returns an error
+// describing the invalid expressions, or nil if all of them are valid.
+func ValidateCELRules(accessPolicy *agenticv0alpha0.XAccessPolicy) error {
+	var messages []string
+	for _, rule := range accessPolicy.Spec.Rules {
+		if rule.Authorization == nil || rule.Authorization.Type != agenticv0alpha0.AuthorizationRuleTypeCEL {
+			continue
+		}
+		if err := validateCELRuleSources(rule); err != nil {
+			messages = append(messages, fmt.Sprintf("rule %s: %v", rule.Name, err))
+			continue
+		}
+		// The identity of the source does not change the validity of the expression.
+		if _, err := compileCELExpression(rule.Authorization.CEL, buildCELPeerIdentity()); err != nil {
+			messages = append(messages, fmt.Sprintf("rule %s: %v", rule.Name, err))
+		}
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(messages, "; "))
+}
+
+// buildCELCondition compiles the expression of a CEL rule into an RBAC policy condition, which Envoy
+// evaluates against its own request attributes.
+func buildCELCondition(accessPolicy *agenticv0alpha0.XAccessPolicy, rule agenticv0alpha0.AccessRule) (*exprpb.Expr, error) {
+	if err := validateCELRuleSources(rule); err != nil {
+		return nil, err
+	}
+	identity := buildCELPeerIdentity()
+	if oidcs := ruleOIDCSources(rule); len(oidcs) > 0 {
+		providerName, err := jwtProviderName(accessPolicy.Namespace, oidcs[0])
+		if err != nil {
+			return nil, err
+		}
+		identity = buildCELIndex(buildCELIndex(buildCELSelect(buildCELIdent("metadata"), "filter_metadata"), wellknownJWTAuthnFilter), providerName)
+	}
+	return compileCELExpression(rule.Authorization.CEL, identity)
+}
+
+// validateCELRuleSources checks that the identity of a CEL rule is the same for all its sources: the claims of
+// the token of an OIDC source differ from the identity of the other sources.
+func validateCELRuleSources(rule agenticv0alpha0.AccessRule) error {
+	if len(ruleOIDCSources(rule)) > 0 && len(RuleSources(rule)) > 1 {
+		return errors.New("CEL rules cannot combine an OIDC source with other sources")
+	}
+	return nil
+}
+
+// compileCELExpression type-checks a CEL expression and rewrites the references to the documented variables
+// into the corresponding Envoy attributes. References to identity are replaced with the given expression.
+func compileCELExpression(expression string, identity *exprpb.Expr) (*exprpb.Expr, error) {
+	env, err := celEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		var messages []string
+		for _, e := range issues.Errors() {
+			messages = append(messages, fmt.Sprintf("%d:%d: %s", e.Location.Line(), e.Location.Column()+1, e.Message))
+		}
+		return nil, fmt.Errorf("invalid CEL expression: %s", strings.Join(messages, "; "))
+	}
+	if !ast.OutputType().IsExactType(cel.BoolType) && !ast.OutputType().IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("invalid CEL expression: must evaluate to a bool, got %s", ast.OutputType())
+	}
+
+	parsed, err := cel.AstToParsedExpr(ast)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert CEL expression: %w", err)
+	}
+	expr := parsed.GetExpr()
+	if err := rewriteCELExpr(expr, identity); err != nil {
+		return nil, fmt.Errorf("invalid CEL expression: %w", err)
+	}
+	return expr, nil
+}
+
+// rewriteCELExpr rewrites in place the references to request.mcp into the dynamic metadata of the MCP
+// filter, and the references to identity into the given expression.
+func rewriteCELExpr(expr *exprpb.Expr, identity *exprpb.Expr) error {
+	if expr == nil {
+		return nil
+	}
+	switch kind := expr.GetExprKind().(type) {
+	case *exprpb.Expr_IdentExpr:
+		switch kind.IdentExpr.GetName() {
+		case celRequestVariable:
+			return errors.New("request can only be used to refer to request.mcp")
+		case celIdentityVariable:
+			expr.ExprKind = cloneCELExpr(identity).GetExprKind()
+		}
+	case *exprpb.Expr_SelectExpr:
+		sel := kind.SelectExpr
+		if isCELIdent(sel.GetOperand(), celRequestVariable) {
+			if sel.GetField() != celMCPField {
+				return fmt.Errorf("undefined field '%s' of request", sel.GetField())
+			}
+			expr.ExprKind = buildCELMCPMetadata().GetExprKind()
+			return nil
+		}
+		if isCELRequestMCP(sel.GetOperand()) {
+			path, ok := celMCPFields[sel.GetField()]
+			if !ok {
+				return fmt.Errorf("undefined field '%s' of request.mcp", sel.GetField())
+			}
+			operand := buildCELMCPMetadata()
+			for _, field := range path[:len(path)-1] {
+				operand = buildCELSelect(operand, field)
+			}
+			sel.Operand = operand
+			sel.Field = path[len(path)-1]
+			return nil
+		}
+		return rewriteCELExpr(sel.GetOperand(), identity)
+	case *exprpb.Expr_CallExpr:
+		if err := rewriteCELExpr(kind.CallExpr.GetTarget(), identity); err != nil {
+			return err
+		}
+		for _, arg := range kind.CallExpr.GetArgs() {
+			if err := rewriteCELExpr(arg, identity); err != nil {
+				return err
+			}
+		}
+	case *exprpb.Expr_ListExpr:
+		for _, element := range kind.ListExpr.GetElements() {
+			if err := rewriteCELExpr(element, identity); err != nil {
+				return err
+			}
+		}
+	case *exprpb.Expr_StructExpr:
+		for _, entry := range kind.StructExpr.GetEntries() {
+			if err := rewriteCELExpr(entry.GetMapKey(), identity); err != nil {
+				return err
+			}
+			if err := rewriteCELExpr(entry.GetValue(), identity); err != nil {
+				return err
+			}
+		}
+	case *exprpb.Expr_ComprehensionExpr:
+		comprehension := kind.ComprehensionExpr
+		for _, variable := range []string{comprehension.GetIterVar(), comprehension.GetAccuVar()} {
+			if variable == celRequestVariable || variable == celIdentityVariable {
+				return fmt.Errorf("%s cannot be used as a comprehension variable", variable)
+			}
+		}
+		for _, e := range []*exprpb.Expr{comprehension.GetIterRange(), comprehension.GetAccuInit(), comprehension.GetLoopCondition(), comprehension.GetLoopStep(), comprehension.GetResult()} {
+			if err := rewriteCELExpr(e, identity); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// buildCELPeerIdentity builds the identity of sources authenticated with mTLS, whose subject is the SPIFFE ID
+// of the peer certificate.
+func buildCELPeerIdentity() *exprpb.Expr {
+	return &exprpb.Expr{
+		ExprKind: &exprpb.Expr_StructExpr{
+			StructExpr: &exprpb.Expr_CreateStruct{
+				Entries: []*exprpb.Expr_CreateStruct_Entry{
+					{
+						KeyKind: &exprpb.Expr_CreateStruct_Entry_MapKey{MapKey: buildCELString("sub")},
+						Value:   buildCELSelect(buildCELIdent("connection"), "uri_san_peer_certificate"),
+					},
+				},
+			},
+		},
+	}
+}
+
+// buildCELMCPMetadata builds the expression of the dynamic metadata of the MCP filter.
+func buildCELMCPMetadata() *exprpb.Expr {
+	return buildCELIndex(buildCELSelect(buildCELIdent("metadata"), "filter_metadata"), mcpProxyFilterName)
+}
+
+func isCELIdent(expr *exprpb.Expr, name string) bool {
+	return expr.GetIdentExpr() != nil && expr.GetIdentExpr().GetName() == name
+}
+
+func isCELRequestMCP(expr *exprpb.Expr) bool {
+	return expr.GetSelectExpr() != nil && expr.GetSelectExpr().GetField() == celMCPField && isCELIdent(expr.GetSelectExpr().GetOperand(), celRequestVariable)
+}
+
+func cloneCELExpr(expr *exprpb.Expr) *exprpb.Expr {
+	return proto.Clone(expr).(*exprpb.Expr)
+}
+
+func buildCELIdent(name string) *exprpb.Expr {
+	return &exprpb.Expr{ExprKind: &exprpb.Expr_IdentExpr{IdentExpr: &exprpb.Expr_Ident{Name: name}}}
+}
+
+func buildCELSelect(operand *exprpb.Expr, field string) *exprpb.Expr {
+	return &exprpb.Expr{ExprKind: &exprpb.Expr_SelectExpr{SelectExpr: &exprpb.Expr_Select{Operand: operand, Field: field}}}
+}
+
+func buildCELString(value string) *exprpb.Expr {
+	return &exprpb.Expr{ExprKind: &exprpb.Expr_ConstExpr{ConstExpr: &exprpb.Constant{ConstantKind: &exprpb.Constant_StringValue{StringValue: value}}}}
+}
+
+// buildCELIndex builds the expression of the value of the given key of a map.
+func buildCELIndex(operand *exprpb.Expr, key string) *exprpb.Expr {
+	return &exprpb.Expr{
+		ExprKind: &exprpb.Expr_CallExpr{
+			CallExpr: &exprpb.Expr_Call{
+				Function: "_[_]",
+				Args:     []*exprpb.Expr{operand, buildCELString(key)},
+			},
+		},
+	}
+}
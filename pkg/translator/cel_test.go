@@ -0,0 +1,227 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package translator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+	"k8s.io/utils/ptr"
+
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
+)
+
+func TestCompileCELExpression(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		want       string
+		wantErr    string
+	}{
+		{
+			name:       "tool name",
+			expression: `request.mcp.tool_name.startsWith("read_")`,
+			want:       `metadata.filter_metadata["mcp_proxy"].params.name.startsWith("read_")`,
+		},
+		{
+			name:       "method and arguments",
+			expression: `request.mcp.method == "tools/call" && request.mcp.params.arguments.path.startsWith("/tmp/")`,
+			want:       `metadata.filter_metadata["mcp_proxy"].method == "tools/call" && metadata.filter_metadata["mcp_proxy"].params.arguments.path.startsWith("/tmp/")`,
+		},
+		{
+			name:       "identity",
+			expression: `identity.sub == "spiffe://example.org/agent"`,
+			want:       `{"sub": connection.uri_san_peer_certificate}.sub == "spiffe://example.org/agent"`,
+		},
+		{
+			name:       "syntax error",
+			expression: `request.mcp.tool_name ==`,
+			wantErr:    "invalid CEL expression: 1:",
+		},
+		{
+			name:       "undeclared variable",
+			expression: `connection.uri_san_peer_certificate == "spiffe://example.org/agent"`,
+			wantErr:    "undeclared reference to 'connection'",
+		},
+		{
+			name:       "not a bool",
+			expression: `"read_"`,
+			wantErr:    "must evaluate to a bool",
+		},
+		{
+			name:       "unknown request field",
+			expression: `request.path == "/mcp"`,
+			wantErr:    "undefined field 'path' of request",
+		},
+		{
+			name:       "unknown request.mcp field",
+			expression: `request.mcp.tool == "read"`,
+			wantErr:    "undefined field 'tool' of request.mcp",
+		},
+		{
+			name:       "request as a whole",
+			expression: `size(request) > 0`,
+			wantErr:    "request can only be used to refer to request.mcp",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := compileCELExpression(tc.expression, buildCELPeerIdentity())
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("compileCELExpression: %v", err)
+			}
+			if got := celExprToString(t, expr); got != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestBuildCELCondition_OIDC(t *testing.T) {
+	policy := newTestOIDCAccessPolicy("default", "policy", "my-backend", &agenticv0alpha0.AuthorizationSourceOIDC{
+		Issuer: "https://issuer.example.com",
+		JWKS:   agenticv0alpha0.JWKS{Inline: ptr.To(testJWKS)},
+	})
+	rule := policy.Spec.Rules[0]
+	rule.Authorization = &agenticv0alpha0.AuthorizationRule{
+		Type: agenticv0alpha0.AuthorizationRuleTypeCEL,
+		CEL:  `"mcp-gateway" in identity.aud`,
+	}
+
+	condition, err := buildCELCondition(policy, rule)
+	if err != nil {
+		t.Fatalf("buildCELCondition: %v", err)
+	}
+	providerName, err := jwtProviderName("default", rule.Source.OIDC)
+	if err != nil {
+		t.Fatalf("jwtProviderName: %v", err)
+	}
+	want := `"mcp-gateway" in metadata.filter_metadata["envoy.filters.http.jwt_authn"]["` + providerName + `"].aud`
+	if got := celExprToString(t, condition); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestTranslateAccessPolicyToRBAC_CEL(t *testing.T) {
+	tr := &Translator{agenticIdentityTrustDomain: testTrustDomain}
+	newPolicy := func(action agenticv0alpha0.AccessRuleAction, expression string) *agenticv0alpha0.XAccessPolicy {
+		policy := newTestAccessPolicy("default", "policy", "my-backend", "spiffe://example.org/agent")
+		policy.Spec.Rules[0].Action = action
+		policy.Spec.Rules[0].Authorization = &agenticv0alpha0.AuthorizationRule{
+			Type: agenticv0alpha0.AuthorizationRuleTypeCEL,
+			CEL:  expression,
+		}
+		return policy
+	}
+	policyName := accessRulePolicyName(newPolicy(agenticv0alpha0.AccessRuleActionAllow, ""), "rule")
+
+	t.Run("allow", func(t *testing.T) {
+		rbacConfig := tr.translatesAccessPolicyToRBAC(newPolicy(agenticv0alpha0.AccessRuleActionAllow, `request.mcp.tool_name == "read"`))
+		policy := rbacConfig.GetRules().GetPolicies()[policyName]
+		if policy == nil {
+			t.Fatalf("expected policy %q", policyName)
+		}
+		if policy.GetCondition() == nil {
+			t.Error("expected policy to have a CEL condition")
+		}
+		if len(policy.GetPermissions()) != 1 || !policy.GetPermissions()[0].GetAny() {
+			t.Errorf("expected any permission, got %v", policy.GetPermissions())
+		}
+	})
+
+	t.Run("allow with invalid expression", func(t *testing.T) {
+		rbacConfig := tr.translatesAccessPolicyToRBAC(newPolicy(agenticv0alpha0.AccessRuleActionAllow, `request.mcp.tool ==`))
+		if _, ok := rbacConfig.GetRules().GetPolicies()[policyName]; ok {
+			t.Errorf("expected no policy for a rule with an invalid expression")
+		}
+	})
+
+	t.Run("deny", func(t *testing.T) {
+		rbacConfig := tr.translateAccessPolicyToDenyRBAC(newPolicy(agenticv0alpha0.AccessRuleActionDeny, `request.mcp.tool_name == "delete"`))
+		policy := rbacConfig.GetRules().GetPolicies()[policyName]
+		if policy.GetCondition() == nil {
+			t.Error("expected policy to have a CEL condition")
+		}
+	})
+
+	t.Run("deny with invalid expression", func(t *testing.T) {
+		rbacConfig := tr.translateAccessPolicyToDenyRBAC(newPolicy(agenticv0alpha0.AccessRuleActionDeny, `request.mcp.tool ==`))
+		policy := rbacConfig.GetRules().GetPolicies()[policyName]
+		if policy.GetCondition() != nil {
+			t.Error("expected policy without a CEL condition")
+		}
+		if len(policy.GetPermissions()) != 1 || policy.GetPermissions()[0].GetAny() {
+			t.Errorf("expected tool calls permission, got %v", policy.GetPermissions())
+		}
+	})
+}
+
+func celExprToString(t *testing.T, expr *exprpb.Expr) string {
+	t.Helper()
+	s, err := cel.AstToString(cel.ParsedExprToAst(&exprpb.ParsedExpr{Expr: expr, SourceInfo: &exprpb.SourceInfo{}}))
+	if err != nil {
+		t.Fatalf("AstToString: %v", err)
+	}
+	return s
+}
+
+func TestBuildCELCondition_MultipleSources(t *testing.T) {
+	policy := newTestAccessPolicy("default", "policy", "my-backend", "spiffe://example.org/agent")
+	rule := policy.Spec.Rules[0]
+	rule.Authorization = &agenticv0alpha0.AuthorizationRule{
+		Type: agenticv0alpha0.AuthorizationRuleTypeCEL,
+		CEL:  `identity.sub.startsWith("spiffe://example.org/")`,
+	}
+	rule.Sources = []agenticv0alpha0.Source{
+		*rule.Source,
+		{Type: agenticv0alpha0.AuthorizationSourceTypeNamespace, Namespace: ptr.To(gwapiv1.Namespace("team-a"))},
+	}
+	rule.Source = nil
+	policy.Spec.Rules[0] = rule
+
+	if _, err := buildCELCondition(policy, rule); err != nil {
+		t.Errorf("expected the peer identity to be shared by non-OIDC sources, got %v", err)
+	}
+	if err := ValidateCELRules(policy); err != nil {
+		t.Errorf("ValidateCELRules: %v", err)
+	}
+
+	// The identity of an OIDC source holds the claims of its token, not the peer identity of the other sources.
+	policy.Spec.Rules[0].Sources = append(policy.Spec.Rules[0].Sources, agenticv0alpha0.Source{
+		Type: agenticv0alpha0.AuthorizationSourceTypeOIDC,
+		OIDC: &agenticv0alpha0.AuthorizationSourceOIDC{
+			Issuer: "https://issuer.example.com",
+			JWKS:   agenticv0alpha0.JWKS{Inline: ptr.To(testJWKS)},
+		},
+	})
+	if _, err := buildCELCondition(policy, policy.Spec.Rules[0]); err == nil {
+		t.Error("expected an error for a CEL rule combining an OIDC source with other sources")
+	}
+	if err := ValidateCELRules(policy); err == nil {
+		t.Error("expected ValidateCELRules to report the CEL rule combining an OIDC source with other sources")
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package translator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	credentialinjectorv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/credential_injector/v3"
+	hcm "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	genericcredentialv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/http/injected_credentials/generic/v3"
+	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	"google.golang.org/protobuf/types/known/anypb"
+	"k8s.io/klog/v2"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
+	"sigs.k8s.io/kube-agentic-networking/pkg/constants"
+)
+
+const (
+	// credentialInjectorFilterNamePrefix is the prefix of the names of the credential_injector filters. Each XBackend
+	// with a credential gets its own filter, disabled on the listener and only enabled on the clusters of the
+	// XBackend, since the filter has no per-route config to select the credential.
+	credentialInjectorFilterNamePrefix = "envoy.filters.http.credential_injector"
+
+	// genericCredentialName is the name of the extension injecting the credential of a generic secret as is.
+	genericCredentialName = "envoy.http.injected_credentials.generic"
+
+	// defaultCredentialKey is the key of the credential in the Secret referenced by an XBackend if not specified.
+	defaultCredentialKey = "credential"
+
+	// defaultCredentialHeader is the header the credential of an XBackend is set in if not specified.
+	defaultCredentialHeader = "Authorization"
+)
+
+// credentialInjectorFilterName returns the name of the credential_injector filter of an XBackend.
+func credentialInjectorFilterName(backend *agenticv0alpha0.XBackend) string {
+	return fmt.Sprintf("%s.%s", credentialInjectorFilterNamePrefix, fmt.Sprintf(constants.ClusterNameFormat, backend.Namespace, backend.Name))
+}
+
+// credentialSecretName returns the name of the SDS secret holding the credential of an XBackend.
+func credentialSecretName(backend *agenticv0alpha0.XBackend) string {
+	return fmt.Sprintf(constants.CredentialSecretNameFormat, backend.Namespace, backend.Name)
+}
+
+// buildCredentialInjectorFilters builds the credential_injector filters of the XBackends a listener routes to.
+// The filters are disabled, see buildPerClusterCredentialInjectorConfig, and get the credentials over SDS so that
+// they are never part of the listener or route configurations.
+func buildCredentialInjectorFilters(backends []*agenticv0alpha0.XBackend) ([]*hcm.HttpFilter, error) {
+	backends = slices.Clone(backends)
+	slices.SortFunc(backends, func(a, b *agenticv0alpha0.XBackend) int {
+		return strings.Compare(a.Namespace+"/"+a.Name, b.Namespace+"/"+b.Name)
+	})
+
+	var filters []*hcm.HttpFilter
+	for _, backend := range backends {
+		credentialInjection := backend.Spec.MCP.CredentialInjection
+		if credentialInjection == nil {
+			continue
+		}
+		header := string(credentialInjection.Header)
+		if header == "" {
+			header = defaultCredentialHeader
+		}
+		credentialAny, err := anypb.New(&genericcredentialv3.Generic{
+			Credential: &tlsv3.SdsSecretConfig{
+				Name: credentialSecretName(backend),
+				SdsConfig: &corev3.ConfigSource{
+					ResourceApiVersion:    corev3.ApiVersion_V3,
+					ConfigSourceSpecifier: &corev3.ConfigSource_Ads{Ads: &corev3.AggregatedConfigSource{}},
+				},
+			},
+			Header: header,
+		})
+		if err != nil {
+			klog.Errorf("Failed to marshal generic credential config: %v", err)
+			return nil, err
+		}
+		credentialInjectorAny, err := anypb.New(&credentialinjectorv3.CredentialInjector{
+			// The credential of the agent, if any, is not meant for the backend.
+			Overwrite: true,
+			Credential: &corev3.TypedExtensionConfig{
+				Name:        genericCredentialName,
+				TypedConfig: credentialAny,
+			},
+		})
+		if err != nil {
+			klog.Errorf("Failed to marshal credential_injector config: %v", err)
+			return nil, err
+		}
+		filters = append(filters, &hcm.HttpFilter{
+			Name:     credentialInjectorFilterName(backend),
+			Disabled: true,
+			ConfigType: &hcm.HttpFilter_TypedConfig{
+				TypedConfig: credentialInjectorAny,
+			},
+		})
+	}
+	return filters, nil
+}
+
+// buildPerClusterCredentialInjectorConfig returns the name of the credential_injector filter of the XBackend of a
+// cluster and the TypedPerFilterConfig enabling it, or nil if the XBackend has no credential.
+func buildPerClusterCredentialInjectorConfig(backend *agenticv0alpha0.XBackend) (string, *anypb.Any, error) {
+	if backend == nil || backend.Spec.MCP.CredentialInjection == nil {
+		return "", nil, nil
+	}
+	enabledAny, err := anypb.New(&routev3.FilterConfig{})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal FilterConfig proto: %w", err)
+	}
+	return credentialInjectorFilterName(backend), enabledAny, nil
+}
+
+// backendCredentialSecret returns the SDS secret holding the credential of an XBackend, or nil if the XBackend has
+// no credential.
+func (t *Translator) backendCredentialSecret(backend *agenticv0alpha0.XBackend) (*tlsv3.Secret, error) {
+	credentialInjection := backend.Spec.MCP.CredentialInjection
+	if credentialInjection == nil {
+		return nil, nil
+	}
+	key := credentialInjection.Key
+	if key == "" {
+		key = defaultCredentialKey
+	}
+	ref := credentialInjection.SecretRef
+	if ref.Group != "" || ref.Kind != "Secret" {
+		return nil, &ControllerError{
+			Reason:  string(gatewayv1.RouteReasonBackendNotFound),
+			Message: fmt.Sprintf("invalid credential reference of Backend %s/%s: unsupported kind %q of %s, the credential must be a Secret", backend.Namespace, backend.Name, ref.Kind, ref.Name),
+		}
+	}
+	credential, err := t.secretKey(backend.Namespace, string(ref.Name), key)
+	if err != nil {
+		return nil, &ControllerError{
+			Reason:  string(gatewayv1.RouteReasonBackendNotFound),
+			Message: fmt.Sprintf("invalid credential reference of Backend %s/%s: %v", backend.Namespace, backend.Name, err),
+		}
+	}
+	return &tlsv3.Secret{
+		Name: credentialSecretName(backend),
+		Type: &tlsv3.Secret_GenericSecret{
+			GenericSecret: &tlsv3.GenericSecret{
+				Secret: &corev3.DataSource{Specifier: &corev3.DataSource_InlineBytes{InlineBytes: credential}},
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package translator
+
+import (
+	"errors"
+	"testing"
+
+	credentialinjectorv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/credential_injector/v3"
+	genericcredentialv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/http/injected_credentials/generic/v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
+)
+
+func newCredentialBackend(name string, credentialInjection *agenticv0alpha0.BackendCredentialInjection) *agenticv0alpha0.XBackend {
+	return &agenticv0alpha0.XBackend{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: agenticv0alpha0.BackendSpec{MCP: agenticv0alpha0.MCPBackend{
+			Hostname:            ptr.To("mcp.example.com"),
+			Port:                443,
+			CredentialInjection: credentialInjection,
+		}},
+	}
+}
+
+func TestBackendCredentialSecret(t *testing.T) {
+	secretIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	_ = secretIndexer.Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api-key"},
+		Data: map[string][]byte{
+			defaultCredentialKey: []byte("Bearer token"),
+			"x-api-key":          []byte("key"),
+		},
+	})
+	tr := &Translator{secretLister: corev1listers.NewSecretLister(secretIndexer)}
+	secretRef := func(kind, name string) gatewayv1.LocalObjectReference {
+		return gatewayv1.LocalObjectReference{Kind: gatewayv1.Kind(kind), Name: gatewayv1.ObjectName(name)}
+	}
+
+	tests := []struct {
+		name              string
+		backend           *agenticv0alpha0.XBackend
+		wantCredential    string
+		wantControllerErr bool
+	}{
+		{
+			name:    "no credential injection",
+			backend: newCredentialBackend("backend", nil),
+		},
+		{
+			name:           "default key",
+			backend:        newCredentialBackend("backend", &agenticv0alpha0.BackendCredentialInjection{SecretRef: secretRef("Secret", "api-key")}),
+			wantCredential: "Bearer token",
+		},
+		{
+			name: "custom key",
+			backend: newCredentialBackend("backend", &agenticv0alpha0.BackendCredentialInjection{
+				SecretRef: secretRef("Secret", "api-key"),
+				Key:       "x-api-key",
+				Header:    "X-API-Key",
+			}),
+			wantCredential: "key",
+		},
+		{
+			name:              "missing Secret",
+			backend:           newCredentialBackend("backend", &agenticv0alpha0.BackendCredentialInjection{SecretRef: secretRef("Secret", "missing")}),
+			wantControllerErr: true,
+		},
+		{
+			name: "missing key",
+			backend: newCredentialBackend("backend", &agenticv0alpha0.BackendCredentialInjection{
+				SecretRef: secretRef("Secret", "api-key"),
+				Key:       "missing",
+			}),
+			wantControllerErr: true,
+		},
+		{
+			name:              "ConfigMap",
+			backend:           newCredentialBackend("backend", &agenticv0alpha0.BackendCredentialInjection{SecretRef: secretRef("ConfigMap", "api-key")}),
+			wantControllerErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			secret, err := tr.backendCredentialSecret(tc.backend)
+			if tc.wantControllerErr {
+				var controllerErr *ControllerError
+				if !errors.As(err, &controllerErr) || controllerErr.Reason != string(gatewayv1.RouteReasonBackendNotFound) {
+					t.Fatalf("expected ControllerError with reason %s, got %v", gatewayv1.RouteReasonBackendNotFound, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.wantCredential == "" {
+				if secret != nil {
+					t.Fatalf("expected no secret, got %v", secret)
+				}
+				return
+			}
+			if secret.GetName() != "credential-default-backend" {
+				t.Errorf("expected secret name credential-default-backend, got %s", secret.GetName())
+			}
+			if got := string(secret.GetGenericSecret().GetSecret().GetInlineBytes()); got != tc.wantCredential {
+				t.Errorf("expected credential %q, got %q", tc.wantCredential, got)
+			}
+		})
+	}
+}
+
+func TestBuildCredentialInjectorFilters(t *testing.T) {
+	withCredential := newCredentialBackend("with-credential", &agenticv0alpha0.BackendCredentialInjection{
+		SecretRef: gatewayv1.LocalObjectReference{Kind: "Secret", Name: "api-key"},
+		Header:    "X-API-Key",
+	})
+	withDefaultHeader := newCredentialBackend("default-header", &agenticv0alpha0.BackendCredentialInjection{
+		SecretRef: gatewayv1.LocalObjectReference{Kind: "Secret", Name: "token"},
+	})
+	withoutCredential := newCredentialBackend("without-credential", nil)
+
+	filters, err := buildCredentialInjectorFilters([]*agenticv0alpha0.XBackend{withCredential, withoutCredential, withDefaultHeader})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(filters) != 2 {
+		t.Fatalf("expected 2 filters, got %d", len(filters))
+	}
+
+	wants := []struct {
+		name, secretName, header string
+	}{
+		{"envoy.filters.http.credential_injector.default-default-header", "credential-default-default-header", defaultCredentialHeader},
+		{"envoy.filters.http.credential_injector.default-with-credential", "credential-default-with-credential", "X-API-Key"},
+	}
+	for i, want := range wants {
+		filter := filters[i]
+		if filter.GetName() != want.name {
+			t.Errorf("expected filter %d to be %s, got %s", i, want.name, filter.GetName())
+		}
+		if !filter.GetDisabled() {
+			t.Errorf("expected filter %s to be disabled on the listener", filter.GetName())
+		}
+		credentialInjector := &credentialinjectorv3.CredentialInjector{}
+		if err := filter.GetTypedConfig().UnmarshalTo(credentialInjector); err != nil {
+			t.Fatalf("failed to unmarshal credential_injector config: %v", err)
+		}
+		if !credentialInjector.GetOverwrite() {
+			t.Errorf("expected filter %s to overwrite the header sent by the agent", filter.GetName())
+		}
+		generic := &genericcredentialv3.Generic{}
+		if err := credentialInjector.GetCredential().GetTypedConfig().UnmarshalTo(generic); err != nil {
+			t.Fatalf("failed to unmarshal generic credential config: %v", err)
+		}
+		if generic.GetCredential().GetName() != want.secretName || generic.GetCredential().GetSdsConfig().GetAds() == nil {
+			t.Errorf("expected filter %s to get secret %s over ADS, got %v", filter.GetName(), want.secretName, generic.GetCredential())
+		}
+		if generic.GetHeader() != want.header {
+			t.Errorf("expected filter %s to set header %s, got %s", filter.GetName(), want.header, generic.GetHeader())
+		}
+	}
+
+	name, config, err := buildPerClusterCredentialInjectorConfig(withCredential)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != wants[1].name || config == nil {
+		t.Errorf("expected the cluster of %s to enable filter %s, got %q", withCredential.Name, wants[1].name, name)
+	}
+	if _, config, _ := buildPerClusterCredentialInjectorConfig(withoutCredential); config != nil {
+		t.Errorf("expected no per-cluster config for %s, got %v", withoutCredential.Name, config)
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package translator
+
+import (
+	"encoding/hex"
+	"time"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"k8s.io/klog/v2"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
+)
+
+const (
+	// The defaults of the active health checks of an XBackend, see agenticv0alpha0.BackendHealthCheck.
+	defaultHealthCheckInterval           = 10 * time.Second
+	defaultHealthCheckTimeout            = 1 * time.Second
+	defaultHealthCheckHealthyThreshold   = 1
+	defaultHealthCheckUnhealthyThreshold = 3
+
+	// The defaults of the outlier detection of the clusters, see agenticv0alpha0.BackendOutlierDetection.
+	defaultOutlierConsecutiveGatewayErrors = 5
+	defaultOutlierInterval                 = 10 * time.Second
+	defaultOutlierBaseEjectionTime         = 30 * time.Second
+	defaultOutlierMaxEjectionPercent       = 50
+
+	// defaultMCPPath is the default of the Path of an XBackend, see agenticv0alpha0.MCPBackend.
+	defaultMCPPath = "/mcp"
+	// mcpPingRequest is the JSON-RPC request of the MCPPing health checks.
+	mcpPingRequest = `{"jsonrpc":"2.0","id":"health-check","method":"ping"}`
+	// mcpPingResult is the part of the response to mcpPingRequest that a healthy MCP server always returns.
+	mcpPingResult = `"result"`
+)
+
+// buildHealthChecks returns the active health checks of the cluster of an XBackend, or nil if the XBackend is not
+// actively health checked.
+func buildHealthChecks(backend *agenticv0alpha0.XBackend) []*corev3.HealthCheck {
+	config := backend.Spec.MCP.HealthCheck
+	if config == nil {
+		return nil
+	}
+
+	httpHealthCheck := &corev3.HealthCheck_HttpHealthCheck{
+		Host: backendHost(backend),
+	}
+	if config.Type == agenticv0alpha0.BackendHealthCheckTypeHTTP {
+		httpHealthCheck.Path = *config.Path
+	} else {
+		// A ping is a regular MCP request, which the Streamable HTTP transport POSTs to the MCP path.
+		httpHealthCheck.Path = backend.Spec.MCP.Path
+		if httpHealthCheck.Path == "" {
+			httpHealthCheck.Path = defaultMCPPath
+		}
+		httpHealthCheck.Method = corev3.RequestMethod_POST
+		httpHealthCheck.RequestHeadersToAdd = []*corev3.HeaderValueOption{
+			{Header: &corev3.HeaderValue{Key: "content-type", Value: "application/json"}},
+			{Header: &corev3.HeaderValue{Key: "accept", Value: "application/json, text/event-stream"}},
+		}
+		httpHealthCheck.Send = &corev3.HealthCheck_Payload{
+			Payload: &corev3.HealthCheck_Payload_Text{Text: hex.EncodeToString([]byte(mcpPingRequest))},
+		}
+		httpHealthCheck.Receive = []*corev3.HealthCheck_Payload{{
+			Payload: &corev3.HealthCheck_Payload_Text{Text: hex.EncodeToString([]byte(mcpPingResult))},
+		}}
+	}
+
+	return []*corev3.HealthCheck{{
+		Interval:           parseDurationOrDefault("health check interval", config.Interval, defaultHealthCheckInterval),
+		Timeout:            parseDurationOrDefault("health check timeout", config.Timeout, defaultHealthCheckTimeout),
+		HealthyThreshold:   wrapperspb.UInt32(uint32OrDefault(config.HealthyThreshold, defaultHealthCheckHealthyThreshold)),
+		UnhealthyThreshold: wrapperspb.UInt32(uint32OrDefault(config.UnhealthyThreshold, defaultHealthCheckUnhealthyThreshold)),
+		HealthChecker: &corev3.HealthCheck_HttpHealthCheck_{
+			HttpHealthCheck: httpHealthCheck,
+		},
+	}}
+}
+
+// buildOutlierDetection returns the outlier detection of a cluster, ejecting the endpoints returning consecutive
+// gateway errors. The defaults apply when config is nil, which is always the case for the clusters of Services.
+func buildOutlierDetection(config *agenticv0alpha0.BackendOutlierDetection) *clusterv3.OutlierDetection {
+	if config == nil {
+		config = &agenticv0alpha0.BackendOutlierDetection{}
+	}
+	return &clusterv3.OutlierDetection{
+		ConsecutiveGatewayFailure: wrapperspb.UInt32(uint32OrDefault(config.ConsecutiveGatewayErrors, defaultOutlierConsecutiveGatewayErrors)),
+		Interval:                  parseDurationOrDefault("outlier detection interval", config.Interval, defaultOutlierInterval),
+		BaseEjectionTime:          parseDurationOrDefault("outlier detection base ejection time", config.BaseEjectionTime, defaultOutlierBaseEjectionTime),
+		MaxEjectionPercent:        wrapperspb.UInt32(uint32OrDefault(config.MaxEjectionPercent, defaultOutlierMaxEjectionPercent)),
+		// Only consecutive gateway errors eject endpoints: the 500 responses of an MCP server are application errors,
+		// such as a failed tool call, rather than a sign of an unhealthy endpoint.
+		EnforcingConsecutiveGatewayFailure: wrapperspb.UInt32(100),
+		EnforcingConsecutive_5Xx:           wrapperspb.UInt32(0),
+		EnforcingSuccessRate:               wrapperspb.UInt32(0),
+	}
+}
+
+// parseDurationOrDefault converts an optional duration of the API, or returns the default duration if it is not set
+// or invalid.
+func parseDurationOrDefault(field string, duration *gatewayv1.Duration, defaultDuration time.Duration) *durationpb.Duration {
+	if duration == nil {
+		return durationpb.New(defaultDuration)
+	}
+	parsed, err := time.ParseDuration(string(*duration))
+	if err != nil {
+		klog.Errorf("Ignoring invalid %s %q: %v", field, *duration, err)
+		return durationpb.New(defaultDuration)
+	}
+	return durationpb.New(parsed)
+}
+
+// uint32OrDefault returns the value of an optional non-negative integer of the API, or the default value if it is
+// not set.
+func uint32OrDefault(value *int32, defaultValue uint32) uint32 {
+	if value == nil || *value < 0 {
+		return defaultValue
+	}
+	return uint32(*value) //nolint:gosec // G115: value is non-negative
+}
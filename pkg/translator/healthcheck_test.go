@@ -0,0 +1,153 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package translator
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
+)
+
+func newHealthCheckedBackend(healthCheck *agenticv0alpha0.BackendHealthCheck, outlierDetection *agenticv0alpha0.BackendOutlierDetection) *agenticv0alpha0.XBackend {
+	return &agenticv0alpha0.XBackend{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "mcp"},
+		Spec: agenticv0alpha0.BackendSpec{MCP: agenticv0alpha0.MCPBackend{
+			ServiceName:      ptr.To("mcp-server"),
+			Port:             8080,
+			Path:             "/api/mcp",
+			HealthCheck:      healthCheck,
+			OutlierDetection: outlierDetection,
+		}},
+	}
+}
+
+func decodePayload(t *testing.T, payload *corev3.HealthCheck_Payload) string {
+	t.Helper()
+	decoded, err := hex.DecodeString(payload.GetText())
+	if err != nil {
+		t.Fatalf("failed to decode health check payload %q: %v", payload.GetText(), err)
+	}
+	return string(decoded)
+}
+
+func TestBuildHealthChecks(t *testing.T) {
+	if healthChecks := buildHealthChecks(newHealthCheckedBackend(nil, nil)); healthChecks != nil {
+		t.Errorf("expected no health checks, got %v", healthChecks)
+	}
+
+	t.Run("MCP ping", func(t *testing.T) {
+		healthChecks := buildHealthChecks(newHealthCheckedBackend(&agenticv0alpha0.BackendHealthCheck{
+			Type: agenticv0alpha0.BackendHealthCheckTypeMCPPing,
+		}, nil))
+		if len(healthChecks) != 1 {
+			t.Fatalf("expected 1 health check, got %d", len(healthChecks))
+		}
+		healthCheck := healthChecks[0]
+		if got := healthCheck.GetInterval().AsDuration(); got != defaultHealthCheckInterval {
+			t.Errorf("expected the default interval %v, got %v", defaultHealthCheckInterval, got)
+		}
+		if got := healthCheck.GetTimeout().AsDuration(); got != defaultHealthCheckTimeout {
+			t.Errorf("expected the default timeout %v, got %v", defaultHealthCheckTimeout, got)
+		}
+		if healthCheck.GetHealthyThreshold().GetValue() != defaultHealthCheckHealthyThreshold || healthCheck.GetUnhealthyThreshold().GetValue() != defaultHealthCheckUnhealthyThreshold {
+			t.Errorf("expected the default thresholds, got %v and %v", healthCheck.GetHealthyThreshold(), healthCheck.GetUnhealthyThreshold())
+		}
+		httpHealthCheck := healthCheck.GetHttpHealthCheck()
+		if httpHealthCheck.GetHost() != "mcp-server.default.svc.cluster.local" || httpHealthCheck.GetPath() != "/api/mcp" {
+			t.Errorf("expected the MCP endpoint mcp-server.default.svc.cluster.local/api/mcp to be checked, got %s%s", httpHealthCheck.GetHost(), httpHealthCheck.GetPath())
+		}
+		if httpHealthCheck.GetMethod() != corev3.RequestMethod_POST {
+			t.Errorf("expected the ping to be POSTed, got %v", httpHealthCheck.GetMethod())
+		}
+		if got := decodePayload(t, httpHealthCheck.GetSend()); got != mcpPingRequest {
+			t.Errorf("expected the ping request %s, got %s", mcpPingRequest, got)
+		}
+		if len(httpHealthCheck.GetReceive()) != 1 || decodePayload(t, httpHealthCheck.GetReceive()[0]) != mcpPingResult {
+			t.Errorf("expected the response to contain %s, got %v", mcpPingResult, httpHealthCheck.GetReceive())
+		}
+	})
+
+	t.Run("HTTP", func(t *testing.T) {
+		healthChecks := buildHealthChecks(newHealthCheckedBackend(&agenticv0alpha0.BackendHealthCheck{
+			Type:               agenticv0alpha0.BackendHealthCheckTypeHTTP,
+			Path:               ptr.To("/healthz"),
+			Interval:           ptr.To(gatewayv1.Duration("5s")),
+			Timeout:            ptr.To(gatewayv1.Duration("500ms")),
+			HealthyThreshold:   ptr.To[int32](2),
+			UnhealthyThreshold: ptr.To[int32](5),
+		}, nil))
+		if len(healthChecks) != 1 {
+			t.Fatalf("expected 1 health check, got %d", len(healthChecks))
+		}
+		healthCheck := healthChecks[0]
+		if healthCheck.GetInterval().AsDuration() != 5*time.Second || healthCheck.GetTimeout().AsDuration() != 500*time.Millisecond {
+			t.Errorf("expected interval 5s and timeout 500ms, got %v and %v", healthCheck.GetInterval().AsDuration(), healthCheck.GetTimeout().AsDuration())
+		}
+		if healthCheck.GetHealthyThreshold().GetValue() != 2 || healthCheck.GetUnhealthyThreshold().GetValue() != 5 {
+			t.Errorf("expected thresholds 2 and 5, got %v and %v", healthCheck.GetHealthyThreshold(), healthCheck.GetUnhealthyThreshold())
+		}
+		httpHealthCheck := healthCheck.GetHttpHealthCheck()
+		if httpHealthCheck.GetPath() != "/healthz" || httpHealthCheck.GetMethod() != corev3.RequestMethod_METHOD_UNSPECIFIED || httpHealthCheck.GetSend() != nil {
+			t.Errorf("expected a GET request to /healthz, got %v", httpHealthCheck)
+		}
+	})
+}
+
+func TestBuildOutlierDetection(t *testing.T) {
+	backend := newHealthCheckedBackend(nil, &agenticv0alpha0.BackendOutlierDetection{
+		ConsecutiveGatewayErrors: ptr.To[int32](3),
+		BaseEjectionTime:         ptr.To(gatewayv1.Duration("1m")),
+		MaxEjectionPercent:       ptr.To[int32](100),
+	})
+	cluster, err := convertBackendToCluster(backend, nil)
+	if err != nil {
+		t.Fatalf("convertBackendToCluster: %v", err)
+	}
+	outlierDetection := cluster.GetOutlierDetection()
+	if outlierDetection.GetConsecutiveGatewayFailure().GetValue() != 3 || outlierDetection.GetBaseEjectionTime().AsDuration() != time.Minute || outlierDetection.GetMaxEjectionPercent().GetValue() != 100 {
+		t.Errorf("expected the outlier detection of the XBackend, got %v", outlierDetection)
+	}
+	if got := outlierDetection.GetInterval().AsDuration(); got != defaultOutlierInterval {
+		t.Errorf("expected the default interval %v, got %v", defaultOutlierInterval, got)
+	}
+
+	// The clusters of Services and of XBackends without outlier detection eject the endpoints returning consecutive
+	// gateway errors, but not 500 responses.
+	serviceCluster := convertServiceRefToCluster("default", "mcp-server", 8080)
+	backendCluster, err := convertBackendToCluster(newHealthCheckedBackend(nil, nil), nil)
+	if err != nil {
+		t.Fatalf("convertBackendToCluster: %v", err)
+	}
+	for _, cluster := range []*clusterv3.Cluster{serviceCluster, backendCluster} {
+		outlierDetection := cluster.GetOutlierDetection()
+		if outlierDetection.GetConsecutiveGatewayFailure().GetValue() != defaultOutlierConsecutiveGatewayErrors || outlierDetection.GetEnforcingConsecutiveGatewayFailure().GetValue() != 100 {
+			t.Errorf("expected cluster %s to eject the endpoints after %d consecutive gateway errors, got %v", cluster.GetName(), defaultOutlierConsecutiveGatewayErrors, outlierDetection)
+		}
+		if outlierDetection.GetEnforcingConsecutive_5Xx() == nil || outlierDetection.GetEnforcingConsecutive_5Xx().GetValue() != 0 {
+			t.Errorf("expected cluster %s not to eject the endpoints returning 500 responses, got %v", cluster.GetName(), outlierDetection)
+		}
+	}
+}
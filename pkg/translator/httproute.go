@@ -24,6 +24,7 @@ import (
 
 	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	ext_authzv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
 	rbacv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/rbac/v3"
 	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
@@ -41,8 +42,10 @@ import (
 
 // translateHTTPRouteToEnvoyRoutes translates a full HTTPRoute into a slice of Envoy Routes.
 // It now correctly handles RequestHeaderModifier filters.
+// The AccessPolicies targeting the Gateway listener are used to filter tools/list responses.
 func (t *Translator) translateHTTPRouteToEnvoyRoutes(
 	httpRoute *gatewayv1.HTTPRoute,
+	gatewayAccessPolicies []*agenticv0alpha0.XAccessPolicy,
 ) ([]*routev3.Route, []*routeBackend, metav1.Condition) {
 	var envoyRoutes []*routev3.Route
 	var allValidBackends []*routeBackend
@@ -106,6 +109,7 @@ func (t *Translator) translateHTTPRouteToEnvoyRoutes(
 				routeAction, validBackends, err := t.buildHTTPRouteAction(
 					httpRoute.Namespace,
 					rule.BackendRefs,
+					gatewayAccessPolicies,
 				)
 				var controllerErr *ControllerError
 				if errors.As(err, &controllerErr) {
@@ -267,6 +271,7 @@ func processRequestHeaderModifierFilter(f *gatewayv1.HTTPHeaderFilter) ([]*corev
 func (t *Translator) buildHTTPRouteAction(
 	namespace string,
 	backendRefs []gatewayv1.HTTPBackendRef,
+	gatewayAccessPolicies []*agenticv0alpha0.XAccessPolicy,
 ) (*routev3.RouteAction, []*routeBackend, error) {
 	weightedClusters := &routev3.WeightedCluster{}
 	var validBackends []*routeBackend
@@ -298,12 +303,43 @@ func (t *Translator) buildHTTPRouteAction(
 		}
 
 		if rb.XBackend() != nil {
-			clusterWeight.TypedPerFilterConfig, err = t.buildPerClusterRBACFilterConfig(t.accessPolicyLister, rb.XBackend())
+			clusterWeight.TypedPerFilterConfig, err = t.buildPerClusterRBACFilterConfig(t.accessPolicyLister, rb.XBackend(), gatewayAccessPolicies)
 			if err != nil {
 				klog.Errorf("Failed to build per-cluster RBAC config for backend %s: %v", rb.ClusterName(), err)
 			}
 		}
-		// TODO(guicassolato): Add per-route ext_authz config - to populate context_metadata with info about which AccessPolicy rule matched
+		extAuthzFilterConfig, err := t.buildPerClusterExtAuthzFilterConfig(rb.XBackend(), gatewayAccessPolicies)
+		if err != nil {
+			klog.Errorf("Failed to build per-cluster ext_authz config for backend %s: %v", rb.ClusterName(), err)
+		}
+		for name, config := range extAuthzFilterConfig {
+			if clusterWeight.TypedPerFilterConfig == nil {
+				clusterWeight.TypedPerFilterConfig = make(map[string]*anypb.Any)
+			}
+			clusterWeight.TypedPerFilterConfig[name] = config
+		}
+		// The credential of the XBackend is only injected into the requests routed to its cluster.
+		credentialInjectorFilterName, credentialInjectorConfig, err := buildPerClusterCredentialInjectorConfig(rb.XBackend())
+		if err != nil {
+			klog.Errorf("Failed to build per-cluster credential_injector config for backend %s: %v", rb.ClusterName(), err)
+		}
+		if credentialInjectorConfig != nil {
+			if clusterWeight.TypedPerFilterConfig == nil {
+				clusterWeight.TypedPerFilterConfig = make(map[string]*anypb.Any)
+			}
+			clusterWeight.TypedPerFilterConfig[credentialInjectorFilterName] = credentialInjectorConfig
+		}
+		// So is the token the identity of the calling agent is exchanged for.
+		tokenExchangeConfig, err := buildPerClusterTokenExchangeConfig(rb.XBackend())
+		if err != nil {
+			klog.Errorf("Failed to build per-cluster token exchange config for backend %s: %v", rb.ClusterName(), err)
+		}
+		if tokenExchangeConfig != nil {
+			if clusterWeight.TypedPerFilterConfig == nil {
+				clusterWeight.TypedPerFilterConfig = make(map[string]*anypb.Any)
+			}
+			clusterWeight.TypedPerFilterConfig[tokenExchangeFilterName] = tokenExchangeConfig
+		}
 		weightedClusters.Clusters = append(weightedClusters.Clusters, clusterWeight)
 	}
 
@@ -316,8 +352,9 @@ func (t *Translator) buildHTTPRouteAction(
 	return action, validBackends, nil
 }
 
-// buildPerClusterRBACFilterConfig creates the TypedPerFilterConfig for a cluster, specifically for the RBAC filter.
-func (t *Translator) buildPerClusterRBACFilterConfig(accessPolicyLister agenticlisters.XAccessPolicyLister, backend *agenticv0alpha0.XBackend) (map[string]*anypb.Any, error) {
+// buildPerClusterRBACFilterConfig creates the TypedPerFilterConfig for a cluster, specifically for the RBAC filters
+// and the tools/list filter derived from the same AccessPolicies and from the AccessPolicies targeting the Gateway listener.
+func (t *Translator) buildPerClusterRBACFilterConfig(accessPolicyLister agenticlisters.XAccessPolicyLister, backend *agenticv0alpha0.XBackend, gatewayAccessPolicies []*agenticv0alpha0.XAccessPolicy) (map[string]*anypb.Any, error) {
 	perFilterConfig := make(map[string]*anypb.Any)
 
 	// Envoy's per-cluster configuration requires an RBACPerRoute message containing
@@ -338,9 +375,100 @@ func (t *Translator) buildPerClusterRBACFilterConfig(accessPolicyLister agenticl
 
 	perFilterConfig[wellknown.HTTPRoleBasedAccessControl] = rbacAny
 
+	// Deny rules are enforced by a separate RBAC filter evaluated before the one above.
+	denyRBACConfig, err := t.denyRBACConfigFromAccessPolicy(accessPolicyLister, backend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate deny RBAC policies: %w", err)
+	}
+	if denyRBACConfig != nil {
+		denyRBACAny, err := anypb.New(&rbacv3.RBACPerRoute{Rbac: denyRBACConfig})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal deny RBACPerRoute proto: %w", err)
+		}
+		perFilterConfig[denyRBACFilterName] = denyRBACAny
+	}
+
+	// The Allow rules of AccessPolicies in Audit mode are evaluated as shadow rules by another RBAC filter.
+	auditRBACConfig, err := t.auditRBACConfigFromAccessPolicy(accessPolicyLister, backend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate audit RBAC policies: %w", err)
+	}
+	if auditRBACConfig != nil {
+		auditRBACAny, err := anypb.New(&rbacv3.RBACPerRoute{Rbac: auditRBACConfig})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal audit RBACPerRoute proto: %w", err)
+		}
+		perFilterConfig[auditRBACFilterName] = auditRBACAny
+	}
+
+	// The tools/list filter hides the tools that the caller is not authorized to call by the rules above.
+	toolsListAny, err := t.buildPerClusterToolsListFilterConfig(accessPolicyLister, backend, gatewayAccessPolicies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate tools/list filter config: %w", err)
+	}
+	if toolsListAny != nil {
+		perFilterConfig[toolsListFilterName] = toolsListAny
+	}
+
+	return perFilterConfig, nil
+}
+
+// buildPerClusterExtAuthzFilterConfig creates the TypedPerFilterConfig for a cluster of the RBAC filters triggering
+// the ext_authz filters and of the ext_authz filters, from the enforced AccessPolicies targeting its XBackend, if any,
+// and the Gateway listener. It returns nil if none of their rules in force is checked by an external authorizer.
+func (t *Translator) buildPerClusterExtAuthzFilterConfig(backend *agenticv0alpha0.XBackend, gatewayAccessPolicies []*agenticv0alpha0.XAccessPolicy) (map[string]*anypb.Any, error) {
+	enforced, _ := splitAccessPoliciesByMode(gatewayAccessPolicies)
+	if backend != nil {
+		accessPolicies, err := AccessPoliciesForBackend(backend, t.accessPolicyLister)
+		if err != nil {
+			return nil, err
+		}
+		enforcedBackend, _ := splitAccessPoliciesByMode(accessPolicies)
+		enforced = append(enforcedBackend, enforced...)
+	}
+
+	rulesByHash := t.externalAuthzRules(enforced)
+	if len(rulesByHash) == 0 {
+		return nil, nil
+	}
+	perFilterConfig := make(map[string]*anypb.Any, 2*len(rulesByHash))
+	for hash, rules := range rulesByHash {
+		rbacAny, err := anypb.New(&rbacv3.RBACPerRoute{Rbac: t.externalAuthzTriggerRBACConfig(hash, rules)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal ext_authz RBACPerRoute proto: %w", err)
+		}
+		perFilterConfig[externalAuthzRBACFilterName(hash)] = rbacAny
+
+		extAuthzAny, err := anypb.New(&ext_authzv3.ExtAuthzPerRoute{
+			Override: &ext_authzv3.ExtAuthzPerRoute_CheckSettings{
+				CheckSettings: &ext_authzv3.CheckSettings{ContextExtensions: externalAuthzContextExtensions(backend, rules)},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal ExtAuthzPerRoute proto: %w", err)
+		}
+		perFilterConfig[externalAuthzFilterName(hash)] = extAuthzAny
+	}
 	return perFilterConfig, nil
 }
 
+// externalAuthzContextExtensions returns the context extensions sent to an external authorizer for the requests to
+// the given XBackend, if any, checked by the given rules. The AccessPolicy and the rule are only set when the
+// authorizer checks a single rule of the cluster: otherwise, the rule that matched the request is the effective
+// shadow policy ID recorded by the RBAC filter triggering the ext_authz filter, see externalAuthzTriggerRBACConfig.
+// The tool called is a per-request value, so it is only in the metadata of the MCP filter, see buildExtAuthzConfig.
+func externalAuthzContextExtensions(backend *agenticv0alpha0.XBackend, rules []externalAuthzRule) map[string]string {
+	contextExtensions := make(map[string]string)
+	if backend != nil {
+		contextExtensions[extAuthzContextExtensionXBackend] = fmt.Sprintf("%s/%s", backend.Namespace, backend.Name)
+	}
+	if len(rules) == 1 {
+		contextExtensions[extAuthzContextExtensionAccessPolicy] = fmt.Sprintf("%s/%s", rules[0].accessPolicy.Namespace, rules[0].accessPolicy.Name)
+		contextExtensions[extAuthzContextExtensionAccessRule] = rules[0].rule.Name
+	}
+	return contextExtensions
+}
+
 // translateHTTPRouteMatch translates a Gateway API HTTPRouteMatch into an Envoy RouteMatch.
 // It returns the result and a condition indicating success or failure.
 func translateHTTPRouteMatch(match gatewayv1.HTTPRouteMatch, generation int64) (*routev3.RouteMatch, metav1.Condition) {
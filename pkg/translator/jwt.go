@@ -0,0 +1,391 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package translator
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	rbacconfigv3 "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	jwtauthnv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/jwt_authn/v3"
+	hcm "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	envoyproxytypes "github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
+	agenticlisters "sigs.k8s.io/kube-agentic-networking/k8s/client/listers/api/v0alpha0"
+	"sigs.k8s.io/kube-agentic-networking/pkg/constants"
+)
+
+const (
+	// defaultJWKSPath is the path the JSON Web Key Set is fetched from when JWKS.Path is not set.
+	defaultJWKSPath = "/.well-known/jwks.json"
+
+	jwtProviderNamePrefix = "oidc-"
+)
+
+// jwtClaim is a claim the payload of a verified token must carry, with its accepted values.
+type jwtClaim struct {
+	name   string
+	values []string
+}
+
+// sourceOIDC returns the OIDC issuer of a source, or nil if the source has another type.
+func sourceOIDC(source agenticv0alpha0.Source) *agenticv0alpha0.AuthorizationSourceOIDC {
+	if source.Type != agenticv0alpha0.AuthorizationSourceTypeOIDC {
+		return nil
+	}
+	return source.OIDC
+}
+
+// ruleOIDCSources returns the OIDC issuers of the sources of an AccessRule.
+func ruleOIDCSources(rule agenticv0alpha0.AccessRule) []*agenticv0alpha0.AuthorizationSourceOIDC {
+	var oidcs []*agenticv0alpha0.AuthorizationSourceOIDC
+	for _, source := range RuleSources(rule) {
+		if oidc := sourceOIDC(source); oidc != nil {
+			oidcs = append(oidcs, oidc)
+		}
+	}
+	return oidcs
+}
+
+// accessPolicyOIDCSources returns the OIDC issuers of the sources of all the rules of an AccessPolicy.
+func accessPolicyOIDCSources(accessPolicy *agenticv0alpha0.XAccessPolicy) []*agenticv0alpha0.AuthorizationSourceOIDC {
+	var oidcs []*agenticv0alpha0.AuthorizationSourceOIDC
+	for _, rule := range accessPolicy.Spec.Rules {
+		oidcs = append(oidcs, ruleOIDCSources(rule)...)
+	}
+	return oidcs
+}
+
+// jwtProviderName returns the name of the jwt_authn provider verifying the tokens of an OIDC source.
+// The payloads of the verified tokens are stored in the dynamic metadata of the jwt_authn filter under the
+// same name. Sources sharing the same issuer and JWKS share the same provider, whatever their namespace, since
+// only the first provider verifying a token stores its payload. The audiences and claims of the sources are
+// matched by the RBAC principals.
+func jwtProviderName(namespace string, oidc *agenticv0alpha0.AuthorizationSourceOIDC) (string, error) {
+	jwks := *oidc.JWKS.DeepCopy()
+	if backendRef := jwks.BackendRef; backendRef != nil {
+		// Resolve the defaults of the reference, so that the sources referencing the same backend from
+		// different namespaces share the same provider.
+		backendRef.Namespace = ptr.To(gatewayv1.Namespace(backendRefNamespace(*backendRef, namespace)))
+		backendRef.Group = ptr.To(ptr.Deref(backendRef.Group, ""))
+		backendRef.Kind = ptr.To(ptr.Deref(backendRef.Kind, "Service"))
+		if jwks.Path == "" {
+			jwks.Path = defaultJWKSPath
+		}
+	}
+	j, err := json.Marshal(struct {
+		Issuer string               `json:"issuer"`
+		JWKS   agenticv0alpha0.JWKS `json:"jwks"`
+	}{oidc.Issuer, jwks})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal OIDC source for provider name generation: %w", err)
+	}
+	return fmt.Sprintf("%s%x", jwtProviderNamePrefix, sha256.Sum256(j)), nil
+}
+
+// oidcClaims returns the claims the verified tokens of an OIDC source must carry to match.
+func oidcClaims(oidc *agenticv0alpha0.AuthorizationSourceOIDC) []jwtClaim {
+	claims := []jwtClaim{{name: "iss", values: []string{oidc.Issuer}}}
+	if len(oidc.Audiences) > 0 {
+		claims = append(claims, jwtClaim{name: "aud", values: oidc.Audiences})
+	}
+	for _, claim := range oidc.Claims {
+		claims = append(claims, jwtClaim{name: claim.Name, values: claim.Values})
+	}
+	return claims
+}
+
+// buildOIDCPrincipal builds the RBAC principal matching the requests carrying a token verified by the provider
+// of the given OIDC source, whose payload carries all the claims of the source.
+func buildOIDCPrincipal(namespace string, oidc *agenticv0alpha0.AuthorizationSourceOIDC) (*rbacconfigv3.Principal, error) {
+	providerName, err := jwtProviderName(namespace, oidc)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []*rbacconfigv3.Principal
+	for _, claim := range oidcClaims(oidc) {
+		var matchers []*matcherv3.ValueMatcher
+		for _, value := range claim.values {
+			// Claims such as aud can either be a string or a list of strings.
+			matchers = append(matchers, buildExactValueMatcher(value), &matcherv3.ValueMatcher{
+				MatchPattern: &matcherv3.ValueMatcher_ListMatch{
+					ListMatch: &matcherv3.ListMatcher{
+						MatchPattern: &matcherv3.ListMatcher_OneOf{OneOf: buildExactValueMatcher(value)},
+					},
+				},
+			})
+		}
+		ids = append(ids, &rbacconfigv3.Principal{
+			Identifier: &rbacconfigv3.Principal_SourcedMetadata{
+				SourcedMetadata: &rbacconfigv3.SourcedMetadata{
+					MetadataMatcher: &matcherv3.MetadataMatcher{
+						Filter: wellknownJWTAuthnFilter,
+						Path: []*matcherv3.MetadataMatcher_PathSegment{
+							{Segment: &matcherv3.MetadataMatcher_PathSegment_Key{Key: providerName}},
+							{Segment: &matcherv3.MetadataMatcher_PathSegment_Key{Key: claim.name}},
+						},
+						Value: buildOrValueMatcher(matchers),
+					},
+				},
+			},
+		})
+	}
+
+	return &rbacconfigv3.Principal{
+		Identifier: &rbacconfigv3.Principal_AndIds{
+			AndIds: &rbacconfigv3.Principal_Set{Ids: ids},
+		},
+	}, nil
+}
+
+// buildJWTAuthnFilter builds the jwt_authn filter verifying the tokens of the OIDC sources of all AccessPolicies.
+// It returns nil if no AccessPolicy has an OIDC source.
+//
+// Requests without a token, or with a token that cannot be verified, are not rejected by the filter. They just
+// do not match the principals of the OIDC sources, so that they can still be allowed by other rules.
+func (t *Translator) buildJWTAuthnFilter(accessPolicyLister agenticlisters.XAccessPolicyLister) (*hcm.HttpFilter, error) {
+	providers, err := t.buildJWTProviders(accessPolicyLister)
+	if err != nil || len(providers) == 0 {
+		return nil, err
+	}
+
+	var requirements []*jwtauthnv3.JwtRequirement
+	for _, name := range slices.Sorted(maps.Keys(providers)) {
+		requirements = append(requirements, &jwtauthnv3.JwtRequirement{
+			RequiresType: &jwtauthnv3.JwtRequirement_ProviderName{ProviderName: name},
+		})
+	}
+	requirements = append(requirements, &jwtauthnv3.JwtRequirement{
+		RequiresType: &jwtauthnv3.JwtRequirement_AllowMissingOrFailed{AllowMissingOrFailed: &emptypb.Empty{}},
+	})
+
+	jwtAuthnProto := &jwtauthnv3.JwtAuthentication{
+		Providers: providers,
+		Rules: []*jwtauthnv3.RequirementRule{
+			{
+				Match: &routev3.RouteMatch{
+					PathSpecifier: &routev3.RouteMatch_Prefix{Prefix: "/"},
+				},
+				RequirementType: &jwtauthnv3.RequirementRule_Requires{
+					Requires: &jwtauthnv3.JwtRequirement{
+						RequiresType: &jwtauthnv3.JwtRequirement_RequiresAny{
+							RequiresAny: &jwtauthnv3.JwtRequirementOrList{Requirements: requirements},
+						},
+					},
+				},
+			},
+		},
+	}
+	jwtAuthnAny, err := anypb.New(jwtAuthnProto)
+	if err != nil {
+		klog.Errorf("Failed to marshal jwt_authn config: %v", err)
+		return nil, err
+	}
+
+	return &hcm.HttpFilter{
+		Name: wellknownJWTAuthnFilter,
+		ConfigType: &hcm.HttpFilter_TypedConfig{
+			TypedConfig: jwtAuthnAny,
+		},
+	}, nil
+}
+
+// buildJWTProviders builds the jwt_authn providers of the OIDC sources of all AccessPolicies, keyed by name.
+func (t *Translator) buildJWTProviders(accessPolicyLister agenticlisters.XAccessPolicyLister) (map[string]*jwtauthnv3.JwtProvider, error) {
+	accessPolicies, err := accessPolicyLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list AccessPolicies: %w", err)
+	}
+
+	providers := make(map[string]*jwtauthnv3.JwtProvider)
+	for _, ap := range accessPolicies {
+		for _, oidc := range accessPolicyOIDCSources(ap) {
+			name, err := jwtProviderName(ap.Namespace, oidc)
+			if err != nil {
+				klog.Error(err)
+				continue
+			}
+			if _, exists := providers[name]; exists {
+				continue
+			}
+			provider := &jwtauthnv3.JwtProvider{
+				Issuer:            oidc.Issuer,
+				PayloadInMetadata: name,
+			}
+			if inline := oidc.JWKS.Inline; inline != nil {
+				provider.JwksSourceSpecifier = &jwtauthnv3.JwtProvider_LocalJwks{
+					LocalJwks: &corev3.DataSource{
+						Specifier: &corev3.DataSource_InlineString{InlineString: *inline},
+					},
+				}
+			} else if backendRef := oidc.JWKS.BackendRef; backendRef != nil {
+				uri, clusterName, err := t.jwksURIAndCluster(*backendRef, ap.Namespace, oidc.JWKS.Path)
+				if err != nil {
+					// Without a provider, the principals of the source never match.
+					klog.Errorf("Failed to resolve JWKS backend of AccessPolicy %s/%s: %v", ap.Namespace, ap.Name, err)
+					continue
+				}
+				provider.JwksSourceSpecifier = &jwtauthnv3.JwtProvider_RemoteJwks{
+					RemoteJwks: &jwtauthnv3.RemoteJwks{
+						HttpUri: &corev3.HttpUri{
+							Uri: uri,
+							HttpUpstreamType: &corev3.HttpUri_Cluster{
+								Cluster: clusterName,
+							},
+							Timeout: durationpb.New(uriTimeout),
+						},
+						// Fetch the keys when the listener is created rather than on the first request.
+						AsyncFetch: &jwtauthnv3.JwksAsyncFetch{},
+					},
+				}
+			} else {
+				continue
+			}
+			providers[name] = provider
+		}
+	}
+	return providers, nil
+}
+
+// jwksURIAndCluster returns the URI the JSON Web Key Set is fetched from and the name of the cluster
+// of the referenced Service or XBackend.
+func (t *Translator) jwksURIAndCluster(backendRef gatewayv1.BackendObjectReference, defaultNamespace, path string) (string, string, error) {
+	if path == "" {
+		path = defaultJWKSPath
+	}
+	if !isXBackendObjectRef(backendRef) {
+		if backendRef.Port == nil {
+			return "", "", fmt.Errorf("port must be specified for Service %s", backendRef.Name)
+		}
+		uri := fmt.Sprintf("http://%s:%d%s", fqdnFromBackendRef(backendRef, defaultNamespace), *backendRef.Port, path)
+		return uri, clusterNameForBackendRefAndProtocol(backendRef, defaultNamespace, string(gatewayv1.HTTPRouteExternalAuthHTTPProtocol)), nil
+	}
+
+	backend, err := t.jwksBackend(backendRef, defaultNamespace)
+	if err != nil {
+		return "", "", err
+	}
+	clusterName := fmt.Sprintf(constants.ClusterNameFormat, backend.Namespace, backend.Name)
+	return fmt.Sprintf("http://%s.%s.svc.cluster.local:%d%s", *backend.Spec.MCP.ServiceName, backend.Namespace, backend.Spec.MCP.Port, path), clusterName, nil
+}
+
+// jwksBackend returns the XBackend serving a JSON Web Key Set. External XBackends are refused, since the
+// certificates of their servers are not verified and the keys fetched from them could not be trusted.
+func (t *Translator) jwksBackend(backendRef gatewayv1.BackendObjectReference, defaultNamespace string) (*agenticv0alpha0.XBackend, error) {
+	if t.backendLister == nil {
+		return nil, fmt.Errorf("failed to get XBackend %s: no XBackend lister", backendRef.Name)
+	}
+	ns := backendRefNamespace(backendRef, defaultNamespace)
+	backend, err := t.backendLister.XBackends(ns).Get(string(backendRef.Name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get XBackend %s/%s: %w", ns, backendRef.Name, err)
+	}
+	if backend.Spec.MCP.Hostname != nil {
+		return nil, fmt.Errorf("XBackend %s/%s has a hostname, JSON Web Key Sets can only be fetched from in-cluster XBackends", ns, backendRef.Name)
+	}
+	return backend, nil
+}
+
+// backendRefNamespace returns the namespace of a backend reference, which defaults to the namespace of the referrer.
+func backendRefNamespace(backendRef gatewayv1.BackendObjectReference, defaultNamespace string) string {
+	if backendRef.Namespace != nil {
+		return string(*backendRef.Namespace)
+	}
+	return defaultNamespace
+}
+
+// isXBackendObjectRef returns true if the BackendObjectReference refers to an XBackend.
+func isXBackendObjectRef(backendRef gatewayv1.BackendObjectReference) bool {
+	return backendRef.Group != nil && string(*backendRef.Group) == agenticv0alpha0.GroupName &&
+		backendRef.Kind != nil && *backendRef.Kind == "XBackend"
+}
+
+// buildJWKSBackendClusters builds the Envoy clusters used to fetch the JSON Web Key Sets referenced by
+// the OIDC sources of all AccessPolicies, along with the SDS secrets holding the client certificates these
+// clusters present to their XBackends.
+func (t *Translator) buildJWKSBackendClusters(accessPolicyLister agenticlisters.XAccessPolicyLister) (map[string]envoyproxytypes.Resource, map[string]*tlsv3.Secret) {
+	clusters := make(map[string]envoyproxytypes.Resource)
+	secrets := make(map[string]*tlsv3.Secret)
+	accessPolicies, err := accessPolicyLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list AccessPolicies: %v", err)
+		return clusters, secrets
+	}
+
+	for _, ap := range accessPolicies {
+		for _, oidc := range accessPolicyOIDCSources(ap) {
+			if oidc.JWKS.BackendRef == nil {
+				continue
+			}
+			backendRef := *oidc.JWKS.BackendRef
+			var cluster *clusterv3.Cluster
+			if isXBackendObjectRef(backendRef) {
+				backend, err := t.jwksBackend(backendRef, ap.Namespace)
+				if err != nil {
+					klog.Errorf("Failed to resolve JWKS backend of AccessPolicy %s/%s: %v", ap.Namespace, ap.Name, err)
+					continue
+				}
+				tlsContext, clientCertificate, err := t.backendTLSContext(backend)
+				if err != nil {
+					klog.Errorf("Failed to resolve the TLS configuration of JWKS backend %s/%s: %v", backend.Namespace, backend.Name, err)
+					continue
+				}
+				cluster, err = convertBackendToCluster(backend, tlsContext)
+				if err != nil {
+					klog.Errorf("Failed to build cluster for JWKS backend %s/%s: %v", backend.Namespace, backend.Name, err)
+					continue
+				}
+				if clientCertificate != nil {
+					secrets[clientCertificate.GetName()] = clientCertificate
+				}
+			} else {
+				if backendRef.Port == nil {
+					continue
+				}
+				clusterName := clusterNameForBackendRefAndProtocol(backendRef, ap.Namespace, string(gatewayv1.HTTPRouteExternalAuthHTTPProtocol))
+				cluster = &clusterv3.Cluster{
+					Name:                 clusterName,
+					ConnectTimeout:       durationpb.New(defaultConnectTimeout),
+					ClusterDiscoveryType: &clusterv3.Cluster_Type{Type: clusterv3.Cluster_STRICT_DNS},
+					//nolint:gosec // G115: port values are within valid uint32 bounds
+					LoadAssignment: createClusterLoadAssignment(clusterName, fqdnFromBackendRef(backendRef, ap.Namespace), uint32(*backendRef.Port)),
+					LbPolicy:       clusterv3.Cluster_ROUND_ROBIN,
+				}
+			}
+			clusters[cluster.GetName()] = cluster
+		}
+	}
+	return clusters, secrets
+}
@@ -0,0 +1,352 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package translator
+
+import (
+	"strings"
+	"testing"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	jwtauthnv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/jwt_authn/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
+	agenticlisters "sigs.k8s.io/kube-agentic-networking/k8s/client/listers/api/v0alpha0"
+)
+
+const testJWKS = `{"keys":[{"kty":"RSA","kid":"test","n":"AQAB","e":"AQAB"}]}`
+
+func TestBuildRulePrincipals_OIDC(t *testing.T) {
+	policy := newTestOIDCAccessPolicy("default", "policy", "my-backend", &agenticv0alpha0.AuthorizationSourceOIDC{
+		Issuer:    "https://issuer.example.com",
+		Audiences: []string{"mcp-gateway"},
+		JWKS:      agenticv0alpha0.JWKS{Inline: ptr.To(testJWKS)},
+		Claims: []agenticv0alpha0.JWTClaimMatch{
+			{Name: "groups", Values: []string{"admins", "developers"}},
+		},
+	})
+
+	tr := &Translator{agenticIdentityTrustDomain: testTrustDomain}
+	principals := tr.buildRulePrincipals(policy, policy.Spec.Rules[0])
+	if len(principals) != 1 {
+		t.Fatalf("expected 1 principal, got %d", len(principals))
+	}
+	if principals[0].GetAny() {
+		t.Fatal("expected OIDC principal not to match any request")
+	}
+
+	providerName, err := jwtProviderName("default", policy.Spec.Rules[0].Source.OIDC)
+	if err != nil {
+		t.Fatalf("jwtProviderName: %v", err)
+	}
+	ids := principals[0].GetAndIds().GetIds()
+	expectedClaims := map[string][]string{
+		"iss":    {"https://issuer.example.com"},
+		"aud":    {"mcp-gateway"},
+		"groups": {"admins", "developers"},
+	}
+	if len(ids) != len(expectedClaims) {
+		t.Fatalf("expected %d claim principals, got %d", len(expectedClaims), len(ids))
+	}
+	for _, id := range ids {
+		matcher := id.GetSourcedMetadata().GetMetadataMatcher()
+		if matcher.GetFilter() != wellknownJWTAuthnFilter {
+			t.Errorf("expected metadata of filter %q, got %q", wellknownJWTAuthnFilter, matcher.GetFilter())
+		}
+		path := matcher.GetPath()
+		if len(path) != 2 || path[0].GetKey() != providerName {
+			t.Errorf("expected metadata path under provider %q, got %v", providerName, path)
+			continue
+		}
+		values, ok := expectedClaims[path[1].GetKey()]
+		if !ok {
+			t.Errorf("unexpected claim %q", path[1].GetKey())
+			continue
+		}
+		// Each value is matched either as a string or as an element of a list.
+		var got []string
+		valueMatchers := matcher.GetValue().GetOrMatch().GetValueMatchers()
+		if valueMatchers == nil {
+			t.Errorf("expected claim %q to be matched as a string or a list", path[1].GetKey())
+			continue
+		}
+		for i := 0; i < len(valueMatchers); i += 2 {
+			got = append(got, valueMatchers[i].GetStringMatch().GetExact())
+			if listValue := valueMatchers[i+1].GetListMatch().GetOneOf().GetStringMatch().GetExact(); listValue != valueMatchers[i].GetStringMatch().GetExact() {
+				t.Errorf("expected claim %q to be matched in a list, got %q", path[1].GetKey(), listValue)
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(values, ",") {
+			t.Errorf("expected claim %q values %v, got %v", path[1].GetKey(), values, got)
+		}
+	}
+}
+
+func TestJWTProviderName(t *testing.T) {
+	oidc := &agenticv0alpha0.AuthorizationSourceOIDC{
+		Issuer: "https://issuer.example.com",
+		JWKS:   agenticv0alpha0.JWKS{Inline: ptr.To(testJWKS)},
+	}
+	name, err := jwtProviderName("default", oidc)
+	if err != nil {
+		t.Fatalf("jwtProviderName: %v", err)
+	}
+
+	withClaims := oidc.DeepCopy()
+	withClaims.Audiences = []string{"mcp-gateway"}
+	withClaims.Claims = []agenticv0alpha0.JWTClaimMatch{{Name: "sub", Values: []string{"agent"}}}
+	if got, _ := jwtProviderName("default", withClaims); got != name {
+		t.Errorf("expected sources differing only by audiences and claims to share provider %q, got %q", name, got)
+	}
+
+	otherIssuer := oidc.DeepCopy()
+	otherIssuer.Issuer = "https://other.example.com"
+	if got, _ := jwtProviderName("default", otherIssuer); got == name {
+		t.Errorf("expected sources with different issuers to have different providers")
+	}
+	if got, _ := jwtProviderName("other", oidc); got != name {
+		t.Errorf("expected sources with the same inline JWKS in different namespaces to share provider %q, got %q", name, got)
+	}
+
+	remote := &agenticv0alpha0.AuthorizationSourceOIDC{
+		Issuer: "https://issuer.example.com",
+		JWKS: agenticv0alpha0.JWKS{
+			BackendRef: &gwapiv1.BackendObjectReference{Name: "keycloak", Port: ptr.To(gwapiv1.PortNumber(8080))},
+		},
+	}
+	remoteName, err := jwtProviderName("default", remote)
+	if err != nil {
+		t.Fatalf("jwtProviderName: %v", err)
+	}
+	if got, _ := jwtProviderName("other", remote); got == remoteName {
+		t.Errorf("expected sources referencing Services of different namespaces to have different providers")
+	}
+	explicit := remote.DeepCopy()
+	explicit.JWKS.BackendRef.Namespace = ptr.To(gwapiv1.Namespace("default"))
+	explicit.JWKS.BackendRef.Group = ptr.To(gwapiv1.Group(""))
+	explicit.JWKS.BackendRef.Kind = ptr.To(gwapiv1.Kind("Service"))
+	explicit.JWKS.Path = defaultJWKSPath
+	if got, _ := jwtProviderName("other", explicit); got != remoteName {
+		t.Errorf("expected sources referencing the same Service from different namespaces to share provider %q, got %q", remoteName, got)
+	}
+}
+
+func TestBuildJWTAuthnFilter(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+	inline := newTestOIDCAccessPolicy("default", "inline", "my-backend", &agenticv0alpha0.AuthorizationSourceOIDC{
+		Issuer: "https://inline.example.com",
+		JWKS:   agenticv0alpha0.JWKS{Inline: ptr.To(testJWKS)},
+	})
+	// A second rule sharing the same issuer and JWKS shares the same provider.
+	inline.Spec.Rules = append(inline.Spec.Rules, agenticv0alpha0.AccessRule{
+		Name:   "same-issuer",
+		Source: inline.Spec.Rules[0].Source.DeepCopy(),
+	})
+	inline.Spec.Rules[1].Source.OIDC.Audiences = []string{"mcp-gateway"}
+	remote := newTestOIDCAccessPolicy("default", "remote", "my-backend", &agenticv0alpha0.AuthorizationSourceOIDC{
+		Issuer: "https://remote.example.com",
+		JWKS: agenticv0alpha0.JWKS{
+			BackendRef: &gwapiv1.BackendObjectReference{
+				Name:      "idp",
+				Namespace: ptr.To(gwapiv1.Namespace("auth")),
+				Port:      ptr.To(gwapiv1.PortNumber(8080)),
+			},
+			Path: "/keys",
+		},
+	})
+	spiffe := newTestAccessPolicy("default", "spiffe", "my-backend", "spiffe://example.com/ns/default/sa/agent")
+	for _, policy := range []*agenticv0alpha0.XAccessPolicy{inline, remote, spiffe} {
+		if err := indexer.Add(policy); err != nil {
+			t.Fatalf("indexer.Add: %v", err)
+		}
+	}
+
+	tr := &Translator{}
+	filter, err := tr.buildJWTAuthnFilter(agenticlisters.NewXAccessPolicyLister(indexer))
+	if err != nil {
+		t.Fatalf("buildJWTAuthnFilter: %v", err)
+	}
+	if filter.GetName() != wellknownJWTAuthnFilter {
+		t.Fatalf("expected filter %q, got %q", wellknownJWTAuthnFilter, filter.GetName())
+	}
+	jwtAuthn := &jwtauthnv3.JwtAuthentication{}
+	if err := filter.GetTypedConfig().UnmarshalTo(jwtAuthn); err != nil {
+		t.Fatalf("failed to unmarshal jwt_authn config: %v", err)
+	}
+
+	inlineName, _ := jwtProviderName("default", inline.Spec.Rules[0].Source.OIDC)
+	remoteName, _ := jwtProviderName("default", remote.Spec.Rules[0].Source.OIDC)
+	providers := jwtAuthn.GetProviders()
+	if len(providers) != 2 {
+		t.Fatalf("expected 2 providers, got %d", len(providers))
+	}
+
+	inlineProvider := providers[inlineName]
+	if inlineProvider.GetIssuer() != "https://inline.example.com" || inlineProvider.GetPayloadInMetadata() != inlineName {
+		t.Errorf("unexpected inline provider: %v", inlineProvider)
+	}
+	if inlineProvider.GetLocalJwks().GetInlineString() != testJWKS {
+		t.Errorf("expected inline JWKS, got %v", inlineProvider.GetLocalJwks())
+	}
+
+	remoteProvider := providers[remoteName]
+	httpURI := remoteProvider.GetRemoteJwks().GetHttpUri()
+	if want := "http://idp.auth.svc.cluster.local:8080/keys"; httpURI.GetUri() != want {
+		t.Errorf("expected JWKS URI %q, got %q", want, httpURI.GetUri())
+	}
+	if want := "idp.auth.svc.cluster.local-http:8080"; httpURI.GetCluster() != want {
+		t.Errorf("expected JWKS cluster %q, got %q", want, httpURI.GetCluster())
+	}
+
+	// Requests are let through without a valid token, and only match the OIDC principals with one.
+	requirements := jwtAuthn.GetRules()[0].GetRequires().GetRequiresAny().GetRequirements()
+	if len(requirements) != 3 {
+		t.Fatalf("expected 3 requirements, got %d", len(requirements))
+	}
+	if requirements[len(requirements)-1].GetAllowMissingOrFailed() == nil {
+		t.Errorf("expected last requirement to allow missing or failed tokens, got %v", requirements[len(requirements)-1])
+	}
+
+	// No filter is built without OIDC sources.
+	if filter, err := tr.buildJWTAuthnFilter(&mockAccessPolicyLister{}); err != nil || filter != nil {
+		t.Errorf("expected no filter without OIDC sources, got %v (err: %v)", filter, err)
+	}
+}
+
+func TestBuildJWKSBackendClusters(t *testing.T) {
+	policyIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+	backendIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+
+	idp := &agenticv0alpha0.XBackend{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "idp"},
+		Spec: agenticv0alpha0.BackendSpec{
+			MCP: agenticv0alpha0.MCPBackend{ServiceName: ptr.To("idp-svc"), Port: 8443},
+		},
+	}
+	externalIDP := &agenticv0alpha0.XBackend{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "external-idp"},
+		Spec: agenticv0alpha0.BackendSpec{
+			MCP: agenticv0alpha0.MCPBackend{Hostname: ptr.To("idp.example.com"), Port: 443},
+		},
+	}
+	for _, backend := range []*agenticv0alpha0.XBackend{idp, externalIDP} {
+		if err := backendIndexer.Add(backend); err != nil {
+			t.Fatalf("indexer.Add: %v", err)
+		}
+	}
+	xbackendRef := newTestOIDCAccessPolicy("default", "xbackend", "my-backend", &agenticv0alpha0.AuthorizationSourceOIDC{
+		Issuer: "https://idp.example.com",
+		JWKS: agenticv0alpha0.JWKS{
+			BackendRef: &gwapiv1.BackendObjectReference{
+				Group: ptr.To(gwapiv1.Group(agenticv0alpha0.GroupName)),
+				Kind:  ptr.To(gwapiv1.Kind("XBackend")),
+				Name:  "idp",
+			},
+		},
+	})
+	serviceRef := newTestOIDCAccessPolicy("default", "service", "my-backend", &agenticv0alpha0.AuthorizationSourceOIDC{
+		Issuer: "https://keycloak.example.com",
+		JWKS: agenticv0alpha0.JWKS{
+			BackendRef: &gwapiv1.BackendObjectReference{Name: "keycloak", Port: ptr.To(gwapiv1.PortNumber(8080))},
+		},
+	})
+	missing := newTestOIDCAccessPolicy("default", "missing", "my-backend", &agenticv0alpha0.AuthorizationSourceOIDC{
+		Issuer: "https://missing.example.com",
+		JWKS: agenticv0alpha0.JWKS{
+			BackendRef: &gwapiv1.BackendObjectReference{
+				Group: ptr.To(gwapiv1.Group(agenticv0alpha0.GroupName)),
+				Kind:  ptr.To(gwapiv1.Kind("XBackend")),
+				Name:  "missing",
+			},
+		},
+	})
+	external := newTestOIDCAccessPolicy("default", "external", "my-backend", &agenticv0alpha0.AuthorizationSourceOIDC{
+		Issuer: "https://external.example.com",
+		JWKS: agenticv0alpha0.JWKS{
+			BackendRef: &gwapiv1.BackendObjectReference{
+				Group: ptr.To(gwapiv1.Group(agenticv0alpha0.GroupName)),
+				Kind:  ptr.To(gwapiv1.Kind("XBackend")),
+				Name:  "external-idp",
+			},
+		},
+	})
+	for _, policy := range []*agenticv0alpha0.XAccessPolicy{xbackendRef, serviceRef, missing, external} {
+		if err := policyIndexer.Add(policy); err != nil {
+			t.Fatalf("indexer.Add: %v", err)
+		}
+	}
+
+	tr := &Translator{backendLister: agenticlisters.NewXBackendLister(backendIndexer)}
+	clusters, _ := tr.buildJWKSBackendClusters(agenticlisters.NewXAccessPolicyLister(policyIndexer))
+	if len(clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %d", len(clusters))
+	}
+
+	if _, ok := clusters["default-idp"].(*clusterv3.Cluster); !ok {
+		t.Fatalf("expected cluster for XBackend default/idp, got %v", clusters)
+	}
+	// The certificates of external servers are not verified, so their keys could not be trusted.
+	if _, ok := clusters["default-external-idp"]; ok {
+		t.Errorf("unexpected cluster for the external XBackend default/external-idp")
+	}
+	if _, ok := clusters["keycloak.default.svc.cluster.local-http:8080"]; !ok {
+		t.Errorf("expected cluster for Service default/keycloak, got %v", clusters)
+	}
+
+	// The JWKS of an in-cluster XBackend is fetched from its Service.
+	filter, err := tr.buildJWTAuthnFilter(agenticlisters.NewXAccessPolicyLister(policyIndexer))
+	if err != nil {
+		t.Fatalf("buildJWTAuthnFilter: %v", err)
+	}
+	jwtAuthn := &jwtauthnv3.JwtAuthentication{}
+	if err := filter.GetTypedConfig().UnmarshalTo(jwtAuthn); err != nil {
+		t.Fatalf("failed to unmarshal jwt_authn config: %v", err)
+	}
+	xbackendName, _ := jwtProviderName("default", xbackendRef.Spec.Rules[0].Source.OIDC)
+	if want, got := "http://idp-svc.default.svc.cluster.local:8443/.well-known/jwks.json", jwtAuthn.GetProviders()[xbackendName].GetRemoteJwks().GetHttpUri().GetUri(); got != want {
+		t.Errorf("expected JWKS URI %q, got %q", want, got)
+	}
+	externalName, _ := jwtProviderName("default", external.Spec.Rules[0].Source.OIDC)
+	if _, ok := jwtAuthn.GetProviders()[externalName]; ok {
+		t.Errorf("expected no provider for an external JWKS backend")
+	}
+	missingName, _ := jwtProviderName("default", missing.Spec.Rules[0].Source.OIDC)
+	if _, ok := jwtAuthn.GetProviders()[missingName]; ok {
+		t.Errorf("expected no provider for a missing JWKS backend")
+	}
+}
+
+// newTestOIDCAccessPolicy returns an XAccessPolicy with a single rule named "rule" that targets the
+// given XBackend and matches the given OIDC source.
+func newTestOIDCAccessPolicy(namespace, name, backendName string, oidc *agenticv0alpha0.AuthorizationSourceOIDC) *agenticv0alpha0.XAccessPolicy {
+	policy := newTestAccessPolicy(namespace, name, backendName, "")
+	policy.Spec.Rules[0].Source = &agenticv0alpha0.Source{
+		Type: agenticv0alpha0.AuthorizationSourceTypeOIDC,
+		OIDC: oidc,
+	}
+	return policy
+}
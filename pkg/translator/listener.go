@@ -20,12 +20,16 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	accesslogv3 "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v3"
+	mutation_rulesv3 "github.com/envoyproxy/go-control-plane/envoy/config/common/mutation_rules/v3"
 	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	listener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
 	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	streamv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/access_loggers/stream/v3"
 	ext_authzv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
 	mcpv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/mcp/v3"
 	rbacv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/rbac/v3"
@@ -36,6 +40,7 @@ import (
 	udpproxy "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/udp/udp_proxy/v3"
 	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
 	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/durationpb"
@@ -43,11 +48,13 @@ import (
 	"google.golang.org/protobuf/types/known/wrapperspb"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
+	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
 	agenticlisters "sigs.k8s.io/kube-agentic-networking/k8s/client/listers/api/v0alpha0"
 	"sigs.k8s.io/kube-agentic-networking/pkg/constants"
 )
@@ -56,6 +63,20 @@ const (
 	uriTimeout = 5 * time.Second
 
 	wellknownJWTAuthnFilter = "envoy.filters.http.jwt_authn"
+
+	stdoutAccessLoggerName = "envoy.access_loggers.stdout"
+
+	// shadowEngineResultKey is the suffix of the dynamic metadata key, following the shadow rule stat prefix,
+	// under which an RBAC filter emits the decision of its shadow rules.
+	shadowEngineResultKey = "shadow_engine_result"
+
+	// The keys of the context extensions sent to external authorizers, see externalAuthzContextExtensions.
+	// extAuthzContextExtensionXBackend is the namespace/name of the XBackend the request is routed to.
+	extAuthzContextExtensionXBackend = "xbackend"
+	// extAuthzContextExtensionAccessPolicy is the namespace/name of the AccessPolicy whose rule is checked.
+	extAuthzContextExtensionAccessPolicy = "access_policy"
+	// extAuthzContextExtensionAccessRule is the name of the rule checked.
+	extAuthzContextExtensionAccessRule = "access_rule"
 )
 
 // setListenerCondition is a helper to safely set a condition on a listener's status
@@ -159,13 +180,22 @@ func (t *Translator) validateListeners(gateway *gatewayv1.Gateway) map[gatewayv1
 	return listenerConditions
 }
 
-func (t *Translator) translateListenerToFilterChain(lis gatewayv1.Listener, routeName string, accessPolicyLister agenticlisters.XAccessPolicyLister) (*listener.FilterChain, error) {
+// translateListenerToFilterChain translates a listener into a filter chain. The gateway RBAC filters enforce the
+// AccessPolicies targeting the Gateway of the listener, see buildGatewayRBACFilters. The accessPolicies are all
+// the AccessPolicies enforced by the filter chain, see listenerAccessPolicies, and the backends are the XBackends
+// it routes to.
+func (t *Translator) translateListenerToFilterChain(lis gatewayv1.Listener, routeName string, accessPolicyLister agenticlisters.XAccessPolicyLister, gatewayRBACFilters []*hcm.HttpFilter, accessPolicies []*agenticv0alpha0.XAccessPolicy, backends []*agenticv0alpha0.XBackend) (*listener.FilterChain, error) {
 	var filterChain *listener.FilterChain
 	var err error
 
 	switch lis.Protocol {
 	case gatewayv1.HTTPProtocolType, gatewayv1.HTTPSProtocolType:
-		filterChain, err = buildHTTPFilterChain(lis, routeName, accessPolicyLister)
+		var jwtAuthnFilter *hcm.HttpFilter
+		jwtAuthnFilter, err = t.buildJWTAuthnFilter(accessPolicyLister)
+		if err != nil {
+			return nil, err
+		}
+		filterChain, err = buildHTTPFilterChain(lis, routeName, jwtAuthnFilter, gatewayRBACFilters, accessPolicies, backends)
 	case gatewayv1.TCPProtocolType, gatewayv1.TLSProtocolType:
 		filterChain, err = buildTCPFilterChain(lis)
 	case gatewayv1.UDPProtocolType:
@@ -277,8 +307,13 @@ func buildLocalReplyConfig() *hcm.LocalReplyConfig {
 	}
 }
 
-func buildHTTPFilterChain(lis gatewayv1.Listener, routeName string, accessPolicyLister agenticlisters.XAccessPolicyLister) (*listener.FilterChain, error) {
-	httpFilters, err := buildHTTPFilters(accessPolicyLister)
+func buildHTTPFilterChain(lis gatewayv1.Listener, routeName string, jwtAuthnFilter *hcm.HttpFilter, gatewayRBACFilters []*hcm.HttpFilter, accessPolicies []*agenticv0alpha0.XAccessPolicy, backends []*agenticv0alpha0.XBackend) (*listener.FilterChain, error) {
+	httpFilters, err := buildHTTPFilters(accessPolicies, jwtAuthnFilter, gatewayRBACFilters, backends)
+	if err != nil {
+		return nil, err
+	}
+
+	accessLogs, err := buildAuditAccessLogs(accessPolicies)
 	if err != nil {
 		return nil, err
 	}
@@ -296,6 +331,7 @@ func buildHTTPFilterChain(lis gatewayv1.Listener, routeName string, accessPolicy
 			},
 		},
 		HttpFilters: httpFilters,
+		AccessLog:   accessLogs,
 	}
 	hcmAny, err := anypb.New(hcmConfig)
 	if err != nil {
@@ -312,6 +348,95 @@ func buildHTTPFilterChain(lis gatewayv1.Listener, routeName string, accessPolicy
 	}, nil
 }
 
+// buildAuditAccessLogs builds the access log recording the shadow decisions of the RBAC filters, along with the
+// MCP request they were made for, so that the decisions of AccessPolicies in Audit mode can be observed.
+// Only the given AccessPolicies, enforced by the filter chain, are considered, and only the requests for which
+// the RBAC filters of the ones in Audit mode made a decision are logged. It returns nil if none is in Audit mode.
+func buildAuditAccessLogs(accessPolicies []*agenticv0alpha0.XAccessPolicy) ([]*accesslogv3.AccessLog, error) {
+	keys := sets.New[string]()
+	for _, accessPolicy := range accessPolicies {
+		if isAuditMode(accessPolicy) {
+			keys.Insert(auditShadowEngineResultKeys(accessPolicy)...)
+		}
+	}
+	if keys.Len() == 0 {
+		return nil, nil
+	}
+
+	// The RBAC filters only emit the shadow decisions of the requests they evaluate.
+	var auditFilters []*accesslogv3.AccessLogFilter
+	for _, key := range sets.List(keys) {
+		auditFilters = append(auditFilters, &accesslogv3.AccessLogFilter{
+			FilterSpecifier: &accesslogv3.AccessLogFilter_MetadataFilter{
+				MetadataFilter: &accesslogv3.MetadataFilter{
+					Matcher: &matcherv3.MetadataMatcher{
+						Filter: wellknown.HTTPRoleBasedAccessControl,
+						Path:   []*matcherv3.MetadataMatcher_PathSegment{{Segment: &matcherv3.MetadataMatcher_PathSegment_Key{Key: key}}},
+						Value:  &matcherv3.ValueMatcher{MatchPattern: &matcherv3.ValueMatcher_PresentMatch{PresentMatch: true}},
+					},
+					MatchIfKeyNotFound: wrapperspb.Bool(false),
+				},
+			},
+		})
+	}
+
+	stdoutAny, err := anypb.New(&streamv3.StdoutAccessLog{
+		AccessLogFormat: &streamv3.StdoutAccessLog_LogFormat{
+			LogFormat: &corev3.SubstitutionFormatString{
+				Format: &corev3.SubstitutionFormatString_JsonFormat{
+					JsonFormat: &structpb.Struct{
+						Fields: map[string]*structpb.Value{
+							"start_time":       structpb.NewStringValue("%START_TIME%"),
+							"method":           structpb.NewStringValue("%REQ(:METHOD)%"),
+							"path":             structpb.NewStringValue("%REQ(X-ENVOY-ORIGINAL-PATH?:PATH)%"),
+							"response_code":    structpb.NewStringValue("%RESPONSE_CODE%"),
+							"upstream_cluster": structpb.NewStringValue("%UPSTREAM_CLUSTER%"),
+							"peer_uri_san":     structpb.NewStringValue("%DOWNSTREAM_PEER_URI_SAN%"),
+							"mcp_method":       structpb.NewStringValue("%DYNAMIC_METADATA(" + mcpProxyFilterName + ":method)%"),
+							"mcp_tool_name":    structpb.NewStringValue("%DYNAMIC_METADATA(" + mcpProxyFilterName + ":params:name)%"),
+							// The RBAC filters emit the shadow decisions keyed with their shadow rule stat prefixes,
+							// e.g. access_policy_audit_shadow_engine_result and access_policy_audit_shadow_effective_policy_id.
+							"rbac": structpb.NewStringValue("%DYNAMIC_METADATA(" + wellknown.HTTPRoleBasedAccessControl + ")%"),
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		klog.Errorf("Failed to marshal stdout access log config: %v", err)
+		return nil, err
+	}
+
+	return []*accesslogv3.AccessLog{{
+		Name: stdoutAccessLoggerName,
+		// Each AccessPolicy in Audit mode emits its own keys, of which an OR filter always has at least two.
+		Filter: &accesslogv3.AccessLogFilter{
+			FilterSpecifier: &accesslogv3.AccessLogFilter_OrFilter{OrFilter: &accesslogv3.OrFilter{Filters: auditFilters}},
+		},
+		ConfigType: &accesslogv3.AccessLog_TypedConfig{TypedConfig: stdoutAny},
+	}}, nil
+}
+
+// auditShadowEngineResultKeys returns the dynamic metadata keys of the shadow decisions of the RBAC filters
+// evaluating the rules of an AccessPolicy in Audit mode, for each kind of resource it targets.
+func auditShadowEngineResultKeys(accessPolicy *agenticv0alpha0.XAccessPolicy) []string {
+	var prefixes []string
+	for _, targetRef := range accessPolicy.Spec.TargetRefs {
+		if IsGatewayTargetRef(targetRef) {
+			prefix := gatewayAuditShadowRulePrefix(accessPolicy)
+			prefixes = append(prefixes, prefix, prefix+"deny_")
+			continue
+		}
+		prefixes = append(prefixes, auditShadowRulePrefix+"_", auditShadowRulePrefix+"_deny_")
+	}
+	keys := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		keys = append(keys, prefix+shadowEngineResultKey)
+	}
+	return keys
+}
+
 func buildTCPFilterChain(lis gatewayv1.Listener) (*listener.FilterChain, error) {
 	// TCP and TLS listeners require a TCP proxy filter.
 	// We'll assume for now that routes for these are not supported and it's a direct pass-through.
@@ -356,18 +481,46 @@ func buildUDPFilterChain(lis gatewayv1.Listener) (*listener.FilterChain, error)
 	}, nil
 }
 
-func buildHTTPFilters(accessPolicyLister agenticlisters.XAccessPolicyLister) ([]*hcm.HttpFilter, error) {
+// buildHTTPFilters builds the HTTP filters of a listener enforcing the given AccessPolicies, see listenerAccessPolicies,
+// and injecting the credentials of the given XBackends, see buildCredentialInjectorFilters and buildTokenExchangeFilter.
+// The jwt_authn filter is omitted if nil, see buildJWTAuthnFilter.
+func buildHTTPFilters(accessPolicies []*agenticv0alpha0.XAccessPolicy, jwtAuthnFilter *hcm.HttpFilter, gatewayRBACFilters []*hcm.HttpFilter, backends []*agenticv0alpha0.XBackend) ([]*hcm.HttpFilter, error) {
 	mcpFilter, err := buildMCPFilter()
 	if err != nil {
 		return nil, err
 	}
 
-	rbacFilter, err := buildRBACFilter()
+	auditRBACFilter, err := buildRBACFilter(auditRBACFilterName, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	denyRBACFilter, err := buildRBACFilter(denyRBACFilterName, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rbacFilter, err := buildRBACFilter(wellknown.HTTPRoleBasedAccessControl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	extAuthzFilters, err := buildExtAuthzFilters(accessPolicies)
+	if err != nil {
+		return nil, err
+	}
+
+	credentialInjectorFilters, err := buildCredentialInjectorFilters(backends)
 	if err != nil {
 		return nil, err
 	}
 
-	extAuthzFilters, err := buildExtAuthzFilters(accessPolicyLister)
+	tokenExchangeFilter, err := buildTokenExchangeFilter(backends)
+	if err != nil {
+		return nil, err
+	}
+
+	toolsListFilter, err := buildToolsListFilter()
 	if err != nil {
 		return nil, err
 	}
@@ -379,14 +532,35 @@ func buildHTTPFilters(accessPolicyLister agenticlisters.XAccessPolicyLister) ([]
 
 	filters := []*hcm.HttpFilter{
 		// IMPORTANT: Order matters here!
-		// RBAC filter must come before the ext_authz filter to ensure evaluation of RBAC shadow rules that trigger ext_authz.
-		// Ext_authz filter must come before router filter to enforce access control before routing.
+		// JWT authn filter must come before the RBAC filters so that the principals of OIDC sources can match the
+		// payloads of the verified tokens.
+		// Audit RBAC filter must come before the other RBAC filters so that its shadow decision is recorded even
+		// for requests they deny.
+		// Gateway RBAC filters must come before the per-backend RBAC filters so that AccessPolicies targeting the
+		// Gateway are evaluated first.
+		// Deny RBAC filter must come before the RBAC filter so that Deny rules are evaluated before Allow rules.
+		// RBAC filter must come before the ext_authz filters so that requests it denies are not sent to external authorizers.
+		// Each ext_authz filter is preceded by the RBAC filter whose shadow rules trigger it.
+		// Ext_authz filters must come before router filter to enforce access control before routing.
+		// Credential injector and token exchange filters must come after all the filters authorizing requests so
+		// that neither they nor the external authorizers ever see the credentials of the backends, and so that no
+		// token is requested for denied requests.
+		// Tools list filter only acts on responses, so it is placed right before the router filter to see them first.
 		// Router filter must come last to handle routing after all other filters have processed the request.
 		mcpFilter,
-		rbacFilter,
 	}
+	if jwtAuthnFilter != nil {
+		filters = append(filters, jwtAuthnFilter)
+	}
+	filters = append(filters, auditRBACFilter)
+	filters = append(filters, gatewayRBACFilters...)
+	filters = append(filters, denyRBACFilter, rbacFilter)
 	filters = append(filters, extAuthzFilters...)
-	return append(filters, routerFilter), nil
+	filters = append(filters, credentialInjectorFilters...)
+	if tokenExchangeFilter != nil {
+		filters = append(filters, tokenExchangeFilter)
+	}
+	return append(filters, toolsListFilter, routerFilter), nil
 }
 
 func buildMCPFilter() (*hcm.HttpFilter, error) {
@@ -405,8 +579,12 @@ func buildMCPFilter() (*hcm.HttpFilter, error) {
 	}, nil
 }
 
-func buildRBACFilter() (*hcm.HttpFilter, error) {
-	rbacProto := &rbacv3.RBAC{}
+// buildRBACFilter builds an RBAC filter with the given listener-level config. Filters with an empty config
+// only enforce the per-route configs.
+func buildRBACFilter(name string, rbacProto *rbacv3.RBAC) (*hcm.HttpFilter, error) {
+	if rbacProto == nil {
+		rbacProto = &rbacv3.RBAC{}
+	}
 	rbacAny, err := anypb.New(rbacProto)
 	if err != nil {
 		klog.Errorf("Failed to marshal rbac config: %v", err)
@@ -414,19 +592,17 @@ func buildRBACFilter() (*hcm.HttpFilter, error) {
 	}
 
 	return &hcm.HttpFilter{
-		Name: wellknown.HTTPRoleBasedAccessControl,
+		Name: name,
 		ConfigType: &hcm.HttpFilter_TypedConfig{
 			TypedConfig: rbacAny,
 		},
 	}, nil
 }
 
-func buildExtAuthzFilters(accessPolicyLister agenticlisters.XAccessPolicyLister) ([]*hcm.HttpFilter, error) {
-	accessPolicies, err := accessPolicyLister.List(labels.Everything())
-	if err != nil {
-		return nil, fmt.Errorf("failed to list AccessPolicies: %w", err)
-	}
-
+// buildExtAuthzFilters builds the ext_authz filters of the ExternalAuth configurations of the AccessPolicies enforced
+// by a listener, see listenerAccessPolicies, so that a Gateway never calls the external authorizers of AccessPolicies
+// it does not enforce.
+func buildExtAuthzFilters(accessPolicies []*agenticv0alpha0.XAccessPolicy) ([]*hcm.HttpFilter, error) {
 	var filters []*hcm.HttpFilter
 	hashes := make(map[string]struct{}) // To track unique externalAuth configs and avoid duplicate filters
 	for _, ap := range accessPolicies {
@@ -435,7 +611,7 @@ func buildExtAuthzFilters(accessPolicyLister agenticlisters.XAccessPolicyLister)
 				continue
 			}
 			extAuthz := rule.Authorization.ExternalAuth
-			hash, err := externalAuthUniqueID(extAuthz)
+			hash, err := externalAuthUniqueID(rule.Authorization)
 			if err != nil {
 				klog.Error(err)
 				continue
@@ -444,6 +620,12 @@ func buildExtAuthzFilters(accessPolicyLister agenticlisters.XAccessPolicyLister)
 				continue // Skip if we've already created a filter for this config
 			}
 			hashes[hash] = struct{}{}
+			// The RBAC filter triggering the ext_authz filter only enforces the per-route configs of the clusters
+			// whose rules use this configuration.
+			triggerFilter, err := buildRBACFilter(externalAuthzRBACFilterName(hash), nil)
+			if err != nil {
+				return nil, err
+			}
 			extAuthzProto := buildExtAuthzConfig(hash)
 			backendRef := extAuthz.BackendRef
 			clusterName := clusterNameForBackendRefAndProtocol(backendRef, ap.GetNamespace(), string(extAuthz.ExternalAuthProtocol))
@@ -502,7 +684,6 @@ func buildExtAuthzFilters(accessPolicyLister agenticlisters.XAccessPolicyLister)
 							Patterns: toEnvoyExactStringMatchers(config.AllowedRequestHeaders),
 						}
 					}
-					// We don't support AllowedResponseHeaders yet
 				}
 			}
 			if forwardRequestBody := extAuthz.ForwardBody; forwardRequestBody != nil {
@@ -511,18 +692,19 @@ func buildExtAuthzFilters(accessPolicyLister agenticlisters.XAccessPolicyLister)
 					AllowPartialMessage: true,
 				}
 			}
+			applyExternalAuthSettings(extAuthzProto, rule.Authorization.ExternalAuthSettings)
 			extAuthzAny, err := anypb.New(extAuthzProto)
 			if err != nil {
 				klog.Errorf("Failed to marshal ext_authz config: %v", err)
 				return nil, err
 			}
 			extAuthzFilter := &hcm.HttpFilter{
-				Name: wellknown.HTTPExternalAuthorization,
+				Name: externalAuthzFilterName(hash),
 				ConfigType: &hcm.HttpFilter_TypedConfig{
 					TypedConfig: extAuthzAny,
 				},
 			}
-			filters = append(filters, extAuthzFilter)
+			filters = append(filters, triggerFilter, extAuthzFilter)
 		}
 	}
 
@@ -545,6 +727,17 @@ func buildRouterFilter() (*hcm.HttpFilter, error) {
 	}, nil
 }
 
+// externalAuthzFilterName returns the name of the ext_authz filter of the ExternalAuth configuration with the given
+// unique ID. Each filter has its own name so that the clusters can set its per-route config, see
+// buildPerClusterExtAuthzFilterConfig.
+func externalAuthzFilterName(hash string) string {
+	return fmt.Sprintf("%s.%s", wellknown.HTTPExternalAuthorization, hash)
+}
+
+// buildExtAuthzConfig builds the ext_authz config of the ExternalAuth configuration with the given unique ID. The filter
+// only calls the external authorizer when the RBAC filter triggering it matched the request, and sends it the
+// metadata of the MCP filter, such as the tool called, and of the RBAC filters, such as the rule that matched
+// under the key of the effective shadow policy ID of the triggering filter.
 func buildExtAuthzConfig(hash string) *ext_authzv3.ExtAuthz {
 	return &ext_authzv3.ExtAuthz{
 		FailureModeAllow: false,
@@ -563,41 +756,83 @@ func buildExtAuthzConfig(hash string) *ext_authzv3.ExtAuthz {
 		},
 		MetadataContextNamespaces: []string{
 			mcpProxyFilterName,
-			wellknownJWTAuthnFilter, // Although we don't directly depend on the JWT authn filter, we propagate metadata that it generates for use in ext_authz, in case the filter is set by the user.
+			wellknownJWTAuthnFilter,              // Propagate the payloads of the tokens verified for OIDC sources for use in ext_authz.
+			wellknown.HTTPRoleBasedAccessControl, // Propagate the rules that matched the request.
 		},
 	}
 }
 
+// applyExternalAuthSettings applies the settings of an ExternalAuth configuration to its ext_authz config, whose
+// service must be set. Unset settings keep the defaults of the ext_authz filter.
+func applyExternalAuthSettings(extAuthzProto *ext_authzv3.ExtAuthz, settings *agenticv0alpha0.ExternalAuthSettings) {
+	if settings == nil {
+		return
+	}
+	if settings.FailureMode != nil {
+		extAuthzProto.FailureModeAllow = *settings.FailureMode == agenticv0alpha0.ExternalAuthFailureModeAllow
+	}
+	if settings.Timeout != nil {
+		timeout, err := time.ParseDuration(string(*settings.Timeout))
+		if err != nil {
+			klog.Errorf("Ignoring invalid ext_authz timeout %q: %v", *settings.Timeout, err)
+		} else if grpcService := extAuthzProto.GetGrpcService(); grpcService != nil {
+			grpcService.Timeout = durationpb.New(timeout)
+		} else if serverURI := extAuthzProto.GetHttpService().GetServerUri(); serverURI != nil {
+			serverURI.Timeout = durationpb.New(timeout)
+		}
+	}
+	if settings.StatusOnError != nil {
+		extAuthzProto.StatusOnError = &typev3.HttpStatus{Code: typev3.StatusCode(*settings.StatusOnError)}
+	}
+	extAuthzProto.IncludePeerCertificate = ptr.Deref(settings.IncludePeerCertificate, false)
+	if httpService := extAuthzProto.GetHttpService(); httpService != nil {
+		if len(settings.AllowedUpstreamHeaders) > 0 || len(settings.AllowedClientHeaders) > 0 {
+			if httpService.AuthorizationResponse == nil {
+				httpService.AuthorizationResponse = &ext_authzv3.AuthorizationResponse{}
+			}
+			authorizationResponse := httpService.AuthorizationResponse
+			if len(settings.AllowedUpstreamHeaders) > 0 {
+				if authorizationResponse.AllowedUpstreamHeaders == nil {
+					authorizationResponse.AllowedUpstreamHeaders = &matcherv3.ListStringMatcher{}
+				}
+				authorizationResponse.AllowedUpstreamHeaders.Patterns = append(authorizationResponse.AllowedUpstreamHeaders.Patterns,
+					toEnvoyExactStringMatchers(settings.AllowedUpstreamHeaders)...)
+			}
+			if len(settings.AllowedClientHeaders) > 0 {
+				authorizationResponse.AllowedClientHeaders = &matcherv3.ListStringMatcher{
+					Patterns: toEnvoyExactStringMatchers(settings.AllowedClientHeaders),
+				}
+			}
+		}
+	} else if len(settings.AllowedUpstreamHeaders) > 0 {
+		// The gRPC authorizer sets headers through header mutations, which are restricted to the allowed headers.
+		extAuthzProto.DecoderHeaderMutationRules = &mutation_rulesv3.HeaderMutationRules{
+			DisallowAll:     wrapperspb.Bool(true),
+			AllowExpression: &matcherv3.RegexMatcher{Regex: headerNamesRegex(settings.AllowedUpstreamHeaders)},
+		}
+	}
+}
+
+// headerNamesRegex returns a regular expression matching exactly the given header names, which Envoy matches in
+// lower case.
+func headerNamesRegex(names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, regexp.QuoteMeta(strings.ToLower(name)))
+	}
+	return fmt.Sprintf("^(%s)$", strings.Join(quoted, "|"))
+}
+
 // TODO: We may want to optimize this in the future by supporting both listener's TLS config and the shared TLS context.
 // https://github.com/kubernetes-sigs/kube-agentic-networking/issues/94
 func buildDownstreamTLSContext() (*anypb.Any, error) {
 	tlsContext := &tlsv3.DownstreamTlsContext{
 		CommonTlsContext: &tlsv3.CommonTlsContext{
 			TlsCertificateSdsSecretConfigs: []*tlsv3.SdsSecretConfig{
-				{
-					Name: constants.SpiffeIdentitySdsConfigName,
-					SdsConfig: &corev3.ConfigSource{
-						ResourceApiVersion: corev3.ApiVersion_V3,
-						ConfigSourceSpecifier: &corev3.ConfigSource_PathConfigSource{
-							PathConfigSource: &corev3.PathConfigSource{
-								Path: fmt.Sprintf("%s/%s", constants.EnvoySdsMountPath, constants.SpiffeIdentitySdsFileName),
-							},
-						},
-					},
-				},
+				spiffeSdsSecretConfig(constants.SpiffeIdentitySdsConfigName, constants.SpiffeIdentitySdsFileName),
 			},
 			ValidationContextType: &tlsv3.CommonTlsContext_ValidationContextSdsSecretConfig{
-				ValidationContextSdsSecretConfig: &tlsv3.SdsSecretConfig{
-					Name: constants.SpiffeTrustSdsConfigName,
-					SdsConfig: &corev3.ConfigSource{
-						ResourceApiVersion: corev3.ApiVersion_V3,
-						ConfigSourceSpecifier: &corev3.ConfigSource_PathConfigSource{
-							PathConfigSource: &corev3.PathConfigSource{
-								Path: fmt.Sprintf("%s/%s", constants.EnvoySdsMountPath, constants.SpiffeTrustSdsFileName),
-							},
-						},
-					},
-				},
+				ValidationContextSdsSecretConfig: spiffeSdsSecretConfig(constants.SpiffeTrustSdsConfigName, constants.SpiffeTrustSdsFileName),
 			},
 		},
 		RequireClientCertificate: wrapperspb.Bool(true),
@@ -610,6 +845,22 @@ func buildDownstreamTLSContext() (*anypb.Any, error) {
 	return anyObj, nil
 }
 
+// spiffeSdsSecretConfig returns the SDS config of the SPIFFE identity or trust bundle of the Gateway, which the
+// Envoy proxy loads from the SDS file of the given name, see renderConfigMap.
+func spiffeSdsSecretConfig(name, fileName string) *tlsv3.SdsSecretConfig {
+	return &tlsv3.SdsSecretConfig{
+		Name: name,
+		SdsConfig: &corev3.ConfigSource{
+			ResourceApiVersion: corev3.ApiVersion_V3,
+			ConfigSourceSpecifier: &corev3.ConfigSource_PathConfigSource{
+				PathConfigSource: &corev3.PathConfigSource{
+					Path: fmt.Sprintf("%s/%s", constants.EnvoySdsMountPath, fileName),
+				},
+			},
+		},
+	}
+}
+
 func createEnvoyAddress(port uint32) *corev3.Address {
 	return &corev3.Address{
 		Address: &corev3.Address_SocketAddress{
@@ -648,8 +899,12 @@ func toEnvoyExactStringMatchers(s []string) []*matcherv3.StringMatcher {
 	return matchers
 }
 
-func externalAuthUniqueID(externalAuth *gatewayv1.HTTPExternalAuthFilter) (string, error) {
-	j, err := json.Marshal(externalAuth)
+func externalAuthUniqueID(authorization *agenticv0alpha0.AuthorizationRule) (string, error) {
+	// Rules calling the same external authorizer with different settings need their own ext_authz filter.
+	j, err := json.Marshal(struct {
+		ExternalAuth *gatewayv1.HTTPExternalAuthFilter     `json:"externalAuth"`
+		Settings     *agenticv0alpha0.ExternalAuthSettings `json:"settings,omitempty"`
+	}{authorization.ExternalAuth, authorization.ExternalAuthSettings})
 	if err != nil {
 		return "", fmt.Errorf("Failed to marshal externalAuth config for unique ID generation: %v", err)
 	}
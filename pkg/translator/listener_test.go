@@ -19,9 +19,19 @@ package translator
 import (
 	"reflect"
 	"testing"
+	"time"
 
+	ext_authzv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
+	hcm "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
 	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/utils/ptr"
 
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
@@ -113,7 +123,7 @@ func TestTranslateListenerToFilterChain(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			fc, err := translator.translateListenerToFilterChain(tc.listener, "route-config", mockLister)
+			fc, err := translator.translateListenerToFilterChain(tc.listener, "route-config", mockLister, nil, nil, nil)
 			if err != nil {
 				t.Fatalf("failed to translate listener: %v", err)
 			}
@@ -131,3 +141,328 @@ func TestTranslateListenerToFilterChain(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildHTTPFilters(t *testing.T) {
+	gatewayRBACFilter, err := buildRBACFilter(gatewayRBACFilterNamePrefix+".default.policy", nil)
+	if err != nil {
+		t.Fatalf("failed to build gateway RBAC filter: %v", err)
+	}
+	filters, err := buildHTTPFilters(nil, nil, []*hcm.HttpFilter{gatewayRBACFilter}, nil)
+	if err != nil {
+		t.Fatalf("failed to build HTTP filters: %v", err)
+	}
+
+	var names []string
+	for _, filter := range filters {
+		names = append(names, filter.GetName())
+	}
+	expected := []string{
+		"envoy.filters.http.mcp",
+		auditRBACFilterName,
+		gatewayRBACFilterNamePrefix + ".default.policy",
+		denyRBACFilterName,
+		wellknown.HTTPRoleBasedAccessControl,
+		toolsListFilterName,
+		wellknown.Router,
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected filters %v, got %v", expected, names)
+	}
+}
+
+func TestBuildAuditAccessLogs(t *testing.T) {
+	enforced := newTestAccessPolicy("default", "enforced", "my-backend", "spiffe://example.com/ns/default/sa/a")
+	accessLogs, err := buildAuditAccessLogs([]*apiv0alpha0.XAccessPolicy{enforced})
+	if err != nil || accessLogs != nil {
+		t.Errorf("expected no access log without AccessPolicies in Audit mode, got %v (err: %v)", accessLogs, err)
+	}
+
+	audited := newTestAccessPolicy("default", "audited", "my-backend", "spiffe://example.com/ns/default/sa/a")
+	audited.Spec.Mode = apiv0alpha0.AccessPolicyModeAudit
+	gatewayAudited := newTestGatewayAccessPolicy("default", "gateway-audited", "my-gateway", "", "spiffe://example.com/ns/default/sa/a")
+	gatewayAudited.Spec.Mode = apiv0alpha0.AccessPolicyModeAudit
+	accessLogs, err = buildAuditAccessLogs([]*apiv0alpha0.XAccessPolicy{enforced, audited, gatewayAudited})
+	if err != nil {
+		t.Fatalf("buildAuditAccessLogs: %v", err)
+	}
+	if len(accessLogs) != 1 || accessLogs[0].GetName() != stdoutAccessLoggerName {
+		t.Fatalf("expected the stdout access log, got %v", accessLogs)
+	}
+
+	// Only the requests for which the audit RBAC filters made a decision are logged.
+	var keys []string
+	for _, filter := range accessLogs[0].GetFilter().GetOrFilter().GetFilters() {
+		metadataFilter := filter.GetMetadataFilter()
+		if metadataFilter.GetMatchIfKeyNotFound() == nil || metadataFilter.GetMatchIfKeyNotFound().GetValue() {
+			t.Errorf("expected requests without the metadata key not to be logged, got %v", metadataFilter)
+		}
+		if metadataFilter.GetMatcher().GetFilter() != wellknown.HTTPRoleBasedAccessControl || !metadataFilter.GetMatcher().GetValue().GetPresentMatch() {
+			t.Errorf("expected a presence match on the RBAC dynamic metadata, got %v", metadataFilter.GetMatcher())
+		}
+		for _, segment := range metadataFilter.GetMatcher().GetPath() {
+			keys = append(keys, segment.GetKey())
+		}
+	}
+	expected := []string{
+		"access_policy_audit_default_gateway-audited_deny_shadow_engine_result",
+		"access_policy_audit_default_gateway-audited_shadow_engine_result",
+		"access_policy_audit_deny_shadow_engine_result",
+		"access_policy_audit_shadow_engine_result",
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected metadata keys %v, got %v", expected, keys)
+	}
+}
+
+func TestListenerAccessPolicies(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+	for _, policy := range []*apiv0alpha0.XAccessPolicy{
+		newTestAccessPolicy("default", "routed", "routed", "spiffe://example.com/ns/default/sa/a"),
+		newTestAccessPolicy("default", "unrouted", "unrouted", "spiffe://example.com/ns/default/sa/a"),
+		newTestGatewayAccessPolicy("default", "gateway", "my-gateway", "", "spiffe://example.com/ns/default/sa/a"),
+		newTestGatewayAccessPolicy("default", "other-listener", "my-gateway", "other", "spiffe://example.com/ns/default/sa/a"),
+		newTestGatewayAccessPolicy("default", "other-gateway", "other-gateway", "", "spiffe://example.com/ns/default/sa/a"),
+	} {
+		if err := indexer.Add(policy); err != nil {
+			t.Fatalf("indexer.Add: %v", err)
+		}
+	}
+	tr := &Translator{accessPolicyLister: agenticlisters.NewXAccessPolicyLister(indexer)}
+	gateway := &gatewayv1.Gateway{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-gateway"}}
+	backends := []*apiv0alpha0.XBackend{{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "routed"}}}
+
+	accessPolicies, err := tr.listenerAccessPolicies(gateway, backends, "http")
+	if err != nil {
+		t.Fatalf("listenerAccessPolicies: %v", err)
+	}
+	var names []string
+	for _, accessPolicy := range accessPolicies {
+		names = append(names, accessPolicy.Name)
+	}
+	if expected := []string{"gateway", "routed"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected AccessPolicies %v, got %v", expected, names)
+	}
+}
+
+func TestBuildExtAuthzFilters(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+	policy := withTestExternalAuth(newTestAccessPolicy("default", "policy", "my-backend", "spiffe://example.com/ns/default/sa/a"), "authz-1")
+	second := *policy.Spec.Rules[0].DeepCopy()
+	second.Name = "second"
+	second.Authorization.ExternalAuth.BackendRef.Name = "authz-2"
+	policy.Spec.Rules = append(policy.Spec.Rules, second)
+	if err := indexer.Add(policy); err != nil {
+		t.Fatalf("indexer.Add: %v", err)
+	}
+
+	filters, err := buildExtAuthzFilters([]*apiv0alpha0.XAccessPolicy{policy})
+	if err != nil {
+		t.Fatalf("buildExtAuthzFilters: %v", err)
+	}
+	var names []string
+	for _, filter := range filters {
+		names = append(names, filter.GetName())
+	}
+	var expected []string
+	for _, rule := range policy.Spec.Rules {
+		hash, err := externalAuthUniqueID(rule.Authorization)
+		if err != nil {
+			t.Fatalf("externalAuthUniqueID: %v", err)
+		}
+		// Each ext_authz filter is preceded by the RBAC filter triggering it.
+		expected = append(expected, externalAuthzRBACFilterName(hash), externalAuthzFilterName(hash))
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected filters %v, got %v", expected, names)
+	}
+}
+
+func TestBuildExtAuthzFilters_Settings(t *testing.T) {
+	newPolicy := func(protocol gatewayv1.HTTPRouteExternalAuthProtocol, settings *apiv0alpha0.ExternalAuthSettings) *apiv0alpha0.XAccessPolicy {
+		policy := withTestExternalAuth(newTestAccessPolicy("default", "policy", "my-backend", "spiffe://example.com/ns/default/sa/a"), "authz")
+		policy.Spec.Rules[0].Authorization.ExternalAuth.ExternalAuthProtocol = protocol
+		if protocol == gatewayv1.HTTPRouteExternalAuthHTTPProtocol {
+			policy.Spec.Rules[0].Authorization.ExternalAuth.HTTPAuthConfig = &gatewayv1.HTTPAuthConfig{}
+		}
+		policy.Spec.Rules[0].Authorization.ExternalAuthSettings = settings
+		return policy
+	}
+	settings := &apiv0alpha0.ExternalAuthSettings{
+		FailureMode:            ptr.To(apiv0alpha0.ExternalAuthFailureModeAllow),
+		Timeout:                ptr.To(gatewayv1.Duration("30s")),
+		StatusOnError:          ptr.To(int32(503)),
+		IncludePeerCertificate: ptr.To(true),
+	}
+
+	tests := []struct {
+		name                 string
+		policy               *apiv0alpha0.XAccessPolicy
+		wantFailureModeAllow bool
+		wantTimeout          *durationpb.Duration
+		wantStatusOnError    typev3.StatusCode
+		wantPeerCertificate  bool
+	}{
+		{
+			name:   "gRPC defaults",
+			policy: newPolicy(gatewayv1.HTTPRouteExternalAuthGRPCProtocol, nil),
+		},
+		{
+			name:        "HTTP defaults",
+			policy:      newPolicy(gatewayv1.HTTPRouteExternalAuthHTTPProtocol, nil),
+			wantTimeout: durationpb.New(uriTimeout),
+		},
+		{
+			name:                 "gRPC settings",
+			policy:               newPolicy(gatewayv1.HTTPRouteExternalAuthGRPCProtocol, settings),
+			wantFailureModeAllow: true,
+			wantTimeout:          durationpb.New(30 * time.Second),
+			wantStatusOnError:    typev3.StatusCode_ServiceUnavailable,
+			wantPeerCertificate:  true,
+		},
+		{
+			name:                 "HTTP settings",
+			policy:               newPolicy(gatewayv1.HTTPRouteExternalAuthHTTPProtocol, settings),
+			wantFailureModeAllow: true,
+			wantTimeout:          durationpb.New(30 * time.Second),
+			wantStatusOnError:    typev3.StatusCode_ServiceUnavailable,
+			wantPeerCertificate:  true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			filters, err := buildExtAuthzFilters([]*apiv0alpha0.XAccessPolicy{tc.policy})
+			if err != nil {
+				t.Fatalf("buildExtAuthzFilters: %v", err)
+			}
+			if len(filters) != 2 {
+				t.Fatalf("expected a trigger RBAC filter and an ext_authz filter, got %d filters", len(filters))
+			}
+			extAuthz := &ext_authzv3.ExtAuthz{}
+			if err := filters[1].GetTypedConfig().UnmarshalTo(extAuthz); err != nil {
+				t.Fatalf("failed to unmarshal ExtAuthz: %v", err)
+			}
+			if got := extAuthz.GetFailureModeAllow(); got != tc.wantFailureModeAllow {
+				t.Errorf("expected FailureModeAllow %v, got %v", tc.wantFailureModeAllow, got)
+			}
+			timeout := extAuthz.GetGrpcService().GetTimeout()
+			if extAuthz.GetHttpService() != nil {
+				timeout = extAuthz.GetHttpService().GetServerUri().GetTimeout()
+			}
+			if !proto.Equal(timeout, tc.wantTimeout) {
+				t.Errorf("expected timeout %v, got %v", tc.wantTimeout, timeout)
+			}
+			if got := extAuthz.GetStatusOnError().GetCode(); got != tc.wantStatusOnError {
+				t.Errorf("expected StatusOnError %v, got %v", tc.wantStatusOnError, got)
+			}
+			if got := extAuthz.GetIncludePeerCertificate(); got != tc.wantPeerCertificate {
+				t.Errorf("expected IncludePeerCertificate %v, got %v", tc.wantPeerCertificate, got)
+			}
+		})
+	}
+
+	// The same external authorizer called with different settings gets its own ext_authz filter.
+	filters, err := buildExtAuthzFilters([]*apiv0alpha0.XAccessPolicy{
+		newPolicy(gatewayv1.HTTPRouteExternalAuthGRPCProtocol, nil),
+		newPolicy(gatewayv1.HTTPRouteExternalAuthGRPCProtocol, settings),
+	})
+	if err != nil {
+		t.Fatalf("buildExtAuthzFilters: %v", err)
+	}
+	if len(filters) != 4 {
+		t.Errorf("expected 2 trigger RBAC filters and 2 ext_authz filters, got %d filters", len(filters))
+	}
+}
+
+func TestBuildExtAuthzFilters_ResponseHeaders(t *testing.T) {
+	buildExtAuthz := func(t *testing.T, externalAuth *gatewayv1.HTTPExternalAuthFilter, settings *apiv0alpha0.ExternalAuthSettings) *ext_authzv3.ExtAuthz {
+		t.Helper()
+		policy := withTestExternalAuth(newTestAccessPolicy("default", "policy", "my-backend", "spiffe://example.com/ns/default/sa/a"), "authz")
+		policy.Spec.Rules[0].Authorization.ExternalAuth = externalAuth
+		policy.Spec.Rules[0].Authorization.ExternalAuthSettings = settings
+		filters, err := buildExtAuthzFilters([]*apiv0alpha0.XAccessPolicy{policy})
+		if err != nil {
+			t.Fatalf("buildExtAuthzFilters: %v", err)
+		}
+		if len(filters) != 2 {
+			t.Fatalf("expected a trigger RBAC filter and an ext_authz filter, got %d filters", len(filters))
+		}
+		extAuthz := &ext_authzv3.ExtAuthz{}
+		if err := filters[1].GetTypedConfig().UnmarshalTo(extAuthz); err != nil {
+			t.Fatalf("failed to unmarshal ExtAuthz: %v", err)
+		}
+		return extAuthz
+	}
+	patterns := func(matcher *matcherv3.ListStringMatcher) []string {
+		var exact []string
+		for _, pattern := range matcher.GetPatterns() {
+			exact = append(exact, pattern.GetExact())
+		}
+		return exact
+	}
+	settings := &apiv0alpha0.ExternalAuthSettings{
+		AllowedUpstreamHeaders: []string{"X-Upstream-Token", "x-tenant.id"},
+		AllowedClientHeaders:   []string{"x-denial-reason"},
+	}
+
+	t.Run("HTTP", func(t *testing.T) {
+		httpAuth := func() *gatewayv1.HTTPExternalAuthFilter {
+			return &gatewayv1.HTTPExternalAuthFilter{
+				ExternalAuthProtocol: gatewayv1.HTTPRouteExternalAuthHTTPProtocol,
+				BackendRef:           gatewayv1.BackendObjectReference{Name: "authz"},
+				HTTPAuthConfig:       &gatewayv1.HTTPAuthConfig{AllowedResponseHeaders: []string{"x-user"}},
+			}
+		}
+
+		authorizationResponse := buildExtAuthz(t, httpAuth(), settings).GetHttpService().GetAuthorizationResponse()
+		if got, want := patterns(authorizationResponse.GetAllowedUpstreamHeaders()), []string{"x-user", "X-Upstream-Token", "x-tenant.id"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected AllowedUpstreamHeaders %v, got %v", want, got)
+		}
+		if got, want := patterns(authorizationResponse.GetAllowedClientHeaders()), []string{"x-denial-reason"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected AllowedClientHeaders %v, got %v", want, got)
+		}
+
+		// Without settings, only the AllowedResponseHeaders of ExternalAuth are forwarded upstream and all the
+		// headers of a denied response are returned to the client.
+		authorizationResponse = buildExtAuthz(t, httpAuth(), nil).GetHttpService().GetAuthorizationResponse()
+		if got, want := patterns(authorizationResponse.GetAllowedUpstreamHeaders()), []string{"x-user"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected AllowedUpstreamHeaders %v, got %v", want, got)
+		}
+		if authorizationResponse.GetAllowedClientHeaders() != nil {
+			t.Errorf("expected no AllowedClientHeaders, got %v", authorizationResponse.GetAllowedClientHeaders())
+		}
+
+		// The settings alone are enough to forward headers upstream.
+		externalAuth := httpAuth()
+		externalAuth.HTTPAuthConfig.AllowedResponseHeaders = nil
+		authorizationResponse = buildExtAuthz(t, externalAuth, &apiv0alpha0.ExternalAuthSettings{
+			AllowedUpstreamHeaders: []string{"x-upstream-token"},
+		}).GetHttpService().GetAuthorizationResponse()
+		if got, want := patterns(authorizationResponse.GetAllowedUpstreamHeaders()), []string{"x-upstream-token"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected AllowedUpstreamHeaders %v, got %v", want, got)
+		}
+	})
+
+	t.Run("gRPC", func(t *testing.T) {
+		grpcAuth := &gatewayv1.HTTPExternalAuthFilter{
+			ExternalAuthProtocol: gatewayv1.HTTPRouteExternalAuthGRPCProtocol,
+			BackendRef:           gatewayv1.BackendObjectReference{Name: "authz"},
+		}
+
+		rules := buildExtAuthz(t, grpcAuth, settings).GetDecoderHeaderMutationRules()
+		if !rules.GetDisallowAll().GetValue() {
+			t.Errorf("expected header mutations to be disallowed by default")
+		}
+		if got, want := rules.GetAllowExpression().GetRegex(), `^(x-upstream-token|x-tenant\.id)$`; got != want {
+			t.Errorf("expected allow expression %q, got %q", want, got)
+		}
+
+		// Without settings, the authorizer can set any header.
+		if rules := buildExtAuthz(t, grpcAuth, nil).GetDecoderHeaderMutationRules(); rules != nil {
+			t.Errorf("expected no header mutation rules, got %v", rules)
+		}
+	})
+}
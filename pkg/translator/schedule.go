@@ -0,0 +1,195 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package translator
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
+)
+
+// windowTimeLayout is the layout of the start and end times of the windows of an AccessRule.
+const windowTimeLayout = "15:04"
+
+// windowLookaheadDays is the number of days the windows of an AccessRule are expanded for. Each window opens at
+// least once a week, so the next time a rule comes into force is always within this range.
+const windowLookaheadDays = 8
+
+// timeInterval is a half-open interval of time during which a window of an AccessRule is open.
+type timeInterval struct {
+	start, end time.Time
+}
+
+// RuleSchedule returns whether an AccessRule is in force at the given time according to its NotBefore, NotAfter
+// and Windows, along with the next time it may come into or go out of force. The next time is zero if the rule
+// never changes state again. It returns an error if the schedule cannot be evaluated, such as with an unknown
+// time zone.
+func RuleSchedule(rule agenticv0alpha0.AccessRule, now time.Time) (inForce bool, next time.Time, err error) {
+	if rule.NotAfter != nil && !now.Before(rule.NotAfter.Time) {
+		return false, time.Time{}, nil
+	}
+
+	inForce = true
+	if len(rule.Windows) > 0 {
+		intervals, err := windowIntervals(rule.Windows, now)
+		if err != nil {
+			return false, time.Time{}, err
+		}
+		inForce, next = intervalsState(intervals, now)
+	}
+	if rule.NotBefore != nil && now.Before(rule.NotBefore.Time) {
+		// The rule is evaluated again at NotBefore, when its windows may or may not be open.
+		inForce, next = false, rule.NotBefore.Time
+	}
+	if rule.NotAfter != nil && (next.IsZero() || rule.NotAfter.Time.Before(next)) {
+		next = rule.NotAfter.Time
+	}
+	return inForce, next, nil
+}
+
+// NextRuleTransition returns the earliest time after now at which a rule of the AccessPolicy may come into or go
+// out of force, or false if none of its rules ever changes state again.
+func NextRuleTransition(accessPolicy *agenticv0alpha0.XAccessPolicy, now time.Time) (time.Time, bool) {
+	var next time.Time
+	for _, rule := range accessPolicy.Spec.Rules {
+		_, ruleNext, err := RuleSchedule(rule, now)
+		if err != nil || ruleNext.IsZero() {
+			continue
+		}
+		if next.IsZero() || ruleNext.Before(next) {
+			next = ruleNext
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// ValidateRuleSchedules checks that the windows of the rules of an AccessPolicy can be evaluated. It returns an
+// error listing the invalid rules.
+func ValidateRuleSchedules(accessPolicy *agenticv0alpha0.XAccessPolicy) error {
+	var messages []string
+	for _, rule := range accessPolicy.Spec.Rules {
+		for _, window := range rule.Windows {
+			if _, _, _, err := parseWindow(window); err != nil {
+				messages = append(messages, fmt.Sprintf("rule %s: %v", rule.Name, err))
+				break
+			}
+		}
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(messages, "; "))
+}
+
+// ruleInForce returns whether the rule is in force at the time of translation. It returns an error if the
+// schedule of the rule cannot be evaluated: such an Allow rule authorizes no request and such a Deny rule denies
+// all tool calls from its sources, like rules with an invalid CEL expression.
+func (t *Translator) ruleInForce(accessPolicy *agenticv0alpha0.XAccessPolicy, rule agenticv0alpha0.AccessRule) (bool, error) {
+	inForce, _, err := RuleSchedule(rule, t.now())
+	if err != nil {
+		klog.Errorf("Failed to evaluate the schedule of rule %s of AccessPolicy %s/%s: %v", rule.Name, accessPolicy.Namespace, accessPolicy.Name, err)
+		return false, err
+	}
+	return inForce, nil
+}
+
+// now returns the current time of the Translator clock.
+func (t *Translator) now() time.Time {
+	if t.clock == nil {
+		return time.Now()
+	}
+	return t.clock.Now()
+}
+
+// parseWindow returns the time zone and the start and end times of day of a window.
+func parseWindow(window agenticv0alpha0.AccessRuleTimeWindow) (*time.Location, time.Time, time.Time, error) {
+	location := time.UTC
+	if window.TimeZone != nil {
+		var err error
+		if location, err = time.LoadLocation(*window.TimeZone); err != nil {
+			return nil, time.Time{}, time.Time{}, fmt.Errorf("unknown time zone %q", *window.TimeZone)
+		}
+	}
+	start, err := time.Parse(windowTimeLayout, window.Start)
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, fmt.Errorf("invalid start time %q", window.Start)
+	}
+	end, err := time.Parse(windowTimeLayout, window.End)
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, fmt.Errorf("invalid end time %q", window.End)
+	}
+	return location, start, end, nil
+}
+
+// windowIntervals expands the windows into the intervals during which they are open, from the day before now,
+// since a window may close on the day after it opens, to windowLookaheadDays after it.
+func windowIntervals(windows []agenticv0alpha0.AccessRuleTimeWindow, now time.Time) ([]timeInterval, error) {
+	var intervals []timeInterval
+	for _, window := range windows {
+		location, start, end, err := parseWindow(window)
+		if err != nil {
+			return nil, err
+		}
+		year, month, day := now.In(location).Date()
+		for offset := -1; offset <= windowLookaheadDays; offset++ {
+			weekday := time.Date(year, month, day+offset, 12, 0, 0, 0, location).Weekday()
+			if len(window.Days) > 0 && !slices.Contains(window.Days, agenticv0alpha0.Weekday(weekday.String())) {
+				continue
+			}
+			endOffset := offset
+			if !end.After(start) {
+				endOffset++
+			}
+			intervals = append(intervals, timeInterval{
+				start: time.Date(year, month, day+offset, start.Hour(), start.Minute(), 0, 0, location),
+				end:   time.Date(year, month, day+endOffset, end.Hour(), end.Minute(), 0, 0, location),
+			})
+		}
+	}
+	return intervals, nil
+}
+
+// intervalsState returns whether now is within any of the intervals, along with the time at which this changes:
+// the end of the overlapping intervals now is within, or the start of the next interval.
+func intervalsState(intervals []timeInterval, now time.Time) (bool, time.Time) {
+	slices.SortFunc(intervals, func(a, b timeInterval) int { return a.start.Compare(b.start) })
+	var merged []timeInterval
+	for _, interval := range intervals {
+		if last := len(merged) - 1; last >= 0 && !interval.start.After(merged[last].end) {
+			if interval.end.After(merged[last].end) {
+				merged[last].end = interval.end
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+	for _, interval := range merged {
+		if now.Before(interval.start) {
+			return false, interval.start
+		}
+		if now.Before(interval.end) {
+			return true, interval.end
+		}
+	}
+	return false, time.Time{}
+}
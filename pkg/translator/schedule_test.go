@@ -0,0 +1,284 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package translator
+
+import (
+	"testing"
+	"time"
+
+	rbacconfigv3 "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
+	rbacv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/rbac/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	testingclock "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
+	agenticlisters "sigs.k8s.io/kube-agentic-networking/k8s/client/listers/api/v0alpha0"
+)
+
+func TestRuleSchedule(t *testing.T) {
+	// Monday, June 2nd 2025.
+	monday := func(hour, minute int) time.Time { return time.Date(2025, time.June, 2, hour, minute, 0, 0, time.UTC) }
+	metaTime := func(t time.Time) *metav1.Time { return &metav1.Time{Time: t} }
+	officeHours := agenticv0alpha0.AccessRuleTimeWindow{
+		Days:  []agenticv0alpha0.Weekday{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"},
+		Start: "09:00",
+		End:   "17:00",
+	}
+
+	tests := []struct {
+		name        string
+		rule        agenticv0alpha0.AccessRule
+		now         time.Time
+		wantInForce bool
+		wantNext    time.Time
+		wantErr     bool
+	}{
+		{
+			name:        "no schedule",
+			now:         monday(10, 0),
+			wantInForce: true,
+		},
+		{
+			name:     "before notBefore",
+			rule:     agenticv0alpha0.AccessRule{NotBefore: metaTime(monday(12, 0)), NotAfter: metaTime(monday(14, 0))},
+			now:      monday(10, 0),
+			wantNext: monday(12, 0),
+		},
+		{
+			name:        "between notBefore and notAfter",
+			rule:        agenticv0alpha0.AccessRule{NotBefore: metaTime(monday(12, 0)), NotAfter: metaTime(monday(14, 0))},
+			now:         monday(12, 0),
+			wantInForce: true,
+			wantNext:    monday(14, 0),
+		},
+		{
+			name: "expired",
+			rule: agenticv0alpha0.AccessRule{NotAfter: metaTime(monday(14, 0))},
+			now:  monday(14, 0),
+		},
+		{
+			name:        "within a window",
+			rule:        agenticv0alpha0.AccessRule{Windows: []agenticv0alpha0.AccessRuleTimeWindow{officeHours}},
+			now:         monday(10, 0),
+			wantInForce: true,
+			wantNext:    monday(17, 0),
+		},
+		{
+			name:     "outside a window",
+			rule:     agenticv0alpha0.AccessRule{Windows: []agenticv0alpha0.AccessRuleTimeWindow{officeHours}},
+			now:      monday(8, 0),
+			wantNext: monday(9, 0),
+		},
+		{
+			name:     "outside a window on the weekend",
+			rule:     agenticv0alpha0.AccessRule{Windows: []agenticv0alpha0.AccessRuleTimeWindow{officeHours}},
+			now:      monday(0, 0).AddDate(0, 0, -2),
+			wantNext: monday(9, 0),
+		},
+		{
+			name: "overnight window opened the day before",
+			rule: agenticv0alpha0.AccessRule{Windows: []agenticv0alpha0.AccessRuleTimeWindow{
+				{Days: []agenticv0alpha0.Weekday{"Sunday"}, Start: "22:00", End: "06:00"},
+			}},
+			now:         monday(1, 0),
+			wantInForce: true,
+			wantNext:    monday(6, 0),
+		},
+		{
+			name: "overlapping windows",
+			rule: agenticv0alpha0.AccessRule{Windows: []agenticv0alpha0.AccessRuleTimeWindow{
+				officeHours,
+				{Start: "16:00", End: "20:00"},
+			}},
+			now:         monday(10, 0),
+			wantInForce: true,
+			wantNext:    monday(20, 0),
+		},
+		{
+			name: "window in a time zone",
+			rule: agenticv0alpha0.AccessRule{Windows: []agenticv0alpha0.AccessRuleTimeWindow{
+				{Start: "09:00", End: "17:00", TimeZone: ptr.To("Europe/Paris")},
+			}},
+			now:      monday(6, 0),
+			wantNext: monday(7, 0),
+		},
+		{
+			name: "window closes after notAfter",
+			rule: agenticv0alpha0.AccessRule{
+				NotAfter: metaTime(monday(12, 0)),
+				Windows:  []agenticv0alpha0.AccessRuleTimeWindow{officeHours},
+			},
+			now:         monday(10, 0),
+			wantInForce: true,
+			wantNext:    monday(12, 0),
+		},
+		{
+			name: "unknown time zone",
+			rule: agenticv0alpha0.AccessRule{Windows: []agenticv0alpha0.AccessRuleTimeWindow{
+				{Start: "09:00", End: "17:00", TimeZone: ptr.To("Mars/Olympus_Mons")},
+			}},
+			now:     monday(10, 0),
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			inForce, next, err := RuleSchedule(tc.rule, tc.now)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("RuleSchedule() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if inForce != tc.wantInForce {
+				t.Errorf("RuleSchedule() inForce = %v, want %v", inForce, tc.wantInForce)
+			}
+			if !next.Equal(tc.wantNext) {
+				t.Errorf("RuleSchedule() next = %v, want %v", next, tc.wantNext)
+			}
+		})
+	}
+}
+
+func TestNextRuleTransition(t *testing.T) {
+	now := time.Date(2025, time.June, 2, 10, 0, 0, 0, time.UTC)
+	policy := &agenticv0alpha0.XAccessPolicy{
+		Spec: agenticv0alpha0.AccessPolicySpec{
+			Rules: []agenticv0alpha0.AccessRule{
+				{Name: "always"},
+				{Name: "break-glass", NotAfter: &metav1.Time{Time: now.Add(4 * time.Hour)}},
+				{Name: "lunch", Windows: []agenticv0alpha0.AccessRuleTimeWindow{{Start: "12:00", End: "13:00"}}},
+			},
+		},
+	}
+	next, ok := NextRuleTransition(policy, now)
+	if !ok || !next.Equal(now.Add(2*time.Hour)) {
+		t.Errorf("NextRuleTransition() = %v, %v, want %v", next, ok, now.Add(2*time.Hour))
+	}
+
+	policy.Spec.Rules = policy.Spec.Rules[:1]
+	if next, ok := NextRuleTransition(policy, now); ok {
+		t.Errorf("NextRuleTransition() = %v, want no transition", next)
+	}
+}
+
+func TestTranslateAccessPolicy_RuleSchedule(t *testing.T) {
+	now := time.Date(2025, time.June, 2, 10, 0, 0, 0, time.UTC)
+	spiffeSource := func(sa string) *agenticv0alpha0.Source {
+		id := agenticv0alpha0.AuthorizationSourceSPIFFE(convertSAtoSPIFFEID(testTrustDomain, "default", sa))
+		return &agenticv0alpha0.Source{Type: agenticv0alpha0.AuthorizationSourceTypeSPIFFE, SPIFFE: &id}
+	}
+	tools := &agenticv0alpha0.AuthorizationRule{
+		Type:  agenticv0alpha0.AuthorizationRuleTypeInlineTools,
+		Tools: []string{"delete_repo"},
+	}
+	policy := &agenticv0alpha0.XAccessPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "policy"},
+		Spec: agenticv0alpha0.AccessPolicySpec{
+			Rules: []agenticv0alpha0.AccessRule{
+				{Name: "on-call", Source: spiffeSource("on-call"), Authorization: tools, NotAfter: &metav1.Time{Time: now.Add(time.Hour)}},
+				{Name: "expired", Source: spiffeSource("former"), Authorization: tools, NotAfter: &metav1.Time{Time: now}},
+				{Name: "deny-later", Action: agenticv0alpha0.AccessRuleActionDeny, Source: spiffeSource("agent"), Authorization: tools, NotBefore: &metav1.Time{Time: now.Add(time.Hour)}},
+				{Name: "deny-invalid", Action: agenticv0alpha0.AccessRuleActionDeny, Source: spiffeSource("untrusted"), Authorization: tools, Windows: []agenticv0alpha0.AccessRuleTimeWindow{
+					{Start: "09:00", End: "17:00", TimeZone: ptr.To("Mars/Olympus_Mons")},
+				}},
+			},
+		},
+	}
+	tr := &Translator{agenticIdentityTrustDomain: testTrustDomain, clock: testingclock.NewFakePassiveClock(now)}
+
+	// The expired Allow rule authorizes no request.
+	verifyRBACConfigContainsRule(t, tr.translatesAccessPolicyToRBAC(policy).GetRules(), map[string]expectedRule{
+		"default/policy/on-call": {
+			principals:  []string{convertSAtoSPIFFEID(testTrustDomain, "default", "on-call")},
+			permissions: []string{`(metadata["mcp_proxy"]["method"] == "tools/call" && metadata["mcp_proxy"]["params"]["name"] == "delete_repo")`},
+		},
+	})
+
+	// The Deny rule not yet in force denies no request and the Deny rule with an invalid schedule denies all
+	// tool calls from its source.
+	denyConfig := tr.translateAccessPolicyToDenyRBAC(policy)
+	if denyConfig.GetRules().GetAction() != rbacconfigv3.RBAC_DENY {
+		t.Errorf("expected DENY action, got %v", denyConfig.GetRules().GetAction())
+	}
+	verifyRBACConfigContainsRule(t, denyConfig.GetRules(), map[string]expectedRule{
+		"default/policy/deny-invalid": {
+			principals:  []string{convertSAtoSPIFFEID(testTrustDomain, "default", "untrusted")},
+			permissions: []string{`metadata["mcp_proxy"]["method"] == "tools/call"`},
+		},
+	})
+
+	// Once the break-glass access ends, its rule authorizes no request either.
+	tr.clock = testingclock.NewFakePassiveClock(now.Add(time.Hour))
+	verifyRBACConfigContainsRule(t, tr.translatesAccessPolicyToRBAC(policy).GetRules(), map[string]expectedRule{})
+	if got := len(tr.translateAccessPolicyToDenyRBAC(policy).GetRules().GetPolicies()); got != 2 {
+		t.Errorf("expected 2 deny policies once deny-later is in force, got %d", got)
+	}
+}
+
+func TestAccessPolicy_AllowRulesOutOfForce(t *testing.T) {
+	now := time.Date(2025, time.June, 2, 10, 0, 0, 0, time.UTC)
+	expire := func(policy *agenticv0alpha0.XAccessPolicy) *agenticv0alpha0.XAccessPolicy {
+		policy.Spec.Rules[0].NotAfter = &metav1.Time{Time: now}
+		policy.Spec.DefaultAllowances = &agenticv0alpha0.DefaultAllowances{
+			InitializeAndListTools: ptr.To(false),
+			CloseSession:           ptr.To(false),
+			HTTPGet:                ptr.To(false),
+		}
+		return policy
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, policy := range []*agenticv0alpha0.XAccessPolicy{
+		expire(newTestAccessPolicy("default", "backend-policy", "my-backend", "spiffe://example.com/ns/default/sa/agent")),
+		expire(newTestGatewayAccessPolicy("default", "gateway-policy", "my-gateway", "", "spiffe://example.com/ns/default/sa/agent")),
+	} {
+		if err := indexer.Add(policy); err != nil {
+			t.Fatalf("indexer.Add: %v", err)
+		}
+	}
+	lister := agenticlisters.NewXAccessPolicyLister(indexer)
+	tr := &Translator{agenticIdentityTrustDomain: testTrustDomain, clock: testingclock.NewFakePassiveClock(now), accessPolicyLister: lister}
+
+	// Once its only Allow rule expired, an AccessPolicy without default allowances allows no request, rather than
+	// leaving an RBAC filter without rules that would allow every request.
+	backend := &agenticv0alpha0.XBackend{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-backend"}}
+	rbacConfig, err := tr.rbacConfigFromAccessPolicy(lister, backend)
+	if err != nil {
+		t.Fatalf("rbacConfigFromAccessPolicy: %v", err)
+	}
+	if rules := rbacConfig.GetRules(); rules == nil || rules.GetAction() != rbacconfigv3.RBAC_ALLOW || len(rules.GetPolicies()) != 0 {
+		t.Errorf("expected an ALLOW section without policies for the backend, got %v", rules)
+	}
+
+	gateway := &gwapiv1.Gateway{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-gateway"}}
+	filters, err := tr.buildGatewayRBACFilters(gateway)
+	if err != nil {
+		t.Fatalf("buildGatewayRBACFilters: %v", err)
+	}
+	if len(filters) != 1 {
+		t.Fatalf("expected 1 filter for the Gateway, got %d", len(filters))
+	}
+	gatewayConfig := &rbacv3.RBAC{}
+	if err := filters[0].GetTypedConfig().UnmarshalTo(gatewayConfig); err != nil {
+		t.Fatalf("failed to unmarshal RBAC config: %v", err)
+	}
+	if rules := gatewayConfig.GetRules(); rules == nil || rules.GetAction() != rbacconfigv3.RBAC_ALLOW || len(rules.GetPolicies()) != 0 {
+		t.Errorf("expected an ALLOW section without policies for the Gateway, got %v", rules)
+	}
+}
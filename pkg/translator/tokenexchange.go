@@ -0,0 +1,216 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package translator
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	ext_authzv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
+	hcm "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	httpv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/upstreams/http/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	envoyproxytypes "github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
+	"sigs.k8s.io/kube-agentic-networking/pkg/constants"
+	"sigs.k8s.io/kube-agentic-networking/pkg/infra/tokenexchange"
+)
+
+const (
+	// tokenExchangeFilterName is the name of the ext_authz filter calling the token exchange server of the controller,
+	// see tokenexchange.Server. It is disabled on the listener and only enabled on the clusters of the XBackends
+	// with a token exchange, see buildPerClusterTokenExchangeConfig.
+	tokenExchangeFilterName = wellknown.HTTPExternalAuthorization + ".token_exchange"
+
+	// tokenExchangeTimeout is the timeout of the calls to the token exchange server, which may call the security
+	// token service of the XBackend.
+	tokenExchangeTimeout = 5 * time.Second
+)
+
+// buildTokenExchangeFilter builds the ext_authz filter exchanging the identity of the calling agent for a token
+// of the XBackend of the request, or nil if none of the XBackends a listener routes to has a token exchange.
+func buildTokenExchangeFilter(backends []*agenticv0alpha0.XBackend) (*hcm.HttpFilter, error) {
+	if !slices.ContainsFunc(backends, func(backend *agenticv0alpha0.XBackend) bool { return backend.Spec.MCP.TokenExchange != nil }) {
+		return nil, nil
+	}
+	extAuthzProto := &ext_authzv3.ExtAuthz{
+		TransportApiVersion: corev3.ApiVersion_V3,
+		Services: &ext_authzv3.ExtAuthz_GrpcService{
+			GrpcService: &corev3.GrpcService{
+				TargetSpecifier: &corev3.GrpcService_EnvoyGrpc_{
+					EnvoyGrpc: &corev3.GrpcService_EnvoyGrpc{ClusterName: constants.TokenExchangeClusterName},
+				},
+				Timeout: durationpb.New(tokenExchangeTimeout),
+			},
+		},
+		// Requests are never forwarded to the XBackend without a token.
+		FailureModeAllow: false,
+		StatusOnError:    &typev3.HttpStatus{Code: typev3.StatusCode_ServiceUnavailable},
+	}
+	extAuthzAny, err := anypb.New(extAuthzProto)
+	if err != nil {
+		klog.Errorf("Failed to marshal token exchange ext_authz config: %v", err)
+		return nil, err
+	}
+	return &hcm.HttpFilter{
+		Name:     tokenExchangeFilterName,
+		Disabled: true,
+		ConfigType: &hcm.HttpFilter_TypedConfig{
+			TypedConfig: extAuthzAny,
+		},
+	}, nil
+}
+
+// buildTokenExchangeCluster builds the cluster of the token exchange server of the controller, or nil if none of the
+// XBackends a Gateway routes to has a token exchange. The proxy authenticates with its agentic identity, which the
+// token exchange server requires, and only accepts the agentic identity of the controller.
+func (t *Translator) buildTokenExchangeCluster(backends []*agenticv0alpha0.XBackend) (*clusterv3.Cluster, error) {
+	if !slices.ContainsFunc(backends, func(backend *agenticv0alpha0.XBackend) bool { return backend.Spec.MCP.TokenExchange != nil }) {
+		return nil, nil
+	}
+	commonTLSContext := spiffeUpstreamCommonTLSContext([]agenticv0alpha0.AuthorizationSourceSPIFFE{
+		agenticv0alpha0.AuthorizationSourceSPIFFE(convertSAtoSPIFFEID(t.agenticIdentityTrustDomain, constants.AgenticNetSystemNamespace, constants.ControllerServiceAccountName)),
+	})
+	// gRPC servers require the negotiation of HTTP/2 with ALPN.
+	commonTLSContext.AlpnProtocols = []string{"h2"}
+	tlsAny, err := anypb.New(&tlsv3.UpstreamTlsContext{CommonTlsContext: commonTLSContext})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal UpstreamTlsContext proto: %w", err)
+	}
+	opts := &httpv3.HttpProtocolOptions{
+		UpstreamProtocolOptions: &httpv3.HttpProtocolOptions_ExplicitHttpConfig_{
+			ExplicitHttpConfig: &httpv3.HttpProtocolOptions_ExplicitHttpConfig{
+				ProtocolConfig: &httpv3.HttpProtocolOptions_ExplicitHttpConfig_Http2ProtocolOptions{
+					Http2ProtocolOptions: &corev3.Http2ProtocolOptions{},
+				},
+			},
+		},
+	}
+	optsAny, err := anypb.New(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal HttpProtocolOptions proto: %w", err)
+	}
+	serverFQDN := fmt.Sprintf("%s.%s.svc.cluster.local", constants.XDSServerServiceName, constants.AgenticNetSystemNamespace)
+	return &clusterv3.Cluster{
+		Name:                 constants.TokenExchangeClusterName,
+		ConnectTimeout:       durationpb.New(defaultConnectTimeout),
+		ClusterDiscoveryType: &clusterv3.Cluster_Type{Type: clusterv3.Cluster_STRICT_DNS},
+		LoadAssignment:       createClusterLoadAssignment(constants.TokenExchangeClusterName, serverFQDN, constants.TokenExchangePort),
+		LbPolicy:             clusterv3.Cluster_ROUND_ROBIN,
+		TypedExtensionProtocolOptions: map[string]*anypb.Any{
+			string(opts.ProtoReflect().Descriptor().FullName()): optsAny,
+		},
+		TransportSocket: &corev3.TransportSocket{
+			Name: "envoy.transport_sockets.tls",
+			ConfigType: &corev3.TransportSocket_TypedConfig{
+				TypedConfig: tlsAny,
+			},
+		},
+	}, nil
+}
+
+// TokenExchangeBackends returns the keys of the XBackends with a token exchange the routes of the given xDS resources
+// route to, which are the only XBackends the token exchange server issues tokens for to the proxy of the resources.
+func TokenExchangeBackends(resources map[resourcev3.Type][]envoyproxytypes.Resource) sets.Set[string] {
+	backends := sets.New[string]()
+	for _, resource := range resources[resourcev3.RouteType] {
+		routeConfig, ok := resource.(*routev3.RouteConfiguration)
+		if !ok {
+			continue
+		}
+		for _, virtualHost := range routeConfig.GetVirtualHosts() {
+			for _, route := range virtualHost.GetRoutes() {
+				for _, clusterWeight := range route.GetRoute().GetWeightedClusters().GetClusters() {
+					perRouteAny, ok := clusterWeight.GetTypedPerFilterConfig()[tokenExchangeFilterName]
+					if !ok {
+						continue
+					}
+					perRoute := &ext_authzv3.ExtAuthzPerRoute{}
+					if err := perRouteAny.UnmarshalTo(perRoute); err != nil {
+						klog.Errorf("Failed to unmarshal token exchange ExtAuthzPerRoute config: %v", err)
+						continue
+					}
+					if backend, ok := perRoute.GetCheckSettings().GetContextExtensions()[constants.TokenExchangeBackendContextKey]; ok {
+						backends.Insert(backend)
+					}
+				}
+			}
+		}
+	}
+	return backends
+}
+
+// buildPerClusterTokenExchangeConfig returns the TypedPerFilterConfig enabling the token exchange ext_authz filter
+// on the cluster of an XBackend, naming the XBackend to the token exchange server, or nil if the XBackend has no
+// token exchange.
+func buildPerClusterTokenExchangeConfig(backend *agenticv0alpha0.XBackend) (*anypb.Any, error) {
+	if backend == nil || backend.Spec.MCP.TokenExchange == nil {
+		return nil, nil
+	}
+	perRouteAny, err := anypb.New(&ext_authzv3.ExtAuthzPerRoute{
+		Override: &ext_authzv3.ExtAuthzPerRoute_CheckSettings{
+			CheckSettings: &ext_authzv3.CheckSettings{
+				ContextExtensions: map[string]string{
+					constants.TokenExchangeBackendContextKey: backend.Namespace + "/" + backend.Name,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal ExtAuthzPerRoute proto: %w", err)
+	}
+	return perRouteAny, nil
+}
+
+// validateBackendTokenExchange checks that the client credentials of the token exchange of an XBackend, if any,
+// can be read by the token exchange server.
+func (t *Translator) validateBackendTokenExchange(backend *agenticv0alpha0.XBackend) error {
+	tokenExchange := backend.Spec.MCP.TokenExchange
+	if tokenExchange == nil || tokenExchange.ClientSecretRef == nil {
+		return nil
+	}
+	ref := tokenExchange.ClientSecretRef
+	if ref.Group != "" || ref.Kind != "Secret" {
+		return &ControllerError{
+			Reason:  string(gatewayv1.RouteReasonBackendNotFound),
+			Message: fmt.Sprintf("invalid client credentials reference of Backend %s/%s: unsupported kind %q of %s, the client credentials must be a Secret", backend.Namespace, backend.Name, ref.Kind, ref.Name),
+		}
+	}
+	for _, key := range []string{tokenexchange.ClientIDKey, tokenexchange.ClientSecretKey} {
+		if _, err := t.secretKey(backend.Namespace, string(ref.Name), key); err != nil {
+			return &ControllerError{
+				Reason:  string(gatewayv1.RouteReasonBackendNotFound),
+				Message: fmt.Sprintf("invalid client credentials reference of Backend %s/%s: %v", backend.Namespace, backend.Name, err),
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package translator
+
+import (
+	"errors"
+	"testing"
+
+	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	ext_authzv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
+	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
+	envoyproxytypes "github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"google.golang.org/protobuf/types/known/anypb"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
+	"sigs.k8s.io/kube-agentic-networking/pkg/constants"
+)
+
+func newTokenExchangeBackend(name string, tokenExchange *agenticv0alpha0.BackendTokenExchange) *agenticv0alpha0.XBackend {
+	return &agenticv0alpha0.XBackend{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: agenticv0alpha0.BackendSpec{MCP: agenticv0alpha0.MCPBackend{
+			Hostname:      ptr.To("mcp.example.com"),
+			Port:          443,
+			TokenExchange: tokenExchange,
+		}},
+	}
+}
+
+func TestBuildTokenExchangeFilter(t *testing.T) {
+	withTokenExchange := newTokenExchangeBackend("with-token-exchange", &agenticv0alpha0.BackendTokenExchange{
+		TokenEndpoint: "https://sts.example.com/token",
+		Audience:      ptr.To("github"),
+	})
+	withoutTokenExchange := newTokenExchangeBackend("without-token-exchange", nil)
+
+	filter, err := buildTokenExchangeFilter([]*agenticv0alpha0.XBackend{withoutTokenExchange})
+	if err != nil || filter != nil {
+		t.Fatalf("expected no filter without token exchange, got %v, %v", filter, err)
+	}
+
+	filter, err = buildTokenExchangeFilter([]*agenticv0alpha0.XBackend{withoutTokenExchange, withTokenExchange})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filter.GetName() != tokenExchangeFilterName || !filter.GetDisabled() {
+		t.Errorf("expected the disabled filter %s, got %s (disabled: %t)", tokenExchangeFilterName, filter.GetName(), filter.GetDisabled())
+	}
+	extAuthz := &ext_authzv3.ExtAuthz{}
+	if err := filter.GetTypedConfig().UnmarshalTo(extAuthz); err != nil {
+		t.Fatalf("failed to unmarshal ext_authz config: %v", err)
+	}
+	if got := extAuthz.GetGrpcService().GetEnvoyGrpc().GetClusterName(); got != constants.TokenExchangeClusterName {
+		t.Errorf("expected the token exchange server to be called through cluster %s, got %s", constants.TokenExchangeClusterName, got)
+	}
+	if extAuthz.GetFailureModeAllow() {
+		t.Errorf("expected requests to be denied when the token exchange server fails")
+	}
+
+	config, err := buildPerClusterTokenExchangeConfig(withTokenExchange)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	perRoute := &ext_authzv3.ExtAuthzPerRoute{}
+	if err := config.UnmarshalTo(perRoute); err != nil {
+		t.Fatalf("failed to unmarshal ExtAuthzPerRoute config: %v", err)
+	}
+	if got := perRoute.GetCheckSettings().GetContextExtensions()[constants.TokenExchangeBackendContextKey]; got != "default/with-token-exchange" {
+		t.Errorf("expected the cluster to name XBackend default/with-token-exchange, got %q", got)
+	}
+	if config, _ := buildPerClusterTokenExchangeConfig(withoutTokenExchange); config != nil {
+		t.Errorf("expected no per-cluster config for %s, got %v", withoutTokenExchange.Name, config)
+	}
+}
+
+func TestBuildTokenExchangeCluster(t *testing.T) {
+	translator := &Translator{agenticIdentityTrustDomain: "cluster.local"}
+	withTokenExchange := newTokenExchangeBackend("with-token-exchange", &agenticv0alpha0.BackendTokenExchange{
+		TokenEndpoint: "https://sts.example.com/token",
+	})
+	withoutTokenExchange := newTokenExchangeBackend("without-token-exchange", nil)
+
+	cluster, err := translator.buildTokenExchangeCluster([]*agenticv0alpha0.XBackend{withoutTokenExchange})
+	if err != nil || cluster != nil {
+		t.Fatalf("expected no cluster without token exchange, got %v, %v", cluster, err)
+	}
+
+	cluster, err = translator.buildTokenExchangeCluster([]*agenticv0alpha0.XBackend{withoutTokenExchange, withTokenExchange})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cluster.GetName() != constants.TokenExchangeClusterName {
+		t.Errorf("expected cluster %s, got %s", constants.TokenExchangeClusterName, cluster.GetName())
+	}
+	address := cluster.GetLoadAssignment().GetEndpoints()[0].GetLbEndpoints()[0].GetEndpoint().GetAddress().GetSocketAddress()
+	if address.GetAddress() != "agentic-net-xds-server.agentic-net-system.svc.cluster.local" || address.GetPortValue() != constants.TokenExchangePort {
+		t.Errorf("expected the token exchange port of the controller Service, got %s:%d", address.GetAddress(), address.GetPortValue())
+	}
+
+	// The proxy presents its agentic identity and only accepts the agentic identity of the controller.
+	tlsContext := &tlsv3.UpstreamTlsContext{}
+	if err := cluster.GetTransportSocket().GetTypedConfig().UnmarshalTo(tlsContext); err != nil {
+		t.Fatalf("failed to unmarshal UpstreamTlsContext: %v", err)
+	}
+	commonTLSContext := tlsContext.GetCommonTlsContext()
+	if configs := commonTLSContext.GetTlsCertificateSdsSecretConfigs(); len(configs) != 1 || configs[0].GetName() != constants.SpiffeIdentitySdsConfigName {
+		t.Errorf("expected the proxy to present its agentic identity, got %v", configs)
+	}
+	matchers := commonTLSContext.GetCombinedValidationContext().GetDefaultValidationContext().GetMatchTypedSubjectAltNames()
+	if len(matchers) != 1 || matchers[0].GetMatcher().GetExact() != "spiffe://cluster.local/ns/agentic-net-system/sa/agentic-net-controller-sa" {
+		t.Errorf("expected the proxy to only accept the agentic identity of the controller, got %v", matchers)
+	}
+	if got := commonTLSContext.GetAlpnProtocols(); len(got) != 1 || got[0] != "h2" {
+		t.Errorf("expected the proxy to negotiate HTTP/2, got %v", got)
+	}
+}
+
+func TestTokenExchangeBackends(t *testing.T) {
+	perRouteConfig := func(name string, tokenExchange *agenticv0alpha0.BackendTokenExchange) map[string]*anypb.Any {
+		config, err := buildPerClusterTokenExchangeConfig(newTokenExchangeBackend(name, tokenExchange))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if config == nil {
+			return nil
+		}
+		return map[string]*anypb.Any{tokenExchangeFilterName: config}
+	}
+	tokenExchange := &agenticv0alpha0.BackendTokenExchange{TokenEndpoint: "https://sts.example.com/token"}
+	resources := map[resourcev3.Type][]envoyproxytypes.Resource{
+		resourcev3.RouteType: {&routev3.RouteConfiguration{
+			VirtualHosts: []*routev3.VirtualHost{{
+				Routes: []*routev3.Route{{
+					Action: &routev3.Route_Route{Route: &routev3.RouteAction{
+						ClusterSpecifier: &routev3.RouteAction_WeightedClusters{WeightedClusters: &routev3.WeightedCluster{
+							Clusters: []*routev3.WeightedCluster_ClusterWeight{
+								{Name: "default-github", TypedPerFilterConfig: perRouteConfig("github", tokenExchange)},
+								{Name: "default-static", TypedPerFilterConfig: perRouteConfig("static", nil)},
+							},
+						}},
+					}},
+				}},
+			}},
+		}},
+	}
+
+	if got := TokenExchangeBackends(resources); !got.Equal(sets.New("default/github")) {
+		t.Errorf("expected the XBackends with a token exchange [default/github], got %v", sets.List(got))
+	}
+}
+
+func TestValidateBackendTokenExchange(t *testing.T) {
+	secretIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	_ = secretIndexer.Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "sts-client"},
+		Data:       map[string][]byte{"client_id": []byte("gateway"), "client_secret": []byte("s3cr3t")},
+	})
+	_ = secretIndexer.Add(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "no-secret"},
+		Data:       map[string][]byte{"client_id": []byte("gateway")},
+	})
+	tr := &Translator{secretLister: corev1listers.NewSecretLister(secretIndexer)}
+	withClientSecret := func(name string) *agenticv0alpha0.XBackend {
+		return newTokenExchangeBackend("backend", &agenticv0alpha0.BackendTokenExchange{
+			TokenEndpoint:   "https://sts.example.com/token",
+			ClientSecretRef: &gatewayv1.LocalObjectReference{Kind: "Secret", Name: gatewayv1.ObjectName(name)},
+		})
+	}
+
+	tests := []struct {
+		name              string
+		backend           *agenticv0alpha0.XBackend
+		wantControllerErr bool
+	}{
+		{
+			name:    "no token exchange",
+			backend: newTokenExchangeBackend("backend", nil),
+		},
+		{
+			name:    "no client credentials",
+			backend: newTokenExchangeBackend("backend", &agenticv0alpha0.BackendTokenExchange{TokenEndpoint: "https://sts.example.com/token"}),
+		},
+		{
+			name:    "client credentials",
+			backend: withClientSecret("sts-client"),
+		},
+		{
+			name:              "missing client secret",
+			backend:           withClientSecret("no-secret"),
+			wantControllerErr: true,
+		},
+		{
+			name:              "missing Secret",
+			backend:           withClientSecret("missing"),
+			wantControllerErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tr.validateBackendTokenExchange(tc.backend)
+			if !tc.wantControllerErr {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var controllerErr *ControllerError
+			if !errors.As(err, &controllerErr) || controllerErr.Reason != string(gatewayv1.RouteReasonBackendNotFound) {
+				t.Fatalf("expected ControllerError with reason %s, got %v", gatewayv1.RouteReasonBackendNotFound, err)
+			}
+		})
+	}
+}
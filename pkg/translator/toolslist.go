@@ -0,0 +1,567 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package translator
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	luav3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/lua/v3"
+	hcm "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"k8s.io/klog/v2"
+
+	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
+	agenticlisters "sigs.k8s.io/kube-agentic-networking/k8s/client/listers/api/v0alpha0"
+)
+
+const (
+	toolsListFilterName = "envoy.filters.http.lua.tools_list"
+
+	// toolsListFilterSourceName is the name of the Lua source code referenced by the per-cluster configs.
+	toolsListFilterSourceName = "tools_list_filter"
+)
+
+// toolsListFilterScript filters the result of MCP tools/list responses down to the tools the caller
+// is authorized to call. The rules are read from the filter context of the per-cluster config, see
+// toolsListFilterContext, and are matched against the URI SANs of the peer certificate and the payloads
+// of the tokens verified by the jwt_authn filter, like the principals of the RBAC filter.
+//
+// The JSON body is scanned rather than decoded and re-encoded, so that the kept tool definitions are
+// returned byte for byte. Both application/json and text/event-stream responses are supported.
+const toolsListFilterScript = `
+local function skip_ws(s, i)
+  local _, e = s:find("^[ \t\r\n]*", i)
+  return e + 1
+end
+
+local function utf8_char(cp)
+  if cp < 0x80 then
+    return string.char(cp)
+  elseif cp < 0x800 then
+    return string.char(0xC0 + math.floor(cp / 0x40), 0x80 + cp % 0x40)
+  elseif cp < 0x10000 then
+    return string.char(0xE0 + math.floor(cp / 0x1000), 0x80 + math.floor(cp / 0x40) % 0x40, 0x80 + cp % 0x40)
+  end
+  return string.char(0xF0 + math.floor(cp / 0x40000), 0x80 + math.floor(cp / 0x1000) % 0x40, 0x80 + math.floor(cp / 0x40) % 0x40, 0x80 + cp % 0x40)
+end
+
+local escapes = { b = "\b", f = "\f", n = "\n", r = "\r", t = "\t" }
+
+-- read_string decodes the JSON string starting at s[i] and returns it with the index following it.
+local function read_string(s, i)
+  if s:sub(i, i) ~= '"' then return nil end
+  local parts = {}
+  local j = i + 1
+  while true do
+    local k = s:find('["\\]', j)
+    if k == nil then return nil end
+    parts[#parts + 1] = s:sub(j, k - 1)
+    if s:sub(k, k) == '"' then
+      return table.concat(parts), k + 1
+    end
+    local c = s:sub(k + 1, k + 1)
+    if c == "u" then
+      local cp = tonumber(s:sub(k + 2, k + 5), 16)
+      if cp == nil then return nil end
+      j = k + 6
+      if cp >= 0xD800 and cp < 0xDC00 and s:sub(j, j + 1) == "\\u" then
+        local low = tonumber(s:sub(j + 2, j + 5), 16)
+        if low ~= nil and low >= 0xDC00 and low < 0xE000 then
+          cp = 0x10000 + (cp - 0xD800) * 0x400 + (low - 0xDC00)
+          j = j + 6
+        end
+      end
+      parts[#parts + 1] = utf8_char(cp)
+    else
+      parts[#parts + 1] = escapes[c] or c
+      j = k + 2
+    end
+  end
+end
+
+-- skip_value returns the index following the JSON value starting at s[i].
+local function skip_value(s, i)
+  i = skip_ws(s, i)
+  local c = s:sub(i, i)
+  if c == '"' then
+    local _, j = read_string(s, i)
+    return j
+  end
+  if c == "{" or c == "[" then
+    local close = c == "{" and "}" or "]"
+    i = skip_ws(s, i + 1)
+    if s:sub(i, i) == close then return i + 1 end
+    while true do
+      if c == "{" then
+        local _, j = read_string(s, i)
+        if j == nil then return nil end
+        i = skip_ws(s, j)
+        if s:sub(i, i) ~= ":" then return nil end
+        i = i + 1
+      end
+      i = skip_value(s, i)
+      if i == nil then return nil end
+      i = skip_ws(s, i)
+      local d = s:sub(i, i)
+      if d == close then return i + 1 end
+      if d ~= "," then return nil end
+      i = skip_ws(s, i + 1)
+    end
+  end
+  local _, e = s:find("^[%w%.%+%-]+", i)
+  if e == nil then return nil end
+  return e + 1
+end
+
+-- find_member returns the index of the value of the given key in the JSON object starting at s[i].
+local function find_member(s, i, key)
+  i = skip_ws(s, i)
+  if s:sub(i, i) ~= "{" then return nil end
+  i = skip_ws(s, i + 1)
+  if s:sub(i, i) == "}" then return nil end
+  while true do
+    local k, j = read_string(s, i)
+    if k == nil then return nil end
+    j = skip_ws(s, j)
+    if s:sub(j, j) ~= ":" then return nil end
+    local v = skip_ws(s, j + 1)
+    if k == key then return v end
+    local e = skip_value(s, v)
+    if e == nil then return nil end
+    e = skip_ws(s, e)
+    if s:sub(e, e) ~= "," then return nil end
+    i = skip_ws(s, e + 1)
+  end
+end
+
+-- has_claim returns whether a claim, either a string or a list of strings, contains one of the accepted values.
+local function has_claim(value, accepted)
+  if type(value) == "table" then
+    for _, v in ipairs(value) do
+      if has_claim(v, accepted) then return true end
+    end
+    return false
+  end
+  for _, a in ipairs(accepted) do
+    if value == a then return true end
+  end
+  return false
+end
+
+local function has_jwt(rule, caller)
+  local payload = caller.jwt[rule.jwt.provider]
+  if payload == nil then return false end
+  for name, accepted in pairs(rule.jwt.claims or {}) do
+    if payload[name] == nil or not has_claim(payload[name], accepted) then return false end
+  end
+  return true
+end
+
+local function has_principal(rule, caller)
+  if rule.principals == nil and rule.jwt == nil then return true end
+  for _, principal in ipairs(rule.principals or {}) do
+    for _, san in ipairs(caller.sans) do
+      if principal == san then return true end
+    end
+  end
+  for _, prefix in ipairs(rule.principal_prefixes or {}) do
+    for _, san in ipairs(caller.sans) do
+      if san:sub(1, #prefix) == prefix then return true end
+    end
+  end
+  return rule.jwt ~= nil and has_jwt(rule, caller)
+end
+
+local function matches_tool(rule, name)
+  if rule.all then return true end
+  for _, tool in ipairs(rule.tools or {}) do
+    if tool == name then return true end
+  end
+  for _, prefix in ipairs(rule.prefixes or {}) do
+    if name:sub(1, #prefix) == prefix then return true end
+  end
+  for _, suffix in ipairs(rule.suffixes or {}) do
+    if #suffix <= #name and name:sub(#name - #suffix + 1) == suffix then return true end
+  end
+  return false
+end
+
+local function matches_any(rules, caller, name)
+  for _, rule in ipairs(rules or {}) do
+    if has_principal(rule, caller) and matches_tool(rule, name) then return true end
+  end
+  return false
+end
+
+-- is_authorized_by returns whether a layer of rules authorizes the caller to call a tool.
+-- A layer without allow rules only denies tools.
+local function is_authorized_by(layer, caller, name)
+  if layer.allow ~= nil and not matches_any(layer.allow, caller, name) then return false end
+  return not matches_any(layer.deny, caller, name)
+end
+
+-- is_authorized returns whether the rules of the backend and of every AccessPolicy targeting the Gateway
+-- authorize the caller to call a tool, like the RBAC filters which all have to allow the call.
+local function is_authorized(ctx, caller, name)
+  if not is_authorized_by(ctx, caller, name) then return false end
+  for _, layer in ipairs(ctx.gateway or {}) do
+    if not is_authorized_by(layer, caller, name) then return false end
+  end
+  return true
+end
+
+-- filter_tools returns the JSON-RPC message with the unauthorized tools removed from its result,
+-- or nil if the message is not a tools/list result.
+local function filter_tools(s, ctx, caller)
+  local result = find_member(s, 1, "result")
+  if result == nil then return nil end
+  local tools = find_member(s, result, "tools")
+  if tools == nil or s:sub(tools, tools) ~= "[" then return nil end
+  local i = skip_ws(s, tools + 1)
+  if s:sub(i, i) == "]" then return nil end
+  local kept = {}
+  while true do
+    local e = skip_value(s, i)
+    if e == nil then return nil end
+    local name = read_string(s, find_member(s, i, "name") or i)
+    if name ~= nil and is_authorized(ctx, caller, name) then
+      kept[#kept + 1] = s:sub(i, e - 1)
+    end
+    e = skip_ws(s, e)
+    local d = s:sub(e, e)
+    if d == "]" then
+      return s:sub(1, tools) .. table.concat(kept, ",") .. s:sub(e)
+    end
+    if d ~= "," then return nil end
+    i = skip_ws(s, e + 1)
+  end
+end
+
+-- filter_event_stream filters the tools/list results carried by the events of a text/event-stream body.
+-- The data of an event may span several "data:" lines, which are joined with newlines as per the SSE spec.
+-- The data lines of a filtered event are replaced by the filtered message, all other lines are kept.
+local function filter_event_stream(s, ctx, caller)
+  local out = {}
+  local lines = {}
+  local changed = false
+
+  local function flush_event()
+    local data = {}
+    for _, line in ipairs(lines) do
+      local value = line.text:match("^data: ?(.*)$")
+      if value ~= nil then data[#data + 1] = value end
+    end
+    local filtered
+    if #data > 0 then filtered = filter_tools(table.concat(data, "\n"), ctx, caller) end
+    local written = false
+    for _, line in ipairs(lines) do
+      if filtered ~= nil and line.text:find("^data:") then
+        if not written then
+          for value in (filtered .. "\n"):gmatch("([^\n]*)\n") do
+            out[#out + 1] = "data: " .. value .. line.eol
+          end
+          written = true
+        end
+      else
+        out[#out + 1] = line.text .. line.eol
+      end
+    end
+    if filtered ~= nil then changed = true end
+    lines = {}
+  end
+
+  local i = 1
+  while i <= #s do
+    local text, eol
+    local e = s:find("[\r\n]", i)
+    if e == nil then
+      text, eol, i = s:sub(i), "", #s + 1
+    else
+      text = s:sub(i, e - 1)
+      eol = s:sub(e, e + 1) == "\r\n" and "\r\n" or s:sub(e, e)
+      i = e + #eol
+    end
+    if text == "" then
+      flush_event()
+      out[#out + 1] = eol
+    else
+      lines[#lines + 1] = { text = text, eol = eol }
+    end
+  end
+  flush_event()
+
+  if changed then return table.concat(out) end
+  return nil
+end
+
+local function peer_uri_sans(handle)
+  local ssl = handle:streamInfo():downstreamSslConnection()
+  if ssl == nil then return {} end
+  return ssl:uriSanPeerCertificate() or {}
+end
+
+local function caller_identity(handle)
+  local jwt = handle:streamInfo():dynamicMetadata():get("envoy.filters.http.jwt_authn")
+  return { sans = peer_uri_sans(handle), jwt = jwt or {} }
+end
+
+function envoy_on_response(response_handle)
+  local ctx = response_handle:filterContext()
+  if ctx == nil or next(ctx) == nil then return end
+  local mcp = response_handle:streamInfo():dynamicMetadata():get("mcp_proxy")
+  if mcp == nil or mcp.method ~= "tools/list" then return end
+
+  local body = response_handle:body()
+  if body == nil or body:length() == 0 then return end
+  local s = body:getBytes(0, body:length())
+  local caller = caller_identity(response_handle)
+
+  local filtered
+  local content_type = response_handle:headers():get("content-type") or ""
+  if content_type:find("text/event-stream", 1, true) then
+    filtered = filter_event_stream(s, ctx, caller)
+  else
+    filtered = filter_tools(s, ctx, caller)
+  end
+  if filtered ~= nil then
+    body:setBytes(filtered)
+  end
+end
+`
+
+// buildToolsListFilter creates the Lua filter that filters tools/list responses per caller.
+// The filter is a no-op unless a per-cluster config references the filtering script.
+func buildToolsListFilter() (*hcm.HttpFilter, error) {
+	luaProto := &luav3.Lua{
+		DefaultSourceCode: &corev3.DataSource{
+			Specifier: &corev3.DataSource_InlineString{InlineString: "function envoy_on_response(response_handle) end"},
+		},
+		SourceCodes: map[string]*corev3.DataSource{
+			toolsListFilterSourceName: {
+				Specifier: &corev3.DataSource_InlineString{InlineString: toolsListFilterScript},
+			},
+		},
+		StatPrefix: "tools_list",
+	}
+	luaAny, err := anypb.New(luaProto)
+	if err != nil {
+		klog.Errorf("Failed to marshal lua config: %v", err)
+		return nil, err
+	}
+
+	return &hcm.HttpFilter{
+		Name: toolsListFilterName,
+		ConfigType: &hcm.HttpFilter_TypedConfig{
+			TypedConfig: luaAny,
+		},
+	}, nil
+}
+
+// buildPerClusterToolsListFilterConfig creates the per-cluster config of the tools/list filter for a backend.
+// It returns nil if no AccessPolicy targets the backend or the Gateway listener, in which case all tools can be
+// called and listed.
+func (t *Translator) buildPerClusterToolsListFilterConfig(accessPolicyLister agenticlisters.XAccessPolicyLister, backend *agenticv0alpha0.XBackend, gatewayAccessPolicies []*agenticv0alpha0.XAccessPolicy) (*anypb.Any, error) {
+	filterContext, err := t.toolsListFilterContext(accessPolicyLister, backend, gatewayAccessPolicies)
+	if err != nil || filterContext == nil {
+		return nil, err
+	}
+	luaPerRouteAny, err := anypb.New(&luav3.LuaPerRoute{
+		Override:      &luav3.LuaPerRoute_Name{Name: toolsListFilterSourceName},
+		FilterContext: filterContext,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal LuaPerRoute proto: %w", err)
+	}
+	return luaPerRouteAny, nil
+}
+
+// toolsListFilterContext builds the rules used by the tools/list filter for a backend from the same
+// AccessPolicies that are translated into its RBAC configs, and from the AccessPolicies targeting the Gateway
+// listener, each of which is enforced by its own RBAC filters. It returns nil if no enforced AccessPolicy applies,
+// since AccessPolicies in Audit mode do not hide any tool.
+//
+// Tools matched by regular expressions cannot be evaluated in Lua, so all tools are listed to the callers of
+// rules using them. Tools authorized by an external authorizer or a CEL expression are all listed too, since
+// the decision is only known per call.
+func (t *Translator) toolsListFilterContext(accessPolicyLister agenticlisters.XAccessPolicyLister, backend *agenticv0alpha0.XBackend, gatewayAccessPolicies []*agenticv0alpha0.XAccessPolicy) (*structpb.Struct, error) {
+	accessPolicies, err := AccessPoliciesForBackend(backend, accessPolicyLister)
+	if err != nil {
+		return nil, err
+	}
+	enforced, _ := splitAccessPoliciesByMode(accessPolicies)
+	enforcedGateway, _ := splitAccessPoliciesByMode(gatewayAccessPolicies)
+	if len(enforced) == 0 && len(enforcedGateway) == 0 {
+		return nil, nil
+	}
+
+	filterContext := t.toolsListLayer(enforced)
+	gateway := []interface{}{}
+	for _, accessPolicy := range enforcedGateway {
+		gateway = append(gateway, t.toolsListLayer([]*agenticv0alpha0.XAccessPolicy{accessPolicy}))
+	}
+	if len(gateway) > 0 {
+		filterContext["gateway"] = gateway
+	}
+	return structpb.NewStruct(filterContext)
+}
+
+// toolsListLayer builds the tools/list filter rules of AccessPolicies enforced by the same RBAC filters.
+// The layer has no allow rules if none of the AccessPolicies has any, since such policies only deny requests.
+func (t *Translator) toolsListLayer(accessPolicies []*agenticv0alpha0.XAccessPolicy) map[string]interface{} {
+	allow := []interface{}{}
+	deny := []interface{}{}
+	for _, accessPolicy := range accessPolicies {
+		for _, rule := range accessPolicy.Spec.Rules {
+			inForce, scheduleErr := t.ruleInForce(accessPolicy, rule)
+			if rule.Action == agenticv0alpha0.AccessRuleActionDeny {
+				authorization := rule.Authorization
+				if scheduleErr != nil {
+					// A Deny rule with an invalid schedule denies all tool calls from its sources.
+					authorization = nil
+				} else if !inForce {
+					continue
+				}
+				if toolsRule := toolsListDenyRule(authorization); toolsRule != nil {
+					deny = append(deny, t.withRulePrincipals(toolsRule, accessPolicy, rule)...)
+				}
+				continue
+			}
+			if !inForce {
+				continue
+			}
+			if toolsRule := toolsListAllowRule(rule.Authorization); toolsRule != nil {
+				allow = append(allow, t.withRulePrincipals(toolsRule, accessPolicy, rule)...)
+			}
+		}
+	}
+
+	layer := map[string]interface{}{"deny": deny}
+	if slices.ContainsFunc(accessPolicies, hasAllowRules) {
+		layer["allow"] = allow
+	}
+	return layer
+}
+
+// toolsListAllowRule returns the tools/list filter rule listing the tools authorized by an Allow rule,
+// or nil if the rule does not authorize any tool.
+func toolsListAllowRule(authorization *agenticv0alpha0.AuthorizationRule) map[string]interface{} {
+	if authorization == nil {
+		return nil
+	}
+	switch authorization.Type {
+	case agenticv0alpha0.AuthorizationRuleTypeInlineTools:
+		// Tools with argument constraints are listed, since they can be called with some arguments.
+		if slices.ContainsFunc(authorization.ToolPatterns, func(pattern agenticv0alpha0.ToolNameMatch) bool {
+			return pattern.Type == agenticv0alpha0.ToolNameMatchRegularExpression
+		}) {
+			return map[string]interface{}{"all": true}
+		}
+		return toolsListToolsRule(authorization)
+	case agenticv0alpha0.AuthorizationRuleTypeExternalAuth, agenticv0alpha0.AuthorizationRuleTypeCEL:
+		return map[string]interface{}{"all": true}
+	default:
+		return nil
+	}
+}
+
+// toolsListDenyRule returns the tools/list filter rule hiding the tools denied by a Deny rule,
+// or nil if the rule does not deny any tool entirely.
+func toolsListDenyRule(authorization *agenticv0alpha0.AuthorizationRule) map[string]interface{} {
+	if authorization == nil {
+		return map[string]interface{}{"all": true}
+	}
+	if authorization.Type != agenticv0alpha0.AuthorizationRuleTypeInlineTools || len(authorization.Arguments) > 0 {
+		return nil
+	}
+	return toolsListToolsRule(authorization)
+}
+
+func toolsListToolsRule(authorization *agenticv0alpha0.AuthorizationRule) map[string]interface{} {
+	tools := []interface{}{}
+	for _, tool := range authorization.Tools {
+		tools = append(tools, tool)
+	}
+	prefixes := []interface{}{}
+	suffixes := []interface{}{}
+	for _, pattern := range authorization.ToolPatterns {
+		switch pattern.Type {
+		case agenticv0alpha0.ToolNameMatchPrefix:
+			prefixes = append(prefixes, pattern.Value)
+		case agenticv0alpha0.ToolNameMatchSuffix:
+			suffixes = append(suffixes, pattern.Value)
+		}
+	}
+	return map[string]interface{}{
+		"tools":    tools,
+		"prefixes": prefixes,
+		"suffixes": suffixes,
+	}
+}
+
+// withRulePrincipals restricts a tools/list filter rule to the sources of an AccessRule, returning a copy of the
+// filter rule for each of them.
+func (t *Translator) withRulePrincipals(toolsRule map[string]interface{}, accessPolicy *agenticv0alpha0.XAccessPolicy, rule agenticv0alpha0.AccessRule) []interface{} {
+	var toolsRules []interface{}
+	for _, source := range RuleSources(rule) {
+		toolsRules = append(toolsRules, t.withSourcePrincipals(maps.Clone(toolsRule), accessPolicy, rule, source))
+	}
+	return toolsRules
+}
+
+// withSourcePrincipals restricts a tools/list filter rule to a source of an AccessRule.
+// Rules without principals apply to any caller.
+func (t *Translator) withSourcePrincipals(toolsRule map[string]interface{}, accessPolicy *agenticv0alpha0.XAccessPolicy, rule agenticv0alpha0.AccessRule, source agenticv0alpha0.Source) map[string]interface{} {
+	if oidc := sourceOIDC(source); oidc != nil {
+		// If the provider name cannot be generated, the empty name never matches, like the RBAC principal.
+		providerName, _ := jwtProviderName(accessPolicy.Namespace, oidc)
+		claims := map[string]interface{}{}
+		for _, claim := range oidcClaims(oidc) {
+			var values []interface{}
+			for _, value := range claim.values {
+				values = append(values, value)
+			}
+			claims[claim.name] = values
+		}
+		toolsRule["jwt"] = map[string]interface{}{
+			"provider": providerName,
+			"claims":   claims,
+		}
+		return toolsRule
+	}
+	sourceIDs, ok := t.ruleSourceIDs(accessPolicy, rule, source)
+	if !ok {
+		return toolsRule
+	}
+	principals := []interface{}{}
+	principalPrefixes := []interface{}{}
+	for _, sourceID := range sourceIDs {
+		if sourceID.prefix {
+			principalPrefixes = append(principalPrefixes, sourceID.value)
+			continue
+		}
+		principals = append(principals, sourceID.value)
+	}
+	toolsRule["principals"] = principals
+	if len(principalPrefixes) > 0 {
+		toolsRule["principal_prefixes"] = principalPrefixes
+	}
+	return toolsRule
+}
@@ -0,0 +1,680 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package translator
+
+import (
+	"reflect"
+	"testing"
+
+	luav3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/lua/v3"
+	lua "github.com/yuin/gopher-lua"
+	"google.golang.org/protobuf/types/known/structpb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
+	agenticlisters "sigs.k8s.io/kube-agentic-networking/k8s/client/listers/api/v0alpha0"
+)
+
+func TestToolsListFilterContext(t *testing.T) {
+	policy := newTestAccessPolicy("default", "policy-1", "my-backend", "spiffe://example.com/ns/default/sa/agent")
+	policy.Spec.Rules[0].Authorization = &agenticv0alpha0.AuthorizationRule{
+		Type:  agenticv0alpha0.AuthorizationRuleTypeInlineTools,
+		Tools: []string{"read_file"},
+		ToolPatterns: []agenticv0alpha0.ToolNameMatch{
+			{Type: agenticv0alpha0.ToolNameMatchPrefix, Value: "github_"},
+			{Type: agenticv0alpha0.ToolNameMatchSuffix, Value: "_read"},
+		},
+	}
+	policy.Spec.Rules = append(policy.Spec.Rules,
+		agenticv0alpha0.AccessRule{
+			Name: "regex",
+			Source: &agenticv0alpha0.Source{
+				Type:           agenticv0alpha0.AuthorizationSourceTypeServiceAccount,
+				ServiceAccount: &agenticv0alpha0.AuthorizationSourceServiceAccount{Name: "lister"},
+			},
+			Authorization: &agenticv0alpha0.AuthorizationRule{
+				Type:  agenticv0alpha0.AuthorizationRuleTypeInlineTools,
+				Tools: []string{"read_file"},
+				ToolPatterns: []agenticv0alpha0.ToolNameMatch{
+					{Type: agenticv0alpha0.ToolNameMatchRegularExpression, Value: "(get|list)_[a-z]+"},
+				},
+			},
+		},
+		agenticv0alpha0.AccessRule{
+			Name: "deny-delete",
+			Source: &agenticv0alpha0.Source{
+				Type:           agenticv0alpha0.AuthorizationSourceTypeServiceAccount,
+				ServiceAccount: &agenticv0alpha0.AuthorizationSourceServiceAccount{Name: "agent"},
+			},
+			Action: agenticv0alpha0.AccessRuleActionDeny,
+			Authorization: &agenticv0alpha0.AuthorizationRule{
+				Type:  agenticv0alpha0.AuthorizationRuleTypeInlineTools,
+				Tools: []string{"github_delete_repo"},
+			},
+		},
+		agenticv0alpha0.AccessRule{
+			Name: "deny-write-outside-workspace",
+			Source: &agenticv0alpha0.Source{
+				Type:           agenticv0alpha0.AuthorizationSourceTypeServiceAccount,
+				ServiceAccount: &agenticv0alpha0.AuthorizationSourceServiceAccount{Name: "agent"},
+			},
+			Action: agenticv0alpha0.AccessRuleActionDeny,
+			Authorization: &agenticv0alpha0.AuthorizationRule{
+				Type:  agenticv0alpha0.AuthorizationRuleTypeInlineTools,
+				Tools: []string{"write_file"},
+				Arguments: []agenticv0alpha0.ToolArgumentMatch{
+					{Name: "path", Type: agenticv0alpha0.ToolArgumentMatchPrefix, Value: "/etc/"},
+				},
+			},
+		},
+		agenticv0alpha0.AccessRule{
+			Name: "ext-authz",
+			Source: &agenticv0alpha0.Source{
+				Type:           agenticv0alpha0.AuthorizationSourceTypeServiceAccount,
+				ServiceAccount: &agenticv0alpha0.AuthorizationSourceServiceAccount{Namespace: "ops", Name: "admin"},
+			},
+			Authorization: &agenticv0alpha0.AuthorizationRule{
+				Type: agenticv0alpha0.AuthorizationRuleTypeExternalAuth,
+				ExternalAuth: &gwapiv1.HTTPExternalAuthFilter{
+					ExternalAuthProtocol: gwapiv1.HTTPRouteExternalAuthGRPCProtocol,
+					BackendRef:           gwapiv1.BackendObjectReference{Name: "authz"},
+				},
+			},
+		},
+		agenticv0alpha0.AccessRule{
+			Name: "read-resources",
+			Source: &agenticv0alpha0.Source{
+				Type:           agenticv0alpha0.AuthorizationSourceTypeServiceAccount,
+				ServiceAccount: &agenticv0alpha0.AuthorizationSourceServiceAccount{Name: "reader"},
+			},
+			Authorization: &agenticv0alpha0.AuthorizationRule{
+				Type:      agenticv0alpha0.AuthorizationRuleTypeInlineResources,
+				Resources: []agenticv0alpha0.ResourceURIMatch{{Type: agenticv0alpha0.ResourceURIMatchPrefix, Value: "file:///"}},
+			},
+		},
+		agenticv0alpha0.AccessRule{
+			Name: "team-a",
+			Source: &agenticv0alpha0.Source{
+				Type:      agenticv0alpha0.AuthorizationSourceTypeNamespace,
+				Namespace: ptr.To(gwapiv1.Namespace("team-a")),
+			},
+			Authorization: &agenticv0alpha0.AuthorizationRule{
+				Type:  agenticv0alpha0.AuthorizationRuleTypeInlineTools,
+				Tools: []string{"search"},
+			},
+		},
+	)
+	otherPolicy := newTestAccessPolicy("default", "other", "other-backend", "spiffe://example.com/ns/default/sa/other")
+	otherPolicy.Spec.Rules[0].Authorization = &agenticv0alpha0.AuthorizationRule{
+		Type:  agenticv0alpha0.AuthorizationRuleTypeInlineTools,
+		Tools: []string{"other_tool"},
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+	for _, p := range []*agenticv0alpha0.XAccessPolicy{policy, otherPolicy} {
+		if err := indexer.Add(p); err != nil {
+			t.Fatalf("indexer.Add: %v", err)
+		}
+	}
+	backend := &agenticv0alpha0.XBackend{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-backend"},
+	}
+
+	tr := &Translator{agenticIdentityTrustDomain: "example.com"}
+	filterContext, err := tr.toolsListFilterContext(agenticlisters.NewXAccessPolicyLister(indexer), backend, nil)
+	if err != nil {
+		t.Fatalf("toolsListFilterContext: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"allow": []interface{}{
+			map[string]interface{}{
+				"principals": []interface{}{"spiffe://example.com/ns/default/sa/agent"},
+				"tools":      []interface{}{"read_file"},
+				"prefixes":   []interface{}{"github_"},
+				"suffixes":   []interface{}{"_read"},
+			},
+			map[string]interface{}{
+				// Regular expressions cannot be evaluated by the filter, so all tools are listed.
+				"principals": []interface{}{"spiffe://example.com/ns/default/sa/lister"},
+				"all":        true,
+			},
+			map[string]interface{}{
+				"principals": []interface{}{"spiffe://example.com/ns/ops/sa/admin"},
+				"all":        true,
+			},
+			map[string]interface{}{
+				"principals":         []interface{}{},
+				"principal_prefixes": []interface{}{"spiffe://example.com/ns/team-a/sa/"},
+				"tools":              []interface{}{"search"},
+				"prefixes":           []interface{}{},
+				"suffixes":           []interface{}{},
+			},
+		},
+		"deny": []interface{}{
+			map[string]interface{}{
+				"principals": []interface{}{"spiffe://example.com/ns/default/sa/agent"},
+				"tools":      []interface{}{"github_delete_repo"},
+				"prefixes":   []interface{}{},
+				"suffixes":   []interface{}{},
+			},
+		},
+	}
+	if got := filterContext.AsMap(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected filter context:\ngot:  %v\nwant: %v", got, expected)
+	}
+}
+
+func TestToolsListLayer_MultipleSources(t *testing.T) {
+	policy := newTestAccessPolicy("default", "policy-1", "my-backend", "spiffe://example.com/ns/default/sa/agent")
+	oidc := &agenticv0alpha0.AuthorizationSourceOIDC{
+		Issuer: "https://issuer.example.com",
+		JWKS:   agenticv0alpha0.JWKS{Inline: ptr.To(testJWKS)},
+	}
+	policy.Spec.Rules[0].Sources = []agenticv0alpha0.Source{
+		*policy.Spec.Rules[0].Source,
+		{Type: agenticv0alpha0.AuthorizationSourceTypeOIDC, OIDC: oidc},
+	}
+	policy.Spec.Rules[0].Source = nil
+	policy.Spec.Rules[0].Authorization = &agenticv0alpha0.AuthorizationRule{
+		Type:  agenticv0alpha0.AuthorizationRuleTypeInlineTools,
+		Tools: []string{"read_file"},
+	}
+
+	tr := &Translator{agenticIdentityTrustDomain: testTrustDomain}
+	allow, _ := tr.toolsListLayer([]*agenticv0alpha0.XAccessPolicy{policy})["allow"].([]interface{})
+	if len(allow) != 2 {
+		t.Fatalf("expected a tools/list rule per source, got %v", allow)
+	}
+	spiffeRule := allow[0].(map[string]interface{})
+	if principals := spiffeRule["principals"]; !reflect.DeepEqual(principals, []interface{}{"spiffe://example.com/ns/default/sa/agent"}) {
+		t.Errorf("expected the SPIFFE ID of the first source, got %v", principals)
+	}
+	if _, ok := spiffeRule["jwt"]; ok {
+		t.Errorf("expected the rule of the SPIFFE source not to require a token, got %v", spiffeRule)
+	}
+	providerName, _ := jwtProviderName("default", oidc)
+	oidcRule := allow[1].(map[string]interface{})
+	if jwt, _ := oidcRule["jwt"].(map[string]interface{}); jwt["provider"] != providerName {
+		t.Errorf("expected the rule of the OIDC source to require a token of provider %q, got %v", providerName, oidcRule)
+	}
+	if _, ok := oidcRule["principals"]; ok {
+		t.Errorf("expected the rule of the OIDC source not to match principals, got %v", oidcRule)
+	}
+	for _, toolsRule := range allow {
+		if tools := toolsRule.(map[string]interface{})["tools"]; !reflect.DeepEqual(tools, []interface{}{"read_file"}) {
+			t.Errorf("expected the tools of the rule, got %v", tools)
+		}
+	}
+}
+
+func TestBuildPerClusterRBACFilterConfig_ToolsListFilter(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+	if err := indexer.Add(newTestAccessPolicy("default", "policy-1", "my-backend", "spiffe://example.com/ns/default/sa/agent")); err != nil {
+		t.Fatalf("indexer.Add: %v", err)
+	}
+	lister := agenticlisters.NewXAccessPolicyLister(indexer)
+	tr := &Translator{}
+
+	t.Run("backend targeted by an AccessPolicy", func(t *testing.T) {
+		backend := &agenticv0alpha0.XBackend{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-backend"},
+		}
+		perFilterConfig, err := tr.buildPerClusterRBACFilterConfig(lister, backend, nil)
+		if err != nil {
+			t.Fatalf("buildPerClusterRBACFilterConfig: %v", err)
+		}
+
+		luaAny, ok := perFilterConfig[toolsListFilterName]
+		if !ok {
+			t.Fatalf("expected per-cluster config for %s", toolsListFilterName)
+		}
+		luaPerRoute := &luav3.LuaPerRoute{}
+		if err := luaAny.UnmarshalTo(luaPerRoute); err != nil {
+			t.Fatalf("failed to unmarshal LuaPerRoute: %v", err)
+		}
+		if luaPerRoute.GetName() != toolsListFilterSourceName {
+			t.Errorf("expected source code %q, got %q", toolsListFilterSourceName, luaPerRoute.GetName())
+		}
+		// The rule of the AccessPolicy has no authorization, so no tool must be listed.
+		if allow := luaPerRoute.GetFilterContext().GetFields()["allow"].GetListValue(); allow == nil || len(allow.GetValues()) != 0 {
+			t.Errorf("expected an empty allow list, got %v", allow)
+		}
+	})
+
+	t.Run("backend not targeted by any AccessPolicy", func(t *testing.T) {
+		backend := &agenticv0alpha0.XBackend{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other-backend"},
+		}
+		perFilterConfig, err := tr.buildPerClusterRBACFilterConfig(lister, backend, nil)
+		if err != nil {
+			t.Fatalf("buildPerClusterRBACFilterConfig: %v", err)
+		}
+		// All tools can be called, so none must be filtered out.
+		if _, ok := perFilterConfig[toolsListFilterName]; ok {
+			t.Errorf("unexpected per-cluster config for %s", toolsListFilterName)
+		}
+	})
+}
+
+func TestToolsListFilterContext_GatewayAccessPolicies(t *testing.T) {
+	backendPolicy := newTestAccessPolicy("default", "deny-only", "my-backend", "spiffe://example.com/ns/default/sa/agent")
+	backendPolicy.Spec.Rules[0].Action = agenticv0alpha0.AccessRuleActionDeny
+	backendPolicy.Spec.Rules[0].Authorization = &agenticv0alpha0.AuthorizationRule{
+		Type:  agenticv0alpha0.AuthorizationRuleTypeInlineTools,
+		Tools: []string{"delete_file"},
+	}
+	gatewayPolicy := newTestGatewayAccessPolicy("default", "gateway", "my-gateway", "", "spiffe://example.com/ns/default/sa/agent")
+	gatewayPolicy.Spec.Rules[0].Authorization = &agenticv0alpha0.AuthorizationRule{
+		Type:         agenticv0alpha0.AuthorizationRuleTypeInlineTools,
+		ToolPatterns: []agenticv0alpha0.ToolNameMatch{{Type: agenticv0alpha0.ToolNameMatchPrefix, Value: "read_"}},
+	}
+	auditedPolicy := newTestGatewayAccessPolicy("default", "audited", "my-gateway", "", "spiffe://example.com/ns/default/sa/agent")
+	auditedPolicy.Spec.Mode = agenticv0alpha0.AccessPolicyModeAudit
+
+	tr := &Translator{agenticIdentityTrustDomain: "example.com"}
+	gatewayAccessPolicies := []*agenticv0alpha0.XAccessPolicy{gatewayPolicy, auditedPolicy}
+
+	t.Run("backend and gateway policies", func(t *testing.T) {
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		if err := indexer.Add(backendPolicy); err != nil {
+			t.Fatalf("indexer.Add: %v", err)
+		}
+		backend := &agenticv0alpha0.XBackend{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-backend"}}
+		filterContext, err := tr.toolsListFilterContext(agenticlisters.NewXAccessPolicyLister(indexer), backend, gatewayAccessPolicies)
+		if err != nil {
+			t.Fatalf("toolsListFilterContext: %v", err)
+		}
+
+		expected := map[string]interface{}{
+			// The backend policy only denies tools, so it does not restrict the listed tools otherwise.
+			"deny": []interface{}{
+				map[string]interface{}{
+					"principals": []interface{}{"spiffe://example.com/ns/default/sa/agent"},
+					"tools":      []interface{}{"delete_file"},
+					"prefixes":   []interface{}{},
+					"suffixes":   []interface{}{},
+				},
+			},
+			"gateway": []interface{}{
+				map[string]interface{}{
+					"allow": []interface{}{
+						map[string]interface{}{
+							"principals": []interface{}{"spiffe://example.com/ns/default/sa/agent"},
+							"tools":      []interface{}{},
+							"prefixes":   []interface{}{"read_"},
+							"suffixes":   []interface{}{},
+						},
+					},
+					"deny": []interface{}{},
+				},
+			},
+		}
+		if got := filterContext.AsMap(); !reflect.DeepEqual(got, expected) {
+			t.Errorf("unexpected filter context:\ngot:  %v\nwant: %v", got, expected)
+		}
+	})
+
+	t.Run("gateway policies only", func(t *testing.T) {
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		backend := &agenticv0alpha0.XBackend{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-backend"}}
+		filterContext, err := tr.toolsListFilterContext(agenticlisters.NewXAccessPolicyLister(indexer), backend, gatewayAccessPolicies)
+		if err != nil {
+			t.Fatalf("toolsListFilterContext: %v", err)
+		}
+		if filterContext == nil {
+			t.Fatal("expected a filter context for the AccessPolicy targeting the Gateway")
+		}
+		if _, ok := filterContext.AsMap()["allow"]; ok {
+			t.Errorf("unexpected backend allow rules: %v", filterContext.AsMap())
+		}
+		if gateway := filterContext.AsMap()["gateway"].([]interface{}); len(gateway) != 1 {
+			t.Errorf("expected the rules of 1 enforced gateway policy, got %v", gateway)
+		}
+	})
+
+	t.Run("audited gateway policies only", func(t *testing.T) {
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		backend := &agenticv0alpha0.XBackend{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-backend"}}
+		filterContext, err := tr.toolsListFilterContext(agenticlisters.NewXAccessPolicyLister(indexer), backend, []*agenticv0alpha0.XAccessPolicy{auditedPolicy})
+		if err != nil {
+			t.Fatalf("toolsListFilterContext: %v", err)
+		}
+		if filterContext != nil {
+			t.Errorf("unexpected filter context: %v", filterContext.AsMap())
+		}
+	})
+}
+
+// toolsListFilterTestHandle mocks the parts of the Envoy Lua response handle used by the tools/list filter.
+const toolsListFilterTestHandle = `
+function new_response_handle(ctx, sans, jwt, method, content_type, body)
+  local handle = { output = body }
+  local buffer = {
+    length = function(self) return #handle.output end,
+    getBytes = function(self, i, n) return handle.output:sub(i + 1, i + n) end,
+    setBytes = function(self, s) handle.output = s end,
+  }
+  local ssl = { uriSanPeerCertificate = function(self) return sans end }
+  local metadata = {
+    get = function(self, name)
+      if name == "mcp_proxy" then return { method = method } end
+      if name == "envoy.filters.http.jwt_authn" then return jwt end
+      return nil
+    end,
+  }
+  local stream_info = {
+    downstreamSslConnection = function(self) if sans == nil then return nil end return ssl end,
+    dynamicMetadata = function(self) return metadata end,
+  }
+  local headers = { get = function(self, name) if name == "content-type" then return content_type end return nil end }
+  handle.filterContext = function(self) return ctx end
+  handle.streamInfo = function(self) return stream_info end
+  handle.headers = function(self) return headers end
+  handle.body = function(self) return buffer end
+  return handle
+end
+`
+
+// toolsListCaller is the identity of the caller of a tools/list request.
+type toolsListCaller struct {
+	sans []string
+	jwt  map[string]interface{}
+}
+
+// runToolsListFilter runs the tools/list filter script on a response body and returns the resulting body.
+func runToolsListFilter(t *testing.T, filterContext map[string]interface{}, caller toolsListCaller, method, contentType, body string) string {
+	t.Helper()
+	L := lua.NewState()
+	defer L.Close()
+	if err := L.DoString(toolsListFilterScript + toolsListFilterTestHandle); err != nil {
+		t.Fatalf("failed to load the tools/list filter script: %v", err)
+	}
+
+	toLua := func(v interface{}) lua.LValue {
+		value, err := structpb.NewValue(v)
+		if err != nil {
+			t.Fatalf("structpb.NewValue: %v", err)
+		}
+		return structValueToLua(L, value)
+	}
+	var sans lua.LValue = lua.LNil
+	if caller.sans != nil {
+		list := []interface{}{}
+		for _, san := range caller.sans {
+			list = append(list, san)
+		}
+		sans = toLua(list)
+	}
+	var jwt lua.LValue = lua.LNil
+	if caller.jwt != nil {
+		jwt = toLua(caller.jwt)
+	}
+	var ctx lua.LValue = lua.LNil
+	if filterContext != nil {
+		ctx = toLua(filterContext)
+	}
+
+	if err := L.CallByParam(lua.P{Fn: L.GetGlobal("new_response_handle"), NRet: 1, Protect: true},
+		ctx, sans, jwt, lua.LString(method), lua.LString(contentType), lua.LString(body)); err != nil {
+		t.Fatalf("new_response_handle: %v", err)
+	}
+	handle := L.Get(-1)
+	L.Pop(1)
+	if err := L.CallByParam(lua.P{Fn: L.GetGlobal("envoy_on_response"), NRet: 0, Protect: true}, handle); err != nil {
+		t.Fatalf("envoy_on_response: %v", err)
+	}
+	return lua.LVAsString(L.GetField(handle, "output"))
+}
+
+// structValueToLua converts a protobuf Value into a Lua value, the way Envoy exposes the filter context.
+func structValueToLua(L *lua.LState, value *structpb.Value) lua.LValue {
+	switch v := value.GetKind().(type) {
+	case *structpb.Value_StringValue:
+		return lua.LString(v.StringValue)
+	case *structpb.Value_NumberValue:
+		return lua.LNumber(v.NumberValue)
+	case *structpb.Value_BoolValue:
+		return lua.LBool(v.BoolValue)
+	case *structpb.Value_StructValue:
+		table := L.NewTable()
+		for key, field := range v.StructValue.GetFields() {
+			table.RawSetString(key, structValueToLua(L, field))
+		}
+		return table
+	case *structpb.Value_ListValue:
+		table := L.NewTable()
+		for _, item := range v.ListValue.GetValues() {
+			table.Append(structValueToLua(L, item))
+		}
+		return table
+	default:
+		return lua.LNil
+	}
+}
+
+func TestToolsListFilterScript(t *testing.T) {
+	agent := toolsListCaller{sans: []string{"spiffe://example.com/ns/default/sa/agent"}}
+	filterContext := map[string]interface{}{
+		"allow": []interface{}{
+			map[string]interface{}{
+				"principals": []interface{}{"spiffe://example.com/ns/default/sa/agent"},
+				"tools":      []interface{}{"read_file", "na\"me", "\U0001F600_tool"},
+				"prefixes":   []interface{}{"github_"},
+				"suffixes":   []interface{}{"_read"},
+			},
+			map[string]interface{}{
+				"principals":         []interface{}{},
+				"principal_prefixes": []interface{}{"spiffe://example.com/ns/team-a/sa/"},
+				"tools":              []interface{}{"search"},
+			},
+			map[string]interface{}{
+				"jwt": map[string]interface{}{
+					"provider": "oidc-1",
+					"claims":   map[string]interface{}{"groups": []interface{}{"admins"}},
+				},
+				"all": true,
+			},
+		},
+		"deny": []interface{}{
+			map[string]interface{}{
+				"principals": []interface{}{"spiffe://example.com/ns/default/sa/agent"},
+				"tools":      []interface{}{"github_delete_repo"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		filterContext map[string]interface{}
+		caller        toolsListCaller
+		method        string
+		contentType   string
+		body          string
+		want          string
+	}{
+		{
+			name:        "unauthorized tools are removed byte for byte",
+			caller:      agent,
+			contentType: "application/json",
+			body:        `{"jsonrpc":"2.0","id":1,"result":{"tools":[ {"name":"read_file", "description":"Reads a file"} , {"name":"write_file"},{"name":"github_delete_repo"},{"name":"github_list_repos"},{"name":"logs_read"}]}}`,
+			want:        `{"jsonrpc":"2.0","id":1,"result":{"tools":[{"name":"read_file", "description":"Reads a file"},{"name":"github_list_repos"},{"name":"logs_read"}]}}`,
+		},
+		{
+			name:        "escaped names",
+			caller:      agent,
+			contentType: "application/json",
+			body:        `{"result":{"tools":[{"name":"read_file"},{"name":"na\"me"},{"name":"na\\me"}]}}`,
+			want:        `{"result":{"tools":[{"name":"read_file"},{"name":"na\"me"}]}}`,
+		},
+		{
+			name:        "surrogate pairs",
+			caller:      agent,
+			contentType: "application/json",
+			body:        `{"result":{"tools":[{"name":"\ud83d\ude00_tool"},{"name":"\ud83d\ude01_tool"},{"name":"\u0072ead_file"}]}}`,
+			want:        `{"result":{"tools":[{"name":"\ud83d\ude00_tool"},{"name":"\u0072ead_file"}]}}`,
+		},
+		{
+			name:        "nested name keys are ignored",
+			caller:      agent,
+			contentType: "application/json",
+			body:        `{"result":{"tools":[{"inputSchema":{"properties":{"name":{"type":"string"}},"name":"read_file"},"name":"write_file"},{"name":"read_file","inputSchema":{"name":"write_file"}}]}}`,
+			want:        `{"result":{"tools":[{"name":"read_file","inputSchema":{"name":"write_file"}}]}}`,
+		},
+		{
+			name:        "empty tools array",
+			caller:      agent,
+			contentType: "application/json",
+			body:        `{"result":{"tools":[]}}`,
+			want:        `{"result":{"tools":[]}}`,
+		},
+		{
+			name:        "all tools removed",
+			caller:      agent,
+			contentType: "application/json",
+			body:        `{"result":{"tools":[{"name":"write_file"}],"nextCursor":"abc"}}`,
+			want:        `{"result":{"tools":[],"nextCursor":"abc"}}`,
+		},
+		{
+			name:        "unparsable body is returned unchanged",
+			caller:      agent,
+			contentType: "application/json",
+			body:        `{"result":{"tools":[{"name":"write_file"},{"name":`,
+			want:        `{"result":{"tools":[{"name":"write_file"},{"name":`,
+		},
+		{
+			name:        "error response is returned unchanged",
+			caller:      agent,
+			contentType: "application/json",
+			body:        `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"Method not found"}}`,
+			want:        `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"Method not found"}}`,
+		},
+		{
+			name:        "other methods are not filtered",
+			caller:      agent,
+			method:      "resources/list",
+			contentType: "application/json",
+			body:        `{"result":{"tools":[{"name":"write_file"}]}}`,
+			want:        `{"result":{"tools":[{"name":"write_file"}]}}`,
+		},
+		{
+			name:        "principal prefix",
+			caller:      toolsListCaller{sans: []string{"spiffe://example.com/ns/team-a/sa/bot"}},
+			contentType: "application/json",
+			body:        `{"result":{"tools":[{"name":"search"},{"name":"read_file"}]}}`,
+			want:        `{"result":{"tools":[{"name":"search"}]}}`,
+		},
+		{
+			name:        "caller without identity",
+			contentType: "application/json",
+			body:        `{"result":{"tools":[{"name":"search"},{"name":"read_file"}]}}`,
+			want:        `{"result":{"tools":[]}}`,
+		},
+		{
+			name:        "jwt claims",
+			caller:      toolsListCaller{jwt: map[string]interface{}{"oidc-1": map[string]interface{}{"groups": []interface{}{"users", "admins"}}}},
+			contentType: "application/json",
+			body:        `{"result":{"tools":[{"name":"search"},{"name":"write_file"}]}}`,
+			want:        `{"result":{"tools":[{"name":"search"},{"name":"write_file"}]}}`,
+		},
+		{
+			name:        "jwt claims not matching",
+			caller:      toolsListCaller{jwt: map[string]interface{}{"oidc-1": map[string]interface{}{"groups": "users"}}},
+			contentType: "application/json",
+			body:        `{"result":{"tools":[{"name":"search"},{"name":"write_file"}]}}`,
+			want:        `{"result":{"tools":[]}}`,
+		},
+		{
+			name:        "event stream",
+			caller:      agent,
+			contentType: "text/event-stream",
+			body:        "event: message\nid: 1\ndata: {\"result\":{\"tools\":[{\"name\":\"read_file\"},{\"name\":\"write_file\"}]}}\n\n",
+			want:        "event: message\nid: 1\ndata: {\"result\":{\"tools\":[{\"name\":\"read_file\"}]}}\n\n",
+		},
+		{
+			name:        "event stream with multi-line data",
+			caller:      agent,
+			contentType: "text/event-stream",
+			body:        "event: message\ndata: {\"result\":{\"tools\":[\ndata: {\"name\":\"write_file\"},\ndata:{\"name\":\"read_file\"}]}}\n\n",
+			want:        "event: message\ndata: {\"result\":{\"tools\":[{\"name\":\"read_file\"}]}}\n\n",
+		},
+		{
+			name:        "event stream with CRLF and several events",
+			caller:      agent,
+			contentType: "text/event-stream",
+			body:        ": keep-alive\r\n\r\ndata: {\"result\":{\"tools\":[{\"name\":\"write_file\"},\r\ndata: {\"name\":\"read_file\"}]}}\r\n\r\ndata: {\"method\":\"notifications/progress\"}\r\n\r\n",
+			want:        ": keep-alive\r\n\r\ndata: {\"result\":{\"tools\":[{\"name\":\"read_file\"}]}}\r\n\r\ndata: {\"method\":\"notifications/progress\"}\r\n\r\n",
+		},
+		{
+			name:        "event stream without result",
+			caller:      agent,
+			contentType: "text/event-stream",
+			body:        "data: {\"method\":\"notifications/progress\"}\n\n",
+			want:        "data: {\"method\":\"notifications/progress\"}\n\n",
+		},
+		{
+			name: "gateway policies",
+			filterContext: map[string]interface{}{
+				// The backend only denies a tool, the AccessPolicy of the Gateway only allows reads.
+				"deny": []interface{}{
+					map[string]interface{}{"tools": []interface{}{"read_secret"}},
+				},
+				"gateway": []interface{}{
+					map[string]interface{}{
+						"allow": []interface{}{map[string]interface{}{"prefixes": []interface{}{"read_"}}},
+						"deny":  []interface{}{},
+					},
+				},
+			},
+			caller:      agent,
+			contentType: "application/json",
+			body:        `{"result":{"tools":[{"name":"read_file"},{"name":"read_secret"},{"name":"write_file"}]}}`,
+			want:        `{"result":{"tools":[{"name":"read_file"}]}}`,
+		},
+		{
+			name:          "no filter context",
+			filterContext: map[string]interface{}{},
+			caller:        agent,
+			contentType:   "application/json",
+			body:          `{"result":{"tools":[{"name":"write_file"}]}}`,
+			want:          `{"result":{"tools":[{"name":"write_file"}]}}`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := tc.filterContext
+			if ctx == nil {
+				ctx = filterContext
+			}
+			method := tc.method
+			if method == "" {
+				method = "tools/list"
+			}
+			if got := runToolsListFilter(t, ctx, tc.caller, method, tc.contentType, tc.body); got != tc.want {
+				t.Errorf("unexpected body:\ngot:  %s\nwant: %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ package translator
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
@@ -39,12 +40,14 @@ import (
 	"k8s.io/client-go/kubernetes"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 	gatewayclient "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
 	gatewaylisters "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1"
 	gatewaylistersv1beta1 "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1beta1"
 
+	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
 	agenticlisters "sigs.k8s.io/kube-agentic-networking/k8s/client/listers/api/v0alpha0"
 	"sigs.k8s.io/kube-agentic-networking/pkg/constants"
 )
@@ -72,11 +75,13 @@ type Translator struct {
 	namespaceLister            corev1listers.NamespaceLister
 	serviceLister              corev1listers.ServiceLister
 	secretLister               corev1listers.SecretLister
+	configMapLister            corev1listers.ConfigMapLister
 	gatewayLister              gatewaylisters.GatewayLister
 	httprouteLister            gatewaylisters.HTTPRouteLister
 	referenceGrantLister       gatewaylistersv1beta1.ReferenceGrantLister // optional, for Service ref cross-namespace validation
 	accessPolicyLister         agenticlisters.XAccessPolicyLister
 	backendLister              agenticlisters.XBackendLister
+	clock                      clock.PassiveClock // evaluates the schedule of the rules of AccessPolicies
 }
 
 func New(
@@ -86,6 +91,7 @@ func New(
 	namespaceLister corev1listers.NamespaceLister,
 	serviceLister corev1listers.ServiceLister,
 	secretLister corev1listers.SecretLister,
+	configMapLister corev1listers.ConfigMapLister,
 	gatewayLister gatewaylisters.GatewayLister,
 	httpRouteLister gatewaylisters.HTTPRouteLister,
 	referenceGrantLister gatewaylistersv1beta1.ReferenceGrantLister,
@@ -99,11 +105,13 @@ func New(
 		namespaceLister,
 		serviceLister,
 		secretLister,
+		configMapLister,
 		gatewayLister,
 		httpRouteLister,
 		referenceGrantLister,
 		accessPolicyLister,
 		backendLister,
+		clock.RealClock{},
 	}
 }
 
@@ -164,8 +172,12 @@ func (t *Translator) buildEnvoyResourcesForGateway(gateway *gatewayv1.Gateway) (
 	envoyRoutes := []envoyproxytypes.Resource{}
 	allListenerStatuses := make(map[gatewayv1.SectionName]gatewayv1.ListenerStatus)
 
-	// 3. Build Envoy Clusters for any external auth configs referenced by AccessPolicies
-	envoyClusters := buildExtAuthzBackendClusters(t.accessPolicyLister)
+	// 3. Build Envoy Clusters for any JWKS backends referenced by AccessPolicies
+	// The client certificates presented to the XBackends and the credentials injected into the requests to them are
+	// delivered over SDS, see backendTLSContext and buildCredentialInjectorFilters.
+	envoyClusters, envoySecrets := t.buildJWKSBackendClusters(t.accessPolicyLister)
+	// The XBackends any listener routes to, whose external authorizers get a cluster, see step 11.
+	var gatewayBackends []*agenticv0alpha0.XBackend
 
 	// 4. Group Gateway listeners by port
 	listenersByPort := make(map[gatewayv1.PortNumber][]gatewayv1.Listener)
@@ -183,6 +195,10 @@ func (t *Translator) buildEnvoyResourcesForGateway(gateway *gatewayv1.Gateway) (
 		var filterChains []*listenerv3.FilterChain
 		// Prepare to collect ALL virtual hosts for this port into a single list.
 		virtualHostsForPort := make(map[string]*routev3.VirtualHost)
+		// The listeners serving each virtual host, used to scope the AccessPolicies of a shared filter chain.
+		listenersForVirtualHost := make(map[string][]gatewayv1.SectionName)
+		// The XBackends each listener routes to, whose AccessPolicies are enforced by its filter chain.
+		backendsByListener := make(map[gatewayv1.SectionName][]*agenticv0alpha0.XBackend)
 		routeName := fmt.Sprintf(constants.RouteNameFormat, port)
 
 		// All these listeners have the same port
@@ -234,8 +250,17 @@ func (t *Translator) buildEnvoyResourcesForGateway(gateway *gatewayv1.Gateway) (
 				// Backends are only included when referenced by an HTTPRoute BackendRef. If an XBackend
 				// (and optionally XAccessPolicy) exists but no HTTPRoute routes traffic to it, no cluster
 				// or RBAC config is generated for that backend.
+				var gatewayAccessPolicies []*agenticv0alpha0.XAccessPolicy
+				if t.accessPolicyLister != nil {
+					var err error
+					gatewayAccessPolicies, err = AccessPoliciesForGateway(gateway, t.accessPolicyLister, listener.Name)
+					if err != nil {
+						// Calls are still authorized by the RBAC filters of the listener, which fail the listener.
+						klog.Errorf("Failed to list AccessPolicies for listener %s of Gateway %s/%s: %v", listener.Name, gateway.Namespace, gateway.Name, err)
+					}
+				}
 				for _, httpRoute := range routesByListener[listener.Name] {
-					routes, allValidBackends, resolvedRefsCondition := t.translateHTTPRouteToEnvoyRoutes(httpRoute)
+					routes, allValidBackends, resolvedRefsCondition := t.translateHTTPRouteToEnvoyRoutes(httpRoute, gatewayAccessPolicies)
 
 					key := types.NamespacedName{Name: httpRoute.Name, Namespace: httpRoute.Namespace}
 					currentParentStatuses := httpRouteStatuses[key]
@@ -254,6 +279,24 @@ func (t *Translator) buildEnvoyResourcesForGateway(gateway *gatewayv1.Gateway) (
 					for _, cluster := range clusters {
 						envoyClusters[cluster.GetName()] = cluster
 					}
+					for _, backend := range allValidBackends {
+						xbackend := backend.XBackend()
+						if xbackend == nil {
+							continue
+						}
+						if backend.clientCert != nil {
+							envoySecrets[backend.clientCert.GetName()] = backend.clientCert
+						}
+						if backend.credential != nil {
+							envoySecrets[backend.credential.GetName()] = backend.credential
+						}
+						if !slices.Contains(backendsByListener[listener.Name], xbackend) {
+							backendsByListener[listener.Name] = append(backendsByListener[listener.Name], xbackend)
+						}
+						if !slices.Contains(gatewayBackends, xbackend) {
+							gatewayBackends = append(gatewayBackends, xbackend)
+						}
+					}
 
 					// Aggregate Envoy routes into VirtualHosts.
 					if routes != nil {
@@ -271,6 +314,9 @@ func (t *Translator) buildEnvoyResourcesForGateway(gateway *gatewayv1.Gateway) (
 								virtualHostsForPort[domain] = vh
 							}
 							vh.Routes = append(vh.Routes, routes...)
+							if !slices.Contains(listenersForVirtualHost[domain], listener.Name) {
+								listenersForVirtualHost[domain] = append(listenersForVirtualHost[domain], listener.Name)
+							}
 							klog.V(4).Infof("created VirtualHost %s for listener %s with domain %s", vh.GetName(), listener.
 								Name, domain)
 							if klog.V(4).Enabled() {
@@ -288,7 +334,15 @@ func (t *Translator) buildEnvoyResourcesForGateway(gateway *gatewayv1.Gateway) (
 			}
 
 			// 8. translate listener into a filter chain (HTTP connection manager that references route config 'route-<port>')
-			filterChain, err := t.translateListenerToFilterChain(listener, routeName, t.accessPolicyLister)
+			gatewayRBACFilters, err := t.buildGatewayRBACFilters(gateway, listener.Name)
+			var accessPolicies []*agenticv0alpha0.XAccessPolicy
+			if err == nil {
+				accessPolicies, err = t.listenerAccessPolicies(gateway, backendsByListener[listener.Name], listener.Name)
+			}
+			var filterChain *listenerv3.FilterChain
+			if err == nil {
+				filterChain, err = t.translateListenerToFilterChain(listener, routeName, t.accessPolicyLister, gatewayRBACFilters, accessPolicies, backendsByListener[listener.Name])
+			}
 			if err != nil {
 				meta.SetStatusCondition(&listenerStatus.Conditions, metav1.Condition{
 					Type:               string(gatewayv1.ListenerConditionProgrammed),
@@ -349,33 +403,121 @@ func (t *Translator) buildEnvoyResourcesForGateway(gateway *gatewayv1.Gateway) (
 			// For HTTPS, we create one filter chain per listener because they have unique
 			// SNI matches and TLS settings.
 			if listeners[0].Protocol == gatewayv1.HTTPProtocolType {
-				filterChain, _ := t.translateListenerToFilterChain(listeners[0], routeName, t.accessPolicyLister)
+				// The filter chain is shared by all listeners on the port, so it includes the filters of the
+				// AccessPolicies targeting any of them, which are disabled for the virtual hosts of the others.
+				var sectionNames []gatewayv1.SectionName
+				var backends []*agenticv0alpha0.XBackend
+				for _, listener := range listeners {
+					sectionNames = append(sectionNames, listener.Name)
+					for _, backend := range backendsByListener[listener.Name] {
+						if !slices.Contains(backends, backend) {
+							backends = append(backends, backend)
+						}
+					}
+				}
+				filterChain, err := t.buildSharedHTTPFilterChain(gateway, listeners[0], routeName, sectionNames, backends, virtualHostsForPort, listenersForVirtualHost)
+				if err != nil {
+					// Never serve the port without the filters enforcing its AccessPolicies.
+					klog.Errorf("Failed to build the filter chain for port %d: %v", port, err)
+					for _, listener := range listeners {
+						listenerStatus, ok := allListenerStatuses[listener.Name]
+						if !ok || !meta.IsStatusConditionTrue(listenerStatus.Conditions, string(gatewayv1.ListenerConditionProgrammed)) {
+							continue
+						}
+						meta.SetStatusCondition(&listenerStatus.Conditions, metav1.Condition{
+							Type:               string(gatewayv1.ListenerConditionProgrammed),
+							Status:             metav1.ConditionFalse,
+							Reason:             string(gatewayv1.ListenerReasonInvalid),
+							Message:            fmt.Sprintf("Failed to program listener: %v", err),
+							ObservedGeneration: gateway.Generation,
+						})
+						allListenerStatuses[listener.Name] = listenerStatus
+					}
+					continue
+				}
 				envoyListener.FilterChains = []*listenerv3.FilterChain{filterChain}
 			}
 			finalEnvoyListeners = append(finalEnvoyListeners, envoyListener)
 		}
 	}
 
-	// 11. Convert clusters map to slice
+	// 11. Build Envoy Clusters for the external authorizers of the AccessPolicies enforced by the listeners, so that
+	// the Gateway only gets the clusters of the ext_authz filters of its listeners.
+	accessPolicies, err := t.listenerAccessPolicies(gateway, gatewayBackends)
+	if err != nil {
+		// The listeners enforcing these AccessPolicies already failed to be programmed.
+		klog.Errorf("Failed to list AccessPolicies for Gateway %s/%s: %v", gateway.Namespace, gateway.Name, err)
+	}
+	for name, cluster := range buildExtAuthzBackendClusters(accessPolicies) {
+		envoyClusters[name] = cluster
+	}
+
+	// 12. Build the Envoy Cluster of the token exchange server, if an XBackend the Gateway routes to has a token
+	// exchange.
+	tokenExchangeCluster, err := t.buildTokenExchangeCluster(gatewayBackends)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to build the token exchange cluster: %w", err)
+	}
+	if tokenExchangeCluster != nil {
+		envoyClusters[tokenExchangeCluster.GetName()] = tokenExchangeCluster
+	}
+
+	// 13. Convert clusters and secrets maps to slices
 	clustersSlice := make([]envoyproxytypes.Resource, 0, len(envoyClusters))
 	for _, cluster := range envoyClusters {
 		clustersSlice = append(clustersSlice, cluster)
 	}
 
+	secretsSlice := make([]envoyproxytypes.Resource, 0, len(envoySecrets))
+	for _, secret := range envoySecrets {
+		secretsSlice = append(secretsSlice, secret)
+	}
+
 	orderedStatuses := make([]gatewayv1.ListenerStatus, len(gateway.Spec.Listeners))
 	for i, listener := range gateway.Spec.Listeners {
 		orderedStatuses[i] = allListenerStatuses[listener.Name]
 	}
 
-	// 12. Return resource map and status objects
+	// 14. Return resource map and status objects
 	return map[resourcev3.Type][]envoyproxytypes.Resource{
 			resourcev3.ListenerType: finalEnvoyListeners,
 			resourcev3.RouteType:    envoyRoutes,
 			resourcev3.ClusterType:  clustersSlice,
+			resourcev3.SecretType:   secretsSlice,
 		}, orderedStatuses,
 		httpRouteStatuses, nil, nil
 }
 
+// buildSharedHTTPFilterChain builds the filter chain shared by the plain HTTP listeners of a port, and scopes the
+// listener-level RBAC filters of the AccessPolicies targeting some of the listeners to their virtual hosts.
+// The backends are the XBackends any of the listeners routes to.
+func (t *Translator) buildSharedHTTPFilterChain(
+	gateway *gatewayv1.Gateway,
+	lis gatewayv1.Listener,
+	routeName string,
+	sectionNames []gatewayv1.SectionName,
+	backends []*agenticv0alpha0.XBackend,
+	virtualHosts map[string]*routev3.VirtualHost,
+	listenersForVirtualHost map[string][]gatewayv1.SectionName,
+) (*listenerv3.FilterChain, error) {
+	gatewayRBACFilters, err := t.buildGatewayRBACFilters(gateway, sectionNames...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build gateway RBAC filters: %w", err)
+	}
+	for domain, vh := range virtualHosts {
+		perFilterConfig, err := t.buildGatewayVirtualHostRBACConfig(gateway, gatewayRBACFilters, listenersForVirtualHost[domain])
+		if err != nil {
+			return nil, fmt.Errorf("failed to scope gateway RBAC filters to virtual host %s: %w", vh.GetName(), err)
+		}
+		vh.TypedPerFilterConfig = perFilterConfig
+	}
+	accessPolicies, err := t.listenerAccessPolicies(gateway, backends, sectionNames...)
+	if err != nil {
+		return nil, err
+	}
+	return t.translateListenerToFilterChain(lis, routeName, t.accessPolicyLister, gatewayRBACFilters, accessPolicies, backends)
+}
+
 func getSupportedKinds(listener gatewayv1.Listener) ([]gatewayv1.RouteGroupKind, bool) {
 	supportedKinds := []gatewayv1.RouteGroupKind{}
 	allKindsValid := true
@@ -558,14 +700,10 @@ func createClusterLoadAssignment(clusterName, serviceHost string, servicePort ui
 	}
 }
 
-func buildExtAuthzBackendClusters(accessPolicyLister agenticlisters.XAccessPolicyLister) map[string]envoyproxytypes.Resource {
+// buildExtAuthzBackendClusters builds the clusters of the external authorizers of the given AccessPolicies, keyed by
+// cluster name.
+func buildExtAuthzBackendClusters(accessPolicies []*agenticv0alpha0.XAccessPolicy) map[string]envoyproxytypes.Resource {
 	clusters := make(map[string]envoyproxytypes.Resource)
-	accessPolicies, err := accessPolicyLister.List(labels.Everything())
-	if err != nil {
-		klog.Errorf("failed to list AccessPolicies: %v", err)
-		return clusters
-	}
-
 	for _, ap := range accessPolicies {
 		for _, rule := range ap.Spec.Rules {
 			if rule.Authorization == nil || rule.Authorization.ExternalAuth == nil {
@@ -18,21 +18,28 @@ package translator
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"strings"
 	"testing"
 
 	clusterv3 "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
 	listenerv3 "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
 	routev3 "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	ext_authzv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/ext_authz/v3"
 	rbacv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/rbac/v3"
+	hcm "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
 	tlsv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
 	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/utils/ptr"
 
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
@@ -42,6 +49,7 @@ import (
 	agenticv0alpha0 "sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
 	agenticclient "sigs.k8s.io/kube-agentic-networking/k8s/client/clientset/versioned/fake"
 	agenticinformers "sigs.k8s.io/kube-agentic-networking/k8s/client/informers/externalversions"
+	agenticlisters "sigs.k8s.io/kube-agentic-networking/k8s/client/listers/api/v0alpha0"
 	"sigs.k8s.io/kube-agentic-networking/pkg/constants"
 )
 
@@ -135,7 +143,7 @@ func TestTranslateGatewayToXDS_Full(t *testing.T) {
 					}},
 					Rules: []agenticv0alpha0.AccessRule{{
 						Name: "tools-for-adk-agent-sa",
-						Source: agenticv0alpha0.Source{
+						Source: &agenticv0alpha0.Source{
 							Type: agenticv0alpha0.AuthorizationSourceTypeServiceAccount,
 							ServiceAccount: &agenticv0alpha0.AuthorizationSourceServiceAccount{
 								Name:      "adk-agent-sa",
@@ -223,6 +231,7 @@ func TestTranslateGatewayToXDS_Full(t *testing.T) {
 				coreInformerFactory.Core().V1().Namespaces().Lister(),
 				coreInformerFactory.Core().V1().Services().Lister(),
 				coreInformerFactory.Core().V1().Secrets().Lister(),
+				coreInformerFactory.Core().V1().ConfigMaps().Lister(),
 				gwInformerFactory.Gateway().V1().Gateways().Lister(),
 				gwInformerFactory.Gateway().V1().HTTPRoutes().Lister(),
 				nil, // referenceGrantLister
@@ -415,3 +424,228 @@ func checkRouteRBAC(t *testing.T, rc *routev3.RouteConfiguration, expectedPrinci
 		t.Errorf("RBAC per-cluster config not found in route configuration %s", rc.GetName())
 	}
 }
+
+// failingAccessPolicyLister is an XAccessPolicyLister failing to list any XAccessPolicy.
+type failingAccessPolicyLister struct{}
+
+func (failingAccessPolicyLister) List(labels.Selector) ([]*agenticv0alpha0.XAccessPolicy, error) {
+	return nil, errors.New("lister unavailable")
+}
+
+func (l failingAccessPolicyLister) XAccessPolicies(string) agenticlisters.XAccessPolicyNamespaceLister {
+	return l
+}
+
+func (failingAccessPolicyLister) Get(string) (*agenticv0alpha0.XAccessPolicy, error) {
+	return nil, errors.New("lister unavailable")
+}
+
+func TestBuildSharedHTTPFilterChain(t *testing.T) {
+	gateway := &gatewayv1.Gateway{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-gateway"}}
+	listener := gatewayv1.Listener{Name: "a", Port: 80, Protocol: gatewayv1.HTTPProtocolType}
+	sectionNames := []gatewayv1.SectionName{"a", "b"}
+
+	t.Run("AccessPolicies scoped to virtual hosts", func(t *testing.T) {
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		if err := indexer.Add(newTestGatewayAccessPolicy("default", "listener-a", "my-gateway", "a", "spiffe://example.com/ns/default/sa/a")); err != nil {
+			t.Fatalf("indexer.Add: %v", err)
+		}
+		tr := &Translator{accessPolicyLister: agenticlisters.NewXAccessPolicyLister(indexer)}
+		virtualHosts := map[string]*routev3.VirtualHost{
+			"a.example.com": {Name: "a", Domains: []string{"a.example.com"}},
+			"b.example.com": {Name: "b", Domains: []string{"b.example.com"}},
+		}
+		listenersForVirtualHost := map[string][]gatewayv1.SectionName{
+			"a.example.com": {"a"},
+			"b.example.com": {"b"},
+		}
+
+		filterChain, err := tr.buildSharedHTTPFilterChain(gateway, listener, "route-80", sectionNames, nil, virtualHosts, listenersForVirtualHost)
+		if err != nil {
+			t.Fatalf("buildSharedHTTPFilterChain: %v", err)
+		}
+		if filterChain == nil {
+			t.Fatal("expected a filter chain")
+		}
+		filterName := gatewayRBACFilterNamePrefix + ".default.listener-a"
+		if _, ok := virtualHosts["a.example.com"].GetTypedPerFilterConfig()[filterName]; ok {
+			t.Errorf("expected %s to be enabled for the virtual host of the targeted listener", filterName)
+		}
+		if _, ok := virtualHosts["b.example.com"].GetTypedPerFilterConfig()[filterName]; !ok {
+			t.Errorf("expected %s to be disabled for the virtual host of the other listener", filterName)
+		}
+	})
+
+	t.Run("AccessPolicies cannot be listed", func(t *testing.T) {
+		tr := &Translator{accessPolicyLister: failingAccessPolicyLister{}}
+		if _, err := tr.buildSharedHTTPFilterChain(gateway, listener, "route-80", sectionNames, nil, nil, nil); err == nil {
+			t.Error("expected an error rather than a filter chain without the gateway RBAC filters")
+		}
+	})
+}
+
+// TestTranslateGatewayToXDS_ExternalAuthIsolation tests that a Gateway only gets the ext_authz filters and clusters
+// of the AccessPolicies targeting it or the XBackends its accepted HTTPRoutes route to.
+func TestTranslateGatewayToXDS_ExternalAuthIsolation(t *testing.T) {
+	ns := "default"
+	newGateway := func(name string, protocol gatewayv1.ProtocolType) *gatewayv1.Gateway {
+		return &gatewayv1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+			Spec: gatewayv1.GatewaySpec{
+				GatewayClassName: "kube-agentic-networking",
+				Listeners:        []gatewayv1.Listener{{Name: "listener", Port: 10001, Protocol: protocol}},
+			},
+		}
+	}
+	newBackend := func(name string) *agenticv0alpha0.XBackend {
+		return &agenticv0alpha0.XBackend{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+			Spec: agenticv0alpha0.BackendSpec{
+				MCP: agenticv0alpha0.MCPBackend{ServiceName: ptr.To("mcp-svc"), Port: 3001, Path: "/mcp"},
+			},
+		}
+	}
+	newRoute := func(name, gatewayName, backendName string) *gatewayv1.HTTPRoute {
+		return &gatewayv1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+			Spec: gatewayv1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1.CommonRouteSpec{
+					ParentRefs: []gatewayv1.ParentReference{{Name: gatewayv1.ObjectName(gatewayName)}},
+				},
+				Rules: []gatewayv1.HTTPRouteRule{{
+					BackendRefs: []gatewayv1.HTTPBackendRef{{
+						BackendRef: gatewayv1.BackendRef{
+							BackendObjectReference: gatewayv1.BackendObjectReference{
+								Name:  gatewayv1.ObjectName(backendName),
+								Group: ptr.To(gatewayv1.Group("agentic.prototype.x-k8s.io")),
+								Kind:  ptr.To(gatewayv1.Kind("XBackend")),
+							},
+						},
+					}},
+				}},
+			},
+		}
+	}
+	gatewayA := newGateway("gateway-a", gatewayv1.HTTPSProtocolType)
+	gatewayB := newGateway("gateway-b", gatewayv1.HTTPProtocolType)
+	mcpSvc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "mcp-svc", Namespace: ns},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 3001}}},
+	}
+	gatewayObjects := []interface{}{
+		gatewayA, gatewayB,
+		newRoute("route-a", "gateway-a", "backend-a"),
+		newRoute("route-b", "gateway-b", "backend-b"),
+	}
+	agenticObjects := []interface{}{
+		newBackend("backend-a"), newBackend("backend-b"), newBackend("unrouted"),
+		withTestExternalAuth(newTestAccessPolicy(ns, "policy-a", "backend-a", "spiffe://cluster.local/ns/default/sa/a"), "authz-a"),
+		withTestExternalAuth(newTestAccessPolicy(ns, "policy-b", "backend-b", "spiffe://cluster.local/ns/default/sa/b"), "authz-b"),
+		withTestExternalAuth(newTestGatewayAccessPolicy(ns, "gateway-policy-b", "gateway-b", "", "spiffe://cluster.local/ns/default/sa/b"), "authz-gateway-b"),
+		withTestExternalAuth(newTestAccessPolicy(ns, "unrouted", "unrouted", "spiffe://cluster.local/ns/default/sa/c"), "authz-unrouted"),
+	}
+
+	k8sClient := fake.NewClientset(mcpSvc)
+	gwClient := gatewayclient.NewClientset()
+	//nolint:staticcheck // generated clientset doesn't have NewClientset without applyconfig
+	agenticClient := agenticclient.NewSimpleClientset()
+	gwInformerFactory := gatewayinformers.NewSharedInformerFactory(gwClient, 0)
+	agenticInformerFactory := agenticinformers.NewSharedInformerFactory(agenticClient, 0)
+	coreInformerFactory := informers.NewSharedInformerFactory(k8sClient, 0)
+	tr := New(
+		"cluster.local",
+		k8sClient,
+		gwClient,
+		coreInformerFactory.Core().V1().Namespaces().Lister(),
+		coreInformerFactory.Core().V1().Services().Lister(),
+		coreInformerFactory.Core().V1().Secrets().Lister(),
+		coreInformerFactory.Core().V1().ConfigMaps().Lister(),
+		gwInformerFactory.Gateway().V1().Gateways().Lister(),
+		gwInformerFactory.Gateway().V1().HTTPRoutes().Lister(),
+		nil, // referenceGrantLister
+		agenticInformerFactory.Agentic().V0alpha0().XAccessPolicies().Lister(),
+		agenticInformerFactory.Agentic().V0alpha0().XBackends().Lister(),
+	)
+	_ = coreInformerFactory.Core().V1().Services().Informer().GetIndexer().Add(mcpSvc)
+	for _, obj := range gatewayObjects {
+		switch obj := obj.(type) {
+		case *gatewayv1.Gateway:
+			_ = gwInformerFactory.Gateway().V1().Gateways().Informer().GetIndexer().Add(obj)
+		case *gatewayv1.HTTPRoute:
+			_ = gwInformerFactory.Gateway().V1().HTTPRoutes().Informer().GetIndexer().Add(obj)
+		}
+	}
+	for _, obj := range agenticObjects {
+		switch obj := obj.(type) {
+		case *agenticv0alpha0.XBackend:
+			_ = agenticInformerFactory.Agentic().V0alpha0().XBackends().Informer().GetIndexer().Add(obj)
+		case *agenticv0alpha0.XAccessPolicy:
+			_ = agenticInformerFactory.Agentic().V0alpha0().XAccessPolicies().Informer().GetIndexer().Add(obj)
+		}
+	}
+
+	clusterName := func(serviceName string) string {
+		backendRef := gatewayv1.BackendObjectReference{Name: gatewayv1.ObjectName(serviceName)}
+		return clusterNameForBackendRefAndProtocol(backendRef, ns, string(gatewayv1.HTTPRouteExternalAuthGRPCProtocol))
+	}
+
+	tests := []struct {
+		gateway      *gatewayv1.Gateway
+		wantClusters []string
+	}{
+		{
+			gateway:      gatewayA,
+			wantClusters: []string{clusterName("authz-a")},
+		},
+		{
+			gateway:      gatewayB,
+			wantClusters: []string{clusterName("authz-gateway-b"), clusterName("authz-b")},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.gateway.Name, func(t *testing.T) {
+			resources, _, _, _, err := tr.TranslateGatewayToXDS(context.Background(), tc.gateway)
+			if err != nil {
+				t.Fatalf("Translation failed: %v", err)
+			}
+
+			var extAuthzClusters []string
+			for _, res := range resources[resourcev3.ClusterType] {
+				name := res.(*clusterv3.Cluster).GetName()
+				if strings.HasPrefix(name, "authz-") {
+					extAuthzClusters = append(extAuthzClusters, name)
+				}
+			}
+			slices.Sort(extAuthzClusters)
+			wantClusters := slices.Sorted(slices.Values(tc.wantClusters))
+			if !slices.Equal(extAuthzClusters, wantClusters) {
+				t.Errorf("expected ext_authz clusters %v, got %v", wantClusters, extAuthzClusters)
+			}
+
+			// The ext_authz filters of the Gateway only call the clusters of its external authorizers, in the
+			// order of the AccessPolicies enforced by its listener.
+			var extAuthzFilterClusters []string
+			for _, res := range resources[resourcev3.ListenerType] {
+				for _, filterChain := range res.(*listenerv3.Listener).GetFilterChains() {
+					hcmConfig := &hcm.HttpConnectionManager{}
+					if err := filterChain.GetFilters()[0].GetTypedConfig().UnmarshalTo(hcmConfig); err != nil {
+						t.Fatalf("failed to unmarshal HttpConnectionManager: %v", err)
+					}
+					for _, filter := range hcmConfig.GetHttpFilters() {
+						if !strings.HasPrefix(filter.GetName(), wellknown.HTTPExternalAuthorization+".") {
+							continue
+						}
+						extAuthz := &ext_authzv3.ExtAuthz{}
+						if err := filter.GetTypedConfig().UnmarshalTo(extAuthz); err != nil {
+							t.Fatalf("failed to unmarshal ExtAuthz: %v", err)
+						}
+						extAuthzFilterClusters = append(extAuthzFilterClusters, extAuthz.GetGrpcService().GetEnvoyGrpc().GetClusterName())
+					}
+				}
+			}
+			if !slices.Equal(extAuthzFilterClusters, tc.wantClusters) {
+				t.Errorf("expected ext_authz filters calling %v, got %v", tc.wantClusters, extAuthzFilterClusters)
+			}
+		})
+	}
+}
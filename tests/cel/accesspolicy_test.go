@@ -50,7 +50,7 @@ func TestValidateXAccessPolicy(t *testing.T) {
 			Rules: []v0alpha0.AccessRule{
 				{
 					Name: "rule-1",
-					Source: v0alpha0.Source{
+					Source: &v0alpha0.Source{
 						Type: v0alpha0.AuthorizationSourceTypeServiceAccount,
 						ServiceAccount: &v0alpha0.AuthorizationSourceServiceAccount{
 							Name: "sa-1",
@@ -76,21 +76,58 @@ func TestValidateXAccessPolicy(t *testing.T) {
 			mutate: func(p *v0alpha0.XAccessPolicy) {
 				p.Spec.TargetRefs[0].Group = "wrong.group"
 			},
-			wantErrors: []string{"TargetRef must have group agentic.prototype.x-k8s.io group and kind XBackend"},
+			wantErrors: []string{"TargetRef must have group agentic.prototype.x-k8s.io and kind XBackend, or group gateway.networking.k8s.io and kind Gateway"},
 		},
 		{
 			desc: "invalid target kind",
 			mutate: func(p *v0alpha0.XAccessPolicy) {
 				p.Spec.TargetRefs[0].Kind = "WrongKind"
 			},
-			wantErrors: []string{"TargetRef must have group agentic.prototype.x-k8s.io group and kind XBackend"},
+			wantErrors: []string{"TargetRef must have group agentic.prototype.x-k8s.io and kind XBackend, or group gateway.networking.k8s.io and kind Gateway"},
+		},
+		{
+			desc: "valid gateway target",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				sectionName := gwapiv1.SectionName("https")
+				p.Spec.TargetRefs = []gwapiv1.LocalPolicyTargetReferenceWithSectionName{
+					{
+						LocalPolicyTargetReference: gwapiv1.LocalPolicyTargetReference{
+							Group: "gateway.networking.k8s.io",
+							Kind:  "Gateway",
+							Name:  "my-gateway",
+						},
+						SectionName: &sectionName,
+					},
+				}
+			},
+		},
+		{
+			desc: "invalid gateway target group",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.TargetRefs[0].Group = "agentic.prototype.x-k8s.io"
+				p.Spec.TargetRefs[0].Kind = "Gateway"
+			},
+			wantErrors: []string{"TargetRef must have group agentic.prototype.x-k8s.io and kind XBackend, or group gateway.networking.k8s.io and kind Gateway"},
+		},
+		{
+			desc: "targets of different kinds",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.TargetRefs = append(p.Spec.TargetRefs, gwapiv1.LocalPolicyTargetReferenceWithSectionName{
+					LocalPolicyTargetReference: gwapiv1.LocalPolicyTargetReference{
+						Group: "gateway.networking.k8s.io",
+						Kind:  "Gateway",
+						Name:  "my-gateway",
+					},
+				})
+			},
+			wantErrors: []string{"All targetRefs must have the same Kind"},
 		},
 		{
 			desc: "duplicate rule names",
 			mutate: func(p *v0alpha0.XAccessPolicy) {
 				p.Spec.Rules = append(p.Spec.Rules, v0alpha0.AccessRule{
 					Name: "rule-1",
-					Source: v0alpha0.Source{
+					Source: &v0alpha0.Source{
 						Type: v0alpha0.AuthorizationSourceTypeServiceAccount,
 						ServiceAccount: &v0alpha0.AuthorizationSourceServiceAccount{
 							Name: "sa-2",
@@ -104,7 +141,7 @@ func TestValidateXAccessPolicy(t *testing.T) {
 			desc: "invalid SPIFFE ID pattern",
 			mutate: func(p *v0alpha0.XAccessPolicy) {
 				spiffe := v0alpha0.AuthorizationSourceSPIFFE("not-a-spiffe-id")
-				p.Spec.Rules[0].Source = v0alpha0.Source{
+				p.Spec.Rules[0].Source = &v0alpha0.Source{
 					Type:   v0alpha0.AuthorizationSourceTypeSPIFFE,
 					SPIFFE: &spiffe,
 				}
@@ -115,12 +152,103 @@ func TestValidateXAccessPolicy(t *testing.T) {
 			desc: "valid SPIFFE ID",
 			mutate: func(p *v0alpha0.XAccessPolicy) {
 				spiffe := v0alpha0.AuthorizationSourceSPIFFE("spiffe://trust.domain/workload")
-				p.Spec.Rules[0].Source = v0alpha0.Source{
+				p.Spec.Rules[0].Source = &v0alpha0.Source{
 					Type:   v0alpha0.AuthorizationSourceTypeSPIFFE,
 					SPIFFE: &spiffe,
 				}
 			},
 		},
+		{
+			desc: "valid multiple sources",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				spiffe := v0alpha0.AuthorizationSourceSPIFFE("spiffe://trust.domain/workload")
+				p.Spec.Rules[0].Sources = []v0alpha0.Source{
+					*p.Spec.Rules[0].Source,
+					{Type: v0alpha0.AuthorizationSourceTypeSPIFFE, SPIFFE: &spiffe},
+				}
+				p.Spec.Rules[0].Source = nil
+			},
+		},
+		{
+			desc: "both source and sources",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Sources = []v0alpha0.Source{*p.Spec.Rules[0].Source}
+			},
+			wantErrors: []string{"exactly one of source or sources must be specified"},
+		},
+		{
+			desc: "neither source nor sources",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Source = nil
+			},
+			wantErrors: []string{"exactly one of source or sources must be specified"},
+		},
+		{
+			desc: "valid notBefore and notAfter",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				notBefore := time.Date(2025, time.June, 2, 10, 0, 0, 0, time.UTC)
+				p.Spec.Rules[0].NotBefore = &metav1.Time{Time: notBefore}
+				p.Spec.Rules[0].NotAfter = &metav1.Time{Time: notBefore.Add(4 * time.Hour)}
+			},
+		},
+		{
+			desc: "notAfter before notBefore",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				notBefore := time.Date(2025, time.June, 2, 10, 0, 0, 0, time.UTC)
+				p.Spec.Rules[0].NotBefore = &metav1.Time{Time: notBefore}
+				p.Spec.Rules[0].NotAfter = &metav1.Time{Time: notBefore.Add(-time.Hour)}
+			},
+			wantErrors: []string{"notBefore must be before notAfter"},
+		},
+		{
+			desc: "valid overnight window",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				timeZone := "Europe/Paris"
+				p.Spec.Rules[0].Windows = []v0alpha0.AccessRuleTimeWindow{
+					{Days: []v0alpha0.Weekday{"Saturday", "Sunday"}, Start: "22:00", End: "06:00", TimeZone: &timeZone},
+				}
+			},
+		},
+		{
+			desc: "invalid window start",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Windows = []v0alpha0.AccessRuleTimeWindow{{Start: "24:00", End: "06:00"}}
+			},
+			wantErrors: []string{"spec.rules[0].windows[0].start in body should match"},
+		},
+		{
+			desc: "invalid source among sources",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Sources = []v0alpha0.Source{
+					*p.Spec.Rules[0].Source,
+					{Type: v0alpha0.AuthorizationSourceTypeOIDC},
+				}
+				p.Spec.Rules[0].Source = nil
+			},
+			wantErrors: []string{"oidc must be specified when type is set to 'OIDC'"},
+		},
+		{
+			desc: "CEL rule combining an OIDC source with other sources",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				jwks := `{"keys":[]}`
+				p.Spec.Rules[0].Sources = []v0alpha0.Source{
+					*p.Spec.Rules[0].Source,
+					{
+						Type: v0alpha0.AuthorizationSourceTypeOIDC,
+						OIDC: &v0alpha0.AuthorizationSourceOIDC{
+							Issuer: "https://issuer.example.com",
+							JWKS:   v0alpha0.JWKS{Inline: &jwks},
+						},
+					},
+				}
+				p.Spec.Rules[0].Source = nil
+				p.Spec.Rules[0].Authorization = &v0alpha0.AuthorizationRule{
+					Type: v0alpha0.AuthorizationRuleTypeCEL,
+					CEL:  `identity.sub == "admin"`,
+				}
+			},
+			wantErrors: []string{"rules with 'CEL' authorization type cannot combine an 'OIDC' source with other sources"},
+		},
 		{
 			desc: "rule name too long",
 			mutate: func(p *v0alpha0.XAccessPolicy) {
@@ -151,7 +279,7 @@ func TestValidateXAccessPolicy(t *testing.T) {
 				for i := 0; i < 10; i++ {
 					p.Spec.Rules = append(p.Spec.Rules, v0alpha0.AccessRule{
 						Name: fmt.Sprintf("rule-%d", i+2),
-						Source: v0alpha0.Source{
+						Source: &v0alpha0.Source{
 							Type: v0alpha0.AuthorizationSourceTypeServiceAccount,
 							ServiceAccount: &v0alpha0.AuthorizationSourceServiceAccount{
 								Name: "sa-1",
@@ -169,7 +297,7 @@ func TestValidateXAccessPolicy(t *testing.T) {
 					Type: v0alpha0.AuthorizationRuleTypeInlineTools,
 				}
 			},
-			wantErrors: []string{"tools must be specified when type is set to 'InlineTools'"},
+			wantErrors: []string{"tools or toolPatterns must be specified when type is set to 'InlineTools'"},
 		},
 		{
 			desc: "missing authorization for ExternalAuth type",
@@ -198,7 +326,7 @@ func TestValidateXAccessPolicy(t *testing.T) {
 			wantErrors: []string{"only one of tools or externalAuth can be specified"},
 		},
 		{
-			desc: "multiple ExternalAuth authorization rules specified",
+			desc: "valid multiple ExternalAuth authorization rules",
 			mutate: func(p *v0alpha0.XAccessPolicy) {
 				p.Spec.Rules[0].Authorization = &v0alpha0.AuthorizationRule{
 					Type: v0alpha0.AuthorizationRuleTypeExternalAuth,
@@ -212,7 +340,7 @@ func TestValidateXAccessPolicy(t *testing.T) {
 				// add another rule with ExternalAuth type
 				p.Spec.Rules = append(p.Spec.Rules, v0alpha0.AccessRule{
 					Name: "rule-2",
-					Source: v0alpha0.Source{
+					Source: &v0alpha0.Source{
 						Type: v0alpha0.AuthorizationSourceTypeServiceAccount,
 						ServiceAccount: &v0alpha0.AuthorizationSourceServiceAccount{
 							Name: "sa-2",
@@ -229,7 +357,514 @@ func TestValidateXAccessPolicy(t *testing.T) {
 					},
 				})
 			},
-			wantErrors: []string{"a maximum of one rule per policy can specify 'ExternalAuth' authorization type"},
+		},
+		{
+			desc: "valid ExternalAuth settings",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				failureMode := v0alpha0.ExternalAuthFailureModeAllow
+				timeout := gwapiv1.Duration("30s")
+				statusOnError := int32(503)
+				includePeerCertificate := true
+				p.Spec.Rules[0].Authorization = &v0alpha0.AuthorizationRule{
+					Type: v0alpha0.AuthorizationRuleTypeExternalAuth,
+					ExternalAuth: &gwapiv1.HTTPExternalAuthFilter{
+						ExternalAuthProtocol: gwapiv1.HTTPRouteExternalAuthGRPCProtocol,
+						BackendRef:           gwapiv1.BackendObjectReference{Name: "ext-auth-svc"},
+					},
+					ExternalAuthSettings: &v0alpha0.ExternalAuthSettings{
+						FailureMode:            &failureMode,
+						Timeout:                &timeout,
+						StatusOnError:          &statusOnError,
+						IncludePeerCertificate: &includePeerCertificate,
+					},
+				}
+			},
+		},
+		{
+			desc: "ExternalAuth settings without ExternalAuth",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				failureMode := v0alpha0.ExternalAuthFailureModeAllow
+				p.Spec.Rules[0].Authorization = &v0alpha0.AuthorizationRule{
+					Type:                 v0alpha0.AuthorizationRuleTypeInlineTools,
+					Tools:                []string{"get_repo"},
+					ExternalAuthSettings: &v0alpha0.ExternalAuthSettings{FailureMode: &failureMode},
+				}
+			},
+			wantErrors: []string{"externalAuthSettings can only be specified when type is set to 'ExternalAuth'"},
+		},
+		{
+			desc: "ExternalAuth status on error is not an error status",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				statusOnError := int32(200)
+				p.Spec.Rules[0].Authorization = &v0alpha0.AuthorizationRule{
+					Type: v0alpha0.AuthorizationRuleTypeExternalAuth,
+					ExternalAuth: &gwapiv1.HTTPExternalAuthFilter{
+						ExternalAuthProtocol: gwapiv1.HTTPRouteExternalAuthGRPCProtocol,
+						BackendRef:           gwapiv1.BackendObjectReference{Name: "ext-auth-svc"},
+					},
+					ExternalAuthSettings: &v0alpha0.ExternalAuthSettings{StatusOnError: &statusOnError},
+				}
+			},
+			wantErrors: []string{"should be greater than or equal to 400"},
+		},
+		{
+			desc: "valid ExternalAuth response headers",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Authorization = &v0alpha0.AuthorizationRule{
+					Type: v0alpha0.AuthorizationRuleTypeExternalAuth,
+					ExternalAuth: &gwapiv1.HTTPExternalAuthFilter{
+						ExternalAuthProtocol: gwapiv1.HTTPRouteExternalAuthHTTPProtocol,
+						BackendRef:           gwapiv1.BackendObjectReference{Name: "ext-auth-svc"},
+						HTTPAuthConfig:       &gwapiv1.HTTPAuthConfig{},
+					},
+					ExternalAuthSettings: &v0alpha0.ExternalAuthSettings{
+						AllowedUpstreamHeaders: []string{"x-upstream-token"},
+						AllowedClientHeaders:   []string{"x-denial-reason"},
+					},
+				}
+			},
+		},
+		{
+			desc: "ExternalAuth client headers with the GRPC protocol",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Authorization = &v0alpha0.AuthorizationRule{
+					Type: v0alpha0.AuthorizationRuleTypeExternalAuth,
+					ExternalAuth: &gwapiv1.HTTPExternalAuthFilter{
+						ExternalAuthProtocol: gwapiv1.HTTPRouteExternalAuthGRPCProtocol,
+						BackendRef:           gwapiv1.BackendObjectReference{Name: "ext-auth-svc"},
+					},
+					ExternalAuthSettings: &v0alpha0.ExternalAuthSettings{
+						AllowedUpstreamHeaders: []string{"x-upstream-token"},
+						AllowedClientHeaders:   []string{"x-denial-reason"},
+					},
+				}
+			},
+			wantErrors: []string{"allowedClientHeaders can only be specified when the externalAuth protocol is 'HTTP'"},
+		},
+		{
+			desc: "valid deny rule",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Action = v0alpha0.AccessRuleActionDeny
+				p.Spec.Rules[0].Authorization = &v0alpha0.AuthorizationRule{
+					Type:  v0alpha0.AuthorizationRuleTypeInlineTools,
+					Tools: []string{"delete_repo"},
+				}
+			},
+		},
+		{
+			desc: "valid tool patterns",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Authorization = &v0alpha0.AuthorizationRule{
+					Type: v0alpha0.AuthorizationRuleTypeInlineTools,
+					ToolPatterns: []v0alpha0.ToolNameMatch{
+						{Type: v0alpha0.ToolNameMatchPrefix, Value: "github_"},
+						{Type: v0alpha0.ToolNameMatchSuffix, Value: "_read"},
+						{Type: v0alpha0.ToolNameMatchRegularExpression, Value: "^(get|list)_[a-z]+$"},
+					},
+				}
+			},
+		},
+		{
+			desc: "regular expression tool pattern too long",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Authorization = &v0alpha0.AuthorizationRule{
+					Type: v0alpha0.AuthorizationRuleTypeInlineTools,
+					ToolPatterns: []v0alpha0.ToolNameMatch{
+						{Type: v0alpha0.ToolNameMatchRegularExpression, Value: strings.Repeat("a", 101)},
+					},
+				}
+			},
+			wantErrors: []string{"regular expression must not be longer than 100 characters"},
+		},
+		{
+			desc: "regular expression tool pattern with counted repetition",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Authorization = &v0alpha0.AuthorizationRule{
+					Type: v0alpha0.AuthorizationRuleTypeInlineTools,
+					ToolPatterns: []v0alpha0.ToolNameMatch{
+						{Type: v0alpha0.ToolNameMatchRegularExpression, Value: "(a+){1000}"},
+					},
+				}
+			},
+			wantErrors: []string{"regular expression must not use counted repetition"},
+		},
+		{
+			desc: "tool patterns with ExternalAuth authorization",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Authorization = &v0alpha0.AuthorizationRule{
+					Type: v0alpha0.AuthorizationRuleTypeExternalAuth,
+					ToolPatterns: []v0alpha0.ToolNameMatch{
+						{Type: v0alpha0.ToolNameMatchPrefix, Value: "github_"},
+					},
+					ExternalAuth: &gwapiv1.HTTPExternalAuthFilter{
+						ExternalAuthProtocol: gwapiv1.HTTPRouteExternalAuthGRPCProtocol,
+						BackendRef: gwapiv1.BackendObjectReference{
+							Name: "ext-auth-svc",
+						},
+					},
+				}
+			},
+			wantErrors: []string{"only one of toolPatterns or externalAuth can be specified"},
+		},
+		{
+			desc: "valid tool argument constraints",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Authorization = &v0alpha0.AuthorizationRule{
+					Type:  v0alpha0.AuthorizationRuleTypeInlineTools,
+					Tools: []string{"read_file"},
+					Arguments: []v0alpha0.ToolArgumentMatch{
+						{Name: "path", Type: v0alpha0.ToolArgumentMatchPrefix, Value: "/workspace/"},
+						{Name: "encoding", Type: v0alpha0.ToolArgumentMatchOneOf, Values: []string{"utf-8"}},
+					},
+				}
+			},
+		},
+		{
+			desc: "tool argument constraint missing values",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Authorization = &v0alpha0.AuthorizationRule{
+					Type:  v0alpha0.AuthorizationRuleTypeInlineTools,
+					Tools: []string{"read_file"},
+					Arguments: []v0alpha0.ToolArgumentMatch{
+						{Name: "encoding", Type: v0alpha0.ToolArgumentMatchOneOf, Value: "utf-8"},
+					},
+				}
+			},
+			wantErrors: []string{
+				"values must be specified when type is set to 'OneOf'",
+			},
+		},
+		{
+			desc: "tool argument constraints with ExternalAuth type",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Authorization = &v0alpha0.AuthorizationRule{
+					Type: v0alpha0.AuthorizationRuleTypeExternalAuth,
+					ExternalAuth: &gwapiv1.HTTPExternalAuthFilter{
+						ExternalAuthProtocol: gwapiv1.HTTPRouteExternalAuthGRPCProtocol,
+						BackendRef:           gwapiv1.BackendObjectReference{Name: "authz"},
+					},
+					Arguments: []v0alpha0.ToolArgumentMatch{
+						{Name: "path", Type: v0alpha0.ToolArgumentMatchPrefix, Value: "/workspace/"},
+					},
+				}
+			},
+			wantErrors: []string{"arguments can only be specified when type is set to 'InlineTools'"},
+		},
+		{
+			desc: "valid resources and prompts rules",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Authorization = &v0alpha0.AuthorizationRule{
+					Type: v0alpha0.AuthorizationRuleTypeInlineResources,
+					Resources: []v0alpha0.ResourceURIMatch{
+						{Type: v0alpha0.ResourceURIMatchPrefix, Value: "file:///workspace/"},
+					},
+				}
+				p.Spec.Rules = append(p.Spec.Rules, v0alpha0.AccessRule{
+					Name: "rule-2",
+					Source: &v0alpha0.Source{
+						Type: v0alpha0.AuthorizationSourceTypeServiceAccount,
+						ServiceAccount: &v0alpha0.AuthorizationSourceServiceAccount{
+							Name: "sa-2",
+						},
+					},
+					Authorization: &v0alpha0.AuthorizationRule{
+						Type:    v0alpha0.AuthorizationRuleTypeInlinePrompts,
+						Prompts: []string{"summarize"},
+					},
+				})
+			},
+		},
+		{
+			desc: "missing resources for InlineResources type",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Authorization = &v0alpha0.AuthorizationRule{
+					Type: v0alpha0.AuthorizationRuleTypeInlineResources,
+				}
+			},
+			wantErrors: []string{"resources must be specified when type is set to 'InlineResources'"},
+		},
+		{
+			desc: "prompts with InlineTools type",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Authorization = &v0alpha0.AuthorizationRule{
+					Type:    v0alpha0.AuthorizationRuleTypeInlineTools,
+					Tools:   []string{"tool-1"},
+					Prompts: []string{"summarize"},
+				}
+			},
+			wantErrors: []string{"prompts can only be specified when type is set to 'InlinePrompts'"},
+		},
+		{
+			desc: "valid CEL rule",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Authorization = &v0alpha0.AuthorizationRule{
+					Type: v0alpha0.AuthorizationRuleTypeCEL,
+					CEL:  `request.mcp.tool_name.startsWith("read_")`,
+				}
+			},
+		},
+		{
+			desc: "missing cel for CEL type",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Authorization = &v0alpha0.AuthorizationRule{
+					Type: v0alpha0.AuthorizationRuleTypeCEL,
+				}
+			},
+			wantErrors: []string{"cel must be specified when type is set to 'CEL'"},
+		},
+		{
+			desc: "cel with InlineTools type",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Authorization = &v0alpha0.AuthorizationRule{
+					Type:  v0alpha0.AuthorizationRuleTypeInlineTools,
+					Tools: []string{"tool-1"},
+					CEL:   `request.mcp.tool_name == "tool-2"`,
+				}
+			},
+			wantErrors: []string{"cel can only be specified when type is set to 'CEL'"},
+		},
+		{
+			desc: "valid OIDC sources",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				jwks := `{"keys":[]}`
+				port := gwapiv1.PortNumber(8080)
+				group := gwapiv1.Group("agentic.prototype.x-k8s.io")
+				kind := gwapiv1.Kind("XBackend")
+				p.Spec.Rules[0].Source = &v0alpha0.Source{
+					Type: v0alpha0.AuthorizationSourceTypeOIDC,
+					OIDC: &v0alpha0.AuthorizationSourceOIDC{
+						Issuer:    "https://issuer.example.com",
+						Audiences: []string{"mcp-gateway"},
+						JWKS:      v0alpha0.JWKS{Inline: &jwks},
+						Claims: []v0alpha0.JWTClaimMatch{
+							{Name: "groups", Values: []string{"admins"}},
+						},
+					},
+				}
+				p.Spec.Rules = append(p.Spec.Rules,
+					v0alpha0.AccessRule{
+						Name: "rule-2",
+						Source: &v0alpha0.Source{
+							Type: v0alpha0.AuthorizationSourceTypeOIDC,
+							OIDC: &v0alpha0.AuthorizationSourceOIDC{
+								Issuer: "https://keycloak.example.com/realms/agents",
+								JWKS: v0alpha0.JWKS{
+									BackendRef: &gwapiv1.BackendObjectReference{Name: "keycloak", Port: &port},
+									Path:       "/realms/agents/protocol/openid-connect/certs",
+								},
+							},
+						},
+					},
+					v0alpha0.AccessRule{
+						Name: "rule-3",
+						Source: &v0alpha0.Source{
+							Type: v0alpha0.AuthorizationSourceTypeOIDC,
+							OIDC: &v0alpha0.AuthorizationSourceOIDC{
+								Issuer: "https://idp.example.com",
+								JWKS: v0alpha0.JWKS{
+									BackendRef: &gwapiv1.BackendObjectReference{Group: &group, Kind: &kind, Name: "idp"},
+								},
+							},
+						},
+					},
+				)
+			},
+		},
+		{
+			desc: "missing oidc for OIDC type",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Source = &v0alpha0.Source{Type: v0alpha0.AuthorizationSourceTypeOIDC}
+			},
+			wantErrors: []string{"oidc must be specified when type is set to 'OIDC'"},
+		},
+		{
+			desc: "oidc with ServiceAccount type",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				jwks := `{"keys":[]}`
+				p.Spec.Rules[0].Source.OIDC = &v0alpha0.AuthorizationSourceOIDC{
+					Issuer: "https://issuer.example.com",
+					JWKS:   v0alpha0.JWKS{Inline: &jwks},
+				}
+			},
+			wantErrors: []string{"oidc can only be specified when type is set to 'OIDC'"},
+		},
+		{
+			desc: "non-https OIDC issuer",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				jwks := `{"keys":[]}`
+				p.Spec.Rules[0].Source = &v0alpha0.Source{
+					Type: v0alpha0.AuthorizationSourceTypeOIDC,
+					OIDC: &v0alpha0.AuthorizationSourceOIDC{
+						Issuer: "http://issuer.example.com",
+						JWKS:   v0alpha0.JWKS{Inline: &jwks},
+					},
+				}
+			},
+			wantErrors: []string{"spec.rules[0].source.oidc.issuer"},
+		},
+		{
+			desc: "both inline JWKS and backendRef",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				jwks := `{"keys":[]}`
+				port := gwapiv1.PortNumber(8080)
+				p.Spec.Rules[0].Source = &v0alpha0.Source{
+					Type: v0alpha0.AuthorizationSourceTypeOIDC,
+					OIDC: &v0alpha0.AuthorizationSourceOIDC{
+						Issuer: "https://issuer.example.com",
+						JWKS: v0alpha0.JWKS{
+							Inline:     &jwks,
+							BackendRef: &gwapiv1.BackendObjectReference{Name: "keycloak", Port: &port},
+						},
+					},
+				}
+			},
+			wantErrors: []string{"exactly one of the fields in [inline backendRef] must be set"},
+		},
+		{
+			desc: "JWKS path without backendRef",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				jwks := `{"keys":[]}`
+				p.Spec.Rules[0].Source = &v0alpha0.Source{
+					Type: v0alpha0.AuthorizationSourceTypeOIDC,
+					OIDC: &v0alpha0.AuthorizationSourceOIDC{
+						Issuer: "https://issuer.example.com",
+						JWKS:   v0alpha0.JWKS{Inline: &jwks, Path: "/keys"},
+					},
+				}
+			},
+			wantErrors: []string{"path can only be specified when backendRef is set"},
+		},
+		{
+			desc: "JWKS backendRef with unsupported kind",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				group := gwapiv1.Group("gateway.networking.k8s.io")
+				kind := gwapiv1.Kind("Gateway")
+				p.Spec.Rules[0].Source = &v0alpha0.Source{
+					Type: v0alpha0.AuthorizationSourceTypeOIDC,
+					OIDC: &v0alpha0.AuthorizationSourceOIDC{
+						Issuer: "https://issuer.example.com",
+						JWKS: v0alpha0.JWKS{
+							BackendRef: &gwapiv1.BackendObjectReference{Group: &group, Kind: &kind, Name: "gateway"},
+						},
+					},
+				}
+			},
+			wantErrors: []string{"backendRef must reference a Service or an XBackend"},
+		},
+		{
+			desc: "invalid rule action",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Action = "Audit"
+			},
+			wantErrors: []string{`spec.rules[0].action: Unsupported value: "Audit"`},
+		},
+		{
+			desc: "deny rule with ExternalAuth authorization",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Action = v0alpha0.AccessRuleActionDeny
+				p.Spec.Rules[0].Authorization = &v0alpha0.AuthorizationRule{
+					Type: v0alpha0.AuthorizationRuleTypeExternalAuth,
+					ExternalAuth: &gwapiv1.HTTPExternalAuthFilter{
+						ExternalAuthProtocol: gwapiv1.HTTPRouteExternalAuthGRPCProtocol,
+						BackendRef: gwapiv1.BackendObjectReference{
+							Name: "ext-auth-svc",
+						},
+					},
+				}
+			},
+			wantErrors: []string{"rules with action 'Deny' cannot specify 'ExternalAuth' authorization type"},
+		},
+		{
+			desc: "audit mode",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Mode = v0alpha0.AccessPolicyModeAudit
+			},
+		},
+		{
+			desc: "invalid mode",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Mode = "DryRun"
+			},
+			wantErrors: []string{`spec.mode: Unsupported value: "DryRun"`},
+		},
+		{
+			desc: "audit mode with ExternalAuth authorization",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Mode = v0alpha0.AccessPolicyModeAudit
+				p.Spec.Rules[0].Authorization = &v0alpha0.AuthorizationRule{
+					Type: v0alpha0.AuthorizationRuleTypeExternalAuth,
+					ExternalAuth: &gwapiv1.HTTPExternalAuthFilter{
+						ExternalAuthProtocol: gwapiv1.HTTPRouteExternalAuthGRPCProtocol,
+						BackendRef: gwapiv1.BackendObjectReference{
+							Name: "ext-auth-svc",
+						},
+					},
+				}
+			},
+			wantErrors: []string{"rules of an AccessPolicy in 'Audit' mode cannot specify 'ExternalAuth' authorization type"},
+		},
+		{
+			desc: "valid namespace sources",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				ns := gwapiv1.Namespace("team-a")
+				p.Spec.Rules = append(p.Spec.Rules,
+					v0alpha0.AccessRule{
+						Name:   "rule-2",
+						Source: &v0alpha0.Source{Type: v0alpha0.AuthorizationSourceTypeNamespace, Namespace: &ns},
+					},
+					v0alpha0.AccessRule{
+						Name: "rule-3",
+						Source: &v0alpha0.Source{
+							Type:              v0alpha0.AuthorizationSourceTypeNamespaceSelector,
+							NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "agents"}},
+						},
+					},
+				)
+			},
+		},
+		{
+			desc: "missing namespace for Namespace type",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Source = &v0alpha0.Source{Type: v0alpha0.AuthorizationSourceTypeNamespace}
+			},
+			wantErrors: []string{"namespace must be specified when type is set to 'Namespace'"},
+		},
+		{
+			desc: "namespace with ServiceAccount type",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				ns := gwapiv1.Namespace("team-a")
+				p.Spec.Rules[0].Source.Namespace = &ns
+			},
+			wantErrors: []string{"namespace can only be specified when type is set to 'Namespace'"},
+		},
+		{
+			desc: "missing namespaceSelector for NamespaceSelector type",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Source = &v0alpha0.Source{Type: v0alpha0.AuthorizationSourceTypeNamespaceSelector}
+			},
+			wantErrors: []string{"namespaceSelector must be specified when type is set to 'NamespaceSelector'"},
+		},
+		{
+			desc: "namespaceSelector with ServiceAccount type",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				p.Spec.Rules[0].Source.NamespaceSelector = &metav1.LabelSelector{}
+			},
+			wantErrors: []string{"namespaceSelector can only be specified when type is set to 'NamespaceSelector'"},
+		},
+		{
+			desc: "SPIFFE ID wildcard",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				spiffe := v0alpha0.AuthorizationSourceSPIFFE("spiffe://cluster.local/ns/team-a/*")
+				p.Spec.Rules[0].Source = &v0alpha0.Source{Type: v0alpha0.AuthorizationSourceTypeSPIFFE, SPIFFE: &spiffe}
+			},
+		},
+		{
+			desc: "SPIFFE ID with a wildcard in the middle",
+			mutate: func(p *v0alpha0.XAccessPolicy) {
+				spiffe := v0alpha0.AuthorizationSourceSPIFFE("spiffe://cluster.local/ns/*/sa/agent")
+				p.Spec.Rules[0].Source = &v0alpha0.Source{Type: v0alpha0.AuthorizationSourceTypeSPIFFE, SPIFFE: &spiffe}
+			},
+			wantErrors: []string{"spec.rules[0].source.spiffe in body should match"},
 		},
 	}
 	for _, tc := range testCases {
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gwapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	"sigs.k8s.io/kube-agentic-networking/api/v0alpha0"
 )
@@ -87,6 +88,226 @@ func TestValidateXBackend(t *testing.T) {
 			},
 			wantErrors: []string{"spec.mcp.port in body should be less than or equal to 65535"},
 		},
+		{
+			desc: "valid TLS configuration",
+			mutate: func(b *v0alpha0.XBackend) {
+				sni := gwapiv1.PreciseHostname("mcp.example.com")
+				b.Spec.MCP.TLS = &v0alpha0.BackendTLS{
+					CACertificateRefs: []gwapiv1.LocalObjectReference{
+						{Group: "", Kind: "ConfigMap", Name: "ca"},
+						{Group: "", Kind: "Secret", Name: "other-ca"},
+					},
+					ClientCertificateRef: &gwapiv1.LocalObjectReference{Group: "", Kind: "Secret", Name: "client"},
+					SNI:                  &sni,
+				}
+			},
+		},
+		{
+			desc: "valid plaintext mode",
+			mutate: func(b *v0alpha0.XBackend) {
+				b.Spec.MCP.TLS = &v0alpha0.BackendTLS{Mode: v0alpha0.BackendTLSModePlaintext}
+			},
+		},
+		{
+			desc: "invalid plaintext mode with a CA certificate",
+			mutate: func(b *v0alpha0.XBackend) {
+				b.Spec.MCP.TLS = &v0alpha0.BackendTLS{
+					Mode:              v0alpha0.BackendTLSModePlaintext,
+					CACertificateRefs: []gwapiv1.LocalObjectReference{{Group: "", Kind: "ConfigMap", Name: "ca"}},
+				}
+			},
+			wantErrors: []string{"caCertificateRefs, clientCertificateRef and sni cannot be specified when mode is 'Plaintext'"},
+		},
+		{
+			desc: "valid SPIFFE mode",
+			mutate: func(b *v0alpha0.XBackend) {
+				b.Spec.MCP.TLS = &v0alpha0.BackendTLS{
+					Mode:            v0alpha0.BackendTLSModeSPIFFE,
+					ServerSPIFFEIDs: []v0alpha0.AuthorizationSourceSPIFFE{"spiffe://cluster.local/ns/default/sa/mcp"},
+				}
+			},
+		},
+		{
+			desc: "invalid SPIFFE mode without server SPIFFE IDs",
+			mutate: func(b *v0alpha0.XBackend) {
+				b.Spec.MCP.TLS = &v0alpha0.BackendTLS{Mode: v0alpha0.BackendTLSModeSPIFFE}
+			},
+			wantErrors: []string{"serverSPIFFEIDs must be specified if and only if mode is 'SPIFFE'"},
+		},
+		{
+			desc: "invalid server SPIFFE IDs in TLS mode",
+			mutate: func(b *v0alpha0.XBackend) {
+				b.Spec.MCP.TLS = &v0alpha0.BackendTLS{
+					ServerSPIFFEIDs: []v0alpha0.AuthorizationSourceSPIFFE{"spiffe://cluster.local/ns/default/sa/mcp"},
+				}
+			},
+			wantErrors: []string{"serverSPIFFEIDs must be specified if and only if mode is 'SPIFFE'"},
+		},
+		{
+			desc: "invalid SPIFFE mode with a client certificate",
+			mutate: func(b *v0alpha0.XBackend) {
+				b.Spec.MCP.TLS = &v0alpha0.BackendTLS{
+					Mode:                 v0alpha0.BackendTLSModeSPIFFE,
+					ServerSPIFFEIDs:      []v0alpha0.AuthorizationSourceSPIFFE{"spiffe://cluster.local/ns/default/sa/mcp"},
+					ClientCertificateRef: &gwapiv1.LocalObjectReference{Group: "", Kind: "Secret", Name: "client"},
+				}
+			},
+			wantErrors: []string{"caCertificateRefs and clientCertificateRef cannot be specified when mode is 'SPIFFE'"},
+		},
+		{
+			desc: "invalid SPIFFE mode with a hostname",
+			mutate: func(b *v0alpha0.XBackend) {
+				b.Spec.MCP.ServiceName = nil
+				b.Spec.MCP.Hostname = ptrTo("example.com")
+				b.Spec.MCP.TLS = &v0alpha0.BackendTLS{
+					Mode:            v0alpha0.BackendTLSModeSPIFFE,
+					ServerSPIFFEIDs: []v0alpha0.AuthorizationSourceSPIFFE{"spiffe://cluster.local/ns/default/sa/mcp"},
+				}
+			},
+			wantErrors: []string{"tls mode 'SPIFFE' can only be specified with serviceName"},
+		},
+		{
+			desc: "invalid CA certificate kind",
+			mutate: func(b *v0alpha0.XBackend) {
+				b.Spec.MCP.TLS = &v0alpha0.BackendTLS{
+					CACertificateRefs: []gwapiv1.LocalObjectReference{{Group: "", Kind: "Service", Name: "ca"}},
+				}
+			},
+			wantErrors: []string{"caCertificateRefs must reference ConfigMaps or Secrets"},
+		},
+		{
+			desc: "invalid client certificate kind",
+			mutate: func(b *v0alpha0.XBackend) {
+				b.Spec.MCP.TLS = &v0alpha0.BackendTLS{
+					ClientCertificateRef: &gwapiv1.LocalObjectReference{Group: "", Kind: "ConfigMap", Name: "client"},
+				}
+			},
+			wantErrors: []string{"clientCertificateRef must reference a Secret"},
+		},
+		{
+			desc: "valid credential injection",
+			mutate: func(b *v0alpha0.XBackend) {
+				b.Spec.MCP.CredentialInjection = &v0alpha0.BackendCredentialInjection{
+					SecretRef: gwapiv1.LocalObjectReference{Group: "", Kind: "Secret", Name: "api-key"},
+					Key:       "token",
+					Header:    "X-API-Key",
+				}
+			},
+		},
+		{
+			desc: "invalid credential kind",
+			mutate: func(b *v0alpha0.XBackend) {
+				b.Spec.MCP.CredentialInjection = &v0alpha0.BackendCredentialInjection{
+					SecretRef: gwapiv1.LocalObjectReference{Group: "", Kind: "ConfigMap", Name: "api-key"},
+				}
+			},
+			wantErrors: []string{"secretRef must reference a Secret"},
+		},
+		{
+			desc: "valid token exchange of the agent's bearer token",
+			mutate: func(b *v0alpha0.XBackend) {
+				b.Spec.MCP.TokenExchange = &v0alpha0.BackendTokenExchange{
+					TokenEndpoint: "https://sts.example.com/token",
+					Audience:      ptrTo("github"),
+					Scopes:        []string{"repo"},
+				}
+			},
+		},
+		{
+			desc: "valid token exchange of the agent's SPIFFE ID",
+			mutate: func(b *v0alpha0.XBackend) {
+				b.Spec.MCP.TokenExchange = &v0alpha0.BackendTokenExchange{
+					TokenEndpoint:    "https://sts.example.com/token",
+					SubjectToken:     v0alpha0.TokenExchangeSubjectTokenSPIFFE,
+					SubjectTokenType: ptrTo("urn:example:token-type:spiffe-id"),
+					ClientSecretRef:  &gwapiv1.LocalObjectReference{Group: "", Kind: "Secret", Name: "sts-client"},
+				}
+			},
+		},
+		{
+			desc: "token exchange of the agent's SPIFFE ID without client credentials",
+			mutate: func(b *v0alpha0.XBackend) {
+				b.Spec.MCP.TokenExchange = &v0alpha0.BackendTokenExchange{
+					TokenEndpoint:    "https://sts.example.com/token",
+					SubjectToken:     v0alpha0.TokenExchangeSubjectTokenSPIFFE,
+					SubjectTokenType: ptrTo("urn:example:token-type:spiffe-id"),
+				}
+			},
+			wantErrors: []string{"subjectTokenType and clientSecretRef must be specified when subjectToken is 'SPIFFE'"},
+		},
+		{
+			desc: "token exchange with an invalid token endpoint",
+			mutate: func(b *v0alpha0.XBackend) {
+				b.Spec.MCP.TokenExchange = &v0alpha0.BackendTokenExchange{TokenEndpoint: "sts.example.com/token"}
+			},
+			wantErrors: []string{"spec.mcp.tokenExchange.tokenEndpoint in body should match"},
+		},
+		{
+			desc: "credential injection and token exchange",
+			mutate: func(b *v0alpha0.XBackend) {
+				b.Spec.MCP.CredentialInjection = &v0alpha0.BackendCredentialInjection{
+					SecretRef: gwapiv1.LocalObjectReference{Group: "", Kind: "Secret", Name: "api-key"},
+				}
+				b.Spec.MCP.TokenExchange = &v0alpha0.BackendTokenExchange{TokenEndpoint: "https://sts.example.com/token"}
+			},
+			wantErrors: []string{"credentialInjection and tokenExchange cannot be specified at the same time"},
+		},
+		{
+			desc: "MCP ping health check",
+			mutate: func(b *v0alpha0.XBackend) {
+				b.Spec.MCP.HealthCheck = &v0alpha0.BackendHealthCheck{Interval: ptrTo(gwapiv1.Duration("5s"))}
+			},
+		},
+		{
+			desc: "HTTP health check",
+			mutate: func(b *v0alpha0.XBackend) {
+				b.Spec.MCP.HealthCheck = &v0alpha0.BackendHealthCheck{
+					Type: v0alpha0.BackendHealthCheckTypeHTTP,
+					Path: ptrTo("/healthz"),
+				}
+			},
+		},
+		{
+			desc: "HTTP health check without path",
+			mutate: func(b *v0alpha0.XBackend) {
+				b.Spec.MCP.HealthCheck = &v0alpha0.BackendHealthCheck{Type: v0alpha0.BackendHealthCheckTypeHTTP}
+			},
+			wantErrors: []string{"path must be specified if and only if type is 'HTTP'"},
+		},
+		{
+			desc: "MCP ping health check with path",
+			mutate: func(b *v0alpha0.XBackend) {
+				b.Spec.MCP.HealthCheck = &v0alpha0.BackendHealthCheck{
+					Type: v0alpha0.BackendHealthCheckTypeMCPPing,
+					Path: ptrTo("/healthz"),
+				}
+			},
+			wantErrors: []string{"path must be specified if and only if type is 'HTTP'"},
+		},
+		{
+			desc: "health check with an invalid interval",
+			mutate: func(b *v0alpha0.XBackend) {
+				b.Spec.MCP.HealthCheck = &v0alpha0.BackendHealthCheck{Interval: ptrTo(gwapiv1.Duration("5 seconds"))}
+			},
+			wantErrors: []string{"spec.mcp.healthCheck.interval in body should match"},
+		},
+		{
+			desc: "outlier detection",
+			mutate: func(b *v0alpha0.XBackend) {
+				b.Spec.MCP.OutlierDetection = &v0alpha0.BackendOutlierDetection{
+					ConsecutiveGatewayErrors: ptrTo(int32(3)),
+					BaseEjectionTime:         ptrTo(gwapiv1.Duration("1m")),
+					MaxEjectionPercent:       ptrTo(int32(100)),
+				}
+			},
+		},
+		{
+			desc: "outlier detection ejecting more than all the endpoints",
+			mutate: func(b *v0alpha0.XBackend) {
+				b.Spec.MCP.OutlierDetection = &v0alpha0.BackendOutlierDetection{MaxEjectionPercent: ptrTo(int32(101))}
+			},
+			wantErrors: []string{"spec.mcp.outlierDetection.maxEjectionPercent in body should be less than or equal to 100"},
+		},
 	}
 
 	for _, tc := range testCases {